@@ -2,6 +2,8 @@ package main
 
 import (
 	"context"
+	"database/sql"
+	"flag"
 	"log"
 	"net/http"
 	"os"
@@ -12,8 +14,48 @@ import (
 	_ "github.com/mattn/go-sqlite3"
 
 	"julo/internal/account"
+	"julo/internal/audit"
 	"julo/internal/auth"
 	authhttp "julo/internal/auth/http"
+	"julo/internal/balance"
+	balancehttp "julo/internal/balance/http"
+	"julo/internal/closure"
+	closurehttp "julo/internal/closure/http"
+	"julo/internal/credit"
+	credithttp "julo/internal/credit/http"
+	"julo/internal/disbursement"
+	"julo/internal/envelope"
+	"julo/internal/fee"
+	"julo/internal/interest"
+	interesthttp "julo/internal/interest/http"
+	"julo/internal/kyc"
+	kychttp "julo/internal/kyc/http"
+	"julo/internal/loan"
+	loanhttp "julo/internal/loan/http"
+	"julo/internal/merchant"
+	merchanthttp "julo/internal/merchant/http"
+	"julo/internal/payment"
+	"julo/internal/payrequest"
+	payrequesthttp "julo/internal/payrequest/http"
+	"julo/internal/pocket"
+	pockethttp "julo/internal/pocket/http"
+	"julo/internal/privacy"
+	privacyhttp "julo/internal/privacy/http"
+	"julo/internal/promotion"
+	promotionhttp "julo/internal/promotion/http"
+	"julo/internal/qr"
+	qrhttp "julo/internal/qr/http"
+	"julo/internal/risk"
+	"julo/internal/schedule"
+	schedulehttp "julo/internal/schedule/http"
+	"julo/internal/statement"
+	statementhttp "julo/internal/statement/http"
+	"julo/internal/stream"
+	streamhttp "julo/internal/stream/http"
+	"julo/internal/topup"
+	topuphttp "julo/internal/topup/http"
+	"julo/internal/voucher"
+	voucherhttp "julo/internal/voucher/http"
 	"julo/internal/wallet"
 	wallethttp "julo/internal/wallet/http"
 
@@ -21,14 +63,124 @@ import (
 )
 
 func main() {
+	payoutLatency := flag.Duration("payout-latency", 2*time.Second, "time the simulated bank takes to complete a payout")
+	payoutFailureRate := flag.Float64("payout-failure-rate", 0, "probability, between 0 and 1, of the simulated bank rejecting a payout")
+	topupBankCode := flag.String("topup-bank-code", "SIMBANK", "code of the bank issuing virtual accounts")
+	topupPrefix := flag.String("topup-prefix", "8808", "company prefix of virtual account numbers")
+	topupSecret := flag.String("topup-secret", os.Getenv("TOPUP_SECRET"), "secret shared with the bank to sign payment notifications")
+	feeRules := flag.String("fee-rules", "", "JSON file with the fee rules, movements are free without it")
+	interestConfig := flag.String("interest-config", "", "JSON file with the interest tiers, balances earn nothing without it")
+	riskConfig := flag.String("risk-config", "", "JSON file with the risk rules, movements are not screened without it")
+	kycDir := flag.String("kyc-dir", "data/kyc", "directory KYC documents are stored in")
+	dbPath := flag.String("db", "", "SQLite database accounts are kept in, they are kept in memory without it")
+	keyringPath := flag.String("keyring", "", "JSON keyring encrypting personal details of accounts, read from "+envelope.KeyringEnv+" when not given")
+	houseXID := flag.String("house-xid", account.SystemXIDPrefix+"house", "owner of the wallet collecting fees, it must start with "+account.SystemXIDPrefix)
+	adminToken := flag.String("admin-token", os.Getenv("ADMIN_TOKEN"), "token operators present to reach the admin endpoints")
+	flag.Parse()
+
+	// a customer could otherwise init as the house and spend the fees
+	if !account.IsReservedXID(*houseXID) {
+		log.Fatalf("house xid %q must start with %s", *houseXID, account.SystemXIDPrefix)
+	}
+	// notifications cannot be trusted without a secret shared with the bank
+	if *topupSecret == "" {
+		log.Println("no topup secret configured, top-up notifications are disabled")
+	}
+	if *adminToken == "" {
+		log.Println("no admin token configured, admin endpoints are disabled")
+	}
+
 	router := chi.NewRouter()
 
-	accounts := account.NewService(account.NewInMemoryRepository())
+	accountRepo, err := newAccountRepository(*dbPath, *keyringPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	accounts := account.NewService(accountRepo)
 	initializer := auth.NewInitializer(accounts)
-	wallets := wallet.NewService(wallet.NewInMemoryRepository())
+	kycs := kyc.NewService(kyc.NewInMemoryRepository(), accounts, kyc.DiskStore{Dir: *kycDir}, kyc.Config{
+		Limits: map[account.KYCStatus]wallet.Limits{
+			account.KYCStatusUnverified: {MaxBalance: 2000000, BlockWithdrawals: true},
+			account.KYCStatusBasic:      {MaxBalance: 10000000, MaxTransaction: 5000000},
+			account.KYCStatusFull:       {MaxBalance: 20000000},
+		},
+	})
+	payouts := disbursement.NewSimulator(disbursement.SimulatorConfig{
+		Latency:     *payoutLatency,
+		FailureRate: *payoutFailureRate,
+	})
+	fees, err := fee.NewEngine(nil)
+	if *feeRules != "" {
+		fees, err = fee.LoadEngine(*feeRules)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	interestRules := &interest.Config{Tiers: []interest.Tier{{}}}
+	if *interestConfig != "" {
+		interestRules, err = interest.LoadConfig(*interestConfig)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	interestRules.Exclude = append(interestRules.Exclude, *houseXID)
+	riskRules := &risk.Config{}
+	if *riskConfig != "" {
+		riskRules, err = risk.LoadConfig(*riskConfig)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	risks, err := risk.NewEngine(*riskRules)
+	if err != nil {
+		log.Fatal(err)
+	}
+	wallets := wallet.NewService(wallet.NewInMemoryRepository(),
+		wallet.WithDisbursementProvider(payouts),
+		wallet.WithFees(fees, *houseXID),
+		wallet.WithRiskAssessor(risks),
+		wallet.WithLimits(kycs),
+	)
+	payouts.OnCallback(payment.DisbursementCallback(payment.NewCallbackProcessor(wallets)))
+	topups := topup.NewService(topup.NewInMemoryRepository(), wallets, *topupBankCode, *topupPrefix)
+	schedules := schedule.NewService(schedule.NewInMemoryRepository(), wallets, schedule.LogNotifier{}, schedule.Config{
+		MaxAttempts: 3,
+		RetryDelay:  time.Hour,
+	})
+	loans := loan.NewService(loan.NewInMemoryRepository(), wallets)
+	payRequests := payrequest.NewService(payrequest.NewInMemoryRepository(), wallets)
+	pockets := pocket.NewService(pocket.NewInMemoryRepository(), wallets)
+	interests := interest.NewService(interest.NewInMemoryRepository(), wallets, *interestRules)
+	codes := qr.NewService(wallets)
+	statements := statement.NewService(wallets)
+	balances := balance.NewService(balance.NewInMemoryRepository(), wallets)
+	vouchers := voucher.NewService(voucher.NewInMemoryRepository(), wallets)
+	promotions := promotion.NewService(promotion.NewInMemoryRepository(), wallets)
+	wallets.OnTransactionCompleted(promotion.TransactionListener(promotions))
+	streams := stream.NewBroker(100)
+	wallets.OnTransactionChanged(stream.TransactionListener(streams, wallets))
+	merchants := merchant.NewService(merchant.NewInMemoryRepository(), wallets, merchant.HTTPNotifier{})
+	audits := audit.NewService(audit.NewInMemoryRepository())
+	closures := closure.NewService(accounts, wallets, pockets, loans, schedules, payRequests, audits)
+	privacies := privacy.NewService(accounts, wallets, kycs, audits)
+	credits := credit.NewService(credit.NewInMemoryRepository(), wallets, credit.Config{
+		GracePeriod: 14 * 24 * time.Hour,
+	})
+
+	workers, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+	go schedule.NewWorker(schedules, time.Minute).Run(workers)
+	go loan.NewWorker(loans, 24*time.Hour).Run(workers)
+	go credit.NewWorker(credits, time.Hour).Run(workers)
+	go interest.NewWorker(interests, time.Hour).Run(workers)
+	go balance.NewWorker(balances, time.Hour).Run(workers)
+	go wallet.NewPayoutWorker(wallets, 5*time.Minute, 10*time.Minute).Run(workers)
 
 	router.Mount("/api/v1", router.Group(func(r chi.Router) {
 		r.Post("/init", authhttp.InitHandler(initializer).ServeHTTP)
+		if *topupSecret != "" {
+			r.Post("/topups/notifications", topuphttp.NotificationHandler(topups, *topupSecret).ServeHTTP)
+		}
 		r.Mount("/wallet", r.Group(func(r chi.Router) {
 			r.Use(authhttp.Middleware)
 			r.Get("/", wallethttp.ViewWalletBalanceHandler(wallets).ServeHTTP)
@@ -37,13 +189,91 @@ func main() {
 			r.Post("/deposits", wallethttp.DepositWalletHandler(wallets).ServeHTTP)
 			r.Post("/withdrawals", wallethttp.WithdrawWalletHandler(wallets).ServeHTTP)
 			r.Get("/transactions", wallethttp.ViewWalletTransactionsHandler(wallets).ServeHTTP)
+			r.Get("/statements", statementhttp.ViewStatementHandler(statements).ServeHTTP)
+			r.Get("/balance", balancehttp.ViewBalanceHandler(balances).ServeHTTP)
+			r.Get("/stream", streamhttp.StreamHandler(streams, wallets, 15*time.Second).ServeHTTP)
+			r.Get("/virtual-account", topuphttp.VirtualAccountHandler(wallets, topups).ServeHTTP)
+			r.Get("/schedules", schedulehttp.ViewSchedulesHandler(schedules).ServeHTTP)
+			r.Post("/schedules", schedulehttp.CreateScheduleHandler(schedules).ServeHTTP)
+			r.Post("/schedules/{id}/pause", schedulehttp.PauseScheduleHandler(schedules).ServeHTTP)
+			r.Post("/schedules/{id}/resume", schedulehttp.ResumeScheduleHandler(schedules).ServeHTTP)
+			r.Delete("/schedules/{id}", schedulehttp.CancelScheduleHandler(schedules).ServeHTTP)
+			r.Get("/credit", credithttp.ViewCreditLineHandler(credits, wallets).ServeHTTP)
+			r.Get("/credit/statements", credithttp.ViewStatementsHandler(credits).ServeHTTP)
+			r.Get("/interest", interesthttp.ViewInterestHandler(interests).ServeHTTP)
+			r.Get("/pockets", pockethttp.ViewPocketsHandler(pockets).ServeHTTP)
+			r.Post("/pockets", pockethttp.CreatePocketHandler(pockets).ServeHTTP)
+			r.Post("/pockets/{id}/deposits", pockethttp.MoveToPocketHandler(pockets).ServeHTTP)
+			r.Post("/pockets/{id}/withdrawals", pockethttp.MoveFromPocketHandler(pockets).ServeHTTP)
+			r.Delete("/pockets/{id}", pockethttp.ClosePocketHandler(pockets).ServeHTTP)
+			r.Get("/payment-requests", payrequesthttp.ViewRequestsHandler(payRequests).ServeHTTP)
+			r.Post("/payment-requests", payrequesthttp.CreateRequestHandler(payRequests).ServeHTTP)
+			r.Get("/payment-requests/{id}", payrequesthttp.ViewRequestHandler(payRequests).ServeHTTP)
+			r.Post("/payment-requests/{id}/pay", payrequesthttp.PayRequestHandler(payRequests).ServeHTTP)
+			r.Post("/payment-requests/{id}/decline", payrequesthttp.DeclineRequestHandler(payRequests).ServeHTTP)
+			r.Post("/vouchers/redeem", voucherhttp.RedeemVoucherHandler(vouchers).ServeHTTP)
+			r.Get("/rewards", promotionhttp.ViewRewardsHandler(promotions).ServeHTTP)
+			r.Get("/qr", qrhttp.ViewQRHandler(codes).ServeHTTP)
+			r.Get("/qr.png", qrhttp.ViewQRImageHandler(codes).ServeHTTP)
+			r.Post("/qr/payments", qrhttp.PayQRHandler(codes).ServeHTTP)
+			r.Get("/checkout/{id}", merchanthttp.ViewCheckoutHandler(merchants).ServeHTTP)
+			r.Get("/kyc", kychttp.ViewStatusHandler(kycs).ServeHTTP)
+			r.Post("/kyc", kychttp.SubmitHandler(kycs).ServeHTTP)
+			r.Post("/checkout/{id}/pay", merchanthttp.PayCheckoutHandler(merchants).ServeHTTP)
 		}))
+		r.Mount("/account", r.Group(func(r chi.Router) {
+			r.Use(authhttp.Middleware)
+			r.Post("/close", closurehttp.CloseAccountHandler(closures).ServeHTTP)
+			r.Get("/export", privacyhttp.ExportHandler(privacies).ServeHTTP)
+		}))
+		r.Mount("/loans", r.Group(func(r chi.Router) {
+			r.Use(authhttp.Middleware)
+			r.Get("/", loanhttp.ViewLoansHandler(loans).ServeHTTP)
+			r.Get("/{id}", loanhttp.ViewLoanHandler(loans).ServeHTTP)
+			r.Post("/{id}/repayments", loanhttp.RepayLoanHandler(loans).ServeHTTP)
+		}))
+		r.Mount("/merchant", r.Group(func(r chi.Router) {
+			r.Use(merchanthttp.Middleware(merchants))
+			r.Post("/orders", merchanthttp.CreateOrderHandler(merchants).ServeHTTP)
+			r.Get("/orders/{id}", merchanthttp.ViewOrderHandler(merchants).ServeHTTP)
+			r.Post("/orders/{id}/refunds", merchanthttp.RefundOrderHandler(merchants).ServeHTTP)
+			r.Get("/settlements", merchanthttp.ViewSettlementHandler(merchants).ServeHTTP)
+		}))
+		if *adminToken != "" {
+			r.Mount("/admin", r.Group(func(r chi.Router) {
+				r.Use(authhttp.AdminMiddleware(*adminToken))
+				r.Post("/loans", loanhttp.CreateLoanHandler(loans).ServeHTTP)
+				r.Get("/loans/collections/preview", loanhttp.PreviewCollectionsHandler(loans).ServeHTTP)
+				r.Post("/credit-lines", credithttp.OpenCreditLineHandler(credits, wallets).ServeHTTP)
+				r.Post("/merchants", merchanthttp.RegisterMerchantHandler(merchants).ServeHTTP)
+				r.Get("/campaigns", promotionhttp.ViewCampaignsHandler(promotions).ServeHTTP)
+				r.Post("/campaigns", promotionhttp.CreateCampaignHandler(promotions).ServeHTTP)
+				r.Post("/vouchers", voucherhttp.GenerateVouchersHandler(vouchers).ServeHTTP)
+				r.Get("/kyc/submissions", kychttp.ViewPendingSubmissionsHandler(kycs).ServeHTTP)
+				r.Post("/kyc/submissions/{id}/approve", kychttp.ApproveSubmissionHandler(kycs).ServeHTTP)
+				r.Post("/kyc/submissions/{id}/reject", kychttp.RejectSubmissionHandler(kycs).ServeHTTP)
+				r.Post("/accounts/{xid}/reopen", closurehttp.ReopenAccountHandler(closures).ServeHTTP)
+				r.Get("/accounts/{xid}/export", privacyhttp.ExportAccountHandler(privacies).ServeHTTP)
+				r.Post("/accounts/{xid}/erase", privacyhttp.EraseAccountHandler(privacies).ServeHTTP)
+				r.Post("/wallets/{xid}/freeze", wallethttp.FreezeWalletHandler(wallets).ServeHTTP)
+				r.Post("/wallets/{xid}/unfreeze", wallethttp.UnfreezeWalletHandler(wallets).ServeHTTP)
+				r.Post("/wallets/{xid}/close", wallethttp.CloseWalletHandler(wallets).ServeHTTP)
+				r.Get("/reviews", wallethttp.ViewReviewQueueHandler(wallets).ServeHTTP)
+				r.Post("/reviews/{id}/approve", wallethttp.ApproveReviewHandler(wallets).ServeHTTP)
+				r.Post("/reviews/{id}/reject", wallethttp.RejectReviewHandler(wallets).ServeHTTP)
+				r.Get("/topups/parked", topuphttp.ViewParkedPaymentsHandler(topups).ServeHTTP)
+				r.Post("/topups/{bank_code}/{reference}/retry", topuphttp.RetryPaymentHandler(topups).ServeHTTP)
+			}))
+		}
 	}))
 
 	server := http.Server{
 		Addr:    ":8080",
 		Handler: router,
 	}
+	// open streams never go idle, they are ended for Shutdown not to wait
+	// on them
+	server.RegisterOnShutdown(streams.Close)
 
 	go func() {
 		// service connections
@@ -59,14 +289,47 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 	log.Println("Shutting down Server ...")
+	stopWorkers()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 	if err := server.Shutdown(ctx); err != nil {
 		log.Fatal("Server Shutdown:", err)
 	}
+	payouts.Close()
 	// catching ctx.Done(). timeout of 5 seconds.
 	<-ctx.Done()
 	log.Println("timeout of 5 seconds.")
 	log.Println("Server exiting")
 }
+
+// newAccountRepository keeps accounts in memory, or in the SQLite database
+// at dbPath. Personal details are encrypted whenever a keyring is
+// configured, which a database requires.
+func newAccountRepository(dbPath string, keyringPath string) (account.Repository, error) {
+	if dbPath == "" && keyringPath == "" && os.Getenv(envelope.KeyringEnv) == "" {
+		return account.NewInMemoryRepository(), nil
+	}
+
+	keys, err := envelope.LoadKeyring(keyringPath)
+	if err != nil {
+		return nil, err
+	}
+	sealer, err := envelope.NewSealer(*keys)
+	if err != nil {
+		return nil, err
+	}
+
+	store := account.NewInMemoryRecordStore()
+	if dbPath != "" {
+		db, err := sql.Open("sqlite3", dbPath)
+		if err != nil {
+			return nil, err
+		}
+		store, err = account.NewSQLiteRecordStore(db)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return account.NewEncryptedRepository(store, sealer), nil
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"time"
+
+	"julo/internal/topup"
+)
+
+// banksim pays into a virtual account the way the bank would, by sending a
+// signed payment notification to the api.
+func main() {
+	url := flag.String("url", "http://localhost:8080/api/v1/topups/notifications", "payment notification endpoint")
+	secret := flag.String("secret", os.Getenv("TOPUP_SECRET"), "secret shared with the api to sign notifications")
+	bankCode := flag.String("bank-code", "SIMBANK", "code of the paying bank")
+	number := flag.String("va", "", "virtual account number to pay into")
+	amount := flag.Int("amount", 0, "amount to pay")
+	flag.Parse()
+
+	if *number == "" || *amount <= 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	bank := topup.BankSimulator{
+		URL:      *url,
+		Secret:   *secret,
+		BankCode: *bankCode,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	n, err := bank.Pay(ctx, *number, *amount)
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("paid %d into %s with reference %s", n.Amount, n.VirtualAccountNumber, n.BankReference)
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+// export downloads the data export of a customer through the admin api and
+// saves the ZIP archive to a file.
+func main() {
+	api := flag.String("api", "http://localhost:8080/api/v1", "base url of the api")
+	adminToken := flag.String("admin-token", os.Getenv("ADMIN_TOKEN"), "token of the admin api")
+	actor := flag.String("actor", os.Getenv("USER"), "operator carrying out the export, recorded in the audit trail")
+	xid := flag.String("xid", "", "customer xid to export")
+	out := flag.String("out", "", "file to write the archive to, <xid>-export.zip by default")
+	flag.Parse()
+
+	if *xid == "" || *adminToken == "" || *actor == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *out == "" {
+		*out = *xid + "-export.zip"
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	endpoint := fmt.Sprintf("%s/admin/accounts/%s/export?actor=%s", *api, url.PathEscape(*xid), url.QueryEscape(*actor))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Token "+*adminToken)
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		log.Fatalf("export failed with status %d: %s", res.StatusCode, body)
+	}
+
+	f, err := os.OpenFile(*out, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		log.Fatal(err)
+	}
+	n, err := io.Copy(f, res.Body)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(*out)
+		log.Fatal(err)
+	}
+	log.Printf("wrote %d bytes of %s's data to %s", n, *xid, *out)
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"log"
+	"os"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"julo/internal/account"
+	"julo/internal/envelope"
+)
+
+// rotatekeys re-encrypts the personal details of every account sealed with a
+// master key other than the current one of the keyring. To rotate, add the
+// new key to the keyring and make it current, run rotatekeys, then drop the
+// old key.
+func main() {
+	dbPath := flag.String("db", "", "SQLite database the accounts are kept in")
+	keyringPath := flag.String("keyring", "", "JSON keyring holding the current and the old master keys, read from "+envelope.KeyringEnv+" when not given")
+	flag.Parse()
+
+	if *dbPath == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	keys, err := envelope.LoadKeyring(*keyringPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	sealer, err := envelope.NewSealer(*keys)
+	if err != nil {
+		log.Fatal(err)
+	}
+	db, err := sql.Open("sqlite3", *dbPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+	store, err := account.NewSQLiteRecordStore(db)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	rotated, err := account.NewEncryptedRepository(store, sealer).RotateKeys(context.Background())
+	if err != nil {
+		log.Fatalf("rotated %d accounts before failing: %s", rotated, err)
+	}
+	log.Printf("rotated %d accounts to key %s", rotated, keys.Current)
+}
@@ -1,5 +1,147 @@
 package account
 
+import (
+	"strings"
+	"time"
+)
+
+type KYCStatus string
+
+var (
+	KYCStatusUnverified = KYCStatus("unverified")
+	KYCStatusBasic      = KYCStatus("basic")
+	KYCStatusFull       = KYCStatus("full")
+)
+
+// Rank orders the statuses from unverified to full, unknown statuses rank
+// as unverified.
+func (s KYCStatus) Rank() int {
+	switch s {
+	case KYCStatusBasic:
+		return 1
+	case KYCStatusFull:
+		return 2
+	}
+	return 0
+}
+
+type Status string
+
+var (
+	StatusActive = Status("active")
+	StatusClosed = Status("closed")
+	// Reopened is a closed account an operator reopened, the next init of
+	// the customer makes it active again.
+	StatusReopened = Status("reopened")
+)
+
 type Account struct {
 	XID string
+	// KYCStatus is how far the identity of the customer was verified,
+	// accounts created before KYC are unverified.
+	KYCStatus KYCStatus
+	// Status is empty for accounts created before closure, they are active.
+	Status   Status
+	ClosedAt time.Time
+	// ErasedAt is when the personal data of the closed account was erased,
+	// such an account cannot be reopened.
+	ErasedAt time.Time
+	// Phone, Email and IDNumber are personal details of the customer, an
+	// EncryptedRepository keeps them encrypted at rest.
+	Phone    string
+	Email    string
+	IDNumber string
+}
+
+const (
+	// SystemXIDPrefix starts the xids of wallets the system owns, such as
+	// the house wallet collecting fees.
+	SystemXIDPrefix = "system:"
+	// MerchantXIDPrefix starts the xids of merchant settlement wallets.
+	MerchantXIDPrefix = "merchant:"
+)
+
+// reservedXIDPrefixes start xids no customer account may take, as they own
+// wallets that have no account behind them.
+var reservedXIDPrefixes = []string{SystemXIDPrefix, MerchantXIDPrefix}
+
+// IsReservedXID reports whether xid belongs to the system rather than a
+// customer.
+func IsReservedXID(xid string) bool {
+	for _, prefix := range reservedXIDPrefixes {
+		if strings.HasPrefix(xid, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Field is a personal detail accounts can be looked up by.
+type Field string
+
+var (
+	FieldPhone    = Field("phone")
+	FieldEmail    = Field("email")
+	FieldIDNumber = Field("id_number")
+)
+
+// Fields lists every personal detail of an account.
+var Fields = []Field{FieldPhone, FieldEmail, FieldIDNumber}
+
+// Normalize brings v to the form lookups by f match on, so an email in
+// another case or a phone number written with spaces still matches.
+func (f Field) Normalize(v string) string {
+	v = strings.TrimSpace(v)
+	switch f {
+	case FieldEmail:
+		return strings.ToLower(v)
+	case FieldPhone:
+		return strings.Map(func(r rune) rune {
+			if r == ' ' || r == '-' || r == '(' || r == ')' {
+				return -1
+			}
+			return r
+		}, v)
+	}
+	return v
+}
+
+// Value is the detail f of a.
+func (a Account) Value(f Field) string {
+	switch f {
+	case FieldPhone:
+		return a.Phone
+	case FieldEmail:
+		return a.Email
+	case FieldIDNumber:
+		return a.IDNumber
+	}
+	return ""
+}
+
+func (a *Account) setValue(f Field, v string) {
+	switch f {
+	case FieldPhone:
+		a.Phone = v
+	case FieldEmail:
+		a.Email = v
+	case FieldIDNumber:
+		a.IDNumber = v
+	}
+}
+
+func (a Account) IsActive() bool {
+	return a.Status == "" || a.Status == StatusActive
+}
+
+func (a Account) IsErased() bool {
+	return !a.ErasedAt.IsZero()
+}
+
+// Verification is the KYC status of a, unverified when it was never set.
+func (a Account) Verification() KYCStatus {
+	if a.KYCStatus == "" {
+		return KYCStatusUnverified
+	}
+	return a.KYCStatus
 }
@@ -0,0 +1,225 @@
+package account
+
+import (
+	"context"
+	"julo/internal/envelope"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Record is an account as an EncryptedRepository stores it, personal
+// details sealed and blind indexed by field.
+type Record struct {
+	XID       string
+	KYCStatus string
+	Status    string
+	ClosedAt  time.Time
+	ErasedAt  time.Time
+	// Sealed holds the sealed value of each personal detail set on the
+	// account, Index its blind index.
+	Sealed map[Field]string
+	Index  map[Field]string
+}
+
+// RecordStore persists account records, it never sees personal details in
+// plaintext.
+type RecordStore interface {
+	CreateRecord(c context.Context, r Record) error
+	UpdateRecord(c context.Context, r Record) error
+	GetRecord(c context.Context, xid string) (*Record, error)
+	// FindRecord gets the record whose blind index of field is index.
+	FindRecord(c context.Context, field Field, index string) (*Record, error)
+	GetRecords(c context.Context) ([]Record, error)
+}
+
+// EncryptedRepository is a Repository encrypting the personal details of
+// accounts before they reach the store.
+type EncryptedRepository struct {
+	store  RecordStore
+	sealer *envelope.Sealer
+}
+
+func NewEncryptedRepository(store RecordStore, sealer *envelope.Sealer) *EncryptedRepository {
+	return &EncryptedRepository{
+		store:  store,
+		sealer: sealer,
+	}
+}
+
+func (r *EncryptedRepository) CreateAccount(c context.Context, a Account) error {
+	rec, err := r.seal(a)
+	if err != nil {
+		return err
+	}
+	return r.store.CreateRecord(c, *rec)
+}
+
+func (r *EncryptedRepository) GetAccount(c context.Context, xid string) (*Account, error) {
+	rec, err := r.store.GetRecord(c, xid)
+	if err != nil {
+		return nil, err
+	}
+	return r.open(*rec)
+}
+
+func (r *EncryptedRepository) UpdateAccount(c context.Context, a Account) error {
+	rec, err := r.seal(a)
+	if err != nil {
+		return err
+	}
+	return r.store.UpdateRecord(c, *rec)
+}
+
+func (r *EncryptedRepository) FindAccount(c context.Context, field Field, value string) (*Account, error) {
+	rec, err := r.store.FindRecord(c, field, r.sealer.BlindIndex(string(field), field.Normalize(value)))
+	if err != nil {
+		return nil, err
+	}
+	return r.open(*rec)
+}
+
+// RotateKeys seals again with the current master key every record holding
+// a value sealed with an older one, returning how many records it
+// rewrote. Older keys can leave the keyring once it ran.
+func (r *EncryptedRepository) RotateKeys(c context.Context) (int, error) {
+	records, err := r.store.GetRecords(c)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed getting account records")
+	}
+
+	var rotated int
+	for _, rec := range records {
+		current := true
+		for _, sealed := range rec.Sealed {
+			current = current && r.sealer.IsCurrent(sealed)
+		}
+		if current {
+			continue
+		}
+
+		a, err := r.open(rec)
+		if err != nil {
+			return rotated, err
+		}
+		err = r.UpdateAccount(c, *a)
+		if err != nil {
+			return rotated, errors.Wrapf(err, "failed rewriting account %s", rec.XID)
+		}
+		rotated++
+	}
+	return rotated, nil
+}
+
+// seal binds each sealed value to its account and field.
+func (r *EncryptedRepository) seal(a Account) (*Record, error) {
+	rec := Record{
+		XID:       a.XID,
+		KYCStatus: string(a.KYCStatus),
+		Status:    string(a.Status),
+		ClosedAt:  a.ClosedAt,
+		ErasedAt:  a.ErasedAt,
+		Sealed:    map[Field]string{},
+		Index:     map[Field]string{},
+	}
+	for _, f := range Fields {
+		v := a.Value(f)
+		if v == "" {
+			continue
+		}
+		sealed, err := r.sealer.Seal(v, a.XID+"/"+string(f))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed sealing %s", f)
+		}
+		rec.Sealed[f] = sealed
+		rec.Index[f] = r.sealer.BlindIndex(string(f), f.Normalize(v))
+	}
+	return &rec, nil
+}
+
+func (r *EncryptedRepository) open(rec Record) (*Account, error) {
+	a := Account{
+		XID:       rec.XID,
+		KYCStatus: KYCStatus(rec.KYCStatus),
+		Status:    Status(rec.Status),
+		ClosedAt:  rec.ClosedAt,
+		ErasedAt:  rec.ErasedAt,
+	}
+	for f, sealed := range rec.Sealed {
+		v, err := r.sealer.Open(sealed, rec.XID+"/"+string(f))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed opening %s of account %s", f, rec.XID)
+		}
+		a.setValue(f, v)
+	}
+	return &a, nil
+}
+
+type InMemoryRecordStore struct {
+	records sync.Map
+}
+
+func NewInMemoryRecordStore() RecordStore {
+	return &InMemoryRecordStore{}
+}
+
+func (s *InMemoryRecordStore) CreateRecord(c context.Context, r Record) error {
+	s.records.Store(r.XID, copyRecord(r))
+	return nil
+}
+
+func (s *InMemoryRecordStore) UpdateRecord(c context.Context, r Record) error {
+	s.records.Store(r.XID, copyRecord(r))
+	return nil
+}
+
+func (s *InMemoryRecordStore) GetRecord(c context.Context, xid string) (*Record, error) {
+	v, ok := s.records.Load(xid)
+	if !ok {
+		return nil, ErrAccountNotFound
+	}
+	r := copyRecord(v.(Record))
+	return &r, nil
+}
+
+func (s *InMemoryRecordStore) FindRecord(c context.Context, field Field, index string) (*Record, error) {
+	var found *Record
+	s.records.Range(func(key, v any) bool {
+		if r := v.(Record); r.Index[field] == index {
+			r = copyRecord(r)
+			found = &r
+			return false
+		}
+		return true
+	})
+	if found == nil {
+		return nil, ErrAccountNotFound
+	}
+	return found, nil
+}
+
+func (s *InMemoryRecordStore) GetRecords(c context.Context) ([]Record, error) {
+	records := []Record{}
+	s.records.Range(func(key, v any) bool {
+		records = append(records, copyRecord(v.(Record)))
+		return true
+	})
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].XID < records[j].XID
+	})
+	return records, nil
+}
+
+func copyRecord(r Record) Record {
+	sealed, index := make(map[Field]string, len(r.Sealed)), make(map[Field]string, len(r.Index))
+	for f, v := range r.Sealed {
+		sealed[f] = v
+	}
+	for f, v := range r.Index {
+		index[f] = v
+	}
+	r.Sealed, r.Index = sealed, index
+	return r
+}
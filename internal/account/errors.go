@@ -5,4 +5,8 @@ import "errors"
 var (
 	ErrAccountAlreadyExists = errors.New("account already exist")
 	ErrAccountNotFound      = errors.New("account not found")
+	ErrAccountClosed        = errors.New("account is closed")
+	ErrAccountNotClosed     = errors.New("account is not closed")
+	ErrAccountErased        = errors.New("account data was erased")
+	ErrReservedXID          = errors.New("customer xid is reserved")
 )
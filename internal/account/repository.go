@@ -8,6 +8,10 @@ import (
 type Repository interface {
 	CreateAccount(c context.Context, a Account) error
 	GetAccount(c context.Context, xid string) (*Account, error)
+	UpdateAccount(c context.Context, a Account) error
+	// FindAccount gets the account whose detail field matches value once
+	// normalized.
+	FindAccount(c context.Context, field Field, value string) (*Account, error)
 }
 
 type InMemoryRepository struct {
@@ -32,3 +36,25 @@ func (r *InMemoryRepository) GetAccount(c context.Context, xid string) (*Account
 	}
 	return v.(*Account), nil
 }
+
+func (r *InMemoryRepository) UpdateAccount(c context.Context, a Account) error {
+	r.store.Store(a.XID, &a)
+	return nil
+}
+
+func (r *InMemoryRepository) FindAccount(c context.Context, field Field, value string) (*Account, error) {
+	value = field.Normalize(value)
+	var found *Account
+	r.store.Range(func(key, v any) bool {
+		a := v.(*Account)
+		if value != "" && field.Normalize(a.Value(field)) == value {
+			found = a
+			return false
+		}
+		return true
+	})
+	if found == nil {
+		return nil, ErrAccountNotFound
+	}
+	return found, nil
+}
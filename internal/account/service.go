@@ -2,6 +2,8 @@ package account
 
 import (
 	"context"
+	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 )
@@ -9,10 +11,18 @@ import (
 type Service interface {
 	CreateAccount(context.Context, Account) error
 	GetAccount(c context.Context, xid string) (*Account, error)
+	SetKYCStatus(c context.Context, xid string, status KYCStatus) (*Account, error)
+	CloseAccount(c context.Context, xid string) (*Account, error)
+	ReopenAccount(c context.Context, xid string) (*Account, error)
+	ActivateAccount(c context.Context, xid string) (*Account, error)
+	EraseAccount(c context.Context, xid string) (*Account, error)
+	SetIDNumber(c context.Context, xid string, idNumber string) (*Account, error)
+	FindAccount(c context.Context, field Field, value string) (*Account, error)
 }
 
 type service struct {
 	repo Repository
+	mu   sync.Mutex
 }
 
 func NewService(repo Repository) Service {
@@ -41,3 +51,107 @@ func (s *service) CreateAccount(c context.Context, a Account) error {
 func (s *service) GetAccount(c context.Context, xid string) (*Account, error) {
 	return s.repo.GetAccount(c, xid)
 }
+
+func (s *service) SetKYCStatus(c context.Context, xid string, status KYCStatus) (*Account, error) {
+	return s.update(c, xid, func(a *Account) error {
+		a.KYCStatus = status
+		return nil
+	})
+}
+
+// SetIDNumber records the national identity number the customer was
+// verified with.
+func (s *service) SetIDNumber(c context.Context, xid string, idNumber string) (*Account, error) {
+	return s.update(c, xid, func(a *Account) error {
+		a.IDNumber = idNumber
+		return nil
+	})
+}
+
+// FindAccount gets the account by one of its personal details.
+func (s *service) FindAccount(c context.Context, field Field, value string) (*Account, error) {
+	if value == "" {
+		return nil, ErrAccountNotFound
+	}
+	return s.repo.FindAccount(c, field, value)
+}
+
+// CloseAccount marks an active account closed.
+func (s *service) CloseAccount(c context.Context, xid string) (*Account, error) {
+	return s.update(c, xid, func(a *Account) error {
+		if !a.IsActive() {
+			return ErrAccountClosed
+		}
+		a.Status = StatusClosed
+		a.ClosedAt = time.Now()
+		return nil
+	})
+}
+
+// ReopenAccount lets the customer of a closed account init again.
+func (s *service) ReopenAccount(c context.Context, xid string) (*Account, error) {
+	return s.update(c, xid, func(a *Account) error {
+		if a.Status != StatusClosed {
+			return ErrAccountNotClosed
+		}
+		if a.IsErased() {
+			return ErrAccountErased
+		}
+		a.Status = StatusReopened
+		return nil
+	})
+}
+
+// ActivateAccount makes a reopened account active. Any other account is
+// already taken.
+func (s *service) ActivateAccount(c context.Context, xid string) (*Account, error) {
+	return s.update(c, xid, func(a *Account) error {
+		if a.Status != StatusReopened {
+			return ErrAccountAlreadyExists
+		}
+		a.Status = StatusActive
+		a.ClosedAt = time.Time{}
+		return nil
+	})
+}
+
+// EraseAccount marks a closed account erased and drops its personal
+// details, erasing the personal data kept elsewhere is up to the services
+// holding it.
+func (s *service) EraseAccount(c context.Context, xid string) (*Account, error) {
+	return s.update(c, xid, func(a *Account) error {
+		if a.Status != StatusClosed {
+			return ErrAccountNotClosed
+		}
+		if a.IsErased() {
+			return ErrAccountErased
+		}
+		a.ErasedAt = time.Now()
+		a.Phone, a.Email, a.IDNumber = "", "", ""
+		return nil
+	})
+}
+
+func (s *service) update(c context.Context, xid string, change func(*Account) error) (*Account, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	acc, err := s.repo.GetAccount(c, xid)
+	if err != nil && err == ErrAccountNotFound {
+		return nil, ErrAccountNotFound
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed getting account")
+	}
+
+	updated := *acc
+	err = change(&updated)
+	if err != nil {
+		return nil, err
+	}
+	err = s.repo.UpdateAccount(c, updated)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed updating account")
+	}
+
+	return &updated, nil
+}
@@ -1,10 +1,16 @@
 package account
 
 import (
+	"bytes"
 	"context"
+	"database/sql"
+	"julo/internal/envelope"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/google/uuid"
+	_ "github.com/mattn/go-sqlite3"
 )
 
 func TestCreateAccount(t *testing.T) {
@@ -47,3 +53,119 @@ func TestCreateAccount(t *testing.T) {
 		})
 	})
 }
+
+func TestEncryptedRepository(t *testing.T) {
+	ctx := context.Background()
+	keys := envelope.Keyring{
+		Current:  "k1",
+		Keys:     map[string][]byte{"k1": bytes.Repeat([]byte{1}, 32)},
+		IndexKey: bytes.Repeat([]byte{9}, 32),
+	}
+	sealer, err := envelope.NewSealer(keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "accounts.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	sqlite, err := NewSQLiteRecordStore(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, store := range map[string]RecordStore{"memory": NewInMemoryRecordStore(), "sqlite": sqlite} {
+		t.Run(name, func(t *testing.T) {
+			repo := NewEncryptedRepository(store, sealer)
+			service := NewService(repo)
+			xid := uuid.NewString()
+			err := service.CreateAccount(ctx, Account{
+				XID:    xid,
+				Status: StatusActive,
+				Phone:  "0812 3456 7890",
+				Email:  "Budi@Example.com",
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			t.Run("store, should not hold plaintext", func(t *testing.T) {
+				rec, err := store.GetRecord(ctx, xid)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if strings.Contains(rec.Sealed[FieldEmail], "Budi") || rec.Index[FieldEmail] == "" {
+					t.Fatalf("expecting sealed and indexed email, got %+v", rec)
+				}
+				if _, ok := rec.Sealed[FieldIDNumber]; ok {
+					t.Fatalf("expecting no id number, got %+v", rec)
+				}
+			})
+
+			t.Run("find account by normalized detail, should success", func(t *testing.T) {
+				acc, err := service.FindAccount(ctx, FieldEmail, " budi@example.COM")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if acc.XID != xid || acc.Phone != "0812 3456 7890" {
+					t.Fatalf("expecting account %s, got %+v", xid, acc)
+				}
+				if _, err := service.FindAccount(ctx, FieldPhone, "081234567890"); err != nil {
+					t.Fatal(err)
+				}
+				if _, err := service.FindAccount(ctx, FieldIDNumber, "3171234567890123"); err != ErrAccountNotFound {
+					t.Fatalf("expecting error %s, got %v", ErrAccountNotFound, err)
+				}
+			})
+
+			t.Run("update account, should index new detail", func(t *testing.T) {
+				_, err := service.SetIDNumber(ctx, xid, "3171234567890123")
+				if err != nil {
+					t.Fatal(err)
+				}
+				acc, err := service.FindAccount(ctx, FieldIDNumber, "3171234567890123")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if acc.Email != "Budi@Example.com" {
+					t.Fatalf("expecting details kept, got %+v", acc)
+				}
+			})
+
+			t.Run("rotate keys, should reseal with current key", func(t *testing.T) {
+				rotatedKeys := keys
+				rotatedKeys.Keys = map[string][]byte{"k1": keys.Keys["k1"], "k2": bytes.Repeat([]byte{2}, 32)}
+				rotatedKeys.Current = "k2"
+				rotatedSealer, err := envelope.NewSealer(rotatedKeys)
+				if err != nil {
+					t.Fatal(err)
+				}
+				rotatedRepo := NewEncryptedRepository(store, rotatedSealer)
+				n, err := rotatedRepo.RotateKeys(ctx)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if n != 1 {
+					t.Fatalf("expecting 1 account rotated, got %d", n)
+				}
+				if n, _ := rotatedRepo.RotateKeys(ctx); n != 0 {
+					t.Fatalf("expecting nothing left to rotate, got %d", n)
+				}
+
+				delete(rotatedKeys.Keys, "k1")
+				retiredSealer, err := envelope.NewSealer(rotatedKeys)
+				if err != nil {
+					t.Fatal(err)
+				}
+				acc, err := NewEncryptedRepository(store, retiredSealer).FindAccount(ctx, FieldPhone, "081234567890")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if acc.IDNumber != "3171234567890123" {
+					t.Fatalf("expecting details readable without old key, got %+v", acc)
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package account
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS accounts (
+	xid               TEXT PRIMARY KEY,
+	kyc_status        TEXT NOT NULL DEFAULT '',
+	status            TEXT NOT NULL DEFAULT '',
+	closed_at         TEXT NOT NULL DEFAULT '',
+	erased_at         TEXT NOT NULL DEFAULT '',
+	phone             TEXT NOT NULL DEFAULT '',
+	phone_index       TEXT NOT NULL DEFAULT '',
+	email             TEXT NOT NULL DEFAULT '',
+	email_index       TEXT NOT NULL DEFAULT '',
+	id_number         TEXT NOT NULL DEFAULT '',
+	id_number_index   TEXT NOT NULL DEFAULT ''
+);
+CREATE INDEX IF NOT EXISTS accounts_phone_index ON accounts (phone_index);
+CREATE INDEX IF NOT EXISTS accounts_email_index ON accounts (email_index);
+CREATE INDEX IF NOT EXISTS accounts_id_number_index ON accounts (id_number_index);
+`
+
+const sqliteColumns = `xid, kyc_status, status, closed_at, erased_at,
+	phone, phone_index, email, email_index, id_number, id_number_index`
+
+// SQLiteRecordStore keeps account records in the accounts table of a SQLite
+// database, each personal detail in a column of its own next to the column
+// of its blind index.
+type SQLiteRecordStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteRecordStore creates the accounts table in db when missing. db is
+// opened by the caller with the sqlite3 driver.
+func NewSQLiteRecordStore(db *sql.DB) (*SQLiteRecordStore, error) {
+	_, err := db.Exec(sqliteSchema)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed creating accounts table")
+	}
+	return &SQLiteRecordStore{db: db}, nil
+}
+
+func (s *SQLiteRecordStore) CreateRecord(c context.Context, r Record) error {
+	_, err := s.db.ExecContext(c, `INSERT INTO accounts (`+sqliteColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`, recordValues(r)...)
+	return errors.Wrap(err, "failed inserting account")
+}
+
+func (s *SQLiteRecordStore) UpdateRecord(c context.Context, r Record) error {
+	values := recordValues(r)
+	_, err := s.db.ExecContext(c, `UPDATE accounts SET kyc_status = ?, status = ?, closed_at = ?, erased_at = ?,
+		phone = ?, phone_index = ?, email = ?, email_index = ?, id_number = ?, id_number_index = ?
+		WHERE xid = ?`, append(values[1:], values[0])...)
+	return errors.Wrap(err, "failed updating account")
+}
+
+func (s *SQLiteRecordStore) GetRecord(c context.Context, xid string) (*Record, error) {
+	return s.queryRecord(c, `SELECT `+sqliteColumns+` FROM accounts WHERE xid = ?`, xid)
+}
+
+func (s *SQLiteRecordStore) FindRecord(c context.Context, field Field, index string) (*Record, error) {
+	var column string
+	switch field {
+	case FieldPhone:
+		column = "phone_index"
+	case FieldEmail:
+		column = "email_index"
+	case FieldIDNumber:
+		column = "id_number_index"
+	default:
+		return nil, ErrAccountNotFound
+	}
+	if index == "" {
+		return nil, ErrAccountNotFound
+	}
+	return s.queryRecord(c, `SELECT `+sqliteColumns+` FROM accounts WHERE `+column+` = ? LIMIT 1`, index)
+}
+
+func (s *SQLiteRecordStore) GetRecords(c context.Context) ([]Record, error) {
+	rows, err := s.db.QueryContext(c, `SELECT `+sqliteColumns+` FROM accounts ORDER BY xid`)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed querying accounts")
+	}
+	defer rows.Close()
+
+	records := []Record{}
+	for rows.Next() {
+		r, err := scanRecord(rows)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, *r)
+	}
+	return records, errors.Wrap(rows.Err(), "failed reading accounts")
+}
+
+func (s *SQLiteRecordStore) queryRecord(c context.Context, query string, args ...interface{}) (*Record, error) {
+	r, err := scanRecord(s.db.QueryRowContext(c, query, args...))
+	if err != nil && errors.Cause(err) == sql.ErrNoRows {
+		return nil, ErrAccountNotFound
+	}
+	return r, err
+}
+
+func recordValues(r Record) []interface{} {
+	return []interface{}{
+		r.XID, r.KYCStatus, r.Status, formatTime(r.ClosedAt), formatTime(r.ErasedAt),
+		r.Sealed[FieldPhone], r.Index[FieldPhone],
+		r.Sealed[FieldEmail], r.Index[FieldEmail],
+		r.Sealed[FieldIDNumber], r.Index[FieldIDNumber],
+	}
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanRecord(row scanner) (*Record, error) {
+	var r Record
+	var closedAt, erasedAt string
+	var values [6]string
+	err := row.Scan(&r.XID, &r.KYCStatus, &r.Status, &closedAt, &erasedAt,
+		&values[0], &values[1], &values[2], &values[3], &values[4], &values[5])
+	if err != nil {
+		return nil, errors.Wrap(err, "failed scanning account")
+	}
+
+	r.ClosedAt, err = parseTime(closedAt)
+	if err != nil {
+		return nil, err
+	}
+	r.ErasedAt, err = parseTime(erasedAt)
+	if err != nil {
+		return nil, err
+	}
+	r.Sealed, r.Index = map[Field]string{}, map[Field]string{}
+	for i, f := range []Field{FieldPhone, FieldEmail, FieldIDNumber} {
+		if values[2*i] != "" {
+			r.Sealed[f] = values[2*i]
+			r.Index[f] = values[2*i+1]
+		}
+	}
+	return &r, nil
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+func parseTime(v string) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, v)
+	return t, errors.Wrap(err, "failed parsing account time")
+}
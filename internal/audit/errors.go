@@ -0,0 +1,7 @@
+package audit
+
+import "errors"
+
+var (
+	ErrMissingRequiredParameter = errors.New("missing required parameter")
+)
@@ -0,0 +1,61 @@
+package audit
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+type Action string
+
+var (
+	ActionAccountClosed   = Action("account_closed")
+	ActionAccountReopened = Action("account_reopened")
+	ActionDataExported    = Action("data_exported")
+	ActionDataErased      = Action("data_erased")
+)
+
+// Entry records an action taken on the data of the customer SubjectXID.
+type Entry struct {
+	ID         string `json:"id"`
+	SubjectXID string `json:"subject_xid"`
+	// ActorXID is who took the action, the customer themselves or an
+	// operator.
+	ActorXID  string    `json:"actor_xid"`
+	Action    Action    `json:"action"`
+	Detail    string    `json:"detail,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Repository interface {
+	CreateEntry(ctx context.Context, e Entry) error
+	GetEntries(ctx context.Context, subjectXID string) ([]Entry, error)
+}
+
+type InMemoryRepository struct {
+	entries sync.Map
+}
+
+func NewInMemoryRepository() Repository {
+	return &InMemoryRepository{}
+}
+
+func (r *InMemoryRepository) CreateEntry(ctx context.Context, e Entry) error {
+	r.entries.Store(e.ID, e)
+	return nil
+}
+
+func (r *InMemoryRepository) GetEntries(ctx context.Context, subjectXID string) ([]Entry, error) {
+	entries := []Entry{}
+	r.entries.Range(func(key, value any) bool {
+		if e := value.(Entry); e.SubjectXID == subjectXID {
+			entries = append(entries, e)
+		}
+		return true
+	})
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].CreatedAt.Before(entries[j].CreatedAt)
+	})
+	return entries, nil
+}
@@ -0,0 +1,47 @@
+package audit
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+type Service interface {
+	// Record appends e to the trail of its subject.
+	Record(ctx context.Context, e Entry) (*Entry, error)
+	GetEntries(ctx context.Context, subjectXID string) ([]Entry, error)
+}
+
+type service struct {
+	repo Repository
+}
+
+func NewService(repo Repository) Service {
+	return &service{
+		repo: repo,
+	}
+}
+
+func (s *service) Record(ctx context.Context, e Entry) (*Entry, error) {
+	if e.SubjectXID == "" || e.ActorXID == "" || e.Action == "" {
+		return nil, ErrMissingRequiredParameter
+	}
+
+	e.ID = uuid.NewString()
+	e.CreatedAt = time.Now()
+	err := s.repo.CreateEntry(ctx, e)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed recording audit entry")
+	}
+	return &e, nil
+}
+
+func (s *service) GetEntries(ctx context.Context, subjectXID string) ([]Entry, error) {
+	entries, err := s.repo.GetEntries(ctx, subjectXID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed getting audit entries")
+	}
+	return entries, nil
+}
@@ -0,0 +1,43 @@
+package audit_test
+
+import (
+	"context"
+	"julo/internal/audit"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestRecord(t *testing.T) {
+	ctx := context.Background()
+	audits := audit.NewService(audit.NewInMemoryRepository())
+	xid := uuid.NewString()
+
+	t.Run("record without actor, should fail", func(t *testing.T) {
+		_, err := audits.Record(ctx, audit.Entry{SubjectXID: xid, Action: audit.ActionDataExported})
+		if err != audit.ErrMissingRequiredParameter {
+			t.Fatalf("expecting error %s, got %v", audit.ErrMissingRequiredParameter, err)
+		}
+	})
+
+	t.Run("record, should list entries of the subject in order", func(t *testing.T) {
+		for _, action := range []audit.Action{audit.ActionAccountClosed, audit.ActionDataErased} {
+			_, err := audits.Record(ctx, audit.Entry{SubjectXID: xid, ActorXID: "ops-1", Action: action})
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		_, err := audits.Record(ctx, audit.Entry{SubjectXID: uuid.NewString(), ActorXID: "ops-1", Action: audit.ActionDataExported})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		entries, err := audits.GetEntries(ctx, xid)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(entries) != 2 || entries[0].Action != audit.ActionAccountClosed || entries[1].Action != audit.ActionDataErased {
+			t.Fatalf("expecting closure then erasure, got %+v", entries)
+		}
+	})
+}
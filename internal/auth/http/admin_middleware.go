@@ -0,0 +1,28 @@
+package http
+
+import (
+	"crypto/subtle"
+	"errors"
+	httphelper "julo/internal/http"
+	"net/http"
+	"strings"
+)
+
+var ErrInvalidAdminToken = errors.New("invalid admin token")
+
+// AdminMiddleware guards operator endpoints, only requests presenting
+// "Authorization: Token <token>" with the configured admin token get through.
+func AdminMiddleware(token string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header := r.Header.Get("Authorization")
+			_, given, found := strings.Cut(header, "Token ")
+			if !found || token == "" || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+				httphelper.WriteErrorJSON(w, http.StatusUnauthorized, ErrInvalidAdminToken)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
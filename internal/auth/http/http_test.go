@@ -10,6 +10,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strings"
 	"testing"
 
 	"github.com/google/uuid"
@@ -121,4 +122,31 @@ func TestInit(t *testing.T) {
 			t.Fatal("status")
 		}
 	})
+
+	t.Run("init with json body, should success", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		body := fmt.Sprintf(`{"customer_xid": %q}`, uuid.NewString())
+		req := httptest.NewRequest("POST", "/", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		inithandler.ServeHTTP(rec, req)
+
+		if rec.Result().StatusCode != http.StatusOK {
+			t.Fatalf("expecting status %v, got %v", http.StatusOK, rec.Result().StatusCode)
+		}
+	})
+
+	t.Run("init with json body missing xid, should fail per field", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/", strings.NewReader(`{"phone": "+6281234567890"}`))
+		req.Header.Set("Content-Type", "application/json")
+		inithandler.ServeHTTP(rec, req)
+
+		if rec.Result().StatusCode != http.StatusBadRequest {
+			t.Fatalf("expecting status %v, got %v", http.StatusBadRequest, rec.Result().StatusCode)
+		}
+		want := `{"status":"fail","data":{"error":{"customer_xid":"missing data for required field."}}}`
+		if got := strings.TrimSpace(rec.Body.String()); got != want {
+			t.Fatalf("expecting %s, got %s", want, got)
+		}
+	})
 }
@@ -11,16 +11,16 @@ func InitHandler(initializer auth.Initializer) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		var response httphelper.Response
 
-		customerXID := r.FormValue("customer_xid")
-		if customerXID == "" {
-			response.Status = "fail"
-			response.Data = map[string]interface{}{"error": map[string]interface{}{"customer_xid": "missing data for required field."}}
-			httphelper.WriteJSON(w, http.StatusBadRequest, response)
+		var req initRequest
+		if err := httphelper.Decode(w, r, &req); err != nil {
+			httphelper.WriteRequestErrorJSON(w, err)
 			return
 		}
 
 		result, err := initializer.Init(r.Context(), auth.InitParam{
-			CustomerXID: customerXID,
+			CustomerXID: req.CustomerXID,
+			Phone:       req.Phone,
+			Email:       req.Email,
 		})
 		if err != nil && err != account.ErrAccountAlreadyExists {
 			httphelper.WriteErrorJSON(w, http.StatusBadRequest, err)
@@ -39,3 +39,9 @@ func InitHandler(initializer auth.Initializer) http.Handler {
 		httphelper.WriteJSON(w, http.StatusOK, response)
 	})
 }
+
+type initRequest struct {
+	CustomerXID string `json:"customer_xid" validate:"required"`
+	Phone       string `json:"phone"`
+	Email       string `json:"email"`
+}
@@ -3,6 +3,7 @@ package auth
 import (
 	"context"
 	"julo/internal/account"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/pkg/errors"
@@ -14,6 +15,10 @@ type Initializer interface {
 
 type InitParam struct {
 	CustomerXID string
+	// Phone and Email are optional contact details stored on a new
+	// account.
+	Phone string
+	Email string
 }
 type InitResult struct {
 	Session Session
@@ -29,19 +34,38 @@ func NewInitializer(account account.Service) Initializer {
 	}
 }
 
+// Init opens a session for the customer, creating the account on first use.
+// The xids of system wallets are refused, no one can get a session on them.
 func (i *initializer) Init(c context.Context, p InitParam) (*InitResult, error) {
-	account := account.Account{
-		XID: p.CustomerXID,
+	if account.IsReservedXID(p.CustomerXID) {
+		return nil, account.ErrReservedXID
+	}
+
+	acc := account.Account{
+		XID:       p.CustomerXID,
+		KYCStatus: account.KYCStatusUnverified,
+		Status:    account.StatusActive,
+		Phone:     p.Phone,
+		Email:     p.Email,
+	}
+	err := i.account.CreateAccount(c, acc)
+	// the customer of an account an operator reopened gets back in
+	if err == account.ErrAccountAlreadyExists {
+		var reopened *account.Account
+		reopened, err = i.account.ActivateAccount(c, p.CustomerXID)
+		if err == nil {
+			acc = *reopened
+		}
 	}
-	err := i.account.CreateAccount(c, account)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed creating account")
 	}
 
 	token := uuid.NewString()
 	session := Session{
-		Token:   token,
-		Account: account,
+		Token:     token,
+		Account:   acc,
+		CreatedAt: time.Now(),
 	}
 
 	err = StoreSession(c, session)
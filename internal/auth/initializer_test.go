@@ -45,6 +45,21 @@ func TestInitializer(t *testing.T) {
 	})
 }
 
+func TestInitReservedXID(t *testing.T) {
+	c := context.Background()
+	accounts := account.NewService(account.NewInMemoryRepository())
+	initializer := auth.NewInitializer(accounts)
+
+	for _, xid := range []string{account.SystemXIDPrefix + "house", account.MerchantXIDPrefix + uuid.NewString()} {
+		_, err := initializer.Init(c, auth.InitParam{
+			CustomerXID: xid,
+		})
+		if err != account.ErrReservedXID {
+			t.Fatalf("expecting error %s for %s, got %v", account.ErrReservedXID, xid, err)
+		}
+	}
+}
+
 func TestSessionManager(t *testing.T) {
 	c := context.Background()
 	t.Run("get inexist session", func(t *testing.T) {
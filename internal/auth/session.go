@@ -3,17 +3,24 @@ package auth
 import (
 	"context"
 	"julo/internal/account"
+	"sort"
 	"sync"
+	"time"
 )
 
 type Session struct {
-	Token   string
-	Account account.Account
+	Token     string
+	Account   account.Account
+	CreatedAt time.Time
 }
 
 type SessionManager interface {
 	StoreSession(ctx context.Context, session Session) error
 	GetSession(ctx context.Context, token string) (*Session, error)
+	// RevokeSessions ends every session of the customer xid.
+	RevokeSessions(ctx context.Context, xid string) error
+	// GetSessions lists the live sessions of the customer xid, oldest first.
+	GetSessions(ctx context.Context, xid string) ([]Session, error)
 }
 
 type InMemorySessionManager struct {
@@ -40,6 +47,30 @@ func (m *InMemorySessionManager) GetSession(ctx context.Context, token string) (
 	return v.(*Session), nil
 }
 
+func (m *InMemorySessionManager) RevokeSessions(ctx context.Context, xid string) error {
+	m.store.Range(func(key, value any) bool {
+		if value.(*Session).Account.XID == xid {
+			m.store.Delete(key)
+		}
+		return true
+	})
+	return nil
+}
+
+func (m *InMemorySessionManager) GetSessions(ctx context.Context, xid string) ([]Session, error) {
+	sessions := []Session{}
+	m.store.Range(func(key, value any) bool {
+		if s := *value.(*Session); s.Account.XID == xid {
+			sessions = append(sessions, s)
+		}
+		return true
+	})
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].CreatedAt.Before(sessions[j].CreatedAt)
+	})
+	return sessions, nil
+}
+
 var sessionManager SessionManager
 var once sync.Once
 
@@ -59,6 +90,14 @@ func GetSession(ctx context.Context, token string) (*Session, error) {
 	return sessionManager.GetSession(ctx, token)
 }
 
+func RevokeSessions(ctx context.Context, xid string) error {
+	return sessionManager.RevokeSessions(ctx, xid)
+}
+
+func GetSessions(ctx context.Context, xid string) ([]Session, error) {
+	return sessionManager.GetSessions(ctx, xid)
+}
+
 type key string
 
 const (
@@ -0,0 +1,9 @@
+package balance
+
+import "errors"
+
+var (
+	ErrMissingRequiredParameter = errors.New("missing required parameter")
+	ErrSnapshotNotFound         = errors.New("snapshot not found")
+	ErrFutureTime               = errors.New("balance cannot be told for a time in the future")
+)
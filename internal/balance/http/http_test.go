@@ -0,0 +1,107 @@
+package http_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"julo/internal/account"
+	"julo/internal/auth"
+	authhttp "julo/internal/auth/http"
+	"julo/internal/balance"
+	balancehttp "julo/internal/balance/http"
+	httphelper "julo/internal/http"
+	"julo/internal/wallet"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/google/uuid"
+)
+
+func TestViewBalance(t *testing.T) {
+	ctx := context.Background()
+	wallets := wallet.NewService(wallet.NewInMemoryRepository())
+	balances := balance.NewService(balance.NewInMemoryRepository(), wallets)
+
+	router := chi.NewRouter()
+	router.Mount("/api/v1", router.Group(func(r chi.Router) {
+		r.Mount("/wallet", r.Group(func(r chi.Router) {
+			r.Use(authhttp.Middleware)
+			r.Get("/balance", balancehttp.ViewBalanceHandler(balances).ServeHTTP)
+		}))
+	}))
+	server := httptest.NewServer(router)
+	defer server.Close()
+	baseUrl := server.URL
+
+	xid, token := uuid.NewString(), uuid.NewString()
+	err := auth.StoreSession(ctx, auth.Session{Token: token, Account: account.Account{XID: xid}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = wallets.EnableWallet(ctx, wallet.EnableWalletParam{OwnerXID: xid})
+	if err != nil {
+		t.Fatal(err)
+	}
+	before := time.Now()
+	_, err = wallets.DepositWallet(ctx, wallet.WalletTransactionParam{
+		ActorXID:    xid,
+		OwnerXID:    xid,
+		ReferenceID: uuid.NewString(),
+		Amount:      40000,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	get := func(t *testing.T, query string) *http.Response {
+		req, err := http.NewRequest(http.MethodGet, baseUrl+"/api/v1/wallet/balance"+query, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Add("Authorization", fmt.Sprintf("Token %s", token))
+		res, err := server.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+	balanceOf := func(t *testing.T, res *http.Response) float64 {
+		var response httphelper.Response
+		if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+			t.Fatal(err)
+		}
+		return response.Data.(map[string]interface{})["balance"].(map[string]interface{})["balance"].(float64)
+	}
+
+	t.Run("get balance now, should count deposit", func(t *testing.T) {
+		res := get(t, "")
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("expecting status %v, got %v", http.StatusOK, res.StatusCode)
+		}
+		if b := balanceOf(t, res); b != 40000 {
+			t.Fatalf("expecting balance 40000, got %v", b)
+		}
+	})
+
+	t.Run("get balance before deposit, should be empty", func(t *testing.T) {
+		res := get(t, "?at="+url.QueryEscape(before.Add(-time.Second).Format(time.RFC3339)))
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("expecting status %v, got %v", http.StatusOK, res.StatusCode)
+		}
+		if b := balanceOf(t, res); b != 0 {
+			t.Fatalf("expecting balance 0, got %v", b)
+		}
+	})
+
+	t.Run("get balance with invalid time, should fail", func(t *testing.T) {
+		for _, query := range []string{"?at=yesterday", "?at=" + url.QueryEscape(time.Now().Add(time.Hour).Format(time.RFC3339))} {
+			if res := get(t, query); res.StatusCode != http.StatusBadRequest {
+				t.Fatalf("expecting status %v for %s, got %v", http.StatusBadRequest, query, res.StatusCode)
+			}
+		}
+	})
+}
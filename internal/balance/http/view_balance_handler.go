@@ -0,0 +1,65 @@
+package http
+
+import (
+	"julo/internal/auth"
+	"julo/internal/balance"
+	httphelper "julo/internal/http"
+	"julo/internal/wallet"
+	"net/http"
+	"time"
+)
+
+type balanceResponse struct {
+	WalletID string    `json:"wallet_id"`
+	At       time.Time `json:"at"`
+	Balance  int       `json:"balance"`
+}
+
+// ViewBalanceHandler tells the balance of the wallet of the session as of
+// the RFC 3339 at query parameter, or now without it.
+func ViewBalanceHandler(balances balance.Service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var response httphelper.Response
+		session := auth.SessionFromContext(r.Context())
+		if session == nil {
+			httphelper.WriteErrorJSON(w, http.StatusUnauthorized, auth.ErrSessionNotFound)
+			return
+		}
+
+		var at time.Time
+		if v := r.URL.Query().Get("at"); v != "" {
+			var err error
+			at, err = time.Parse(time.RFC3339, v)
+			if err != nil {
+				httphelper.WriteErrorJSON(w, http.StatusBadRequest, err)
+				return
+			}
+		}
+
+		b, err := balances.GetBalance(r.Context(), balance.GetBalanceParam{
+			OwnerXID: session.Account.XID,
+			At:       at,
+		})
+		if err != nil {
+			switch err {
+			case wallet.ErrWalletNotFound:
+				httphelper.WriteErrorJSON(w, http.StatusNotFound, err)
+			case balance.ErrMissingRequiredParameter, balance.ErrFutureTime:
+				httphelper.WriteErrorJSON(w, http.StatusBadRequest, err)
+			default:
+				httphelper.WriteErrorJSON(w, http.StatusInternalServerError, err)
+			}
+			return
+		}
+
+		response.Status = "success"
+		response.Data = map[string]interface{}{
+			"balance": balanceResponse{
+				WalletID: b.WalletID,
+				At:       b.At,
+				Balance:  b.Balance,
+			},
+		}
+		httphelper.WriteJSON(w, http.StatusOK, response)
+	})
+}
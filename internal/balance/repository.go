@@ -0,0 +1,69 @@
+package balance
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Snapshot is the balance of a wallet at the start of a day, counting the
+// transactions that succeeded before At.
+type Snapshot struct {
+	WalletID  string
+	At        time.Time
+	Balance   int
+	CreatedAt time.Time
+}
+
+type Repository interface {
+	SaveSnapshot(ctx context.Context, s Snapshot) error
+	// GetLatestSnapshot gets the snapshot of the wallet taken at or most
+	// recently before at.
+	GetLatestSnapshot(ctx context.Context, walletID string, at time.Time) (*Snapshot, error)
+}
+
+type InMemoryRepository struct {
+	// snapshots holds the snapshots of each wallet, oldest first
+	snapshots sync.Map
+	mu        sync.Mutex
+}
+
+func NewInMemoryRepository() Repository {
+	return &InMemoryRepository{}
+}
+
+func (r *InMemoryRepository) SaveSnapshot(ctx context.Context, s Snapshot) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var snapshots []Snapshot
+	if v, ok := r.snapshots.Load(s.WalletID); ok {
+		snapshots = v.([]Snapshot)
+	}
+	i := sort.Search(len(snapshots), func(i int) bool { return !snapshots[i].At.Before(s.At) })
+	updated := make([]Snapshot, 0, len(snapshots)+1)
+	updated = append(updated, snapshots[:i]...)
+	updated = append(updated, s)
+	if i < len(snapshots) && snapshots[i].At.Equal(s.At) {
+		i++
+	}
+	updated = append(updated, snapshots[i:]...)
+	r.snapshots.Store(s.WalletID, updated)
+	return nil
+}
+
+func (r *InMemoryRepository) GetLatestSnapshot(ctx context.Context, walletID string, at time.Time) (*Snapshot, error) {
+	v, ok := r.snapshots.Load(walletID)
+	if !ok {
+		return nil, ErrSnapshotNotFound
+	}
+
+	snapshots := v.([]Snapshot)
+	i := sort.Search(len(snapshots), func(i int) bool { return snapshots[i].At.After(at) })
+	if i == 0 {
+		return nil, ErrSnapshotNotFound
+	}
+	s := snapshots[i-1]
+	return &s, nil
+}
@@ -0,0 +1,145 @@
+package balance
+
+import (
+	"context"
+	"julo/internal/wallet"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type GetBalanceParam struct {
+	OwnerXID string
+	// At is the moment to tell the balance as of, now when zero.
+	At time.Time
+}
+
+type Balance struct {
+	WalletID string
+	At       time.Time
+	Balance  int
+	// SnapshotAt is the snapshot the balance was replayed from, zero when
+	// it was replayed from the first transaction.
+	SnapshotAt time.Time
+}
+
+type Service interface {
+	GetBalance(ctx context.Context, param GetBalanceParam) (*Balance, error)
+	TakeSnapshots(ctx context.Context, now time.Time) ([]Snapshot, error)
+}
+
+type service struct {
+	repo    Repository
+	wallets wallet.Service
+}
+
+func NewService(repo Repository, wallets wallet.Service) Service {
+	return &service{
+		repo:    repo,
+		wallets: wallets,
+	}
+}
+
+// GetBalance tells the balance of the wallet of the owner as of the moment
+// At, counting the transactions that had succeeded by then. It replays the
+// transactions settled since the latest snapshot before At onto that
+// snapshot, so only the days since are read.
+func (s *service) GetBalance(ctx context.Context, param GetBalanceParam) (*Balance, error) {
+	if param.OwnerXID == "" {
+		return nil, ErrMissingRequiredParameter
+	}
+	now := time.Now()
+	if param.At.IsZero() {
+		param.At = now
+	}
+	if param.At.After(now) {
+		return nil, ErrFutureTime
+	}
+
+	wal, err := s.wallets.GetWalletByXID(ctx, param.OwnerXID)
+	if err != nil {
+		return nil, err
+	}
+
+	b := Balance{
+		WalletID: wal.ID,
+		At:       param.At,
+	}
+	snapshot, err := s.repo.GetLatestSnapshot(ctx, wal.ID, param.At)
+	if err != nil && err != ErrSnapshotNotFound {
+		return nil, errors.Wrap(err, "failed getting snapshot")
+	}
+	if snapshot != nil {
+		b.Balance = snapshot.Balance
+		b.SnapshotAt = snapshot.At
+	}
+
+	// a transaction settled at At itself counts
+	transactions, err := s.wallets.GetSettledTransactions(ctx, wallet.GetSettledTransactionsParam{
+		WalletID: wal.ID,
+		From:     b.SnapshotAt,
+		To:       param.At.Add(time.Nanosecond),
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range transactions {
+		b.Balance += t.SignedAmount()
+	}
+	return &b, nil
+}
+
+// TakeSnapshots snapshots every wallet at the start of the day of now,
+// catching up on the days missed since its latest snapshot. A wallet without
+// snapshots gets one for today only. Days already snapshotted are skipped, so
+// it can run any number of times a day.
+func (s *service) TakeSnapshots(ctx context.Context, now time.Time) ([]Snapshot, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	wallets, err := s.wallets.GetWallets(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	taken := []Snapshot{}
+	for _, wal := range wallets {
+		latest, err := s.repo.GetLatestSnapshot(ctx, wal.ID, today)
+		if err != nil && err != ErrSnapshotNotFound {
+			return taken, errors.Wrap(err, "failed getting snapshot")
+		}
+
+		prev := Snapshot{WalletID: wal.ID}
+		day := today
+		if latest != nil {
+			prev = *latest
+			day = latest.At.AddDate(0, 0, 1)
+		}
+		for ; !day.After(today); day = day.AddDate(0, 0, 1) {
+			transactions, err := s.wallets.GetSettledTransactions(ctx, wallet.GetSettledTransactionsParam{
+				WalletID: wal.ID,
+				From:     prev.At,
+				To:       day,
+			})
+			if err != nil {
+				return taken, err
+			}
+
+			next := Snapshot{
+				WalletID:  wal.ID,
+				At:        day,
+				Balance:   prev.Balance,
+				CreatedAt: now,
+			}
+			for _, t := range transactions {
+				next.Balance += t.SignedAmount()
+			}
+			err = s.repo.SaveSnapshot(ctx, next)
+			if err != nil {
+				return taken, errors.Wrap(err, "failed saving snapshot")
+			}
+			taken = append(taken, next)
+			prev = next
+		}
+	}
+	return taken, nil
+}
@@ -0,0 +1,106 @@
+package balance_test
+
+import (
+	"context"
+	"julo/internal/balance"
+	"julo/internal/wallet"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestBalance(t *testing.T) {
+	ctx := context.Background()
+	repo := wallet.NewInMemoryRepository()
+	wallets := wallet.NewService(repo)
+	balances := balance.NewService(balance.NewInMemoryRepository(), wallets)
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	day := func(offset int, hour int) time.Time {
+		return today.AddDate(0, 0, offset).Add(time.Duration(hour) * time.Hour)
+	}
+
+	wal := wallet.Wallet{ID: uuid.NewString(), OwnerXID: uuid.NewString(), Status: wallet.WalletStatusEnabled, EnabledAt: day(-4, 0)}
+	if err := repo.CreateWallet(ctx, wal); err != nil {
+		t.Fatal(err)
+	}
+	var transactions []wallet.WalletTransaction
+	book := func(typ wallet.TransactionType, amount int, status wallet.TransactionStatus, settledAt time.Time) {
+		trx := wallet.WalletTransaction{
+			ID:          uuid.NewString(),
+			WalletID:    wal.ID,
+			ReferenceID: uuid.NewString(),
+			Type:        typ,
+			Amount:      amount,
+			Status:      status,
+			Date:        settledAt,
+			SettledAt:   settledAt,
+		}
+		if err := repo.CreateTransaction(ctx, trx); err != nil {
+			t.Fatal(err)
+		}
+		transactions = append(transactions, trx)
+	}
+	book(wallet.TransactionTypeDeposit, 100, wallet.TransactionStatusSuccess, day(-3, 10))
+	book(wallet.TransactionTypeWithdrawal, 30, wallet.TransactionStatusSuccess, day(-2, 12))
+	book(wallet.TransactionTypeWithdrawal, 999, wallet.TransactionStatusFailed, day(-2, 13))
+	book(wallet.TransactionTypeDeposit, 50, wallet.TransactionStatusSuccess, day(-1, 9))
+	book(wallet.TransactionTypeDeposit, 5, wallet.TransactionStatusSuccess, now)
+
+	t.Run("take first snapshot, should snapshot that day only", func(t *testing.T) {
+		snapshots, err := balances.TakeSnapshots(ctx, day(-2, 8))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(snapshots) != 1 || !snapshots[0].At.Equal(day(-2, 0)) || snapshots[0].Balance != 100 {
+			t.Fatalf("expecting snapshot of 100 at %s, got %+v", day(-2, 0), snapshots)
+		}
+	})
+
+	t.Run("take snapshots later, should catch up on missed days", func(t *testing.T) {
+		snapshots, err := balances.TakeSnapshots(ctx, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(snapshots) != 2 || snapshots[0].Balance != 70 || snapshots[1].Balance != 120 || !snapshots[1].At.Equal(today) {
+			t.Fatalf("expecting snapshots of 70 and 120, got %+v", snapshots)
+		}
+
+		snapshots, err = balances.TakeSnapshots(ctx, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(snapshots) != 0 {
+			t.Fatalf("expecting day already snapshotted, got %+v", snapshots)
+		}
+	})
+
+	t.Run("get balance at past moments, should match the ledger", func(t *testing.T) {
+		for _, at := range []time.Time{day(-4, 0), day(-3, 10), day(-2, 11), day(-2, 12), day(-1, 10), now} {
+			b, err := balances.GetBalance(ctx, balance.GetBalanceParam{OwnerXID: wal.OwnerXID, At: at})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := wallet.BalanceAt(transactions, at.Add(time.Nanosecond)); b.Balance != want {
+				t.Fatalf("expecting balance %d at %s, got %d", want, at, b.Balance)
+			}
+		}
+
+		b, err := balances.GetBalance(ctx, balance.GetBalanceParam{OwnerXID: wal.OwnerXID, At: day(-1, 10)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !b.SnapshotAt.Equal(day(-1, 0)) {
+			t.Fatalf("expecting balance replayed from snapshot at %s, got %s", day(-1, 0), b.SnapshotAt)
+		}
+	})
+
+	t.Run("get balance in the future, should fail", func(t *testing.T) {
+		_, err := balances.GetBalance(ctx, balance.GetBalanceParam{OwnerXID: wal.OwnerXID, At: now.Add(time.Hour)})
+		if err != balance.ErrFutureTime {
+			t.Fatalf("expecting error %s, got %v", balance.ErrFutureTime, err)
+		}
+	})
+}
@@ -0,0 +1,49 @@
+package balance
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Worker takes the daily balance snapshots every interval. Snapshotting is
+// idempotent within a day, so the interval only bounds how late a day is
+// snapshotted.
+type Worker struct {
+	balances Service
+	interval time.Duration
+}
+
+func NewWorker(balances Service, interval time.Duration) *Worker {
+	return &Worker{
+		balances: balances,
+		interval: interval,
+	}
+}
+
+// Run blocks, snapshotting straight away to catch up on downtime and then
+// every interval until ctx is done.
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	w.snapshot(ctx, time.Now())
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			w.snapshot(ctx, now)
+		}
+	}
+}
+
+func (w *Worker) snapshot(ctx context.Context, now time.Time) {
+	snapshots, err := w.balances.TakeSnapshots(ctx, now)
+	if err != nil {
+		log.Println(err)
+	}
+	if len(snapshots) > 0 {
+		log.Printf("took %d balance snapshots", len(snapshots))
+	}
+}
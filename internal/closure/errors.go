@@ -0,0 +1,13 @@
+package closure
+
+import "errors"
+
+var (
+	ErrMissingRequiredParameter = errors.New("missing required parameter")
+	ErrPendingTransactions      = errors.New("wallet has transactions still pending")
+	ErrOutstandingCredit        = errors.New("credit drawn from the wallet must be repaid first")
+	ErrOutstandingLoan          = errors.New("loans must be paid off first")
+	ErrActiveSchedules          = errors.New("scheduled transfers must be cancelled first")
+	ErrPendingPaymentRequests   = errors.New("payment requests must be answered first")
+	ErrBalanceBelowFee          = errors.New("remaining balance does not cover the payout fee")
+)
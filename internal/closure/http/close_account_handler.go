@@ -0,0 +1,149 @@
+package http
+
+import (
+	"julo/internal/account"
+	"julo/internal/auth"
+	"julo/internal/closure"
+	"julo/internal/disbursement"
+	httphelper "julo/internal/http"
+	"julo/internal/wallet"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi"
+)
+
+type payoutResponse struct {
+	ID          string `json:"id"`
+	Status      string `json:"status"`
+	Amount      int    `json:"amount"`
+	ReferenceID string `json:"reference_id"`
+}
+
+type accountResponse struct {
+	CustomerXID string     `json:"customer_xid"`
+	Status      string     `json:"status"`
+	ClosedAt    *time.Time `json:"closed_at,omitempty"`
+}
+
+func newAccountResponse(a account.Account) accountResponse {
+	res := accountResponse{
+		CustomerXID: a.XID,
+		Status:      string(a.Status),
+	}
+	if !a.ClosedAt.IsZero() {
+		res.ClosedAt = &a.ClosedAt
+	}
+	return res
+}
+
+// CloseAccountHandler closes the account of the session, paying the balance
+// out to the bank account in bank_code, account_number and account_name.
+func CloseAccountHandler(closures closure.Service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var response httphelper.Response
+		session := auth.SessionFromContext(r.Context())
+		if session == nil {
+			httphelper.WriteErrorJSON(w, http.StatusUnauthorized, auth.ErrSessionNotFound)
+			return
+		}
+
+		var req closeAccountRequest
+		if err := httphelper.Decode(w, r, &req); err != nil {
+			httphelper.WriteRequestErrorJSON(w, err)
+			return
+		}
+		var destination *disbursement.BankAccount
+		if req.AccountNumber != "" {
+			destination = &disbursement.BankAccount{
+				BankCode:      req.BankCode,
+				AccountNumber: req.AccountNumber,
+				AccountName:   req.AccountName,
+			}
+		}
+
+		c, err := closures.CloseAccount(r.Context(), closure.CloseAccountParam{
+			CustomerXID: session.Account.XID,
+			Destination: destination,
+		})
+		if err != nil {
+			ve, ok := err.(wallet.ValidationError)
+			switch {
+			case ok:
+				response.Status = "failed"
+				response.Data = ve.GetErrors()
+				httphelper.WriteJSON(w, http.StatusBadRequest, response)
+			case err == account.ErrAccountNotFound:
+				httphelper.WriteErrorJSON(w, http.StatusNotFound, err)
+			case err == closure.ErrMissingRequiredParameter, err == closure.ErrPendingTransactions, err == closure.ErrOutstandingCredit,
+				err == closure.ErrOutstandingLoan, err == closure.ErrActiveSchedules, err == closure.ErrPendingPaymentRequests,
+				err == closure.ErrBalanceBelowFee, err == account.ErrAccountClosed, err == wallet.ErrWalletFrozen:
+				httphelper.WriteErrorJSON(w, http.StatusBadRequest, err)
+			default:
+				httphelper.WriteErrorJSON(w, http.StatusInternalServerError, err)
+			}
+			return
+		}
+
+		data := map[string]interface{}{
+			"account": newAccountResponse(c.Account),
+		}
+		if c.Payout != nil {
+			data["payout"] = payoutResponse{
+				ID:          c.Payout.ID,
+				Status:      string(c.Payout.Status),
+				Amount:      c.Payout.Amount,
+				ReferenceID: c.Payout.ReferenceID,
+			}
+		}
+		response.Status = "success"
+		response.Data = data
+		httphelper.WriteJSON(w, http.StatusOK, response)
+	})
+}
+
+// ReopenAccountHandler reopens the closed account of the "xid" url
+// parameter on behalf of the operator in actor.
+func ReopenAccountHandler(closures closure.Service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var response httphelper.Response
+
+		var req actorRequest
+		if err := httphelper.Decode(w, r, &req); err != nil {
+			httphelper.WriteRequestErrorJSON(w, err)
+			return
+		}
+
+		acc, err := closures.ReopenAccount(r.Context(), closure.ReopenAccountParam{
+			CustomerXID: chi.URLParam(r, "xid"),
+			ActorXID:    req.Actor,
+		})
+		if err != nil {
+			switch err {
+			case account.ErrAccountNotFound:
+				httphelper.WriteErrorJSON(w, http.StatusNotFound, err)
+			case closure.ErrMissingRequiredParameter, account.ErrAccountNotClosed:
+				httphelper.WriteErrorJSON(w, http.StatusBadRequest, err)
+			default:
+				httphelper.WriteErrorJSON(w, http.StatusInternalServerError, err)
+			}
+			return
+		}
+
+		response.Status = "success"
+		response.Data = map[string]interface{}{
+			"account": newAccountResponse(*acc),
+		}
+		httphelper.WriteJSON(w, http.StatusOK, response)
+	})
+}
+
+type closeAccountRequest struct {
+	BankCode      string `json:"bank_code"`
+	AccountNumber string `json:"account_number"`
+	AccountName   string `json:"account_name"`
+}
+
+type actorRequest struct {
+	Actor string `json:"actor" validate:"required"`
+}
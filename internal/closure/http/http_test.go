@@ -0,0 +1,140 @@
+package http_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"julo/internal/account"
+	"julo/internal/audit"
+	"julo/internal/auth"
+	authhttp "julo/internal/auth/http"
+	"julo/internal/closure"
+	closurehttp "julo/internal/closure/http"
+	httphelper "julo/internal/http"
+	"julo/internal/loan"
+	"julo/internal/payrequest"
+	"julo/internal/pocket"
+	"julo/internal/schedule"
+	"julo/internal/wallet"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/google/uuid"
+)
+
+func TestCloseAccount(t *testing.T) {
+	accounts := account.NewService(account.NewInMemoryRepository())
+	initializer := auth.NewInitializer(accounts)
+	wallets := wallet.NewService(wallet.NewInMemoryRepository())
+	closures := closure.NewService(
+		accounts,
+		wallets,
+		pocket.NewService(pocket.NewInMemoryRepository(), wallets),
+		loan.NewService(loan.NewInMemoryRepository(), wallets),
+		schedule.NewService(schedule.NewInMemoryRepository(), wallets, schedule.LogNotifier{}, schedule.Config{}),
+		payrequest.NewService(payrequest.NewInMemoryRepository(), wallets),
+		audit.NewService(audit.NewInMemoryRepository()),
+	)
+	adminToken := uuid.NewString()
+
+	router := chi.NewRouter()
+	router.Mount("/api/v1", router.Group(func(r chi.Router) {
+		r.Post("/init", authhttp.InitHandler(initializer).ServeHTTP)
+		r.Mount("/account", r.Group(func(r chi.Router) {
+			r.Use(authhttp.Middleware)
+			r.Post("/close", closurehttp.CloseAccountHandler(closures).ServeHTTP)
+		}))
+		r.Mount("/admin", r.Group(func(r chi.Router) {
+			r.Use(authhttp.AdminMiddleware(adminToken))
+			r.Post("/accounts/{xid}/reopen", closurehttp.ReopenAccountHandler(closures).ServeHTTP)
+		}))
+	}))
+	server := httptest.NewServer(router)
+	defer server.Close()
+	baseUrl := server.URL
+
+	xid := uuid.NewString()
+	initForm := url.Values{}
+	initForm.Set("customer_xid", xid)
+	initAccount := func(t *testing.T) *http.Response {
+		req := buildAuthenticatedRequest(t, http.MethodPost, baseUrl+"/api/v1/init", "", bytes.NewBufferString(initForm.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		res, err := server.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+
+	res := initAccount(t)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expecting status %v, got %v", http.StatusOK, res.StatusCode)
+	}
+	token := decode(t, res)["token"].(string)
+
+	t.Run("close account, should end session", func(t *testing.T) {
+		req := buildAuthenticatedRequest(t, http.MethodPost, baseUrl+"/api/v1/account/close", token, nil)
+		res, err := server.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("expecting status %v, got %v", http.StatusOK, res.StatusCode)
+		}
+		acc := decode(t, res)["account"].(map[string]interface{})
+		if acc["status"] != string(account.StatusClosed) {
+			t.Fatalf("expecting closed account, got %v", acc)
+		}
+
+		req = buildAuthenticatedRequest(t, http.MethodPost, baseUrl+"/api/v1/account/close", token, nil)
+		res, err = server.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.StatusCode != http.StatusUnauthorized {
+			t.Fatalf("expecting status %v, got %v", http.StatusUnauthorized, res.StatusCode)
+		}
+
+		t.Run("init closed account, should fail", func(t *testing.T) {
+			if res := initAccount(t); res.StatusCode != http.StatusBadRequest {
+				t.Fatalf("expecting status %v, got %v", http.StatusBadRequest, res.StatusCode)
+			}
+		})
+
+		t.Run("reopen account, should allow init", func(t *testing.T) {
+			req := buildAuthenticatedRequest(t, http.MethodPost, baseUrl+"/api/v1/admin/accounts/"+xid+"/reopen?actor=ops-1", adminToken, nil)
+			res, err := server.Client().Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.StatusCode != http.StatusOK {
+				t.Fatalf("expecting status %v, got %v", http.StatusOK, res.StatusCode)
+			}
+			if res := initAccount(t); res.StatusCode != http.StatusOK {
+				t.Fatalf("expecting status %v, got %v", http.StatusOK, res.StatusCode)
+			}
+		})
+	})
+}
+
+func decode(t *testing.T, res *http.Response) map[string]interface{} {
+	var response httphelper.Response
+	err := json.NewDecoder(res.Body).Decode(&response)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return response.Data.(map[string]interface{})
+}
+
+func buildAuthenticatedRequest(t *testing.T, method string, url string, token string, body io.Reader) *http.Request {
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Add("Authorization", fmt.Sprintf("Token %s", token))
+	return req
+}
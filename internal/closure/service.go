@@ -0,0 +1,310 @@
+package closure
+
+import (
+	"context"
+	"julo/internal/account"
+	"julo/internal/audit"
+	"julo/internal/auth"
+	"julo/internal/disbursement"
+	"julo/internal/loan"
+	"julo/internal/payrequest"
+	"julo/internal/pocket"
+	"julo/internal/schedule"
+	"julo/internal/wallet"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+type CloseAccountParam struct {
+	CustomerXID string
+	// Destination receives the remaining balance, it is only needed when
+	// there is one.
+	Destination *disbursement.BankAccount
+}
+
+type ReopenAccountParam struct {
+	CustomerXID string
+	// ActorXID is the operator reopening the account.
+	ActorXID string
+}
+
+type Closure struct {
+	Account account.Account
+	// Payout is the withdrawal of the remaining balance, nil when the
+	// wallet was empty or never enabled.
+	Payout *wallet.WalletTransactionResult
+}
+
+type Service interface {
+	CloseAccount(ctx context.Context, param CloseAccountParam) (*Closure, error)
+	ReopenAccount(ctx context.Context, param ReopenAccountParam) (*account.Account, error)
+}
+
+type service struct {
+	accounts    account.Service
+	wallets     wallet.Service
+	pockets     pocket.Service
+	loans       loan.Service
+	schedules   schedule.Service
+	payRequests payrequest.Service
+	audits      audit.Service
+	mu          sync.Mutex
+}
+
+func NewService(accounts account.Service, wallets wallet.Service, pockets pocket.Service, loans loan.Service, schedules schedule.Service, payRequests payrequest.Service, audits audit.Service) Service {
+	return &service{
+		accounts:    accounts,
+		wallets:     wallets,
+		pockets:     pockets,
+		loans:       loans,
+		schedules:   schedules,
+		payRequests: payRequests,
+		audits:      audits,
+	}
+}
+
+// CloseAccount pays the remaining balance out to the nominated destination,
+// disables the wallet, ends every session of the customer and marks the
+// account closed. The customer cannot init again until an operator reopens
+// the account. Customers still owing on a loan, with schedules running or
+// with payment requests awaiting an answer must settle them first.
+func (s *service) CloseAccount(ctx context.Context, param CloseAccountParam) (*Closure, error) {
+	if param.CustomerXID == "" {
+		return nil, ErrMissingRequiredParameter
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	acc, err := s.accounts.GetAccount(ctx, param.CustomerXID)
+	if err != nil && err == account.ErrAccountNotFound {
+		return nil, account.ErrAccountNotFound
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed getting account")
+	}
+	if !acc.IsActive() {
+		return nil, account.ErrAccountClosed
+	}
+	err = s.checkCommitments(ctx, param.CustomerXID)
+	if err != nil {
+		return nil, err
+	}
+
+	var closure Closure
+	wal, err := s.wallets.GetWalletByXID(ctx, param.CustomerXID)
+	if err != nil && err != wallet.ErrWalletNotFound {
+		return nil, errors.Wrap(err, "failed getting wallet")
+	}
+	if wal != nil {
+		closure.Payout, err = s.emptyWallet(ctx, wal, param.Destination)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = auth.RevokeSessions(ctx, param.CustomerXID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed revoking sessions")
+	}
+	closed, err := s.accounts.CloseAccount(ctx, param.CustomerXID)
+	if err != nil {
+		return nil, err
+	}
+	closure.Account = *closed
+
+	entry := audit.Entry{
+		SubjectXID: param.CustomerXID,
+		ActorXID:   param.CustomerXID,
+		Action:     audit.ActionAccountClosed,
+	}
+	if closure.Payout != nil {
+		entry.Detail = "payout " + closure.Payout.ReferenceID
+	}
+	_, err = s.audits.Record(ctx, entry)
+	if err != nil {
+		return nil, err
+	}
+	return &closure, nil
+}
+
+// checkCommitments refuses to close the account of xid while money may still
+// have to move through its wallet.
+func (s *service) checkCommitments(ctx context.Context, xid string) error {
+	loans, err := s.loans.GetLoans(ctx, xid)
+	if err != nil {
+		return errors.Wrap(err, "failed getting loans")
+	}
+	for _, l := range loans {
+		if l.Status != loan.LoanStatusPaidOff {
+			return ErrOutstandingLoan
+		}
+	}
+
+	schedules, err := s.schedules.GetSchedules(ctx, xid)
+	if err != nil {
+		return errors.Wrap(err, "failed getting schedules")
+	}
+	for _, sc := range schedules {
+		if sc.Status == schedule.ScheduleStatusActive || sc.Status == schedule.ScheduleStatusPaused {
+			return ErrActiveSchedules
+		}
+	}
+
+	sent, err := s.payRequests.GetSentRequests(ctx, xid)
+	if err != nil {
+		return errors.Wrap(err, "failed getting payment requests")
+	}
+	for _, r := range sent {
+		if r.IsOpen() {
+			return ErrPendingPaymentRequests
+		}
+	}
+	received, err := s.payRequests.GetReceivedRequests(ctx, xid)
+	if err != nil {
+		return errors.Wrap(err, "failed getting payment requests")
+	}
+	for _, r := range received {
+		for _, p := range r.Payers {
+			if p.XID == xid && p.Status == payrequest.PayerStatusPending {
+				return ErrPendingPaymentRequests
+			}
+		}
+	}
+	return nil
+}
+
+// emptyWallet sweeps the pockets of wal back into its balance, pays the
+// balance out net of the payout fee and disables the wallet. Wallets under an
+// operator freeze or still settling movements are left alone. A disabled
+// wallet is enabled for the sweep and payout and disabled again should they
+// fail. A payout the bank rejects leaves the balance in the disabled wallet
+// for operators to settle.
+func (s *service) emptyWallet(ctx context.Context, wal *wallet.Wallet, destination *disbursement.BankAccount) (*wallet.WalletTransactionResult, error) {
+	switch {
+	case wal.Status == wallet.WalletStatusClosed:
+		return nil, nil
+	case wal.Status == wallet.WalletStatusFrozen:
+		return nil, wallet.ErrWalletFrozen
+	case wal.Held > 0:
+		return nil, ErrPendingTransactions
+	case wal.CreditUsed() > 0:
+		return nil, ErrOutstandingCredit
+	}
+
+	pockets, err := s.pockets.GetPockets(ctx, wal.OwnerXID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed getting pockets")
+	}
+	total := wal.Balance
+	for _, p := range pockets {
+		total += p.Balance
+	}
+
+	var amount int
+	if total > 0 {
+		if destination == nil {
+			return nil, ErrMissingRequiredParameter
+		}
+		if err := destination.Validate(); err != nil {
+			ve := wallet.NewValidationError()
+			ve.AddError("bank_account", err)
+			return nil, ve
+		}
+		amount = s.payoutAmount(ctx, total)
+		if amount <= 0 {
+			return nil, ErrBalanceBelowFee
+		}
+	}
+
+	// sweeps and payouts go through the wallet like any movement
+	reenabled := total > 0 && wal.Status == wallet.WalletStatusDisabled
+	if reenabled {
+		_, err := s.wallets.EnableWallet(ctx, wallet.EnableWalletParam{OwnerXID: wal.OwnerXID})
+		if err != nil {
+			return nil, errors.Wrap(err, "failed enabling wallet for payout")
+		}
+	}
+	payout, err := s.payOut(ctx, wal.OwnerXID, pockets, amount, destination)
+	if err != nil {
+		if reenabled {
+			_, disableErr := s.wallets.DisableWallet(ctx, wallet.DisableWalletParam{OwnerXID: wal.OwnerXID})
+			if disableErr != nil {
+				return nil, errors.Wrap(disableErr, "failed disabling wallet again")
+			}
+		}
+		return nil, err
+	}
+
+	_, err = s.wallets.DisableWallet(ctx, wallet.DisableWalletParam{OwnerXID: wal.OwnerXID})
+	if err != nil && err != wallet.ErrWalletDisabled {
+		return nil, errors.Wrap(err, "failed disabling wallet")
+	}
+	return payout, nil
+}
+
+// payoutAmount is the largest closure payout total can cover along with its
+// fee, fees growing with the amount paid out.
+func (s *service) payoutAmount(ctx context.Context, total int) int {
+	covers := func(amount int) bool {
+		return amount+s.wallets.CalculateFee(ctx, wallet.TransactionTypeClosurePayout, amount) <= total
+	}
+	low, high := 0, total
+	for low < high {
+		mid := low + (high-low+1)/2
+		if covers(mid) {
+			low = mid
+		} else {
+			high = mid - 1
+		}
+	}
+	return low
+}
+
+// payOut closes the active pockets of ownerXID and withdraws amount to
+// destination, nil when there is nothing to pay out.
+func (s *service) payOut(ctx context.Context, ownerXID string, pockets []pocket.Pocket, amount int, destination *disbursement.BankAccount) (*wallet.WalletTransactionResult, error) {
+	for _, p := range pockets {
+		if p.Status != pocket.PocketStatusActive {
+			continue
+		}
+		_, err := s.pockets.ClosePocket(ctx, pocket.PocketParam{OwnerXID: ownerXID, PocketID: p.ID})
+		if err != nil {
+			return nil, errors.Wrap(err, "failed closing pocket")
+		}
+	}
+	if amount <= 0 {
+		return nil, nil
+	}
+	return s.wallets.WithdrawWallet(ctx, wallet.WalletTransactionParam{
+		ActorXID:    ownerXID,
+		OwnerXID:    ownerXID,
+		ReferenceID: wallet.SystemReferencePrefix + "closure-" + uuid.NewString(),
+		Amount:      amount,
+		Type:        wallet.TransactionTypeClosurePayout,
+		Destination: destination,
+	})
+}
+
+// ReopenAccount lets the customer of a closed account init again, they
+// enable their wallet themselves.
+func (s *service) ReopenAccount(ctx context.Context, param ReopenAccountParam) (*account.Account, error) {
+	if param.CustomerXID == "" || param.ActorXID == "" {
+		return nil, ErrMissingRequiredParameter
+	}
+
+	acc, err := s.accounts.ReopenAccount(ctx, param.CustomerXID)
+	if err != nil {
+		return nil, err
+	}
+	_, err = s.audits.Record(ctx, audit.Entry{
+		SubjectXID: param.CustomerXID,
+		ActorXID:   param.ActorXID,
+		Action:     audit.ActionAccountReopened,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return acc, nil
+}
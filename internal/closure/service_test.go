@@ -0,0 +1,408 @@
+package closure_test
+
+import (
+	"context"
+	"julo/internal/account"
+	"julo/internal/audit"
+	"julo/internal/auth"
+	"julo/internal/closure"
+	"julo/internal/disbursement"
+	"julo/internal/fee"
+	"julo/internal/loan"
+	"julo/internal/payrequest"
+	"julo/internal/pocket"
+	"julo/internal/schedule"
+	"julo/internal/wallet"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestCloseAccount(t *testing.T) {
+	ctx := context.Background()
+	accounts := account.NewService(account.NewInMemoryRepository())
+	initializer := auth.NewInitializer(accounts)
+	wallets := wallet.NewService(wallet.NewInMemoryRepository())
+	closures := closure.NewService(
+		accounts,
+		wallets,
+		pocket.NewService(pocket.NewInMemoryRepository(), wallets),
+		loan.NewService(loan.NewInMemoryRepository(), wallets),
+		schedule.NewService(schedule.NewInMemoryRepository(), wallets, schedule.LogNotifier{}, schedule.Config{}),
+		payrequest.NewService(payrequest.NewInMemoryRepository(), wallets),
+		audit.NewService(audit.NewInMemoryRepository()),
+	)
+
+	xid := uuid.NewString()
+	result, err := initializer.Init(ctx, auth.InitParam{CustomerXID: xid})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = wallets.EnableWallet(ctx, wallet.EnableWalletParam{OwnerXID: xid})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = wallets.DepositWallet(ctx, wallet.WalletTransactionParam{
+		ActorXID:    xid,
+		OwnerXID:    xid,
+		ReferenceID: uuid.NewString(),
+		Amount:      30000,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("close without destination, should fail", func(t *testing.T) {
+		_, err := closures.CloseAccount(ctx, closure.CloseAccountParam{CustomerXID: xid})
+		if err != closure.ErrMissingRequiredParameter {
+			t.Fatalf("expecting error %s, got %v", closure.ErrMissingRequiredParameter, err)
+		}
+	})
+
+	t.Run("close account, should pay out balance and end sessions", func(t *testing.T) {
+		c, err := closures.CloseAccount(ctx, closure.CloseAccountParam{
+			CustomerXID: xid,
+			Destination: &disbursement.BankAccount{BankCode: "BCA", AccountNumber: "1234567890", AccountName: "Budi"},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c.Account.Status != account.StatusClosed {
+			t.Fatalf("expecting status %s, got %s", account.StatusClosed, c.Account.Status)
+		}
+		if c.Payout == nil || c.Payout.Amount != 30000 {
+			t.Fatalf("expecting payout of 30000, got %+v", c.Payout)
+		}
+
+		wal, err := wallets.GetWalletByXID(ctx, xid)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if wal.Balance != 0 || wal.Status != wallet.WalletStatusDisabled {
+			t.Fatalf("expecting empty disabled wallet, got %d %s", wal.Balance, wal.Status)
+		}
+		if _, err := auth.GetSession(ctx, result.Session.Token); err != auth.ErrSessionNotFound {
+			t.Fatalf("expecting error %s, got %v", auth.ErrSessionNotFound, err)
+		}
+
+		t.Run("init closed account, should fail", func(t *testing.T) {
+			_, err := initializer.Init(ctx, auth.InitParam{CustomerXID: xid})
+			if err == nil {
+				t.Fatal("expecting error")
+			}
+		})
+
+		t.Run("init reopened account, should success", func(t *testing.T) {
+			_, err := closures.ReopenAccount(ctx, closure.ReopenAccountParam{CustomerXID: xid, ActorXID: "ops-1"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			result, err := initializer.Init(ctx, auth.InitParam{CustomerXID: xid})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Session.Account.Status != account.StatusActive {
+				t.Fatalf("expecting status %s, got %s", account.StatusActive, result.Session.Account.Status)
+			}
+			_, err = wallets.EnableWallet(ctx, wallet.EnableWalletParam{OwnerXID: xid})
+			if err != nil {
+				t.Fatal(err)
+			}
+		})
+	})
+
+	t.Run("reopen active account, should fail", func(t *testing.T) {
+		_, err := closures.ReopenAccount(ctx, closure.ReopenAccountParam{CustomerXID: xid, ActorXID: "ops-1"})
+		if err != account.ErrAccountNotClosed {
+			t.Fatalf("expecting error %s, got %v", account.ErrAccountNotClosed, err)
+		}
+	})
+}
+
+func TestCloseAccountWithPockets(t *testing.T) {
+	ctx := context.Background()
+	accounts := account.NewService(account.NewInMemoryRepository())
+	initializer := auth.NewInitializer(accounts)
+	wallets := wallet.NewService(wallet.NewInMemoryRepository())
+	pockets := pocket.NewService(pocket.NewInMemoryRepository(), wallets)
+	closures := closure.NewService(
+		accounts,
+		wallets,
+		pockets,
+		loan.NewService(loan.NewInMemoryRepository(), wallets),
+		schedule.NewService(schedule.NewInMemoryRepository(), wallets, schedule.LogNotifier{}, schedule.Config{}),
+		payrequest.NewService(payrequest.NewInMemoryRepository(), wallets),
+		audit.NewService(audit.NewInMemoryRepository()),
+	)
+
+	xid := uuid.NewString()
+	_, err := initializer.Init(ctx, auth.InitParam{CustomerXID: xid})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = wallets.EnableWallet(ctx, wallet.EnableWalletParam{OwnerXID: xid})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = wallets.DepositWallet(ctx, wallet.WalletTransactionParam{
+		ActorXID:    xid,
+		OwnerXID:    xid,
+		ReferenceID: uuid.NewString(),
+		Amount:      30000,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := pockets.CreatePocket(ctx, pocket.CreatePocketParam{OwnerXID: xid, Name: "holiday"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = pockets.MoveToPocket(ctx, pocket.MovePocketParam{
+		OwnerXID:    xid,
+		PocketID:    p.ID,
+		ReferenceID: uuid.NewString(),
+		Amount:      30000,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the owner disabling the wallet leaves the money in the pocket
+	_, err = wallets.DisableWallet(ctx, wallet.DisableWalletParam{OwnerXID: xid})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("close without destination, should fail", func(t *testing.T) {
+		_, err := closures.CloseAccount(ctx, closure.CloseAccountParam{CustomerXID: xid})
+		if err != closure.ErrMissingRequiredParameter {
+			t.Fatalf("expecting error %s, got %v", closure.ErrMissingRequiredParameter, err)
+		}
+	})
+
+	t.Run("close account, should sweep pockets into the payout", func(t *testing.T) {
+		c, err := closures.CloseAccount(ctx, closure.CloseAccountParam{
+			CustomerXID: xid,
+			Destination: &disbursement.BankAccount{BankCode: "BCA", AccountNumber: "1234567890", AccountName: "Budi"},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c.Payout == nil || c.Payout.Amount != 30000 {
+			t.Fatalf("expecting payout of 30000, got %+v", c.Payout)
+		}
+
+		swept, err := pockets.GetPocket(ctx, pocket.PocketParam{OwnerXID: xid, PocketID: p.ID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if swept.Balance != 0 || swept.Status != pocket.PocketStatusClosed {
+			t.Fatalf("expecting empty closed pocket, got %d %s", swept.Balance, swept.Status)
+		}
+		wal, err := wallets.GetWalletByXID(ctx, xid)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if wal.Balance != 0 || wal.Status != wallet.WalletStatusDisabled {
+			t.Fatalf("expecting empty disabled wallet, got %d %s", wal.Balance, wal.Status)
+		}
+	})
+}
+
+type riskFunc func(check wallet.RiskCheck) wallet.RiskDecision
+
+func (f riskFunc) Assess(ctx context.Context, check wallet.RiskCheck) wallet.RiskAssessment {
+	return wallet.RiskAssessment{Decision: f(check)}
+}
+
+func TestCloseAccountPayout(t *testing.T) {
+	ctx := context.Background()
+	destination := &disbursement.BankAccount{BankCode: "BCA", AccountNumber: "1234567890", AccountName: "Budi"}
+	fees, err := fee.NewEngine(map[string]fee.Rule{
+		string(wallet.TransactionTypeClosurePayout): {Flat: 2500, BasisPoints: 100},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	denied := uuid.NewString()
+	risks := riskFunc(func(check wallet.RiskCheck) wallet.RiskDecision {
+		if check.OwnerXID == denied && check.Type == wallet.TransactionTypeClosurePayout {
+			return wallet.RiskDecisionDeny
+		}
+		return wallet.RiskDecisionAllow
+	})
+	accounts := account.NewService(account.NewInMemoryRepository())
+	initializer := auth.NewInitializer(accounts)
+	wallets := wallet.NewService(wallet.NewInMemoryRepository(), wallet.WithFees(fees, "house"), wallet.WithRiskAssessor(risks))
+	pockets := pocket.NewService(pocket.NewInMemoryRepository(), wallets)
+	closures := closure.NewService(
+		accounts,
+		wallets,
+		pockets,
+		loan.NewService(loan.NewInMemoryRepository(), wallets),
+		schedule.NewService(schedule.NewInMemoryRepository(), wallets, schedule.LogNotifier{}, schedule.Config{}),
+		payrequest.NewService(payrequest.NewInMemoryRepository(), wallets),
+		audit.NewService(audit.NewInMemoryRepository()),
+	)
+
+	open := func(t *testing.T, xid string, amount int) {
+		_, err := initializer.Init(ctx, auth.InitParam{CustomerXID: xid})
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = wallets.EnableWallet(ctx, wallet.EnableWalletParam{OwnerXID: xid})
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = wallets.DepositWallet(ctx, wallet.WalletTransactionParam{
+			ActorXID:    xid,
+			OwnerXID:    xid,
+			ReferenceID: uuid.NewString(),
+			Amount:      amount,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Run("close account, should pay out balance net of fee", func(t *testing.T) {
+		xid := uuid.NewString()
+		open(t, xid, 30000)
+		c, err := closures.CloseAccount(ctx, closure.CloseAccountParam{CustomerXID: xid, Destination: destination})
+		if err != nil {
+			t.Fatal(err)
+		}
+		// 27228 is charged 2500 + 272
+		if c.Payout == nil || c.Payout.Amount != 27228 || c.Payout.Fee != 2772 {
+			t.Fatalf("expecting payout of 27228 charged 2772, got %+v", c.Payout)
+		}
+		wal, _ := wallets.GetWalletByXID(ctx, xid)
+		if wal.Balance != 0 || wal.Status != wallet.WalletStatusDisabled {
+			t.Fatalf("expecting empty disabled wallet, got %d %s", wal.Balance, wal.Status)
+		}
+	})
+
+	t.Run("close account with balance below fee, should fail", func(t *testing.T) {
+		xid := uuid.NewString()
+		open(t, xid, 2000)
+		_, err := closures.CloseAccount(ctx, closure.CloseAccountParam{CustomerXID: xid, Destination: destination})
+		if err != closure.ErrBalanceBelowFee {
+			t.Fatalf("expecting error %s, got %v", closure.ErrBalanceBelowFee, err)
+		}
+	})
+
+	t.Run("close account with invalid destination, should fail before enabling", func(t *testing.T) {
+		xid := uuid.NewString()
+		open(t, xid, 30000)
+		_, err := wallets.DisableWallet(ctx, wallet.DisableWalletParam{OwnerXID: xid})
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = closures.CloseAccount(ctx, closure.CloseAccountParam{CustomerXID: xid, Destination: &disbursement.BankAccount{AccountNumber: "1234567890"}})
+		if _, ok := err.(wallet.ValidationError); !ok {
+			t.Fatalf("expecting validation error, got %v", err)
+		}
+		wal, _ := wallets.GetWalletByXID(ctx, xid)
+		if wal.Status != wallet.WalletStatusDisabled {
+			t.Fatalf("expecting status %s, got %s", wallet.WalletStatusDisabled, wal.Status)
+		}
+	})
+
+	t.Run("close account with denied payout, should disable wallet again", func(t *testing.T) {
+		open(t, denied, 30000)
+		_, err := wallets.DisableWallet(ctx, wallet.DisableWalletParam{OwnerXID: denied})
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = closures.CloseAccount(ctx, closure.CloseAccountParam{CustomerXID: denied, Destination: destination})
+		if err != wallet.ErrTransactionDenied {
+			t.Fatalf("expecting error %s, got %v", wallet.ErrTransactionDenied, err)
+		}
+		wal, _ := wallets.GetWalletByXID(ctx, denied)
+		if wal.Balance != 30000 || wal.Status != wallet.WalletStatusDisabled {
+			t.Fatalf("expecting disabled wallet with 30000, got %d %s", wal.Balance, wal.Status)
+		}
+		acc, _ := accounts.GetAccount(ctx, denied)
+		if !acc.IsActive() {
+			t.Fatalf("expecting active account, got %s", acc.Status)
+		}
+	})
+}
+
+func TestCloseAccountWithCommitments(t *testing.T) {
+	ctx := context.Background()
+	accounts := account.NewService(account.NewInMemoryRepository())
+	initializer := auth.NewInitializer(accounts)
+	wallets := wallet.NewService(wallet.NewInMemoryRepository())
+	loans := loan.NewService(loan.NewInMemoryRepository(), wallets)
+	schedules := schedule.NewService(schedule.NewInMemoryRepository(), wallets, schedule.LogNotifier{}, schedule.Config{})
+	payRequests := payrequest.NewService(payrequest.NewInMemoryRepository(), wallets)
+	closures := closure.NewService(
+		accounts,
+		wallets,
+		pocket.NewService(pocket.NewInMemoryRepository(), wallets),
+		loans,
+		schedules,
+		payRequests,
+		audit.NewService(audit.NewInMemoryRepository()),
+	)
+
+	open := func(t *testing.T) string {
+		xid := uuid.NewString()
+		_, err := initializer.Init(ctx, auth.InitParam{CustomerXID: xid})
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = wallets.EnableWallet(ctx, wallet.EnableWalletParam{OwnerXID: xid})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return xid
+	}
+	other := open(t)
+
+	cases := []struct {
+		name   string
+		err    error
+		commit func(t *testing.T, xid string)
+	}{
+		{"close with outstanding loan", closure.ErrOutstandingLoan, func(t *testing.T, xid string) {
+			_, err := loans.CreateLoan(ctx, loan.CreateLoanParam{AccountXID: xid, Principal: 100000, TenorMonths: 3})
+			if err != nil {
+				t.Fatal(err)
+			}
+		}},
+		{"close with active schedule", closure.ErrActiveSchedules, func(t *testing.T, xid string) {
+			_, err := schedules.CreateSchedule(ctx, schedule.CreateScheduleParam{OwnerXID: xid, RecipientXID: other, Amount: 10000, Recurrence: "@every 24h", StartsAt: time.Now().Add(time.Hour)})
+			if err != nil {
+				t.Fatal(err)
+			}
+		}},
+		{"close with sent payment request", closure.ErrPendingPaymentRequests, func(t *testing.T, xid string) {
+			_, err := payRequests.CreateRequest(ctx, payrequest.CreateRequestParam{RequesterXID: xid, Amount: 10000, PayerXIDs: []string{other}})
+			if err != nil {
+				t.Fatal(err)
+			}
+		}},
+		{"close with received payment request", closure.ErrPendingPaymentRequests, func(t *testing.T, xid string) {
+			_, err := payRequests.CreateRequest(ctx, payrequest.CreateRequestParam{RequesterXID: other, Amount: 10000, PayerXIDs: []string{xid}})
+			if err != nil {
+				t.Fatal(err)
+			}
+		}},
+	}
+	for _, c := range cases {
+		t.Run(c.name+", should fail", func(t *testing.T) {
+			xid := open(t)
+			c.commit(t, xid)
+			_, err := closures.CloseAccount(ctx, closure.CloseAccountParam{CustomerXID: xid})
+			if err != c.err {
+				t.Fatalf("expecting error %s, got %v", c.err, err)
+			}
+			acc, _ := accounts.GetAccount(ctx, xid)
+			if !acc.IsActive() {
+				t.Fatalf("expecting active account, got %s", acc.Status)
+			}
+		})
+	}
+}
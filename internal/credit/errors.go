@@ -0,0 +1,11 @@
+package credit
+
+import "errors"
+
+var (
+	ErrCreditLineNotFound       = errors.New("credit line not found")
+	ErrMissingRequiredParameter = errors.New("missing required parameter")
+	ErrInvalidLimit             = errors.New("invalid credit limit")
+	ErrInvalidInterest          = errors.New("invalid interest rate")
+	ErrInvalidStatementDay      = errors.New("statement day must be between 1 and 28")
+)
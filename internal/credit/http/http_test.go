@@ -0,0 +1,171 @@
+package http_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"julo/internal/account"
+	"julo/internal/auth"
+	authhttp "julo/internal/auth/http"
+	"julo/internal/credit"
+	credithttp "julo/internal/credit/http"
+	httphelper "julo/internal/http"
+	"julo/internal/wallet"
+	wallethttp "julo/internal/wallet/http"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/google/uuid"
+)
+
+func TestCreditLine(t *testing.T) {
+	ctx := context.Background()
+	wallets := wallet.NewService(wallet.NewInMemoryRepository())
+	credits := credit.NewService(credit.NewInMemoryRepository(), wallets, credit.Config{})
+	adminToken := uuid.NewString()
+
+	router := chi.NewRouter()
+	router.Mount("/api/v1", router.Group(func(r chi.Router) {
+		r.Mount("/wallet", r.Group(func(r chi.Router) {
+			r.Use(authhttp.Middleware)
+			r.Get("/", wallethttp.ViewWalletBalanceHandler(wallets).ServeHTTP)
+			r.Get("/credit", credithttp.ViewCreditLineHandler(credits, wallets).ServeHTTP)
+			r.Get("/credit/statements", credithttp.ViewStatementsHandler(credits).ServeHTTP)
+		}))
+		r.Mount("/admin", r.Group(func(r chi.Router) {
+			r.Use(authhttp.AdminMiddleware(adminToken))
+			r.Post("/credit-lines", credithttp.OpenCreditLineHandler(credits, wallets).ServeHTTP)
+		}))
+	}))
+	server := httptest.NewServer(router)
+	defer server.Close()
+	baseUrl := server.URL
+
+	owner := uuid.NewString()
+	_, err := wallets.EnableWallet(ctx, wallet.EnableWalletParam{
+		OwnerXID: owner,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := uuid.NewString()
+	err = auth.StoreSession(ctx, auth.Session{
+		Token:   token,
+		Account: account.Account{XID: owner},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("view credit line without one, should fail", func(t *testing.T) {
+		req := buildAuthenticatedRequest(t, http.MethodGet, baseUrl+"/api/v1/wallet/credit", token, nil)
+		res, err := server.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.StatusCode != http.StatusNotFound {
+			t.Fatalf("expecting status %v, got %v", http.StatusNotFound, res.StatusCode)
+		}
+	})
+
+	t.Run("open credit line, should success", func(t *testing.T) {
+		form := url.Values{}
+		form.Set("customer_xid", owner)
+		form.Set("limit", "500000")
+		form.Set("annual_interest_bps", "2400")
+		form.Set("statement_day", "25")
+		req := buildAuthenticatedRequest(t, http.MethodPost, baseUrl+"/api/v1/admin/credit-lines", adminToken, bytes.NewBufferString(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		res, err := server.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.StatusCode != http.StatusCreated {
+			t.Fatalf("expecting status %v, got %v", http.StatusCreated, res.StatusCode)
+		}
+
+		_, err = wallets.WithdrawWallet(ctx, wallet.WalletTransactionParam{
+			ActorXID:    owner,
+			OwnerXID:    owner,
+			ReferenceID: uuid.NewString(),
+			Amount:      200000,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		t.Run("view wallet, should report credit", func(t *testing.T) {
+			req := buildAuthenticatedRequest(t, http.MethodGet, baseUrl+"/api/v1/wallet", token, nil)
+			res, err := server.Client().Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.StatusCode != http.StatusOK {
+				t.Fatalf("expecting status %v, got %v", http.StatusOK, res.StatusCode)
+			}
+
+			var response httphelper.Response
+			err = json.NewDecoder(res.Body).Decode(&response)
+			if err != nil {
+				t.Fatal(err)
+			}
+			wal := response.Data.(map[string]interface{})["wallet"].(map[string]interface{})
+			c := wal["credit"].(map[string]interface{})
+			if wal["balance"] != float64(-200000) || c["credit_used"] != float64(200000) || c["available_credit"] != float64(300000) {
+				t.Fatalf("unexpected wallet %v", wal)
+			}
+		})
+
+		t.Run("view credit line, should success", func(t *testing.T) {
+			req := buildAuthenticatedRequest(t, http.MethodGet, baseUrl+"/api/v1/wallet/credit", token, nil)
+			res, err := server.Client().Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.StatusCode != http.StatusOK {
+				t.Fatalf("expecting status %v, got %v", http.StatusOK, res.StatusCode)
+			}
+
+			var response httphelper.Response
+			err = json.NewDecoder(res.Body).Decode(&response)
+			if err != nil {
+				t.Fatal(err)
+			}
+			l := response.Data.(map[string]interface{})["credit_line"].(map[string]interface{})
+			if l["statement_day"] != float64(25) || l["cash_balance"] != float64(0) {
+				t.Fatalf("unexpected credit line %v", l)
+			}
+		})
+	})
+
+	t.Run("open credit line with customer token, should fail", func(t *testing.T) {
+		form := url.Values{}
+		form.Set("customer_xid", owner)
+		form.Set("limit", "900000")
+		req := buildAuthenticatedRequest(t, http.MethodPost, baseUrl+"/api/v1/admin/credit-lines", token, bytes.NewBufferString(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		res, err := server.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.StatusCode != http.StatusUnauthorized {
+			t.Fatalf("expecting status %v, got %v", http.StatusUnauthorized, res.StatusCode)
+		}
+	})
+}
+
+func buildAuthenticatedRequest(t *testing.T, method string, url string, token string, body io.Reader) *http.Request {
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Add("Authorization", fmt.Sprintf("Token %s", token))
+	return req
+}
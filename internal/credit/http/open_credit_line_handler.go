@@ -0,0 +1,58 @@
+package http
+
+import (
+	"julo/internal/credit"
+	httphelper "julo/internal/http"
+	"julo/internal/wallet"
+	"net/http"
+)
+
+// OpenCreditLineHandler is an operator endpoint granting the account in
+// customer_xid a credit line, or changing the terms of its credit line.
+func OpenCreditLineHandler(credits credit.Service, wallets wallet.Service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var response httphelper.Response
+
+		req := openCreditLineRequest{StatementDay: 1}
+		if err := httphelper.Decode(w, r, &req); err != nil {
+			httphelper.WriteRequestErrorJSON(w, err)
+			return
+		}
+
+		l, err := credits.OpenCreditLine(r.Context(), credit.OpenCreditLineParam{
+			OwnerXID:                  req.CustomerXID,
+			Limit:                     req.Limit,
+			AnnualInterestBasisPoints: req.AnnualInterestBasisPoints,
+			StatementDay:              req.StatementDay,
+		})
+		if err != nil {
+			switch err {
+			case credit.ErrMissingRequiredParameter, credit.ErrInvalidLimit, credit.ErrInvalidInterest, credit.ErrInvalidStatementDay,
+				wallet.ErrWalletNotFound, wallet.ErrInvalidCreditLimit:
+				httphelper.WriteErrorJSON(w, http.StatusBadRequest, err)
+			default:
+				httphelper.WriteErrorJSON(w, http.StatusInternalServerError, err)
+			}
+			return
+		}
+
+		wal, err := wallets.GetWalletByXID(r.Context(), l.OwnerXID)
+		if err != nil {
+			httphelper.WriteErrorJSON(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		response.Status = "success"
+		response.Data = map[string]interface{}{
+			"credit_line": newCreditLineResponse(*l, *wal),
+		}
+		httphelper.WriteJSON(w, http.StatusCreated, response)
+	})
+}
+
+type openCreditLineRequest struct {
+	CustomerXID               string `json:"customer_xid" validate:"required"`
+	Limit                     int    `json:"limit" validate:"required"`
+	AnnualInterestBasisPoints int    `json:"annual_interest_bps"`
+	StatementDay              int    `json:"statement_day"`
+}
@@ -0,0 +1,55 @@
+package http
+
+import (
+	"julo/internal/credit"
+	"julo/internal/wallet"
+	"time"
+)
+
+type creditLineResponse struct {
+	OwnedBy                   string    `json:"owned_by"`
+	Limit                     int       `json:"limit"`
+	AnnualInterestBasisPoints int       `json:"annual_interest_bps"`
+	StatementDay              int       `json:"statement_day"`
+	NextStatementAt           time.Time `json:"next_statement_at"`
+	CashBalance               int       `json:"cash_balance"`
+	CreditUsed                int       `json:"credit_used"`
+	AvailableCredit           int       `json:"available_credit"`
+	OpenedAt                  time.Time `json:"opened_at"`
+}
+
+func newCreditLineResponse(l credit.CreditLine, w wallet.Wallet) creditLineResponse {
+	return creditLineResponse{
+		OwnedBy:                   l.OwnerXID,
+		Limit:                     l.Limit,
+		AnnualInterestBasisPoints: l.AnnualInterestBasisPoints,
+		StatementDay:              l.StatementDay,
+		NextStatementAt:           l.NextStatementAt,
+		CashBalance:               w.CashBalance(),
+		CreditUsed:                w.CreditUsed(),
+		AvailableCredit:           w.AvailableCredit(),
+		OpenedAt:                  l.OpenedAt,
+	}
+}
+
+type statementResponse struct {
+	ID                    string    `json:"id"`
+	PeriodStart           time.Time `json:"period_start"`
+	PeriodEnd             time.Time `json:"period_end"`
+	Interest              int       `json:"interest"`
+	InterestTransactionID string    `json:"interest_transaction_id,omitempty"`
+	DueAmount             int       `json:"due_amount"`
+	DueDate               time.Time `json:"due_date"`
+}
+
+func newStatementResponse(s credit.Statement) statementResponse {
+	return statementResponse{
+		ID:                    s.ID,
+		PeriodStart:           s.PeriodStart,
+		PeriodEnd:             s.PeriodEnd,
+		Interest:              s.Interest,
+		InterestTransactionID: s.InterestTransactionID,
+		DueAmount:             s.DueAmount,
+		DueDate:               s.DueDate,
+	}
+}
@@ -0,0 +1,69 @@
+package http
+
+import (
+	"julo/internal/auth"
+	"julo/internal/credit"
+	httphelper "julo/internal/http"
+	"julo/internal/wallet"
+	"net/http"
+)
+
+func ViewCreditLineHandler(credits credit.Service, wallets wallet.Service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var response httphelper.Response
+		session := auth.SessionFromContext(r.Context())
+		if session == nil {
+			httphelper.WriteErrorJSON(w, http.StatusUnauthorized, auth.ErrSessionNotFound)
+			return
+		}
+
+		l, err := credits.GetCreditLine(r.Context(), session.Account.XID)
+		if err != nil && err == credit.ErrCreditLineNotFound {
+			httphelper.WriteErrorJSON(w, http.StatusNotFound, err)
+			return
+		} else if err != nil {
+			httphelper.WriteErrorJSON(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		wal, err := wallets.GetWalletByXID(r.Context(), session.Account.XID)
+		if err != nil {
+			httphelper.WriteErrorJSON(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		response.Status = "success"
+		response.Data = map[string]interface{}{
+			"credit_line": newCreditLineResponse(*l, *wal),
+		}
+		httphelper.WriteJSON(w, http.StatusOK, response)
+	})
+}
+
+func ViewStatementsHandler(credits credit.Service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var response httphelper.Response
+		session := auth.SessionFromContext(r.Context())
+		if session == nil {
+			httphelper.WriteErrorJSON(w, http.StatusUnauthorized, auth.ErrSessionNotFound)
+			return
+		}
+
+		result, err := credits.GetStatements(r.Context(), session.Account.XID)
+		if err != nil {
+			httphelper.WriteErrorJSON(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		data := make([]statementResponse, 0, len(result))
+		for _, s := range result {
+			data = append(data, newStatementResponse(s))
+		}
+
+		response.Status = "success"
+		response.Data = map[string]interface{}{
+			"statements": data,
+		}
+		httphelper.WriteJSON(w, http.StatusOK, response)
+	})
+}
@@ -0,0 +1,102 @@
+package credit
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// CreditLine is the pay-later credit granted on the wallet of OwnerXID. The
+// limit itself is enforced by the wallet, the credit line tracks the interest
+// and the monthly statements.
+type CreditLine struct {
+	OwnerXID                  string
+	Limit                     int
+	AnnualInterestBasisPoints int
+	// StatementDay is the day of the month statements are closed on.
+	StatementDay int
+	// UsedBalanceDays sums the credit used on every day accrued since the
+	// last statement, interest is charged on its daily average.
+	UsedBalanceDays int
+	AccruedUntil    time.Time
+	NextStatementAt time.Time
+	OpenedAt        time.Time
+}
+
+type Statement struct {
+	ID          string
+	OwnerXID    string
+	PeriodStart time.Time
+	PeriodEnd   time.Time
+	Interest    int
+	// InterestTransactionID is the wallet transaction charging Interest,
+	// empty when there was nothing to charge.
+	InterestTransactionID string
+	// DueAmount is the credit used at the close of the statement, interest
+	// included, which is to be paid back by DueDate.
+	DueAmount int
+	DueDate   time.Time
+	CreatedAt time.Time
+}
+
+type Repository interface {
+	SaveCreditLine(ctx context.Context, l CreditLine) error
+	GetCreditLine(ctx context.Context, ownerXID string) (*CreditLine, error)
+	GetCreditLines(ctx context.Context) ([]CreditLine, error)
+	CreateStatement(ctx context.Context, s Statement) error
+	GetStatements(ctx context.Context, ownerXID string) ([]Statement, error)
+}
+
+type InMemoryRepository struct {
+	lines      sync.Map
+	statements sync.Map
+}
+
+func NewInMemoryRepository() Repository {
+	return &InMemoryRepository{}
+}
+
+func (r *InMemoryRepository) SaveCreditLine(ctx context.Context, l CreditLine) error {
+	r.lines.Store(l.OwnerXID, &l)
+	return nil
+}
+
+func (r *InMemoryRepository) GetCreditLine(ctx context.Context, ownerXID string) (*CreditLine, error) {
+	v, ok := r.lines.Load(ownerXID)
+	if !ok {
+		return nil, ErrCreditLineNotFound
+	}
+
+	l := *v.(*CreditLine)
+	return &l, nil
+}
+
+func (r *InMemoryRepository) GetCreditLines(ctx context.Context) ([]CreditLine, error) {
+	lines := []CreditLine{}
+	r.lines.Range(func(key, value any) bool {
+		lines = append(lines, *value.(*CreditLine))
+		return true
+	})
+	sort.Slice(lines, func(i, j int) bool {
+		return lines[i].OpenedAt.Before(lines[j].OpenedAt)
+	})
+	return lines, nil
+}
+
+func (r *InMemoryRepository) CreateStatement(ctx context.Context, s Statement) error {
+	var statements []Statement
+	if v, ok := r.statements.Load(s.OwnerXID); ok {
+		statements = v.([]Statement)
+	}
+	r.statements.Store(s.OwnerXID, append(statements[:len(statements):len(statements)], s))
+	return nil
+}
+
+func (r *InMemoryRepository) GetStatements(ctx context.Context, ownerXID string) ([]Statement, error) {
+	v, ok := r.statements.Load(ownerXID)
+	if !ok {
+		return []Statement{}, nil
+	}
+	return v.([]Statement), nil
+}
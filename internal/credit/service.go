@@ -0,0 +1,310 @@
+package credit
+
+import (
+	"context"
+	"julo/internal/wallet"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+type OpenCreditLineParam struct {
+	OwnerXID                  string
+	Limit                     int
+	AnnualInterestBasisPoints int
+	StatementDay              int
+}
+
+func (p OpenCreditLineParam) Validate() error {
+	if p.OwnerXID == "" {
+		return ErrMissingRequiredParameter
+	}
+	if p.Limit < 0 {
+		return ErrInvalidLimit
+	}
+	if p.AnnualInterestBasisPoints < 0 {
+		return ErrInvalidInterest
+	}
+	if p.StatementDay < 1 || p.StatementDay > 28 {
+		return ErrInvalidStatementDay
+	}
+	return nil
+}
+
+type Config struct {
+	// GracePeriod is the time between the close of a statement and the
+	// date its due amount is to be paid.
+	GracePeriod time.Duration
+}
+
+type Service interface {
+	OpenCreditLine(ctx context.Context, param OpenCreditLineParam) (*CreditLine, error)
+	GetCreditLine(ctx context.Context, ownerXID string) (*CreditLine, error)
+	GetStatements(ctx context.Context, ownerXID string) ([]Statement, error)
+	AccrueInterest(ctx context.Context, now time.Time) error
+	CloseStatements(ctx context.Context, now time.Time) ([]Statement, error)
+}
+
+type service struct {
+	repo    Repository
+	wallets wallet.Service
+	config  Config
+	mu      sync.Mutex
+}
+
+func NewService(repo Repository, wallets wallet.Service, config Config) Service {
+	if config.GracePeriod <= 0 {
+		config.GracePeriod = 14 * 24 * time.Hour
+	}
+	return &service{
+		repo:    repo,
+		wallets: wallets,
+		config:  config,
+	}
+}
+
+// OpenCreditLine grants a credit line on the owner's wallet, or changes the
+// terms of the existing one. Interest accrued so far is kept.
+func (s *service) OpenCreditLine(ctx context.Context, param OpenCreditLineParam) (*CreditLine, error) {
+	err := param.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	l, err := s.repo.GetCreditLine(ctx, param.OwnerXID)
+	if err != nil && err == ErrCreditLineNotFound {
+		l = &CreditLine{
+			OwnerXID:        param.OwnerXID,
+			AccruedUntil:    startOfDay(now),
+			NextStatementAt: nextStatementAt(now, param.StatementDay),
+			OpenedAt:        now,
+		}
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed getting credit line")
+	}
+
+	_, err = s.wallets.SetCreditLimit(ctx, wallet.SetCreditLimitParam{
+		OwnerXID: param.OwnerXID,
+		Limit:    param.Limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if l.StatementDay != param.StatementDay {
+		l.NextStatementAt = nextStatementAt(now, param.StatementDay)
+	}
+	l.Limit = param.Limit
+	l.AnnualInterestBasisPoints = param.AnnualInterestBasisPoints
+	l.StatementDay = param.StatementDay
+	err = s.repo.SaveCreditLine(ctx, *l)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed saving credit line")
+	}
+
+	return l, nil
+}
+
+func (s *service) GetCreditLine(ctx context.Context, ownerXID string) (*CreditLine, error) {
+	l, err := s.repo.GetCreditLine(ctx, ownerXID)
+	if err != nil && err == ErrCreditLineNotFound {
+		return nil, ErrCreditLineNotFound
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed getting credit line")
+	}
+	return l, nil
+}
+
+func (s *service) GetStatements(ctx context.Context, ownerXID string) ([]Statement, error) {
+	statements, err := s.repo.GetStatements(ctx, ownerXID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed getting statements")
+	}
+	return statements, nil
+}
+
+// AccrueInterest records the credit used for every day that ended since the
+// last accrual. Days missed while the service was down are accrued with the
+// credit used back then. A credit line that cannot be accrued does not hold
+// up the others, the first error is returned once all were tried.
+func (s *service) AccrueInterest(ctx context.Context, now time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	lines, err := s.repo.GetCreditLines(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed getting credit lines")
+	}
+
+	var firstErr error
+	for _, l := range lines {
+		err = s.accrue(ctx, &l, now)
+		if err != nil && firstErr == nil {
+			firstErr = errors.Wrapf(err, "failed accruing credit line of %s", l.OwnerXID)
+		}
+	}
+	return firstErr
+}
+
+// CloseStatements closes the statements of the credit lines whose statement
+// day has come, charging the interest accrued over the period to the wallet.
+// A credit line that cannot be closed does not hold up the others, the first
+// error is returned along with the statements closed.
+func (s *service) CloseStatements(ctx context.Context, now time.Time) ([]Statement, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	lines, err := s.repo.GetCreditLines(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed getting credit lines")
+	}
+
+	statements := []Statement{}
+	var firstErr error
+	for _, l := range lines {
+		closed, err := s.closeDue(ctx, &l, now)
+		statements = append(statements, closed...)
+		if err != nil && firstErr == nil {
+			firstErr = errors.Wrapf(err, "failed closing credit line of %s", l.OwnerXID)
+		}
+	}
+	return statements, firstErr
+}
+
+// closeDue closes the statements of l that are due at now. After downtime
+// several statements may have come due, each is closed on its own period.
+func (s *service) closeDue(ctx context.Context, l *CreditLine, now time.Time) ([]Statement, error) {
+	statements := []Statement{}
+	for !now.Before(l.NextStatementAt) {
+		err := s.accrue(ctx, l, l.NextStatementAt)
+		if err != nil {
+			return statements, err
+		}
+
+		statement, err := s.close(ctx, l)
+		if err != nil {
+			return statements, err
+		}
+		statements = append(statements, *statement)
+	}
+	return statements, nil
+}
+
+// accrue adds the credit used at the end of every day since the last
+// accrual, replaying the transactions of the wallet into the balance of each
+// day.
+func (s *service) accrue(ctx context.Context, l *CreditLine, now time.Time) error {
+	today := startOfDay(now)
+	if !l.AccruedUntil.Before(today) {
+		return nil
+	}
+
+	wal, err := s.wallets.GetWalletByXID(ctx, l.OwnerXID)
+	if err != nil {
+		return errors.Wrap(err, "failed getting wallet")
+	}
+	transactions, err := s.wallets.GetSettledTransactions(ctx, wallet.GetSettledTransactionsParam{
+		WalletID: wal.ID,
+		To:       today,
+	})
+	if err != nil {
+		return err
+	}
+
+	for day := l.AccruedUntil; day.Before(today); day = day.AddDate(0, 0, 1) {
+		if balance := wallet.BalanceAt(transactions, day.AddDate(0, 0, 1)); balance < 0 {
+			l.UsedBalanceDays += -balance
+		}
+	}
+	l.AccruedUntil = today
+
+	err = s.repo.SaveCreditLine(ctx, *l)
+	if err != nil {
+		return errors.Wrap(err, "failed saving credit line")
+	}
+	return nil
+}
+
+func (s *service) close(ctx context.Context, l *CreditLine) (*Statement, error) {
+	periodEnd := l.NextStatementAt
+	statement := Statement{
+		ID:          uuid.NewString(),
+		OwnerXID:    l.OwnerXID,
+		PeriodStart: previousStatementAt(periodEnd, l.OpenedAt),
+		PeriodEnd:   periodEnd,
+		Interest:    interest(l.UsedBalanceDays, l.AnnualInterestBasisPoints),
+		DueDate:     periodEnd.Add(s.config.GracePeriod),
+		CreatedAt:   time.Now(),
+	}
+
+	if statement.Interest > 0 {
+		result, err := s.wallets.WithdrawWallet(ctx, wallet.WalletTransactionParam{
+			ActorXID:    "credit:" + l.OwnerXID,
+			OwnerXID:    l.OwnerXID,
+			ReferenceID: wallet.SystemReferencePrefix + "credit-interest-" + periodEnd.Format("20060102"),
+			Amount:      statement.Interest,
+			Type:        wallet.TransactionTypeCreditInterest,
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "failed charging interest")
+		}
+		statement.InterestTransactionID = result.ID
+	}
+
+	wal, err := s.wallets.GetWalletByXID(ctx, l.OwnerXID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed getting wallet")
+	}
+	statement.DueAmount = wal.CreditUsed()
+
+	err = s.repo.CreateStatement(ctx, statement)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed creating statement")
+	}
+
+	l.UsedBalanceDays = 0
+	l.NextStatementAt = nextStatementAt(periodEnd, l.StatementDay)
+	err = s.repo.SaveCreditLine(ctx, *l)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed saving credit line")
+	}
+
+	return &statement, nil
+}
+
+// interest is the interest on usedBalanceDays at the annual rate, using an
+// actual/365 day count and rounding half up to the minor unit.
+func interest(usedBalanceDays int, annualBasisPoints int) int {
+	const denominator = 10000 * 365
+	return (usedBalanceDays*annualBasisPoints + denominator/2) / denominator
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// nextStatementAt is the first statement day strictly after t.
+func nextStatementAt(t time.Time, day int) time.Time {
+	next := time.Date(t.Year(), t.Month(), day, 0, 0, 0, 0, t.Location())
+	if !next.After(t) {
+		next = next.AddDate(0, 1, 0)
+	}
+	return next
+}
+
+// previousStatementAt is the start of the period closing at end, the
+// statement day a month earlier, or the day the credit line was opened when
+// that is later.
+func previousStatementAt(end time.Time, openedAt time.Time) time.Time {
+	start := end.AddDate(0, -1, 0)
+	if opened := startOfDay(openedAt); opened.After(start) {
+		return opened
+	}
+	return start
+}
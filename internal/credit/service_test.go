@@ -0,0 +1,227 @@
+package credit_test
+
+import (
+	"context"
+	"julo/internal/credit"
+	"julo/internal/wallet"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestCreditLine(t *testing.T) {
+	ctx := context.Background()
+	wallets := wallet.NewService(wallet.NewInMemoryRepository())
+	credits := credit.NewService(credit.NewInMemoryRepository(), wallets, credit.Config{
+		GracePeriod: 10 * 24 * time.Hour,
+	})
+
+	owner := uuid.NewString()
+	_, err := wallets.EnableWallet(ctx, wallet.EnableWalletParam{
+		OwnerXID: owner,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 36.5% a year is 0.1% a day
+	l, err := credits.OpenCreditLine(ctx, credit.OpenCreditLineParam{
+		OwnerXID:                  owner,
+		Limit:                     100000,
+		AnnualInterestBasisPoints: 3650,
+		StatementDay:              1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = wallets.WithdrawWallet(ctx, wallet.WalletTransactionParam{
+		ActorXID:    owner,
+		OwnerXID:    owner,
+		ReferenceID: uuid.NewString(),
+		Amount:      50000,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = wallets.WithdrawWallet(ctx, wallet.WalletTransactionParam{
+		ActorXID:    owner,
+		OwnerXID:    owner,
+		ReferenceID: uuid.NewString(),
+		Amount:      60000,
+	})
+	if err != wallet.ErrInsufficientBalance {
+		t.Fatalf("expecting error %s, got %v", wallet.ErrInsufficientBalance, err)
+	}
+
+	w, err := wallets.GetWalletByXID(ctx, owner)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w.CashBalance() != 0 || w.CreditUsed() != 50000 || w.AvailableCredit() != 50000 {
+		t.Fatalf("expecting cash 0, credit used 50000 and 50000 available, got %d, %d and %d", w.CashBalance(), w.CreditUsed(), w.AvailableCredit())
+	}
+
+	_, err = credits.OpenCreditLine(ctx, credit.OpenCreditLineParam{
+		OwnerXID:     owner,
+		Limit:        10000,
+		StatementDay: 1,
+	})
+	if err != wallet.ErrInvalidCreditLimit {
+		t.Fatalf("expecting error %s, got %v", wallet.ErrInvalidCreditLimit, err)
+	}
+
+	statements, err := credits.CloseStatements(ctx, l.NextStatementAt.Add(-time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(statements) != 0 {
+		t.Fatalf("expecting no statement before the statement day, got %+v", statements)
+	}
+
+	days := int(l.NextStatementAt.Sub(l.AccruedUntil).Hours()+12) / 24
+	err = credits.AccrueInterest(ctx, l.AccruedUntil.AddDate(0, 0, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	statements, err = credits.CloseStatements(ctx, l.NextStatementAt.AddDate(0, 1, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(statements) != 2 {
+		t.Fatalf("expecting 2 statements to catch up, got %+v", statements)
+	}
+	first := statements[0]
+	if first.Interest != 50*days || first.DueAmount != 50000+50*days {
+		t.Fatalf("expecting interest %d over %d days due with the credit used, got %+v", 50*days, days, first)
+	}
+	if !first.DueDate.Equal(first.PeriodEnd.Add(10 * 24 * time.Hour)) {
+		t.Fatalf("expecting due date 10 days after %s, got %s", first.PeriodEnd, first.DueDate)
+	}
+	if !statements[1].PeriodStart.Equal(first.PeriodEnd) {
+		t.Fatalf("expecting the second period to start at %s, got %s", first.PeriodEnd, statements[1].PeriodStart)
+	}
+
+	w, err = wallets.GetWalletByXID(ctx, owner)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w.CreditUsed() != statements[1].DueAmount {
+		t.Fatalf("expecting credit used %d, got %d", statements[1].DueAmount, w.CreditUsed())
+	}
+}
+
+func TestAccrueInterestReplaysMissedDays(t *testing.T) {
+	ctx := context.Background()
+	repo := wallet.NewInMemoryRepository()
+	wallets := wallet.NewService(repo)
+	credits := credit.NewService(credit.NewInMemoryRepository(), wallets, credit.Config{})
+
+	owner := uuid.NewString()
+	_, err := wallets.EnableWallet(ctx, wallet.EnableWalletParam{OwnerXID: owner})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err := credits.OpenCreditLine(ctx, credit.OpenCreditLineParam{
+		OwnerXID:                  owner,
+		Limit:                     100000,
+		AnnualInterestBasisPoints: 3650,
+		StatementDay:              1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = wallets.WithdrawWallet(ctx, wallet.WalletTransactionParam{
+		ActorXID:    owner,
+		OwnerXID:    owner,
+		ReferenceID: uuid.NewString(),
+		Amount:      50000,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the credit is repaid on the third day, while the service is down
+	w, err := wallets.GetWalletByXID(ctx, owner)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repaidAt := l.AccruedUntil.AddDate(0, 0, 2).Add(12 * time.Hour)
+	err = repo.CreateTransaction(ctx, wallet.WalletTransaction{
+		ID:          uuid.NewString(),
+		WalletID:    w.ID,
+		ActorXID:    owner,
+		ReferenceID: uuid.NewString(),
+		Type:        wallet.TransactionTypeDeposit,
+		Date:        repaidAt,
+		Amount:      50000,
+		Status:      wallet.TransactionStatusSuccess,
+		SettledAt:   repaidAt,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Balance += 50000
+	err = repo.UpdateWallet(ctx, *w)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = credits.AccrueInterest(ctx, l.AccruedUntil.AddDate(0, 0, 5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err = credits.GetCreditLine(ctx, owner)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.UsedBalanceDays != 2*50000 {
+		t.Fatalf("expecting 2 days of 50000 credit used, got %d", l.UsedBalanceDays)
+	}
+}
+
+func TestCloseStatementsOfDisabledWallet(t *testing.T) {
+	ctx := context.Background()
+	wallets := wallet.NewService(wallet.NewInMemoryRepository())
+	credits := credit.NewService(credit.NewInMemoryRepository(), wallets, credit.Config{})
+
+	owner := uuid.NewString()
+	_, err := wallets.EnableWallet(ctx, wallet.EnableWalletParam{OwnerXID: owner})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err := credits.OpenCreditLine(ctx, credit.OpenCreditLineParam{
+		OwnerXID:                  owner,
+		Limit:                     100000,
+		AnnualInterestBasisPoints: 3650,
+		StatementDay:              1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = wallets.WithdrawWallet(ctx, wallet.WalletTransactionParam{
+		ActorXID:    owner,
+		OwnerXID:    owner,
+		ReferenceID: uuid.NewString(),
+		Amount:      50000,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = wallets.DisableWallet(ctx, wallet.DisableWalletParam{OwnerXID: owner})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	statements, err := credits.CloseStatements(ctx, l.NextStatementAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(statements) != 1 || statements[0].Interest == 0 || statements[0].InterestTransactionID == "" {
+		t.Fatalf("expecting interest charged on the disabled wallet, got %+v", statements)
+	}
+	if statements[0].DueAmount != 50000+statements[0].Interest {
+		t.Fatalf("expecting %d due, got %d", 50000+statements[0].Interest, statements[0].DueAmount)
+	}
+}
@@ -0,0 +1,41 @@
+package credit
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Worker accrues interest on the credit used and closes statements that are
+// due.
+type Worker struct {
+	credits  Service
+	interval time.Duration
+}
+
+func NewWorker(credits Service, interval time.Duration) *Worker {
+	return &Worker{
+		credits:  credits,
+		interval: interval,
+	}
+}
+
+// Run blocks, running every interval until ctx is done.
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if _, err := w.credits.CloseStatements(ctx, now); err != nil {
+				log.Println(err)
+			}
+			if err := w.credits.AccrueInterest(ctx, now); err != nil {
+				log.Println(err)
+			}
+		}
+	}
+}
@@ -0,0 +1,10 @@
+package disbursement
+
+import "errors"
+
+var (
+	ErrPayoutNotFound     = errors.New("payout not found")
+	ErrInvalidBankAccount = errors.New("invalid bank account")
+	ErrInvalidAmount      = errors.New("invalid payout amount")
+	ErrProviderClosed     = errors.New("disbursement provider is closed")
+)
@@ -0,0 +1,65 @@
+package disbursement
+
+import (
+	"context"
+	"time"
+)
+
+type BankAccount struct {
+	BankCode      string
+	AccountNumber string
+	AccountName   string
+}
+
+func (a BankAccount) Validate() error {
+	if a.BankCode == "" || a.AccountNumber == "" {
+		return ErrInvalidBankAccount
+	}
+	return nil
+}
+
+type PayoutStatus string
+
+var (
+	PayoutStatusPending = PayoutStatus("pending")
+	PayoutStatusSuccess = PayoutStatus("success")
+	PayoutStatusFailed  = PayoutStatus("failed")
+)
+
+type PayoutRequest struct {
+	// ReferenceID identifies the payout on our side, it is echoed back in
+	// callbacks so they can be matched with the originating transaction.
+	ReferenceID string
+	Destination BankAccount
+	Amount      int
+}
+
+type Payout struct {
+	ID            string
+	ReferenceID   string
+	Destination   BankAccount
+	Amount        int
+	Status        PayoutStatus
+	FailureReason string
+	CreatedAt     time.Time
+	CompletedAt   time.Time
+}
+
+// Callback is sent by the provider once a payout reaches a final status.
+type Callback struct {
+	PayoutID    string
+	ReferenceID string
+	Status      PayoutStatus
+	Reason      string
+}
+
+type CallbackFunc func(ctx context.Context, cb Callback) error
+
+// Provider sends money out of the system to bank accounts. Payouts are
+// asynchronous: Submit accepts the payout and its outcome is reported later
+// through the registered callbacks, or can be polled with GetPayout.
+type Provider interface {
+	Submit(ctx context.Context, req PayoutRequest) (*Payout, error)
+	GetPayout(ctx context.Context, id string) (*Payout, error)
+	OnCallback(fn CallbackFunc)
+}
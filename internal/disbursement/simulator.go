@@ -0,0 +1,149 @@
+package disbursement
+
+import (
+	"context"
+	"log"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type SimulatorConfig struct {
+	// Latency is how long the fake bank takes before completing a payout.
+	Latency time.Duration
+	// FailureRate is the probability, between 0 and 1, that a payout is
+	// rejected by the fake bank.
+	FailureRate float64
+	// Seed makes the failures reproducible, zero seeds from the clock.
+	Seed int64
+}
+
+// Simulator is an in-process fake bank implementing Provider, so the payout
+// flow can be exercised without any external dependency.
+type Simulator struct {
+	config    SimulatorConfig
+	mu        sync.Mutex
+	rand      *rand.Rand
+	payouts   map[string]*Payout
+	callbacks []CallbackFunc
+	timers    map[string]*time.Timer
+	wg        sync.WaitGroup
+	closed    bool
+}
+
+func NewSimulator(config SimulatorConfig) *Simulator {
+	seed := config.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return &Simulator{
+		config:  config,
+		rand:    rand.New(rand.NewSource(seed)),
+		payouts: map[string]*Payout{},
+		timers:  map[string]*time.Timer{},
+	}
+}
+
+func (s *Simulator) Submit(ctx context.Context, req PayoutRequest) (*Payout, error) {
+	err := req.Destination.Validate()
+	if err != nil {
+		return nil, err
+	}
+	if req.Amount <= 0 {
+		return nil, ErrInvalidAmount
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil, ErrProviderClosed
+	}
+
+	payout := &Payout{
+		ID:          uuid.NewString(),
+		ReferenceID: req.ReferenceID,
+		Destination: req.Destination,
+		Amount:      req.Amount,
+		Status:      PayoutStatusPending,
+		CreatedAt:   time.Now(),
+	}
+	s.payouts[payout.ID] = payout
+
+	s.wg.Add(1)
+	s.timers[payout.ID] = time.AfterFunc(s.config.Latency, func() {
+		defer s.wg.Done()
+		s.complete(payout.ID)
+	})
+
+	p := *payout
+	return &p, nil
+}
+
+func (s *Simulator) GetPayout(ctx context.Context, id string) (*Payout, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	payout, ok := s.payouts[id]
+	if !ok {
+		return nil, ErrPayoutNotFound
+	}
+	p := *payout
+	return &p, nil
+}
+
+func (s *Simulator) OnCallback(fn CallbackFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.callbacks = append(s.callbacks, fn)
+}
+
+// Close stops accepting payouts and waits for callbacks in flight. Payouts
+// not completed yet are dropped: they fail without a callback, which only
+// shows through GetPayout.
+func (s *Simulator) Close() {
+	s.mu.Lock()
+	s.closed = true
+	for id, timer := range s.timers {
+		if timer.Stop() {
+			s.wg.Done()
+			payout := s.payouts[id]
+			payout.Status = PayoutStatusFailed
+			payout.FailureReason = "dropped on provider shutdown"
+			payout.CompletedAt = time.Now()
+		}
+		delete(s.timers, id)
+	}
+	s.mu.Unlock()
+
+	s.wg.Wait()
+}
+
+func (s *Simulator) complete(id string) {
+	s.mu.Lock()
+	delete(s.timers, id)
+	payout := s.payouts[id]
+	payout.Status = PayoutStatusSuccess
+	if s.rand.Float64() < s.config.FailureRate {
+		payout.Status = PayoutStatusFailed
+		payout.FailureReason = "rejected by beneficiary bank"
+	}
+	payout.CompletedAt = time.Now()
+
+	cb := Callback{
+		PayoutID:    payout.ID,
+		ReferenceID: payout.ReferenceID,
+		Status:      payout.Status,
+		Reason:      payout.FailureReason,
+	}
+	callbacks := make([]CallbackFunc, len(s.callbacks))
+	copy(callbacks, s.callbacks)
+	s.mu.Unlock()
+
+	for _, fn := range callbacks {
+		if err := fn(context.Background(), cb); err != nil {
+			log.Printf("disbursement callback for payout %s: %s", cb.PayoutID, err)
+		}
+	}
+}
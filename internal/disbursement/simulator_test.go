@@ -0,0 +1,149 @@
+package disbursement_test
+
+import (
+	"context"
+	"julo/internal/disbursement"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestSimulator(t *testing.T) {
+	ctx := context.Background()
+	destination := disbursement.BankAccount{
+		BankCode:      "BCA",
+		AccountNumber: "1234567890",
+		AccountName:   "John Doe",
+	}
+
+	t.Run("submit payout, should complete with callback", func(t *testing.T) {
+		simulator := disbursement.NewSimulator(disbursement.SimulatorConfig{
+			Latency: 10 * time.Millisecond,
+		})
+		defer simulator.Close()
+
+		callbacks := make(chan disbursement.Callback, 1)
+		simulator.OnCallback(func(ctx context.Context, cb disbursement.Callback) error {
+			callbacks <- cb
+			return nil
+		})
+
+		refid := uuid.NewString()
+		payout, err := simulator.Submit(ctx, disbursement.PayoutRequest{
+			ReferenceID: refid,
+			Destination: destination,
+			Amount:      10000,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if payout.Status != disbursement.PayoutStatusPending {
+			t.Fatalf("expecting status %s, got %s", disbursement.PayoutStatusPending, payout.Status)
+		}
+
+		select {
+		case cb := <-callbacks:
+			if cb.ReferenceID != refid {
+				t.Fatalf("expecting reference %s, got %s", refid, cb.ReferenceID)
+			}
+			if cb.Status != disbursement.PayoutStatusSuccess {
+				t.Fatalf("expecting status %s, got %s", disbursement.PayoutStatusSuccess, cb.Status)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("timeout waiting for callback")
+		}
+
+		t.Run("query completed payout, should return final status", func(t *testing.T) {
+			p, err := simulator.GetPayout(ctx, payout.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p.Status != disbursement.PayoutStatusSuccess {
+				t.Fatalf("expecting status %s, got %s", disbursement.PayoutStatusSuccess, p.Status)
+			}
+		})
+	})
+
+	t.Run("submit payout with failure rate 1, should fail", func(t *testing.T) {
+		simulator := disbursement.NewSimulator(disbursement.SimulatorConfig{
+			FailureRate: 1,
+		})
+		defer simulator.Close()
+
+		callbacks := make(chan disbursement.Callback, 1)
+		simulator.OnCallback(func(ctx context.Context, cb disbursement.Callback) error {
+			callbacks <- cb
+			return nil
+		})
+
+		_, err := simulator.Submit(ctx, disbursement.PayoutRequest{
+			ReferenceID: uuid.NewString(),
+			Destination: destination,
+			Amount:      10000,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		select {
+		case cb := <-callbacks:
+			if cb.Status != disbursement.PayoutStatusFailed {
+				t.Fatalf("expecting status %s, got %s", disbursement.PayoutStatusFailed, cb.Status)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("timeout waiting for callback")
+		}
+	})
+
+	t.Run("submit payout without bank account, should fail", func(t *testing.T) {
+		simulator := disbursement.NewSimulator(disbursement.SimulatorConfig{})
+		defer simulator.Close()
+
+		_, err := simulator.Submit(ctx, disbursement.PayoutRequest{
+			ReferenceID: uuid.NewString(),
+			Amount:      10000,
+		})
+		if err != disbursement.ErrInvalidBankAccount {
+			t.Fatalf("expecting error %s, got %s", disbursement.ErrInvalidBankAccount, err)
+		}
+	})
+
+	t.Run("get inexist payout, should fail", func(t *testing.T) {
+		simulator := disbursement.NewSimulator(disbursement.SimulatorConfig{})
+		defer simulator.Close()
+
+		_, err := simulator.GetPayout(ctx, uuid.NewString())
+		if err != disbursement.ErrPayoutNotFound {
+			t.Fatalf("expecting error %s, got %s", disbursement.ErrPayoutNotFound, err)
+		}
+	})
+
+	t.Run("close with payout in flight, should fail it without callback", func(t *testing.T) {
+		simulator := disbursement.NewSimulator(disbursement.SimulatorConfig{
+			Latency: time.Hour,
+		})
+		simulator.OnCallback(func(ctx context.Context, cb disbursement.Callback) error {
+			t.Errorf("unexpected callback for payout %s", cb.PayoutID)
+			return nil
+		})
+
+		payout, err := simulator.Submit(ctx, disbursement.PayoutRequest{
+			ReferenceID: uuid.NewString(),
+			Destination: destination,
+			Amount:      10000,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		simulator.Close()
+
+		p, err := simulator.GetPayout(ctx, payout.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p.Status != disbursement.PayoutStatusFailed {
+			t.Fatalf("expecting status %s, got %s", disbursement.PayoutStatusFailed, p.Status)
+		}
+	})
+}
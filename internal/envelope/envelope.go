@@ -0,0 +1,155 @@
+package envelope
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const version = "v1"
+
+// Sealer encrypts values with a fresh AES-GCM data key each, the data key
+// being stored next to the value wrapped by a master key of the keyring.
+// Sealed values are strings fit for a text column:
+//
+//	v1:<master key id>:<wrapped data key>:<nonce and ciphertext>
+type Sealer struct {
+	keys Keyring
+}
+
+func NewSealer(keys Keyring) (*Sealer, error) {
+	err := keys.Validate()
+	if err != nil {
+		return nil, err
+	}
+	return &Sealer{keys: keys}, nil
+}
+
+// Seal encrypts plaintext bound to aad, the same aad must be given to open
+// it. Callers pass what the value belongs to, such as the row and column, so
+// sealed values cannot be swapped between rows.
+func (s *Sealer) Seal(plaintext string, aad string) (string, error) {
+	dataKey := make([]byte, keySize)
+	_, err := io.ReadFull(rand.Reader, dataKey)
+	if err != nil {
+		return "", errors.Wrap(err, "failed generating data key")
+	}
+
+	wrapped, err := encrypt(s.keys.Keys[s.keys.Current], dataKey, []byte(s.keys.Current))
+	if err != nil {
+		return "", errors.Wrap(err, "failed wrapping data key")
+	}
+	ciphertext, err := encrypt(dataKey, []byte(plaintext), []byte(aad))
+	if err != nil {
+		return "", errors.Wrap(err, "failed encrypting value")
+	}
+
+	return strings.Join([]string{
+		version,
+		s.keys.Current,
+		base64.RawStdEncoding.EncodeToString(wrapped),
+		base64.RawStdEncoding.EncodeToString(ciphertext),
+	}, ":"), nil
+}
+
+// Open decrypts a value sealed with any key still in the keyring.
+func (s *Sealer) Open(sealed string, aad string) (string, error) {
+	keyID, wrapped, ciphertext, err := parse(sealed)
+	if err != nil {
+		return "", err
+	}
+	masterKey, ok := s.keys.Keys[keyID]
+	if !ok {
+		return "", errors.Wrapf(ErrUnknownKey, "key %s", keyID)
+	}
+
+	dataKey, err := decrypt(masterKey, wrapped, []byte(keyID))
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := decrypt(dataKey, ciphertext, []byte(aad))
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// IsCurrent tells whether sealed was sealed with the current master key, the
+// values that are not need rotating.
+func (s *Sealer) IsCurrent(sealed string) bool {
+	keyID, _, _, err := parse(sealed)
+	return err == nil && keyID == s.keys.Current
+}
+
+// BlindIndex is a keyed hash of value for looking up sealed values by exact
+// match without decrypting them. field keeps equal values of different
+// fields from sharing an index.
+func (s *Sealer) BlindIndex(field string, value string) string {
+	mac := hmac.New(sha256.New, s.keys.IndexKey)
+	mac.Write([]byte(field))
+	mac.Write([]byte{0})
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func parse(sealed string) (keyID string, wrapped []byte, ciphertext []byte, err error) {
+	parts := strings.Split(sealed, ":")
+	if len(parts) != 4 || parts[0] != version {
+		return "", nil, nil, ErrMalformed
+	}
+	wrapped, err = base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", nil, nil, ErrMalformed
+	}
+	ciphertext, err = base64.RawStdEncoding.DecodeString(parts[3])
+	if err != nil {
+		return "", nil, nil, ErrMalformed
+	}
+	return parts[1], wrapped, ciphertext, nil
+}
+
+// encrypt seals plaintext with AES-GCM under key, prefixing the nonce.
+func encrypt(key []byte, plaintext []byte, aad []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize(), gcm.NonceSize()+len(plaintext)+gcm.Overhead())
+	_, err = io.ReadFull(rand.Reader, nonce)
+	if err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plaintext, aad), nil
+}
+
+func decrypt(key []byte, ciphertext []byte, aad []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) < gcm.NonceSize() {
+		return nil, ErrMalformed
+	}
+	nonce, ciphertext := ciphertext[:gcm.NonceSize()], ciphertext[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, aad)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return plaintext, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
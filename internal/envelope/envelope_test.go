@@ -0,0 +1,120 @@
+package envelope_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"julo/internal/envelope"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestSeal(t *testing.T) {
+	keys := envelope.Keyring{
+		Current:  "k1",
+		Keys:     map[string][]byte{"k1": bytes.Repeat([]byte{1}, 32)},
+		IndexKey: bytes.Repeat([]byte{9}, 32),
+	}
+	sealer, err := envelope.NewSealer(keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sealed, err := sealer.Seal("budi@example.com", "xid-1/email")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(sealed, "budi") || !strings.HasPrefix(sealed, "v1:k1:") {
+		t.Fatalf("expecting value sealed with k1, got %s", sealed)
+	}
+
+	t.Run("open, should give plaintext", func(t *testing.T) {
+		v, err := sealer.Open(sealed, "xid-1/email")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v != "budi@example.com" {
+			t.Fatalf("expecting budi@example.com, got %s", v)
+		}
+	})
+
+	t.Run("open with other aad, should fail", func(t *testing.T) {
+		_, err := sealer.Open(sealed, "xid-2/email")
+		if err != envelope.ErrDecrypt {
+			t.Fatalf("expecting error %s, got %v", envelope.ErrDecrypt, err)
+		}
+	})
+
+	t.Run("seal twice, should differ but index the same", func(t *testing.T) {
+		again, err := sealer.Seal("budi@example.com", "xid-1/email")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if again == sealed {
+			t.Fatal("expecting a fresh data key and nonce")
+		}
+		if sealer.BlindIndex("email", "budi@example.com") != sealer.BlindIndex("email", "budi@example.com") {
+			t.Fatal("expecting stable blind index")
+		}
+		if sealer.BlindIndex("email", "budi@example.com") == sealer.BlindIndex("phone", "budi@example.com") {
+			t.Fatal("expecting blind index to depend on field")
+		}
+	})
+
+	t.Run("rotate, should open old values and seal with new key", func(t *testing.T) {
+		keys.Keys = map[string][]byte{"k1": keys.Keys["k1"], "k2": bytes.Repeat([]byte{2}, 32)}
+		keys.Current = "k2"
+		rotated, err := envelope.NewSealer(keys)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rotated.IsCurrent(sealed) {
+			t.Fatal("expecting k1 value not current")
+		}
+		v, err := rotated.Open(sealed, "xid-1/email")
+		if err != nil || v != "budi@example.com" {
+			t.Fatalf("expecting old value opened, got %s %v", v, err)
+		}
+
+		delete(keys.Keys, "k1")
+		retired, err := envelope.NewSealer(keys)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := retired.Open(sealed, "xid-1/email"); errors.Cause(err) != envelope.ErrUnknownKey {
+			t.Fatalf("expecting error %s, got %v", envelope.ErrUnknownKey, err)
+		}
+	})
+}
+
+func TestLoadKeyring(t *testing.T) {
+	key := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 32))
+	path := filepath.Join(t.TempDir(), "keyring.json")
+
+	t.Run("load keyring, should success", func(t *testing.T) {
+		err := os.WriteFile(path, []byte(`{"current": "k1", "keys": {"k1": "`+key+`"}, "index_key": "`+key+`"}`), 0o600)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys, err := envelope.LoadKeyring(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if keys.Current != "k1" || len(keys.Keys["k1"]) != 32 {
+			t.Fatalf("expecting key k1, got %+v", keys)
+		}
+	})
+
+	t.Run("load keyring without current key, should fail", func(t *testing.T) {
+		err := os.WriteFile(path, []byte(`{"current": "k2", "keys": {"k1": "`+key+`"}, "index_key": "`+key+`"}`), 0o600)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := envelope.LoadKeyring(path); errors.Cause(err) != envelope.ErrInvalidKeyring {
+			t.Fatalf("expecting error %s, got %v", envelope.ErrInvalidKeyring, err)
+		}
+	})
+}
@@ -0,0 +1,10 @@
+package envelope
+
+import "errors"
+
+var (
+	ErrInvalidKeyring = errors.New("invalid keyring")
+	ErrUnknownKey     = errors.New("value sealed with a key not in the keyring")
+	ErrMalformed      = errors.New("malformed sealed value")
+	ErrDecrypt        = errors.New("failed decrypting sealed value")
+)
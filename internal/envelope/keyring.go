@@ -0,0 +1,91 @@
+package envelope
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// KeyringEnv holds the keyring as JSON when it is not read from a file.
+const KeyringEnv = "PII_KEYRING"
+
+const keySize = 32
+
+// Keyring holds the master keys wrapping data keys, by id, and the key of
+// blind indexes. Values are sealed with the Current master key, older keys
+// stay in the keyring until every value was rotated off them. The index key
+// never rotates, indexes would have to be rebuilt from the plaintext.
+type Keyring struct {
+	Current  string
+	Keys     map[string][]byte
+	IndexKey []byte
+}
+
+type keyringFile struct {
+	Current  string            `json:"current"`
+	Keys     map[string]string `json:"keys"`
+	IndexKey string            `json:"index_key"`
+}
+
+// LoadKeyring reads the keyring from the JSON file at path, or from the
+// PII_KEYRING environment variable when path is empty. Keys are base64
+// encoded 32 byte AES keys:
+//
+//	{"current": "2024-01", "keys": {"2024-01": "..."}, "index_key": "..."}
+func LoadKeyring(path string) (*Keyring, error) {
+	var b []byte
+	if path != "" {
+		var err error
+		b, err = os.ReadFile(path)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed reading keyring")
+		}
+	} else if v := os.Getenv(KeyringEnv); v != "" {
+		b = []byte(v)
+	} else {
+		return nil, errors.Wrap(ErrInvalidKeyring, "no keyring file given and "+KeyringEnv+" not set")
+	}
+
+	var f keyringFile
+	err := json.Unmarshal(b, &f)
+	if err != nil {
+		return nil, errors.Wrap(ErrInvalidKeyring, err.Error())
+	}
+
+	k := Keyring{
+		Current: f.Current,
+		Keys:    map[string][]byte{},
+	}
+	for id, v := range f.Keys {
+		k.Keys[id], err = base64.StdEncoding.DecodeString(v)
+		if err != nil {
+			return nil, errors.Wrapf(ErrInvalidKeyring, "key %s is not base64", id)
+		}
+	}
+	k.IndexKey, err = base64.StdEncoding.DecodeString(f.IndexKey)
+	if err != nil {
+		return nil, errors.Wrap(ErrInvalidKeyring, "index key is not base64")
+	}
+	return &k, k.Validate()
+}
+
+func (k Keyring) Validate() error {
+	if _, ok := k.Keys[k.Current]; !ok {
+		return errors.Wrapf(ErrInvalidKeyring, "current key %q not in keyring", k.Current)
+	}
+	for id, key := range k.Keys {
+		if id == "" || strings.Contains(id, ":") {
+			return errors.Wrapf(ErrInvalidKeyring, "key id %q must be non empty without colons", id)
+		}
+		if len(key) != keySize {
+			return errors.Wrapf(ErrInvalidKeyring, "key %s must be %d bytes", id, keySize)
+		}
+	}
+	if len(k.IndexKey) != keySize {
+		return errors.Wrapf(ErrInvalidKeyring, "index key must be %d bytes", keySize)
+	}
+	return nil
+}
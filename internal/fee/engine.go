@@ -0,0 +1,113 @@
+package fee
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+// Tier prices amounts up to UpTo, a zero UpTo leaves the tier unbounded.
+type Tier struct {
+	UpTo        int `json:"up_to"`
+	Flat        int `json:"flat"`
+	BasisPoints int `json:"basis_points"`
+}
+
+// Rule prices a movement as a flat amount plus a percentage, expressed in
+// basis points (1% is 100). When tiers are given, the first tier covering the
+// amount provides the flat amount and percentage instead. The result is then
+// kept between Min and Max, a zero Max meaning no maximum.
+type Rule struct {
+	Flat        int    `json:"flat"`
+	BasisPoints int    `json:"basis_points"`
+	Tiers       []Tier `json:"tiers"`
+	Min         int    `json:"min"`
+	Max         int    `json:"max"`
+}
+
+func (r Rule) Validate() error {
+	if r.Flat < 0 || r.BasisPoints < 0 || r.Min < 0 || r.Max < 0 {
+		return ErrInvalidRule
+	}
+	if r.Max > 0 && r.Min > r.Max {
+		return ErrInvalidRule
+	}
+	for i, t := range r.Tiers {
+		if t.Flat < 0 || t.BasisPoints < 0 || t.UpTo < 0 {
+			return ErrInvalidRule
+		}
+		// only the last tier may be unbounded, and bounds must increase
+		if t.UpTo == 0 && i != len(r.Tiers)-1 {
+			return ErrInvalidRule
+		}
+		if i > 0 && t.UpTo != 0 && t.UpTo <= r.Tiers[i-1].UpTo {
+			return ErrInvalidRule
+		}
+	}
+	return nil
+}
+
+func (r Rule) Calculate(amount int) int {
+	flat, bps := r.Flat, r.BasisPoints
+	if len(r.Tiers) > 0 {
+		flat, bps = 0, 0
+		for _, t := range r.Tiers {
+			if t.UpTo == 0 || amount <= t.UpTo {
+				flat, bps = t.Flat, t.BasisPoints
+				break
+			}
+		}
+	}
+
+	// the percentage is rounded half up to the minor unit
+	fee := flat + (amount*bps+5000)/10000
+	if fee < r.Min {
+		fee = r.Min
+	}
+	if r.Max > 0 && fee > r.Max {
+		fee = r.Max
+	}
+	return fee
+}
+
+// Engine prices movements by kind, kinds without a rule are free.
+type Engine struct {
+	rules map[string]Rule
+}
+
+func NewEngine(rules map[string]Rule) (*Engine, error) {
+	for kind, rule := range rules {
+		if err := rule.Validate(); err != nil {
+			return nil, errors.Wrapf(err, "rule for %s", kind)
+		}
+	}
+	return &Engine{
+		rules: rules,
+	}, nil
+}
+
+// LoadEngine reads the rules from a JSON file holding an object keyed by
+// kind, e.g. {"withdrawal": {"flat": 2500}}.
+func LoadEngine(path string) (*Engine, error) {
+	bs, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed reading fee rules")
+	}
+
+	var rules map[string]Rule
+	err = json.Unmarshal(bs, &rules)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed parsing fee rules")
+	}
+
+	return NewEngine(rules)
+}
+
+func (e *Engine) Calculate(kind string, amount int) int {
+	rule, ok := e.rules[kind]
+	if !ok {
+		return 0
+	}
+	return rule.Calculate(amount)
+}
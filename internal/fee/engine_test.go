@@ -0,0 +1,66 @@
+package fee_test
+
+import (
+	"julo/internal/fee"
+	"testing"
+)
+
+func TestCalculate(t *testing.T) {
+	engine, err := fee.NewEngine(map[string]fee.Rule{
+		"flat": {
+			Flat: 2500,
+		},
+		"percentage": {
+			BasisPoints: 150,
+			Min:         1000,
+			Max:         10000,
+		},
+		"tiered": {
+			Tiers: []fee.Tier{
+				{UpTo: 100000, Flat: 1000},
+				{UpTo: 1000000, Flat: 2500},
+				{BasisPoints: 50},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		kind   string
+		amount int
+		fee    int
+	}{
+		{"flat", 50000, 2500},
+		{"percentage", 10000, 1000},
+		{"percentage", 100000, 1500},
+		{"percentage", 100033, 1500},
+		{"percentage", 100034, 1501},
+		{"percentage", 5000000, 10000},
+		{"tiered", 100000, 1000},
+		{"tiered", 100001, 2500},
+		{"tiered", 2000000, 10000},
+		{"free", 50000, 0},
+	}
+	for _, c := range cases {
+		if got := engine.Calculate(c.kind, c.amount); got != c.fee {
+			t.Fatalf("%s of %d: expecting fee %d, got %d", c.kind, c.amount, c.fee, got)
+		}
+	}
+}
+
+func TestInvalidRule(t *testing.T) {
+	rules := []fee.Rule{
+		{Flat: -1},
+		{Min: 5000, Max: 1000},
+		{Tiers: []fee.Tier{{Flat: 1000}, {UpTo: 100000, Flat: 2000}}},
+		{Tiers: []fee.Tier{{UpTo: 100000}, {UpTo: 50000}}},
+	}
+	for _, rule := range rules {
+		_, err := fee.NewEngine(map[string]fee.Rule{"withdrawal": rule})
+		if err == nil {
+			t.Fatalf("expecting error for rule %+v", rule)
+		}
+	}
+}
@@ -0,0 +1,7 @@
+package fee
+
+import "errors"
+
+var (
+	ErrInvalidRule = errors.New("invalid fee rule")
+)
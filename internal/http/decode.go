@@ -0,0 +1,278 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxBodyBytes is the largest request body Decode reads.
+const MaxBodyBytes = 1 << 20
+
+// Decode reads the request into dst, a pointer to a struct whose fields are
+// named by their json tags. The body may be JSON, in which case fields dst
+// does not have are rejected, or form encoded, in which case query
+// parameters count too and slices are given by repeating the field. Fields
+// tagged `validate:"required"` must be given, not null and, for strings,
+// times and slices, not empty. Times are given in RFC 3339. Problems with the
+// fields come back as FieldErrors; anything else means the body could not be
+// read at all.
+func Decode(w http.ResponseWriter, r *http.Request, dst interface{}) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		panic("http: Decode needs a pointer to a struct")
+	}
+	v = v.Elem()
+
+	if r.Body != nil {
+		r.Body = http.MaxBytesReader(w, r.Body, MaxBodyBytes)
+	}
+
+	var values map[string]fieldValue
+	var err error
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case "application/json":
+		values, err = jsonValues(r)
+	case "", "application/x-www-form-urlencoded", "multipart/form-data":
+		values, err = formValues(r, mediaType)
+	default:
+		return ErrUnsupportedMediaType
+	}
+	if err != nil {
+		return err
+	}
+
+	errs := FieldErrors{}
+	fields := map[string]bool{}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		name := fieldName(sf)
+		if name == "" {
+			continue
+		}
+		fields[name] = true
+
+		// a JSON null is as good as a field left out
+		fv, ok := values[name]
+		if !ok || fv.isNull() {
+			if sf.Tag.Get("validate") == "required" {
+				errs.Add(name, MessageRequired)
+			}
+			continue
+		}
+		if err := fv.set(v.Field(i)); err != nil {
+			errs.Add(name, invalidMessage(sf.Type))
+			continue
+		}
+		if sf.Tag.Get("validate") == "required" && isEmpty(v.Field(i)) {
+			errs.Add(name, MessageRequired)
+		}
+	}
+	if mediaType == "application/json" {
+		for name := range values {
+			if !fields[name] {
+				errs.Add(name, MessageUnknownField)
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// WriteRequestErrorJSON responds to a request that could not be decoded or
+// failed validation. Field errors all take the same shape,
+// {"status": "fail", "data": {"error": {"<field>": "<message>"}}}.
+func WriteRequestErrorJSON(w http.ResponseWriter, err error) {
+	var fe FieldErrors
+	switch {
+	case errors.As(err, &fe):
+		var response Response
+		response.Status = "fail"
+		response.Data = map[string]interface{}{"error": fe}
+		WriteJSON(w, http.StatusBadRequest, response)
+	case err == ErrBodyTooLarge:
+		WriteErrorJSON(w, http.StatusRequestEntityTooLarge, err)
+	case err == ErrUnsupportedMediaType:
+		WriteErrorJSON(w, http.StatusUnsupportedMediaType, err)
+	default:
+		WriteErrorJSON(w, http.StatusBadRequest, err)
+	}
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// fieldValue is the value of a field as it came in, either raw JSON or the
+// texts of a form field, which is repeated for slices.
+type fieldValue struct {
+	json  json.RawMessage
+	texts []string
+}
+
+func (fv fieldValue) isNull() bool {
+	return fv.json != nil && bytes.Equal(bytes.TrimSpace(fv.json), []byte("null"))
+}
+
+func (fv fieldValue) set(dst reflect.Value) error {
+	if fv.json != nil {
+		return json.Unmarshal(fv.json, dst.Addr().Interface())
+	}
+
+	if dst.Kind() == reflect.Pointer {
+		p := reflect.New(dst.Type().Elem())
+		if err := fv.set(p.Elem()); err != nil {
+			return err
+		}
+		dst.Set(p)
+		return nil
+	}
+	if dst.Kind() == reflect.Slice {
+		items := reflect.MakeSlice(dst.Type(), len(fv.texts), len(fv.texts))
+		for i, text := range fv.texts {
+			if err := (fieldValue{texts: []string{text}}).set(items.Index(i)); err != nil {
+				return err
+			}
+		}
+		dst.Set(items)
+		return nil
+	}
+	text := fv.texts[0]
+	if dst.Type() == timeType {
+		at, err := time.Parse(time.RFC3339, text)
+		if err != nil {
+			return err
+		}
+		dst.Set(reflect.ValueOf(at))
+		return nil
+	}
+	switch dst.Kind() {
+	case reflect.String:
+		dst.SetString(text)
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(text, 10, dst.Type().Bits())
+		if err != nil {
+			return err
+		}
+		dst.SetInt(n)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(text)
+		if err != nil {
+			return err
+		}
+		dst.SetBool(b)
+	default:
+		panic("http: Decode does not support fields of type " + dst.Type().String())
+	}
+	return nil
+}
+
+func jsonValues(r *http.Request) (map[string]fieldValue, error) {
+	if r.Body == nil {
+		return nil, ErrMalformedBody
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, readError(err)
+	}
+
+	var raw map[string]json.RawMessage
+	dec := json.NewDecoder(bytes.NewReader(body))
+	if err := dec.Decode(&raw); err != nil || raw == nil {
+		return nil, ErrMalformedBody
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, ErrMalformedBody
+	}
+
+	values := make(map[string]fieldValue, len(raw))
+	for name, v := range raw {
+		values[name] = fieldValue{json: v}
+	}
+	return values, nil
+}
+
+func formValues(r *http.Request, mediaType string) (map[string]fieldValue, error) {
+	var err error
+	if mediaType == "multipart/form-data" {
+		err = r.ParseMultipartForm(MaxBodyBytes)
+	} else {
+		err = r.ParseForm()
+	}
+	if err != nil {
+		return nil, readError(err)
+	}
+
+	values := make(map[string]fieldValue, len(r.Form))
+	for name, vs := range r.Form {
+		if len(vs) > 0 {
+			values[name] = fieldValue{texts: vs}
+		}
+	}
+	return values, nil
+}
+
+func readError(err error) error {
+	var mbe *http.MaxBytesError
+	if errors.As(err, &mbe) || strings.Contains(err.Error(), "request body too large") {
+		return ErrBodyTooLarge
+	}
+	return ErrMalformedBody
+}
+
+func fieldName(sf reflect.StructField) string {
+	if !sf.IsExported() {
+		return ""
+	}
+	name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		return sf.Name
+	}
+	return name
+}
+
+// isEmpty tells whether a given field is as good as missing, an empty
+// string, time or slice.
+func isEmpty(v reflect.Value) bool {
+	if v.Type() == timeType {
+		return v.Interface().(time.Time).IsZero()
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return v.String() == ""
+	case reflect.Slice:
+		return v.Len() == 0
+	}
+	return false
+}
+
+func invalidMessage(t reflect.Type) string {
+	if t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return "not a valid datetime."
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return "not a valid string."
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		return "not a valid integer."
+	case reflect.Bool:
+		return "not a valid boolean."
+	}
+	return MessageInvalidValue
+}
@@ -0,0 +1,179 @@
+package http_test
+
+import (
+	"bytes"
+	httphelper "julo/internal/http"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type transferRequest struct {
+	ReferenceID string    `json:"reference_id" validate:"required"`
+	Amount      int       `json:"amount" validate:"required"`
+	IsDisabled  *bool     `json:"is_disabled"`
+	Note        string    `json:"note"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	Shares      []int     `json:"share"`
+}
+
+func TestDecode(t *testing.T) {
+	yes := true
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        transferRequest
+		err         error
+	}{
+		{
+			name:        "json body, should decode",
+			contentType: "application/json; charset=utf-8",
+			body:        `{"reference_id": "ref-1", "amount": 5000, "is_disabled": true}`,
+			want:        transferRequest{ReferenceID: "ref-1", Amount: 5000, IsDisabled: &yes},
+		},
+		{
+			name:        "form body, should decode",
+			contentType: "application/x-www-form-urlencoded",
+			body:        url.Values{"reference_id": {"ref-1"}, "amount": {"5000"}, "is_disabled": {"true"}, "other": {"x"}}.Encode(),
+			want:        transferRequest{ReferenceID: "ref-1", Amount: 5000, IsDisabled: &yes},
+		},
+		{
+			name:        "json with wrong types and unknown fields, should fail per field",
+			contentType: "application/json",
+			body:        `{"reference_id": "", "amount": "5000", "is_disabled": 1, "other": "x"}`,
+			err: httphelper.FieldErrors{
+				"reference_id": httphelper.MessageRequired,
+				"amount":       "not a valid integer.",
+				"is_disabled":  "not a valid boolean.",
+				"other":        httphelper.MessageUnknownField,
+			},
+		},
+		{
+			name:        "form with wrong types, should fail per field",
+			contentType: "application/x-www-form-urlencoded",
+			body:        url.Values{"amount": {"lots"}, "is_disabled": {"maybe"}}.Encode(),
+			err: httphelper.FieldErrors{
+				"reference_id": httphelper.MessageRequired,
+				"amount":       "not a valid integer.",
+				"is_disabled":  "not a valid boolean.",
+			},
+		},
+		{
+			name:        "json null for a required field, should fail",
+			contentType: "application/json",
+			body:        `{"reference_id": null, "amount": 5000}`,
+			err:         httphelper.FieldErrors{"reference_id": httphelper.MessageRequired},
+		},
+		{
+			name:        "json null for a required integer, should fail",
+			contentType: "application/json",
+			body:        `{"reference_id": "ref-1", "amount": null}`,
+			err:         httphelper.FieldErrors{"amount": httphelper.MessageRequired},
+		},
+		{
+			name:        "json null for an optional field, should be left out",
+			contentType: "application/json",
+			body:        `{"reference_id": "ref-1", "amount": 5000, "is_disabled": null, "note": null}`,
+			want:        transferRequest{ReferenceID: "ref-1", Amount: 5000},
+		},
+		{
+			name:        "form with a time, should decode",
+			contentType: "application/x-www-form-urlencoded",
+			body:        url.Values{"reference_id": {"ref-1"}, "amount": {"5000"}, "expires_at": {"2024-01-31T10:00:00Z"}}.Encode(),
+			want:        transferRequest{ReferenceID: "ref-1", Amount: 5000, ExpiresAt: time.Date(2024, 1, 31, 10, 0, 0, 0, time.UTC)},
+		},
+		{
+			name:        "repeated form field, should decode into a slice",
+			contentType: "application/x-www-form-urlencoded",
+			body:        url.Values{"reference_id": {"ref-1"}, "amount": {"5000"}, "share": {"3000", "2000"}}.Encode(),
+			want:        transferRequest{ReferenceID: "ref-1", Amount: 5000, Shares: []int{3000, 2000}},
+		},
+		{
+			name:        "json array, should decode into a slice",
+			contentType: "application/json",
+			body:        `{"reference_id": "ref-1", "amount": 5000, "share": [3000, 2000]}`,
+			want:        transferRequest{ReferenceID: "ref-1", Amount: 5000, Shares: []int{3000, 2000}},
+		},
+		{
+			name:        "repeated form field with an invalid item, should fail",
+			contentType: "application/x-www-form-urlencoded",
+			body:        url.Values{"reference_id": {"ref-1"}, "amount": {"5000"}, "share": {"3000", "lots"}}.Encode(),
+			err:         httphelper.FieldErrors{"share": "not a valid integer."},
+		},
+		{
+			name:        "invalid time, should fail",
+			contentType: "application/json",
+			body:        `{"reference_id": "ref-1", "amount": 5000, "expires_at": "tomorrow"}`,
+			err:         httphelper.FieldErrors{"expires_at": "not a valid datetime."},
+		},
+		{
+			name:        "malformed json, should fail",
+			contentType: "application/json",
+			body:        `{"reference_id": "ref-1"`,
+			err:         httphelper.ErrMalformedBody,
+		},
+		{
+			name:        "trailing json, should fail",
+			contentType: "application/json",
+			body:        `{"reference_id": "ref-1", "amount": 5000} {}`,
+			err:         httphelper.ErrMalformedBody,
+		},
+		{
+			name:        "body over the limit, should fail",
+			contentType: "application/json",
+			body:        `{"note": "` + strings.Repeat("a", httphelper.MaxBodyBytes) + `"}`,
+			err:         httphelper.ErrBodyTooLarge,
+		},
+		{
+			name:        "other content type, should fail",
+			contentType: "text/plain",
+			body:        "ref-1",
+			err:         httphelper.ErrUnsupportedMediaType,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+
+			var got transferRequest
+			err := httphelper.Decode(httptest.NewRecorder(), req, &got)
+			if tt.err != nil {
+				if !reflect.DeepEqual(err, tt.err) {
+					t.Fatalf("expecting error %v, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("expecting %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestWriteRequestErrorJSON(t *testing.T) {
+	rec := httptest.NewRecorder()
+	httphelper.WriteRequestErrorJSON(rec, httphelper.FieldErrors{"amount": "not a valid integer."})
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expecting status %v, got %v", http.StatusBadRequest, rec.Code)
+	}
+	want := `{"status":"fail","data":{"error":{"amount":"not a valid integer."}}}`
+	if got := strings.TrimSpace(rec.Body.String()); got != want {
+		t.Fatalf("expecting %s, got %s", want, got)
+	}
+
+	rec = httptest.NewRecorder()
+	httphelper.WriteRequestErrorJSON(rec, httphelper.ErrBodyTooLarge)
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expecting status %v, got %v", http.StatusRequestEntityTooLarge, rec.Code)
+	}
+}
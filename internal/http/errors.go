@@ -0,0 +1,31 @@
+package http
+
+import "errors"
+
+var (
+	ErrBodyTooLarge         = errors.New("request body too large")
+	ErrUnsupportedMediaType = errors.New("unsupported content type, use application/json or form encoding")
+	ErrMalformedBody        = errors.New("malformed request body")
+)
+
+// Messages reported for a field of the request.
+const (
+	MessageRequired     = "missing data for required field."
+	MessageUnknownField = "unknown field."
+	MessageInvalidValue = "not a valid value."
+)
+
+// FieldErrors are validation errors of a request keyed by the field they are
+// about.
+type FieldErrors map[string]string
+
+// Add reports message for field, keeping the first message of a field.
+func (e FieldErrors) Add(field string, message string) {
+	if _, ok := e[field]; !ok {
+		e[field] = message
+	}
+}
+
+func (e FieldErrors) Error() string {
+	return "validation error"
+}
@@ -0,0 +1,155 @@
+package interest
+
+import (
+	"encoding/json"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Micros is the number of accrual units in a minor unit. Interest accrues in
+// millionths of the minor unit and is only rounded when it is posted.
+const Micros = 1000000
+
+// Tier sets the annual rate, in basis points, earned by the part of the
+// balance up to UpTo, a zero UpTo leaves the tier unbounded. Each tier only
+// applies to the part of the balance above the previous tier.
+type Tier struct {
+	UpTo              int `json:"up_to"`
+	AnnualBasisPoints int `json:"annual_basis_points"`
+}
+
+// DayCount is the convention turning an annual rate into a daily one.
+type DayCount string
+
+var (
+	// DayCountActual365 divides the annual rate over 365 days every year.
+	DayCountActual365 = DayCount("act/365")
+	// DayCountActual360 divides the annual rate over 360 days.
+	DayCountActual360 = DayCount("act/360")
+	// DayCountActualActual divides the annual rate over the days of the
+	// year, 366 in leap years.
+	DayCountActualActual = DayCount("act/act")
+)
+
+// Rounding is how accrued interest is rounded to the minor unit when posted.
+type Rounding string
+
+var (
+	RoundingHalfUp = Rounding("half_up")
+	RoundingDown   = Rounding("down")
+	RoundingUp     = Rounding("up")
+)
+
+type Config struct {
+	Tiers    []Tier   `json:"tiers"`
+	DayCount DayCount `json:"day_count"`
+	Rounding Rounding `json:"rounding"`
+	// Exclude lists the owners whose wallets earn no interest, such as the
+	// house wallet.
+	Exclude []string `json:"exclude"`
+}
+
+// LoadConfig reads a JSON config from path.
+func LoadConfig(path string) (*Config, error) {
+	f, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed reading interest config")
+	}
+
+	var c Config
+	err = json.Unmarshal(f, &c)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed parsing interest config")
+	}
+
+	err = c.Validate()
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (c Config) Validate() error {
+	if len(c.Tiers) == 0 {
+		return ErrInvalidConfig
+	}
+	for i, t := range c.Tiers {
+		if t.UpTo < 0 || t.AnnualBasisPoints < 0 {
+			return ErrInvalidConfig
+		}
+		// only the last tier may be unbounded, and bounds must increase
+		if t.UpTo == 0 && i != len(c.Tiers)-1 {
+			return ErrInvalidConfig
+		}
+		if i > 0 && t.UpTo != 0 && t.UpTo <= c.Tiers[i-1].UpTo {
+			return ErrInvalidConfig
+		}
+	}
+	switch c.DayCount {
+	case "", DayCountActual365, DayCountActual360, DayCountActualActual:
+	default:
+		return ErrInvalidConfig
+	}
+	switch c.Rounding {
+	case "", RoundingHalfUp, RoundingDown, RoundingUp:
+	default:
+		return ErrInvalidConfig
+	}
+	return nil
+}
+
+// DailyInterest is the interest, in micros, earned on day by a balance held
+// over the whole day. Negative balances earn nothing.
+func (c Config) DailyInterest(balance int, day time.Time) int64 {
+	var yearly, lower int64
+	for _, t := range c.Tiers {
+		if int64(balance) <= lower {
+			break
+		}
+		upper := int64(balance)
+		if t.UpTo != 0 && int64(t.UpTo) < upper {
+			upper = int64(t.UpTo)
+		}
+		yearly += (upper - lower) * int64(t.AnnualBasisPoints)
+		lower = upper
+	}
+
+	// yearly is in hundredths of a basis point of the minor unit
+	return yearly * (Micros / 10000) / int64(c.daysInYear(day))
+}
+
+// Round turns micros into minor units following the rounding of c.
+func (c Config) Round(micros int64) int {
+	switch c.Rounding {
+	case RoundingDown:
+		return int(micros / Micros)
+	case RoundingUp:
+		return int((micros + Micros - 1) / Micros)
+	default:
+		return int((micros + Micros/2) / Micros)
+	}
+}
+
+func (c Config) daysInYear(day time.Time) int {
+	switch c.DayCount {
+	case DayCountActual360:
+		return 360
+	case DayCountActualActual:
+		year := day.Year()
+		if year%4 == 0 && (year%100 != 0 || year%400 == 0) {
+			return 366
+		}
+	}
+	return 365
+}
+
+func (c Config) excluded(ownerXID string) bool {
+	for _, xid := range c.Exclude {
+		if xid == ownerXID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,8 @@
+package interest
+
+import "errors"
+
+var (
+	ErrInvalidConfig   = errors.New("invalid interest config")
+	ErrAccrualNotFound = errors.New("interest accrual not found")
+)
@@ -0,0 +1,98 @@
+package http_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"julo/internal/account"
+	"julo/internal/auth"
+	authhttp "julo/internal/auth/http"
+	httphelper "julo/internal/http"
+	"julo/internal/interest"
+	interesthttp "julo/internal/interest/http"
+	"julo/internal/wallet"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/google/uuid"
+)
+
+func TestViewInterest(t *testing.T) {
+	ctx := context.Background()
+	wallets := wallet.NewService(wallet.NewInMemoryRepository())
+	interests := interest.NewService(interest.NewInMemoryRepository(), wallets, interest.Config{
+		Tiers: []interest.Tier{{AnnualBasisPoints: 1000}},
+	})
+
+	router := chi.NewRouter()
+	router.Mount("/api/v1", router.Group(func(r chi.Router) {
+		r.Mount("/wallet", r.Group(func(r chi.Router) {
+			r.Use(authhttp.Middleware)
+			r.Get("/interest", interesthttp.ViewInterestHandler(interests).ServeHTTP)
+		}))
+	}))
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	owner := uuid.NewString()
+	_, err := wallets.EnableWallet(ctx, wallet.EnableWalletParam{
+		OwnerXID: owner,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = wallets.DepositWallet(ctx, wallet.WalletTransactionParam{
+		ActorXID:    owner,
+		OwnerXID:    owner,
+		ReferenceID: uuid.NewString(),
+		Amount:      3650000,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := uuid.NewString()
+	err = auth.StoreSession(ctx, auth.Session{
+		Token:   token,
+		Account: account.Account{XID: owner},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// accrue the day of the deposit only
+	_, err = interests.Accrue(ctx, time.Now().AddDate(0, 0, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/api/v1/wallet/interest", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Add("Authorization", fmt.Sprintf("Token %s", token))
+	res, err := server.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expecting status %v, got %v", http.StatusOK, res.StatusCode)
+	}
+
+	var response httphelper.Response
+	err = json.NewDecoder(res.Body).Decode(&response)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := response.Data.(map[string]interface{})["interest"].(map[string]interface{})
+	// the day may have ended a month and been posted already
+	postings := data["postings"].([]interface{})
+	if len(postings) == 0 && data["accrued"] != float64(1000) {
+		t.Fatalf("expecting 1000 accrued, got %v", data)
+	}
+	if len(postings) == 1 && postings[0].(map[string]interface{})["amount"] != float64(1000) {
+		t.Fatalf("expecting 1000 posted, got %v", data)
+	}
+}
@@ -0,0 +1,71 @@
+package http
+
+import (
+	"julo/internal/auth"
+	httphelper "julo/internal/http"
+	"julo/internal/interest"
+	"net/http"
+	"time"
+)
+
+type postingResponse struct {
+	ID            string    `json:"id"`
+	PeriodStart   time.Time `json:"period_start"`
+	PeriodEnd     time.Time `json:"period_end"`
+	Amount        int       `json:"amount"`
+	TransactionID string    `json:"transaction_id"`
+	PostedAt      time.Time `json:"posted_at"`
+}
+
+// ViewInterestHandler reports the interest accrued in the current period,
+// rounded down to the minor unit, and the interest posted so far.
+func ViewInterestHandler(interests interest.Service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var response httphelper.Response
+		session := auth.SessionFromContext(r.Context())
+		if session == nil {
+			httphelper.WriteErrorJSON(w, http.StatusUnauthorized, auth.ErrSessionNotFound)
+			return
+		}
+
+		var accrued int
+		var periodStart, accruedUntil time.Time
+		a, err := interests.GetAccrual(r.Context(), session.Account.XID)
+		if err != nil && err != interest.ErrAccrualNotFound {
+			httphelper.WriteErrorJSON(w, http.StatusInternalServerError, err)
+			return
+		}
+		if a != nil {
+			accrued = int(a.Accrued / interest.Micros)
+			periodStart, accruedUntil = a.PeriodStart, a.AccruedUntil
+		}
+
+		postings, err := interests.GetPostings(r.Context(), session.Account.XID)
+		if err != nil {
+			httphelper.WriteErrorJSON(w, http.StatusInternalServerError, err)
+			return
+		}
+		data := make([]postingResponse, 0, len(postings))
+		for _, p := range postings {
+			data = append(data, postingResponse{
+				ID:            p.ID,
+				PeriodStart:   p.PeriodStart,
+				PeriodEnd:     p.PeriodEnd,
+				Amount:        p.Amount,
+				TransactionID: p.TransactionID,
+				PostedAt:      p.PostedAt,
+			})
+		}
+
+		response.Status = "success"
+		response.Data = map[string]interface{}{
+			"interest": map[string]interface{}{
+				"accrued":       accrued,
+				"period_start":  periodStart,
+				"accrued_until": accruedUntil,
+				"postings":      data,
+			},
+		}
+		httphelper.WriteJSON(w, http.StatusOK, response)
+	})
+}
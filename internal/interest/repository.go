@@ -0,0 +1,77 @@
+package interest
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Accrual is the interest a wallet accrued since its last posting.
+type Accrual struct {
+	OwnerXID string
+	// PeriodStart is the first day of the period the next posting covers.
+	PeriodStart time.Time
+	// AccruedUntil is the first day not accrued yet.
+	AccruedUntil time.Time
+	// Accrued is in micros and may carry the rounding remainder of the
+	// previous posting.
+	Accrued int64
+}
+
+type Posting struct {
+	ID            string
+	OwnerXID      string
+	PeriodStart   time.Time
+	PeriodEnd     time.Time
+	Amount        int
+	TransactionID string
+	PostedAt      time.Time
+}
+
+type Repository interface {
+	SaveAccrual(ctx context.Context, a Accrual) error
+	GetAccrual(ctx context.Context, ownerXID string) (*Accrual, error)
+	CreatePosting(ctx context.Context, p Posting) error
+	GetPostings(ctx context.Context, ownerXID string) ([]Posting, error)
+}
+
+type InMemoryRepository struct {
+	accruals sync.Map
+	postings sync.Map
+}
+
+func NewInMemoryRepository() Repository {
+	return &InMemoryRepository{}
+}
+
+func (r *InMemoryRepository) SaveAccrual(ctx context.Context, a Accrual) error {
+	r.accruals.Store(a.OwnerXID, &a)
+	return nil
+}
+
+func (r *InMemoryRepository) GetAccrual(ctx context.Context, ownerXID string) (*Accrual, error) {
+	v, ok := r.accruals.Load(ownerXID)
+	if !ok {
+		return nil, ErrAccrualNotFound
+	}
+
+	a := *v.(*Accrual)
+	return &a, nil
+}
+
+func (r *InMemoryRepository) CreatePosting(ctx context.Context, p Posting) error {
+	var postings []Posting
+	if v, ok := r.postings.Load(p.OwnerXID); ok {
+		postings = v.([]Posting)
+	}
+	r.postings.Store(p.OwnerXID, append(postings[:len(postings):len(postings)], p))
+	return nil
+}
+
+func (r *InMemoryRepository) GetPostings(ctx context.Context, ownerXID string) ([]Posting, error) {
+	v, ok := r.postings.Load(ownerXID)
+	if !ok {
+		return []Posting{}, nil
+	}
+	return v.([]Posting), nil
+}
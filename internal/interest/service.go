@@ -0,0 +1,193 @@
+package interest
+
+import (
+	"context"
+	"julo/internal/wallet"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+type Service interface {
+	// Accrue brings the accrual of every wallet up to the day of now and
+	// posts the interest of the months that ended on the way.
+	Accrue(ctx context.Context, now time.Time) ([]Posting, error)
+	GetAccrual(ctx context.Context, ownerXID string) (*Accrual, error)
+	GetPostings(ctx context.Context, ownerXID string) ([]Posting, error)
+}
+
+type service struct {
+	repo    Repository
+	wallets wallet.Service
+	config  Config
+	mu      sync.Mutex
+}
+
+func NewService(repo Repository, wallets wallet.Service, config Config) Service {
+	return &service{
+		repo:    repo,
+		wallets: wallets,
+		config:  config,
+	}
+}
+
+// Accrue accrues each day on the balance the wallet had at the end of that
+// day, replayed from its transactions, so a run catching up on days missed
+// during downtime accrues exactly what daily runs would have. Wallets are
+// accrued one by one, the first error met is returned once all were tried.
+func (s *service) Accrue(ctx context.Context, now time.Time) ([]Posting, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	wallets, err := s.wallets.GetWallets(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed getting wallets")
+	}
+
+	today := startOfDay(now)
+	postings := []Posting{}
+	var firstErr error
+	for _, wal := range wallets {
+		if s.config.excluded(wal.OwnerXID) || wal.EnabledAt.IsZero() {
+			continue
+		}
+
+		// a wallet that cannot be accrued does not hold up the others
+		posted, err := s.accrue(ctx, wal, today)
+		if err != nil && firstErr == nil {
+			firstErr = errors.Wrapf(err, "failed accruing interest of %s", wal.OwnerXID)
+		}
+		postings = append(postings, posted...)
+	}
+	return postings, firstErr
+}
+
+func (s *service) accrue(ctx context.Context, wal wallet.Wallet, today time.Time) ([]Posting, error) {
+	a, err := s.repo.GetAccrual(ctx, wal.OwnerXID)
+	if err != nil && err == ErrAccrualNotFound {
+		start := startOfDay(wal.EnabledAt.In(today.Location()))
+		a = &Accrual{
+			OwnerXID:     wal.OwnerXID,
+			PeriodStart:  start,
+			AccruedUntil: start,
+		}
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed getting accrual")
+	}
+	if !a.AccruedUntil.Before(today) {
+		return nil, nil
+	}
+
+	result, err := s.wallets.GetWalletTransactions(ctx, wallet.GetWalletTransactionsParam{
+		WalletID: wal.ID,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed getting wallet transactions")
+	}
+	transactions := result.Transactions
+
+	postings := []Posting{}
+	for day := a.AccruedUntil; !day.After(today); day = day.AddDate(0, 0, 1) {
+		if day.Day() == 1 && day.After(a.PeriodStart) {
+			p, err := s.post(ctx, wal, a, day)
+			if err != nil {
+				return postings, err
+			}
+			if p != nil {
+				postings = append(postings, *p)
+				// the interest posted earns interest from now on
+				transactions = append(transactions, wallet.WalletTransaction{
+					Type:      wallet.TransactionTypeInterest,
+					Amount:    p.Amount,
+					Status:    wallet.TransactionStatusSuccess,
+					SettledAt: day,
+				})
+			}
+		}
+		if day.Equal(today) {
+			break
+		}
+
+		balance := wallet.BalanceAt(transactions, day.AddDate(0, 0, 1))
+		a.Accrued += s.config.DailyInterest(balance, day)
+		a.AccruedUntil = day.AddDate(0, 0, 1)
+	}
+
+	err = s.repo.SaveAccrual(ctx, *a)
+	if err != nil {
+		return postings, errors.Wrap(err, "failed saving accrual")
+	}
+	return postings, nil
+}
+
+// post credits the interest accrued over the period ending at end and starts
+// the next period, carrying the rounding remainder over. Nothing is posted to
+// a wallet that is not enabled, such as a disabled or frozen one, its
+// interest keeps accruing until the wallet is enabled again.
+func (s *service) post(ctx context.Context, wal wallet.Wallet, a *Accrual, end time.Time) (*Posting, error) {
+	amount := s.config.Round(a.Accrued)
+	if amount <= 0 {
+		a.PeriodStart = end
+		return nil, nil
+	}
+
+	if wallet.StatusError(wal.Status) != nil {
+		return nil, nil
+	}
+
+	result, err := s.wallets.DepositWallet(ctx, wallet.WalletTransactionParam{
+		ActorXID:    "interest",
+		OwnerXID:    wal.OwnerXID,
+		ReferenceID: wallet.SystemReferencePrefix + "interest-" + a.PeriodStart.Format("2006-01"),
+		Amount:      amount,
+		Type:        wallet.TransactionTypeInterest,
+	})
+	// the wallet may have changed status since it was listed
+	if err != nil && (err == wallet.ErrWalletDisabled || err == wallet.ErrWalletFrozen || err == wallet.ErrWalletClosed) {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed posting interest")
+	}
+
+	p := Posting{
+		ID:            uuid.NewString(),
+		OwnerXID:      wal.OwnerXID,
+		PeriodStart:   a.PeriodStart,
+		PeriodEnd:     end,
+		Amount:        amount,
+		TransactionID: result.ID,
+		PostedAt:      result.DepositedAt,
+	}
+	err = s.repo.CreatePosting(ctx, p)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed creating posting")
+	}
+
+	a.Accrued -= int64(amount) * Micros
+	a.PeriodStart = end
+	return &p, nil
+}
+
+func (s *service) GetAccrual(ctx context.Context, ownerXID string) (*Accrual, error) {
+	a, err := s.repo.GetAccrual(ctx, ownerXID)
+	if err != nil && err == ErrAccrualNotFound {
+		return nil, ErrAccrualNotFound
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed getting accrual")
+	}
+	return a, nil
+}
+
+func (s *service) GetPostings(ctx context.Context, ownerXID string) ([]Posting, error) {
+	postings, err := s.repo.GetPostings(ctx, ownerXID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed getting postings")
+	}
+	return postings, nil
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,233 @@
+package interest_test
+
+import (
+	"context"
+	"julo/internal/interest"
+	"julo/internal/wallet"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestDailyInterest(t *testing.T) {
+	config := interest.Config{
+		Tiers: []interest.Tier{
+			{UpTo: 1000000, AnnualBasisPoints: 0},
+			{UpTo: 0, AnnualBasisPoints: 365},
+		},
+	}
+	day := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		dayCount interest.DayCount
+		balance  int
+		micros   int64
+	}{
+		{interest.DayCountActual365, -5000, 0},
+		{interest.DayCountActual365, 1000000, 0},
+		// 3.65% of the 1000000 above the first tier over 365 days
+		{interest.DayCountActual365, 2000000, 100 * interest.Micros},
+		{interest.DayCountActual360, 2000000, 101388888},
+		{interest.DayCountActualActual, 2000000, 99726775},
+	}
+	for _, c := range cases {
+		config.DayCount = c.dayCount
+		if micros := config.DailyInterest(c.balance, day); micros != c.micros {
+			t.Fatalf("%s on %d: expecting %d, got %d", c.dayCount, c.balance, c.micros, micros)
+		}
+	}
+
+	for rounding, expected := range map[interest.Rounding]int{
+		interest.RoundingHalfUp: 2,
+		interest.RoundingDown:   1,
+		interest.RoundingUp:     2,
+	} {
+		config.Rounding = rounding
+		if amount := config.Round(interest.Micros * 3 / 2); amount != expected {
+			t.Fatalf("%s: expecting %d, got %d", rounding, expected, amount)
+		}
+	}
+
+	invalid := interest.Config{
+		Tiers: []interest.Tier{{UpTo: 0, AnnualBasisPoints: 100}, {UpTo: 1000, AnnualBasisPoints: 200}},
+	}
+	if err := invalid.Validate(); err != interest.ErrInvalidConfig {
+		t.Fatalf("expecting error %s, got %v", interest.ErrInvalidConfig, err)
+	}
+}
+
+func TestAccrueCatchUp(t *testing.T) {
+	ctx := context.Background()
+	config := interest.Config{
+		Tiers:    []interest.Tier{{AnnualBasisPoints: 1000}},
+		DayCount: interest.DayCountActual365,
+		Exclude:  []string{"house"},
+	}
+
+	newWallet := func() (wallet.Service, interest.Service, string) {
+		wallets := wallet.NewService(wallet.NewInMemoryRepository())
+		owner := uuid.NewString()
+		for _, xid := range []string{owner, "house"} {
+			_, err := wallets.EnableWallet(ctx, wallet.EnableWalletParam{
+				OwnerXID: xid,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = wallets.DepositWallet(ctx, wallet.WalletTransactionParam{
+				ActorXID:    xid,
+				OwnerXID:    xid,
+				ReferenceID: uuid.NewString(),
+				Amount:      3650000,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		return wallets, interest.NewService(interest.NewInMemoryRepository(), wallets, config), owner
+	}
+
+	now := time.Now()
+	end := now.AddDate(0, 0, 75)
+
+	// one service runs every day, the other was down and catches up at once
+	dailyWallets, daily, dailyOwner := newWallet()
+	for day := now; !day.After(end); day = day.AddDate(0, 0, 1) {
+		if _, err := daily.Accrue(ctx, day); err != nil {
+			t.Fatal(err)
+		}
+	}
+	catchUpWallets, catchUp, catchUpOwner := newWallet()
+	postings, err := catchUp.Accrue(ctx, end)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = catchUp.Accrue(ctx, end); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(postings) < 2 {
+		t.Fatalf("expecting a posting for every month ended, got %+v", postings)
+	}
+	// 10% of 3650000 is 1000 a day for the first period
+	first := postings[0]
+	days := int(first.PeriodEnd.Sub(first.PeriodStart).Hours()+12) / 24
+	if first.Amount != 1000*days {
+		t.Fatalf("expecting %d for %d days, got %d", 1000*days, days, first.Amount)
+	}
+
+	dailyPostings, err := daily.GetPostings(ctx, dailyOwner)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dailyPostings) != len(postings) {
+		t.Fatalf("expecting %d postings, got %d", len(postings), len(dailyPostings))
+	}
+	for i := range postings {
+		if dailyPostings[i].Amount != postings[i].Amount {
+			t.Fatalf("posting %d: expecting %d as when running daily, got %d", i, dailyPostings[i].Amount, postings[i].Amount)
+		}
+	}
+
+	dailyWallet, _ := dailyWallets.GetWalletByXID(ctx, dailyOwner)
+	catchUpWallet, _ := catchUpWallets.GetWalletByXID(ctx, catchUpOwner)
+	if dailyWallet.Balance != catchUpWallet.Balance {
+		t.Fatalf("expecting balance %d as when running daily, got %d", dailyWallet.Balance, catchUpWallet.Balance)
+	}
+	house, _ := catchUpWallets.GetWalletByXID(ctx, "house")
+	if house.Balance != 3650000 {
+		t.Fatalf("expecting excluded wallet to earn nothing, got balance %d", house.Balance)
+	}
+}
+
+func TestAccrueSkipsWalletsNotEnabled(t *testing.T) {
+	ctx := context.Background()
+	wallets := wallet.NewService(wallet.NewInMemoryRepository())
+	interests := interest.NewService(interest.NewInMemoryRepository(), wallets, interest.Config{
+		Tiers:    []interest.Tier{{AnnualBasisPoints: 1000}},
+		DayCount: interest.DayCountActual365,
+	})
+
+	frozen, enabled := uuid.NewString(), uuid.NewString()
+	for _, xid := range []string{frozen, enabled} {
+		_, err := wallets.EnableWallet(ctx, wallet.EnableWalletParam{OwnerXID: xid})
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = wallets.DepositWallet(ctx, wallet.WalletTransactionParam{
+			ActorXID:    xid,
+			OwnerXID:    xid,
+			ReferenceID: uuid.NewString(),
+			Amount:      3650000,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err := wallets.FreezeWallet(ctx, wallet.FreezeWalletParam{
+		OwnerXID: frozen,
+		ActorXID: "operator",
+		Reason:   wallet.FreezeReasonLegal,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	postings, err := interests.Accrue(ctx, time.Now().AddDate(0, 0, 45))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(postings) == 0 {
+		t.Fatal("expecting the enabled wallet to be posted to")
+	}
+	for _, p := range postings {
+		if p.OwnerXID != enabled {
+			t.Fatalf("expecting postings to the enabled wallet only, got %+v", p)
+		}
+	}
+
+	a, err := interests.GetAccrual(ctx, frozen)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Accrued == 0 {
+		t.Fatal("expecting the frozen wallet to keep accruing until it can be posted to")
+	}
+}
+
+func TestPostingNotBlockedByCustomerReference(t *testing.T) {
+	ctx := context.Background()
+	wallets := wallet.NewService(wallet.NewInMemoryRepository())
+	interests := interest.NewService(interest.NewInMemoryRepository(), wallets, interest.Config{
+		Tiers:    []interest.Tier{{AnnualBasisPoints: 1000}},
+		DayCount: interest.DayCountActual365,
+	})
+
+	now := time.Now()
+	owner := uuid.NewString()
+	_, err := wallets.EnableWallet(ctx, wallet.EnableWalletParam{OwnerXID: owner})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a customer picking the reference interest used to be posted under
+	for _, month := range []time.Time{now, now.AddDate(0, 1, 0)} {
+		_, err = wallets.DepositWallet(ctx, wallet.WalletTransactionParam{
+			ActorXID:    owner,
+			OwnerXID:    owner,
+			ReferenceID: "interest-" + month.Format("2006-01"),
+			Amount:      1825000,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	postings, err := interests.Accrue(ctx, now.AddDate(0, 0, 45))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(postings) == 0 {
+		t.Fatal("expecting interest posted")
+	}
+}
@@ -0,0 +1,48 @@
+package interest
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Worker accrues interest every interval. Accruing is idempotent within a
+// day, so the interval only bounds how late a day is accrued.
+type Worker struct {
+	interest Service
+	interval time.Duration
+}
+
+func NewWorker(interest Service, interval time.Duration) *Worker {
+	return &Worker{
+		interest: interest,
+		interval: interval,
+	}
+}
+
+// Run blocks, accruing straight away to catch up on downtime and then every
+// interval until ctx is done.
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	w.accrue(ctx, time.Now())
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			w.accrue(ctx, now)
+		}
+	}
+}
+
+func (w *Worker) accrue(ctx context.Context, now time.Time) {
+	postings, err := w.interest.Accrue(ctx, now)
+	if err != nil {
+		log.Println(err)
+	}
+	for _, p := range postings {
+		log.Printf("posted interest of %d to %s for %s", p.Amount, p.OwnerXID, p.PeriodStart.Format("2006-01"))
+	}
+}
@@ -0,0 +1,55 @@
+package kyc
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// DocumentStore keeps the documents customers upload.
+type DocumentStore interface {
+	// SaveDocument stores content under name and returns where it was
+	// stored.
+	SaveDocument(ctx context.Context, name string, content io.Reader) (string, error)
+	// DeleteDocument removes the document stored at path, a document already
+	// gone is not an error.
+	DeleteDocument(ctx context.Context, path string) error
+}
+
+// DiskStore stores documents as files below Dir, readable by the server
+// user only.
+type DiskStore struct {
+	Dir string
+}
+
+func (d DiskStore) SaveDocument(ctx context.Context, name string, content io.Reader) (string, error) {
+	path := filepath.Join(d.Dir, filepath.FromSlash(name))
+	err := os.MkdirAll(filepath.Dir(path), 0o700)
+	if err != nil {
+		return "", errors.Wrap(err, "failed creating document directory")
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return "", errors.Wrap(err, "failed creating document")
+	}
+	_, err = io.Copy(f, content)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return "", errors.Wrap(err, "failed writing document")
+	}
+	return path, nil
+}
+
+func (d DiskStore) DeleteDocument(ctx context.Context, path string) error {
+	err := os.Remove(path)
+	if err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "failed deleting document")
+	}
+	return nil
+}
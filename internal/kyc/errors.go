@@ -0,0 +1,17 @@
+package kyc
+
+import "errors"
+
+var (
+	ErrSubmissionNotFound       = errors.New("kyc submission not found")
+	ErrMissingRequiredParameter = errors.New("missing required parameter")
+	ErrInvalidTier              = errors.New("invalid kyc tier")
+	ErrInvalidIDNumber          = errors.New("invalid id number")
+	ErrInvalidDateOfBirth       = errors.New("invalid date of birth")
+	ErrMissingDocument          = errors.New("missing required document")
+	ErrInvalidDocument          = errors.New("document must be a jpeg, png or pdf")
+	ErrDocumentTooLarge         = errors.New("document too large")
+	ErrAlreadyVerified          = errors.New("account already verified for the tier")
+	ErrSubmissionPending        = errors.New("a kyc submission is already pending review")
+	ErrSubmissionReviewed       = errors.New("kyc submission already reviewed")
+)
@@ -0,0 +1,171 @@
+package http_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"julo/internal/account"
+	"julo/internal/auth"
+	authhttp "julo/internal/auth/http"
+	httphelper "julo/internal/http"
+	"julo/internal/kyc"
+	kychttp "julo/internal/kyc/http"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/google/uuid"
+)
+
+func TestKYC(t *testing.T) {
+	ctx := context.Background()
+	accounts := account.NewService(account.NewInMemoryRepository())
+	kycs := kyc.NewService(kyc.NewInMemoryRepository(), accounts, kyc.DiskStore{Dir: t.TempDir()}, kyc.Config{})
+	adminToken := uuid.NewString()
+
+	router := chi.NewRouter()
+	router.Mount("/api/v1", router.Group(func(r chi.Router) {
+		r.Mount("/wallet", r.Group(func(r chi.Router) {
+			r.Use(authhttp.Middleware)
+			r.Get("/kyc", kychttp.ViewStatusHandler(kycs).ServeHTTP)
+			r.Post("/kyc", kychttp.SubmitHandler(kycs).ServeHTTP)
+		}))
+		r.Mount("/admin", r.Group(func(r chi.Router) {
+			r.Use(authhttp.AdminMiddleware(adminToken))
+			r.Get("/kyc/submissions", kychttp.ViewPendingSubmissionsHandler(kycs).ServeHTTP)
+			r.Post("/kyc/submissions/{id}/approve", kychttp.ApproveSubmissionHandler(kycs).ServeHTTP)
+			r.Post("/kyc/submissions/{id}/reject", kychttp.RejectSubmissionHandler(kycs).ServeHTTP)
+		}))
+	}))
+	server := httptest.NewServer(router)
+	defer server.Close()
+	baseUrl := server.URL
+
+	xid, token := uuid.NewString(), uuid.NewString()
+	err := accounts.CreateAccount(ctx, account.Account{XID: xid, KYCStatus: account.KYCStatusUnverified})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = auth.StoreSession(ctx, auth.Session{
+		Token:   token,
+		Account: account.Account{XID: xid},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("submit without id card, should fail", func(t *testing.T) {
+		req := buildSubmission(t, baseUrl, token, map[string]string{"tier": "basic"}, nil)
+		res, err := server.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.StatusCode != http.StatusBadRequest {
+			t.Fatalf("expecting status %v, got %v", http.StatusBadRequest, res.StatusCode)
+		}
+	})
+
+	t.Run("submit for basic tier, should success", func(t *testing.T) {
+		req := buildSubmission(t, baseUrl, token, map[string]string{"tier": "basic"}, []string{"id_card"})
+		res, err := server.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.StatusCode != http.StatusCreated {
+			t.Fatalf("expecting status %v, got %v", http.StatusCreated, res.StatusCode)
+		}
+		sub := decode(t, res)["submission"].(map[string]interface{})
+		id := sub["id"].(string)
+		if _, ok := sub["documents"].([]interface{})[0].(map[string]interface{})["path"]; ok {
+			t.Fatal("expecting document path not exposed")
+		}
+
+		t.Run("list pending submissions, should include it", func(t *testing.T) {
+			req := buildAuthenticatedRequest(t, http.MethodGet, baseUrl+"/api/v1/admin/kyc/submissions", adminToken, nil)
+			res, err := server.Client().Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.StatusCode != http.StatusOK {
+				t.Fatalf("expecting status %v, got %v", http.StatusOK, res.StatusCode)
+			}
+			submissions := decode(t, res)["submissions"].([]interface{})
+			if len(submissions) != 1 || submissions[0].(map[string]interface{})["id"] != id {
+				t.Fatalf("expecting submission %s pending, got %v", id, submissions)
+			}
+		})
+
+		t.Run("approve submission, should verify account", func(t *testing.T) {
+			req := buildAuthenticatedRequest(t, http.MethodPost, baseUrl+"/api/v1/admin/kyc/submissions/"+id+"/approve", adminToken, nil)
+			res, err := server.Client().Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.StatusCode != http.StatusOK {
+				t.Fatalf("expecting status %v, got %v", http.StatusOK, res.StatusCode)
+			}
+
+			req = buildAuthenticatedRequest(t, http.MethodGet, baseUrl+"/api/v1/wallet/kyc", token, nil)
+			res, err = server.Client().Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if status := decode(t, res)["kyc_status"]; status != string(account.KYCStatusBasic) {
+				t.Fatalf("expecting status %s, got %v", account.KYCStatusBasic, status)
+			}
+		})
+	})
+}
+
+func buildSubmission(t *testing.T, baseUrl string, token string, fields map[string]string, documents []string) *http.Request {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	fields["full_name"] = "Budi Santoso"
+	fields["id_number"] = "3171234567890123"
+	fields["date_of_birth"] = "1990-01-02"
+	for k, v := range fields {
+		if err := mw.WriteField(k, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, kind := range documents {
+		w, err := mw.CreateFormFile(kind, kind+".png")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := png.Encode(w, image.NewGray(image.Rect(0, 0, 8, 8))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := mw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	req := buildAuthenticatedRequest(t, http.MethodPost, baseUrl+"/api/v1/wallet/kyc", token, &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return req
+}
+
+func decode(t *testing.T, res *http.Response) map[string]interface{} {
+	var response httphelper.Response
+	err := json.NewDecoder(res.Body).Decode(&response)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return response.Data.(map[string]interface{})
+}
+
+func buildAuthenticatedRequest(t *testing.T, method string, url string, token string, body io.Reader) *http.Request {
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Add("Authorization", fmt.Sprintf("Token %s", token))
+	return req
+}
@@ -0,0 +1,65 @@
+package http
+
+import (
+	"julo/internal/kyc"
+	"time"
+)
+
+type documentResponse struct {
+	Kind        string `json:"kind"`
+	ContentType string `json:"content_type"`
+	Size        int    `json:"size"`
+}
+
+// submissionResponse leaves out where documents are stored, operators get
+// to them on the server.
+type submissionResponse struct {
+	ID          string             `json:"id"`
+	CustomerXID string             `json:"customer_xid"`
+	Tier        string             `json:"tier"`
+	FullName    string             `json:"full_name"`
+	IDNumber    string             `json:"id_number"`
+	DateOfBirth string             `json:"date_of_birth"`
+	Address     string             `json:"address,omitempty"`
+	Documents   []documentResponse `json:"documents"`
+	Status      string             `json:"status"`
+	Reason      string             `json:"reason,omitempty"`
+	CreatedAt   time.Time          `json:"created_at"`
+	ReviewedAt  *time.Time         `json:"reviewed_at,omitempty"`
+}
+
+func newSubmissionResponse(s kyc.Submission) submissionResponse {
+	documents := make([]documentResponse, len(s.Documents))
+	for i, d := range s.Documents {
+		documents[i] = documentResponse{
+			Kind:        string(d.Kind),
+			ContentType: d.ContentType,
+			Size:        d.Size,
+		}
+	}
+	res := submissionResponse{
+		ID:          s.ID,
+		CustomerXID: s.CustomerXID,
+		Tier:        string(s.Tier),
+		FullName:    s.FullName,
+		IDNumber:    s.IDNumber,
+		DateOfBirth: s.DateOfBirth.Format(dateLayout),
+		Address:     s.Address,
+		Documents:   documents,
+		Status:      string(s.Status),
+		Reason:      s.Reason,
+		CreatedAt:   s.CreatedAt,
+	}
+	if !s.ReviewedAt.IsZero() {
+		res.ReviewedAt = &s.ReviewedAt
+	}
+	return res
+}
+
+func newSubmissionsResponse(submissions []kyc.Submission) []submissionResponse {
+	res := make([]submissionResponse, len(submissions))
+	for i, s := range submissions {
+		res[i] = newSubmissionResponse(s)
+	}
+	return res
+}
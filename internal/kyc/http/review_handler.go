@@ -0,0 +1,80 @@
+package http
+
+import (
+	"context"
+	httphelper "julo/internal/http"
+	"julo/internal/kyc"
+	"net/http"
+
+	"github.com/go-chi/chi"
+)
+
+// ViewPendingSubmissionsHandler lists the submissions waiting for review,
+// oldest first.
+func ViewPendingSubmissionsHandler(kycs kyc.Service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var response httphelper.Response
+
+		submissions, err := kycs.GetPendingSubmissions(r.Context())
+		if err != nil {
+			httphelper.WriteErrorJSON(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		response.Status = "success"
+		response.Data = map[string]interface{}{
+			"submissions": newSubmissionsResponse(submissions),
+		}
+		httphelper.WriteJSON(w, http.StatusOK, response)
+	})
+}
+
+type reviewAction func(context.Context, kyc.ReviewParam) (*kyc.Submission, error)
+
+// ApproveSubmissionHandler and RejectSubmissionHandler review the submission
+// identified by the "id" url parameter, with an optional reason.
+func ApproveSubmissionHandler(kycs kyc.Service) http.Handler {
+	return reviewHandler(kycs.ApproveSubmission)
+}
+
+func RejectSubmissionHandler(kycs kyc.Service) http.Handler {
+	return reviewHandler(kycs.RejectSubmission)
+}
+
+func reviewHandler(action reviewAction) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var response httphelper.Response
+
+		var req reviewRequest
+		if err := httphelper.Decode(w, r, &req); err != nil {
+			httphelper.WriteRequestErrorJSON(w, err)
+			return
+		}
+
+		sub, err := action(r.Context(), kyc.ReviewParam{
+			SubmissionID: chi.URLParam(r, "id"),
+			Reason:       req.Reason,
+		})
+		if err != nil {
+			switch err {
+			case kyc.ErrSubmissionNotFound:
+				httphelper.WriteErrorJSON(w, http.StatusNotFound, err)
+			case kyc.ErrMissingRequiredParameter, kyc.ErrSubmissionReviewed:
+				httphelper.WriteErrorJSON(w, http.StatusBadRequest, err)
+			default:
+				httphelper.WriteErrorJSON(w, http.StatusInternalServerError, err)
+			}
+			return
+		}
+
+		response.Status = "success"
+		response.Data = map[string]interface{}{
+			"submission": newSubmissionResponse(*sub),
+		}
+		httphelper.WriteJSON(w, http.StatusOK, response)
+	})
+}
+
+type reviewRequest struct {
+	Reason string `json:"reason"`
+}
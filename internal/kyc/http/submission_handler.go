@@ -0,0 +1,142 @@
+package http
+
+import (
+	"errors"
+	"julo/internal/account"
+	"julo/internal/auth"
+	httphelper "julo/internal/http"
+	"julo/internal/kyc"
+	"net/http"
+	"time"
+)
+
+const dateLayout = "2006-01-02"
+
+// maxSubmissionSize bounds the whole multipart body, both documents and the
+// identity fields.
+const maxSubmissionSize = 2*kyc.MaxDocumentSize + 1<<20
+
+var ErrInvalidForm = errors.New("invalid multipart form")
+
+// SubmitHandler takes a multipart form with tier (basic or full),
+// full_name, id_number, date_of_birth (YYYY-MM-DD), address and the
+// id_card and selfie files.
+func SubmitHandler(kycs kyc.Service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var response httphelper.Response
+		session := auth.SessionFromContext(r.Context())
+		if session == nil {
+			httphelper.WriteErrorJSON(w, http.StatusUnauthorized, auth.ErrSessionNotFound)
+			return
+		}
+
+		// the form is parsed ahead of Decode, whose body limit the documents
+		// would not fit in
+		r.Body = http.MaxBytesReader(w, r.Body, maxSubmissionSize)
+		err := r.ParseMultipartForm(1 << 20)
+		if err != nil {
+			httphelper.WriteErrorJSON(w, http.StatusBadRequest, ErrInvalidForm)
+			return
+		}
+		defer r.MultipartForm.RemoveAll()
+
+		var req submitRequest
+		if err := httphelper.Decode(w, r, &req); err != nil {
+			httphelper.WriteRequestErrorJSON(w, err)
+			return
+		}
+
+		dob, err := time.Parse(dateLayout, req.DateOfBirth)
+		if err != nil {
+			httphelper.WriteErrorJSON(w, http.StatusBadRequest, kyc.ErrInvalidDateOfBirth)
+			return
+		}
+
+		var uploads []kyc.Upload
+		for _, kind := range []kyc.DocumentKind{kyc.DocumentKindIDCard, kyc.DocumentKindSelfie} {
+			f, _, err := r.FormFile(string(kind))
+			if err == http.ErrMissingFile {
+				continue
+			} else if err != nil {
+				httphelper.WriteErrorJSON(w, http.StatusBadRequest, ErrInvalidForm)
+				return
+			}
+			defer f.Close()
+			uploads = append(uploads, kyc.Upload{Kind: kind, Content: f})
+		}
+
+		sub, err := kycs.Submit(r.Context(), kyc.SubmitParam{
+			CustomerXID: session.Account.XID,
+			Tier:        account.KYCStatus(req.Tier),
+			FullName:    req.FullName,
+			IDNumber:    req.IDNumber,
+			DateOfBirth: dob,
+			Address:     req.Address,
+			Documents:   uploads,
+		})
+		if err != nil {
+			switch err {
+			case kyc.ErrMissingRequiredParameter, kyc.ErrInvalidTier, kyc.ErrInvalidIDNumber, kyc.ErrInvalidDateOfBirth,
+				kyc.ErrMissingDocument, kyc.ErrInvalidDocument, kyc.ErrDocumentTooLarge, kyc.ErrAlreadyVerified:
+				httphelper.WriteErrorJSON(w, http.StatusBadRequest, err)
+			case kyc.ErrSubmissionPending:
+				httphelper.WriteErrorJSON(w, http.StatusConflict, err)
+			case account.ErrAccountNotFound:
+				httphelper.WriteErrorJSON(w, http.StatusNotFound, err)
+			default:
+				httphelper.WriteErrorJSON(w, http.StatusInternalServerError, err)
+			}
+			return
+		}
+
+		response.Status = "success"
+		response.Data = map[string]interface{}{
+			"submission": newSubmissionResponse(*sub),
+		}
+		httphelper.WriteJSON(w, http.StatusCreated, response)
+	})
+}
+
+// ViewStatusHandler shows the KYC status of the customer along with their
+// submissions.
+func ViewStatusHandler(kycs kyc.Service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var response httphelper.Response
+		session := auth.SessionFromContext(r.Context())
+		if session == nil {
+			httphelper.WriteErrorJSON(w, http.StatusUnauthorized, auth.ErrSessionNotFound)
+			return
+		}
+
+		status, err := kycs.GetStatus(r.Context(), session.Account.XID)
+		if err != nil && err == account.ErrAccountNotFound {
+			httphelper.WriteErrorJSON(w, http.StatusNotFound, err)
+			return
+		} else if err != nil {
+			httphelper.WriteErrorJSON(w, http.StatusInternalServerError, err)
+			return
+		}
+		submissions, err := kycs.GetSubmissions(r.Context(), session.Account.XID)
+		if err != nil {
+			httphelper.WriteErrorJSON(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		response.Status = "success"
+		response.Data = map[string]interface{}{
+			"kyc_status":  status,
+			"submissions": newSubmissionsResponse(submissions),
+		}
+		httphelper.WriteJSON(w, http.StatusOK, response)
+	})
+}
+
+// submitRequest holds the identity fields of a submission, the documents
+// being read from the form as files.
+type submitRequest struct {
+	Tier        string `json:"tier" validate:"required"`
+	FullName    string `json:"full_name" validate:"required"`
+	IDNumber    string `json:"id_number" validate:"required"`
+	DateOfBirth string `json:"date_of_birth" validate:"required"`
+	Address     string `json:"address"`
+}
@@ -0,0 +1,108 @@
+package kyc
+
+import (
+	"context"
+	"julo/internal/account"
+	"sort"
+	"sync"
+	"time"
+)
+
+type DocumentKind string
+
+var (
+	DocumentKindIDCard = DocumentKind("id_card")
+	DocumentKindSelfie = DocumentKind("selfie")
+)
+
+type Document struct {
+	Kind        DocumentKind
+	Path        string
+	ContentType string
+	Size        int
+}
+
+type SubmissionStatus string
+
+var (
+	SubmissionStatusPending  = SubmissionStatus("pending")
+	SubmissionStatusApproved = SubmissionStatus("approved")
+	SubmissionStatusRejected = SubmissionStatus("rejected")
+)
+
+// Submission is the identity data and documents a customer sent to be
+// verified for Tier.
+type Submission struct {
+	ID          string
+	CustomerXID string
+	Tier        account.KYCStatus
+	FullName    string
+	IDNumber    string
+	DateOfBirth time.Time
+	Address     string
+	Documents   []Document
+	Status      SubmissionStatus
+	// Reason is the operator's note on the review.
+	Reason     string
+	CreatedAt  time.Time
+	ReviewedAt time.Time
+	// ErasedAt is when the identity data and documents were erased, the
+	// submission is kept as a record of the review.
+	ErasedAt time.Time
+}
+
+type Repository interface {
+	SaveSubmission(ctx context.Context, s Submission) error
+	GetSubmission(ctx context.Context, id string) (*Submission, error)
+	GetSubmissions(ctx context.Context, customerXID string) ([]Submission, error)
+	GetSubmissionsByStatus(ctx context.Context, status SubmissionStatus) ([]Submission, error)
+}
+
+type InMemoryRepository struct {
+	submissions sync.Map
+}
+
+func NewInMemoryRepository() Repository {
+	return &InMemoryRepository{}
+}
+
+func (r *InMemoryRepository) SaveSubmission(ctx context.Context, s Submission) error {
+	s.Documents = append([]Document(nil), s.Documents...)
+	r.submissions.Store(s.ID, &s)
+	return nil
+}
+
+func (r *InMemoryRepository) GetSubmission(ctx context.Context, id string) (*Submission, error) {
+	v, ok := r.submissions.Load(id)
+	if !ok {
+		return nil, ErrSubmissionNotFound
+	}
+
+	s := *v.(*Submission)
+	s.Documents = append([]Document(nil), s.Documents...)
+	return &s, nil
+}
+
+func (r *InMemoryRepository) GetSubmissions(ctx context.Context, customerXID string) ([]Submission, error) {
+	return r.filter(func(s Submission) bool { return s.CustomerXID == customerXID }), nil
+}
+
+func (r *InMemoryRepository) GetSubmissionsByStatus(ctx context.Context, status SubmissionStatus) ([]Submission, error) {
+	return r.filter(func(s Submission) bool { return s.Status == status }), nil
+}
+
+func (r *InMemoryRepository) filter(match func(Submission) bool) []Submission {
+	submissions := []Submission{}
+	r.submissions.Range(func(key, value any) bool {
+		s := *value.(*Submission)
+		if match(s) {
+			s.Documents = append([]Document(nil), s.Documents...)
+			submissions = append(submissions, s)
+		}
+		return true
+	})
+	sort.Slice(submissions, func(i, j int) bool {
+		return submissions[i].CreatedAt.Before(submissions[j].CreatedAt)
+	})
+	return submissions
+}
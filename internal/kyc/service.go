@@ -0,0 +1,352 @@
+package kyc
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"julo/internal/account"
+	"julo/internal/wallet"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+// MaxDocumentSize is the largest document accepted, in bytes.
+const MaxDocumentSize = 5 << 20
+
+var documentExtensions = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"application/pdf": ".pdf",
+}
+
+// requiredDocuments lists the documents each tier is verified with.
+var requiredDocuments = map[account.KYCStatus][]DocumentKind{
+	account.KYCStatusBasic: {DocumentKindIDCard},
+	account.KYCStatusFull:  {DocumentKindIDCard, DocumentKindSelfie},
+}
+
+type Upload struct {
+	Kind    DocumentKind
+	Content io.Reader
+}
+
+type SubmitParam struct {
+	CustomerXID string
+	Tier        account.KYCStatus
+	FullName    string
+	// IDNumber is the 16 digit national identity number.
+	IDNumber    string
+	DateOfBirth time.Time
+	// Address is only required for the full tier.
+	Address   string
+	Documents []Upload
+}
+
+func (p SubmitParam) Validate() error {
+	if p.CustomerXID == "" || p.FullName == "" || p.IDNumber == "" || p.DateOfBirth.IsZero() {
+		return ErrMissingRequiredParameter
+	}
+	required, ok := requiredDocuments[p.Tier]
+	if !ok {
+		return ErrInvalidTier
+	}
+	if p.Tier == account.KYCStatusFull && p.Address == "" {
+		return ErrMissingRequiredParameter
+	}
+	if len(p.IDNumber) != 16 {
+		return ErrInvalidIDNumber
+	}
+	for _, c := range p.IDNumber {
+		if c < '0' || c > '9' {
+			return ErrInvalidIDNumber
+		}
+	}
+	if !p.DateOfBirth.Before(time.Now()) {
+		return ErrInvalidDateOfBirth
+	}
+
+	for _, kind := range required {
+		var found bool
+		for _, u := range p.Documents {
+			if u.Kind == kind && u.Content != nil {
+				found = true
+			}
+		}
+		if !found {
+			return ErrMissingDocument
+		}
+	}
+	return nil
+}
+
+// EraseParam replaces the identity data of the customer with Pseudonym.
+type EraseParam struct {
+	CustomerXID string
+	Pseudonym   string
+}
+
+type ReviewParam struct {
+	SubmissionID string
+	Reason       string
+}
+
+// Config holds the wallet limits of each KYC status.
+type Config struct {
+	Limits map[account.KYCStatus]wallet.Limits
+}
+
+type Service interface {
+	Submit(ctx context.Context, param SubmitParam) (*Submission, error)
+	GetStatus(ctx context.Context, customerXID string) (account.KYCStatus, error)
+	GetSubmissions(ctx context.Context, customerXID string) ([]Submission, error)
+	GetPendingSubmissions(ctx context.Context) ([]Submission, error)
+	ApproveSubmission(ctx context.Context, param ReviewParam) (*Submission, error)
+	RejectSubmission(ctx context.Context, param ReviewParam) (*Submission, error)
+	Limits(ctx context.Context, ownerXID string) (*wallet.Limits, error)
+	EraseSubmissions(ctx context.Context, param EraseParam) ([]Submission, error)
+}
+
+type service struct {
+	repo      Repository
+	accounts  account.Service
+	documents DocumentStore
+	config    Config
+	mu        sync.Mutex
+}
+
+func NewService(repo Repository, accounts account.Service, documents DocumentStore, config Config) Service {
+	return &service{
+		repo:      repo,
+		accounts:  accounts,
+		documents: documents,
+		config:    config,
+	}
+}
+
+// Submit stores the documents and queues the submission for review. A
+// customer has at most one submission pending at a time.
+func (s *service) Submit(ctx context.Context, param SubmitParam) (*Submission, error) {
+	err := param.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status, err := s.GetStatus(ctx, param.CustomerXID)
+	if err != nil {
+		return nil, err
+	}
+	if status.Rank() >= param.Tier.Rank() {
+		return nil, ErrAlreadyVerified
+	}
+	submissions, err := s.GetSubmissions(ctx, param.CustomerXID)
+	if err != nil {
+		return nil, err
+	}
+	for _, sub := range submissions {
+		if sub.Status == SubmissionStatusPending {
+			return nil, ErrSubmissionPending
+		}
+	}
+
+	sub := Submission{
+		ID:          uuid.NewString(),
+		CustomerXID: param.CustomerXID,
+		Tier:        param.Tier,
+		FullName:    param.FullName,
+		IDNumber:    param.IDNumber,
+		DateOfBirth: param.DateOfBirth,
+		Address:     param.Address,
+		Status:      SubmissionStatusPending,
+		CreatedAt:   time.Now(),
+	}
+	for _, u := range param.Documents {
+		doc, err := s.saveDocument(ctx, sub.ID, u)
+		if err != nil {
+			return nil, err
+		}
+		sub.Documents = append(sub.Documents, *doc)
+	}
+
+	err = s.repo.SaveSubmission(ctx, sub)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed saving kyc submission")
+	}
+	return &sub, nil
+}
+
+// saveDocument checks the upload is an accepted document and stores it
+// under the submission, named after its kind.
+func (s *service) saveDocument(ctx context.Context, submissionID string, u Upload) (*Document, error) {
+	content, err := io.ReadAll(io.LimitReader(u.Content, MaxDocumentSize+1))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed reading document")
+	}
+	if len(content) > MaxDocumentSize {
+		return nil, ErrDocumentTooLarge
+	}
+	contentType := http.DetectContentType(content)
+	ext, ok := documentExtensions[contentType]
+	if !ok {
+		return nil, ErrInvalidDocument
+	}
+
+	path, err := s.documents.SaveDocument(ctx, submissionID+"/"+string(u.Kind)+ext, bytes.NewReader(content))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed saving document")
+	}
+	return &Document{
+		Kind:        u.Kind,
+		Path:        path,
+		ContentType: contentType,
+		Size:        len(content),
+	}, nil
+}
+
+func (s *service) GetStatus(ctx context.Context, customerXID string) (account.KYCStatus, error) {
+	acc, err := s.accounts.GetAccount(ctx, customerXID)
+	if err != nil && err == account.ErrAccountNotFound {
+		return "", account.ErrAccountNotFound
+	} else if err != nil {
+		return "", errors.Wrap(err, "failed getting account")
+	}
+	return acc.Verification(), nil
+}
+
+func (s *service) GetSubmissions(ctx context.Context, customerXID string) ([]Submission, error) {
+	submissions, err := s.repo.GetSubmissions(ctx, customerXID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed getting kyc submissions")
+	}
+	return submissions, nil
+}
+
+func (s *service) GetPendingSubmissions(ctx context.Context) ([]Submission, error) {
+	submissions, err := s.repo.GetSubmissionsByStatus(ctx, SubmissionStatusPending)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed getting kyc submissions")
+	}
+	return submissions, nil
+}
+
+// ApproveSubmission verifies the customer for the tier of the submission.
+func (s *service) ApproveSubmission(ctx context.Context, param ReviewParam) (*Submission, error) {
+	return s.review(ctx, param, SubmissionStatusApproved)
+}
+
+// RejectSubmission leaves the customer at its current status, they may
+// submit again.
+func (s *service) RejectSubmission(ctx context.Context, param ReviewParam) (*Submission, error) {
+	return s.review(ctx, param, SubmissionStatusRejected)
+}
+
+func (s *service) review(ctx context.Context, param ReviewParam, status SubmissionStatus) (*Submission, error) {
+	if param.SubmissionID == "" {
+		return nil, ErrMissingRequiredParameter
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sub, err := s.repo.GetSubmission(ctx, param.SubmissionID)
+	if err != nil && err == ErrSubmissionNotFound {
+		return nil, ErrSubmissionNotFound
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed getting kyc submission")
+	}
+	if sub.Status != SubmissionStatusPending {
+		return nil, ErrSubmissionReviewed
+	}
+
+	if status == SubmissionStatusApproved {
+		_, err = s.accounts.SetKYCStatus(ctx, sub.CustomerXID, sub.Tier)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed updating kyc status")
+		}
+		_, err = s.accounts.SetIDNumber(ctx, sub.CustomerXID, sub.IDNumber)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed updating id number")
+		}
+	}
+
+	sub.Status = status
+	sub.Reason = param.Reason
+	sub.ReviewedAt = time.Now()
+	err = s.repo.SaveSubmission(ctx, *sub)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed saving kyc submission")
+	}
+	return sub, nil
+}
+
+// Limits tells the wallet limits of the KYC status of the customer. Wallets
+// not owned by a customer, such as the house or merchant wallets, are not
+// limited.
+func (s *service) Limits(ctx context.Context, ownerXID string) (*wallet.Limits, error) {
+	status, err := s.GetStatus(ctx, ownerXID)
+	if err != nil && err == account.ErrAccountNotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	limits, ok := s.config.Limits[status]
+	if !ok {
+		return nil, nil
+	}
+	return &limits, nil
+}
+
+// EraseSubmissions deletes the documents of every submission of the customer
+// and replaces the full name with the pseudonym, dropping the rest of the
+// identity data. The tier and review outcome are kept, a submission still
+// pending is rejected.
+func (s *service) EraseSubmissions(ctx context.Context, param EraseParam) ([]Submission, error) {
+	if param.CustomerXID == "" || param.Pseudonym == "" {
+		return nil, ErrMissingRequiredParameter
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	submissions, err := s.repo.GetSubmissions(ctx, param.CustomerXID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed getting kyc submissions")
+	}
+
+	now := time.Now()
+	for i := range submissions {
+		sub := &submissions[i]
+		for j := range sub.Documents {
+			if sub.Documents[j].Path == "" {
+				continue
+			}
+			err = s.documents.DeleteDocument(ctx, sub.Documents[j].Path)
+			if err != nil {
+				return nil, err
+			}
+			sub.Documents[j].Path = ""
+		}
+		if sub.Status == SubmissionStatusPending {
+			sub.Status = SubmissionStatusRejected
+			sub.Reason = "data erased"
+			sub.ReviewedAt = now
+		}
+		sub.FullName = param.Pseudonym
+		sub.IDNumber = ""
+		sub.DateOfBirth = time.Time{}
+		sub.Address = ""
+		sub.ErasedAt = now
+		err = s.repo.SaveSubmission(ctx, *sub)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed saving kyc submission")
+		}
+	}
+	return submissions, nil
+}
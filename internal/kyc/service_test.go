@@ -0,0 +1,112 @@
+package kyc_test
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"io"
+	"julo/internal/account"
+	"julo/internal/kyc"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func pngDocument(t *testing.T) io.Reader {
+	var buf bytes.Buffer
+	err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, 8, 8)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &buf
+}
+
+func TestSubmission(t *testing.T) {
+	ctx := context.Background()
+	accounts := account.NewService(account.NewInMemoryRepository())
+	service := kyc.NewService(kyc.NewInMemoryRepository(), accounts, kyc.DiskStore{Dir: t.TempDir()}, kyc.Config{})
+
+	xid := uuid.NewString()
+	err := accounts.CreateAccount(ctx, account.Account{XID: xid, KYCStatus: account.KYCStatusUnverified})
+	if err != nil {
+		t.Fatal(err)
+	}
+	param := func(tier account.KYCStatus, documents ...kyc.Upload) kyc.SubmitParam {
+		return kyc.SubmitParam{
+			CustomerXID: xid,
+			Tier:        tier,
+			FullName:    "Budi Santoso",
+			IDNumber:    "3171234567890123",
+			DateOfBirth: time.Date(1990, 1, 2, 0, 0, 0, 0, time.UTC),
+			Address:     "Jl. Sudirman 1, Jakarta",
+			Documents:   documents,
+		}
+	}
+
+	t.Run("submit without id card, should fail", func(t *testing.T) {
+		_, err := service.Submit(ctx, param(account.KYCStatusBasic))
+		if err != kyc.ErrMissingDocument {
+			t.Fatalf("expecting error %s, got %v", kyc.ErrMissingDocument, err)
+		}
+	})
+
+	t.Run("submit text as id card, should fail", func(t *testing.T) {
+		_, err := service.Submit(ctx, param(account.KYCStatusBasic, kyc.Upload{Kind: kyc.DocumentKindIDCard, Content: strings.NewReader("not an image")}))
+		if err != kyc.ErrInvalidDocument {
+			t.Fatalf("expecting error %s, got %v", kyc.ErrInvalidDocument, err)
+		}
+	})
+
+	t.Run("submit for basic tier, should store documents", func(t *testing.T) {
+		sub, err := service.Submit(ctx, param(account.KYCStatusBasic, kyc.Upload{Kind: kyc.DocumentKindIDCard, Content: pngDocument(t)}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sub.Status != kyc.SubmissionStatusPending || len(sub.Documents) != 1 {
+			t.Fatalf("expecting pending submission with one document, got %+v", sub)
+		}
+		if _, err := os.Stat(sub.Documents[0].Path); err != nil {
+			t.Fatalf("expecting document stored on disk, got %v", err)
+		}
+
+		t.Run("submit again while pending, should fail", func(t *testing.T) {
+			_, err := service.Submit(ctx, param(account.KYCStatusFull,
+				kyc.Upload{Kind: kyc.DocumentKindIDCard, Content: pngDocument(t)},
+				kyc.Upload{Kind: kyc.DocumentKindSelfie, Content: pngDocument(t)},
+			))
+			if err != kyc.ErrSubmissionPending {
+				t.Fatalf("expecting error %s, got %v", kyc.ErrSubmissionPending, err)
+			}
+		})
+
+		t.Run("approve submission, should verify account", func(t *testing.T) {
+			_, err := service.ApproveSubmission(ctx, kyc.ReviewParam{SubmissionID: sub.ID})
+			if err != nil {
+				t.Fatal(err)
+			}
+			status, err := service.GetStatus(ctx, xid)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if status != account.KYCStatusBasic {
+				t.Fatalf("expecting status %s, got %s", account.KYCStatusBasic, status)
+			}
+
+			_, err = service.RejectSubmission(ctx, kyc.ReviewParam{SubmissionID: sub.ID})
+			if err != kyc.ErrSubmissionReviewed {
+				t.Fatalf("expecting error %s, got %v", kyc.ErrSubmissionReviewed, err)
+			}
+		})
+
+		t.Run("submit for basic tier again, should fail", func(t *testing.T) {
+			_, err := service.Submit(ctx, param(account.KYCStatusBasic, kyc.Upload{Kind: kyc.DocumentKindIDCard, Content: pngDocument(t)}))
+			if err != kyc.ErrAlreadyVerified {
+				t.Fatalf("expecting error %s, got %v", kyc.ErrAlreadyVerified, err)
+			}
+		})
+	})
+}
@@ -0,0 +1,137 @@
+package loan
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"julo/internal/wallet"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type CollectionOutcome string
+
+var (
+	CollectionOutcomeCollected = CollectionOutcome("collected")
+	CollectionOutcomePartial   = CollectionOutcome("partial")
+	CollectionOutcomeFailed    = CollectionOutcome("failed")
+)
+
+// Collection records one attempt to auto-debit the due installments of a loan
+// from the borrower's wallet.
+type Collection struct {
+	LoanID        string
+	AccountXID    string
+	Date          time.Time
+	Due           int
+	Collected     int
+	Outcome       CollectionOutcome
+	TransactionID string
+	Reason        string
+}
+
+type CollectParam struct {
+	Now time.Time
+	// DryRun reports what would be collected without debiting any wallet
+	// or recording the attempts.
+	DryRun bool
+}
+
+// CollectDueInstallments debits whatever is due on the active loans from the
+// borrowers' wallets. When a wallet cannot cover the full amount the
+// available balance is collected and the rest stays due, to be attempted
+// again on the next run.
+func (s *service) CollectDueInstallments(ctx context.Context, param CollectParam) ([]Collection, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	loans, err := s.repo.GetActiveLoans(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed getting active loans")
+	}
+
+	collections := []Collection{}
+	for _, l := range loans {
+		due := l.Due(param.Now)
+		if due <= 0 {
+			continue
+		}
+
+		c, err := s.collect(ctx, &l, due, param)
+		if err != nil {
+			return collections, err
+		}
+		collections = append(collections, *c)
+		if param.DryRun {
+			continue
+		}
+
+		l.Collections = append(l.Collections, *c)
+		err = s.repo.UpdateLoan(ctx, l)
+		if err != nil {
+			return collections, errors.Wrap(err, "failed updating loan")
+		}
+	}
+	return collections, nil
+}
+
+func (s *service) collect(ctx context.Context, l *Loan, due int, param CollectParam) (*Collection, error) {
+	c := &Collection{
+		LoanID:     l.ID,
+		AccountXID: l.AccountXID,
+		Date:       param.Now,
+		Due:        due,
+		Outcome:    CollectionOutcomeFailed,
+	}
+
+	w, err := s.wallets.GetWalletByXID(ctx, l.AccountXID)
+	if err != nil && err == wallet.ErrWalletNotFound {
+		c.Reason = err.Error()
+		return c, nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed getting wallet")
+	}
+	if err := wallet.StatusError(w.Status); err != nil {
+		c.Reason = err.Error()
+		return c, nil
+	}
+
+	amount := due
+	if available := w.AvailableBalance(); available < amount {
+		amount = available
+	}
+	if amount <= 0 {
+		c.Reason = wallet.ErrInsufficientBalance.Error()
+		return c, nil
+	}
+
+	if !param.DryRun {
+		referenceID := wallet.SystemReferencePrefix + "loan-" + l.ID + "-collection-" + strconv.Itoa(len(l.Collections)+1)
+		r, err := s.repay(ctx, l, "loan:"+l.ID, referenceID, referenceID, amount)
+		if err != nil {
+			c.Reason = err.Error()
+			return c, nil
+		}
+		c.TransactionID = r.TransactionID
+	}
+
+	c.Collected = amount
+	c.Outcome = CollectionOutcomeCollected
+	if amount < due {
+		c.Outcome = CollectionOutcomePartial
+	}
+	return c, nil
+}
+
+// PrintCollections writes collections to w as a table, one line per loan.
+func PrintCollections(w io.Writer, collections []Collection) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "LOAN\tCUSTOMER\tDUE\tCOLLECTED\tOUTCOME\tREASON")
+	for _, c := range collections {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%s\t%s\n", c.LoanID, c.AccountXID, c.Due, c.Collected, c.Outcome, c.Reason)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,15 @@
+package loan
+
+import "errors"
+
+var (
+	ErrLoanNotFound             = errors.New("loan not found")
+	ErrLoanNotApproved          = errors.New("loan is not waiting for disbursement")
+	ErrLoanNotActive            = errors.New("loan is not active")
+	ErrMissingRequiredParameter = errors.New("missing required parameter")
+	ErrInvalidPrincipal         = errors.New("invalid principal")
+	ErrInvalidTenor             = errors.New("invalid tenor")
+	ErrInvalidInterest          = errors.New("invalid interest rate")
+	ErrInvalidRepaymentAmount   = errors.New("invalid repayment amount")
+	ErrRepaymentExceedsBalance  = errors.New("repayment exceeds outstanding amount")
+)
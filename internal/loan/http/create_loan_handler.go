@@ -0,0 +1,58 @@
+package http
+
+import (
+	httphelper "julo/internal/http"
+	"julo/internal/loan"
+	"julo/internal/wallet"
+	"net/http"
+	"time"
+)
+
+// CreateLoanHandler is an operator endpoint that books a loan for the
+// account in customer_xid and disburses it straight away.
+func CreateLoanHandler(loans loan.Service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var response httphelper.Response
+
+		var req createLoanRequest
+		if err := httphelper.Decode(w, r, &req); err != nil {
+			httphelper.WriteRequestErrorJSON(w, err)
+			return
+		}
+
+		l, err := loans.CreateLoan(r.Context(), loan.CreateLoanParam{
+			AccountXID:                 req.CustomerXID,
+			Principal:                  req.Principal,
+			TenorMonths:                req.TenorMonths,
+			MonthlyInterestBasisPoints: req.MonthlyInterestBasisPoints,
+			LateFee:                    req.LateFee,
+		})
+		if err == nil {
+			l, err = loans.DisburseLoan(r.Context(), l.ID)
+		}
+		if err != nil {
+			switch err {
+			case loan.ErrMissingRequiredParameter, loan.ErrInvalidPrincipal, loan.ErrInvalidTenor, loan.ErrInvalidInterest,
+				wallet.ErrWalletNotFound, wallet.ErrWalletDisabled:
+				httphelper.WriteErrorJSON(w, http.StatusBadRequest, err)
+			default:
+				httphelper.WriteErrorJSON(w, http.StatusInternalServerError, err)
+			}
+			return
+		}
+
+		response.Status = "success"
+		response.Data = map[string]interface{}{
+			"loan": newLoanResponse(*l, time.Now()),
+		}
+		httphelper.WriteJSON(w, http.StatusCreated, response)
+	})
+}
+
+type createLoanRequest struct {
+	CustomerXID                string `json:"customer_xid" validate:"required"`
+	Principal                  int    `json:"principal" validate:"required"`
+	TenorMonths                int    `json:"tenor_months" validate:"required"`
+	MonthlyInterestBasisPoints int    `json:"monthly_interest_bps"`
+	LateFee                    int    `json:"late_fee"`
+}
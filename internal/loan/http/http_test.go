@@ -0,0 +1,244 @@
+package http_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"julo/internal/account"
+	"julo/internal/auth"
+	authhttp "julo/internal/auth/http"
+	httphelper "julo/internal/http"
+	"julo/internal/loan"
+	loanhttp "julo/internal/loan/http"
+	"julo/internal/wallet"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/google/uuid"
+)
+
+func TestLoans(t *testing.T) {
+	ctx := context.Background()
+	wallets := wallet.NewService(wallet.NewInMemoryRepository())
+	loans := loan.NewService(loan.NewInMemoryRepository(), wallets)
+	adminToken := uuid.NewString()
+
+	router := chi.NewRouter()
+	router.Mount("/api/v1", router.Group(func(r chi.Router) {
+		r.Mount("/loans", r.Group(func(r chi.Router) {
+			r.Use(authhttp.Middleware)
+			r.Get("/", loanhttp.ViewLoansHandler(loans).ServeHTTP)
+			r.Get("/{id}", loanhttp.ViewLoanHandler(loans).ServeHTTP)
+			r.Post("/{id}/repayments", loanhttp.RepayLoanHandler(loans).ServeHTTP)
+		}))
+		r.Mount("/admin", r.Group(func(r chi.Router) {
+			r.Use(authhttp.AdminMiddleware(adminToken))
+			r.Post("/loans", loanhttp.CreateLoanHandler(loans).ServeHTTP)
+			r.Get("/loans/collections/preview", loanhttp.PreviewCollectionsHandler(loans).ServeHTTP)
+		}))
+	}))
+	server := httptest.NewServer(router)
+	defer server.Close()
+	baseUrl := server.URL
+
+	borrower := uuid.NewString()
+	_, err := wallets.EnableWallet(ctx, wallet.EnableWalletParam{
+		OwnerXID: borrower,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := uuid.NewString()
+	err = auth.StoreSession(ctx, auth.Session{
+		Token:   token,
+		Account: account.Account{XID: borrower},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	form := url.Values{}
+	form.Set("customer_xid", borrower)
+	form.Set("principal", "300000")
+	form.Set("tenor_months", "3")
+	form.Set("monthly_interest_bps", "150")
+
+	t.Run("create loan with customer token, should fail", func(t *testing.T) {
+		req := buildAuthenticatedRequest(t, http.MethodPost, baseUrl+"/api/v1/admin/loans", token, bytes.NewBufferString(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		res, err := server.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.StatusCode != http.StatusUnauthorized {
+			t.Fatalf("expecting status %v, got %v", http.StatusUnauthorized, res.StatusCode)
+		}
+	})
+
+	t.Run("create loan, should disburse", func(t *testing.T) {
+		req := buildAuthenticatedRequest(t, http.MethodPost, baseUrl+"/api/v1/admin/loans", adminToken, bytes.NewBufferString(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		res, err := server.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.StatusCode != http.StatusCreated {
+			t.Fatalf("expecting status %v, got %v", http.StatusCreated, res.StatusCode)
+		}
+
+		l := decodeLoan(t, res)
+		id := l["id"].(string)
+		if l["status"] != string(loan.LoanStatusActive) || l["outstanding"] != float64(313500) {
+			t.Fatalf("unexpected loan %v", l)
+		}
+
+		t.Run("view loans, should list loan", func(t *testing.T) {
+			req := buildAuthenticatedRequest(t, http.MethodGet, baseUrl+"/api/v1/loans", token, nil)
+			res, err := server.Client().Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.StatusCode != http.StatusOK {
+				t.Fatalf("expecting status %v, got %v", http.StatusOK, res.StatusCode)
+			}
+
+			var response httphelper.Response
+			err = json.NewDecoder(res.Body).Decode(&response)
+			if err != nil {
+				t.Fatal(err)
+			}
+			data := response.Data.(map[string]interface{})
+			if len(data["loans"].([]interface{})) != 1 {
+				t.Fatalf("expecting 1 loan, got %v", data["loans"])
+			}
+		})
+
+		t.Run("repay loan, should reduce outstanding", func(t *testing.T) {
+			form := url.Values{}
+			form.Set("amount", "104500")
+			form.Set("reference_id", uuid.NewString())
+			req := buildAuthenticatedRequest(t, http.MethodPost, baseUrl+"/api/v1/loans/"+id+"/repayments", token, bytes.NewBufferString(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			res, err := server.Client().Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.StatusCode != http.StatusCreated {
+				t.Fatalf("expecting status %v, got %v", http.StatusCreated, res.StatusCode)
+			}
+
+			l := decodeLoan(t, res)
+			installments := l["installments"].([]interface{})
+			if l["outstanding"] != float64(209000) || installments[0].(map[string]interface{})["outstanding"] != float64(0) {
+				t.Fatalf("unexpected loan after repayment %v", l)
+			}
+		})
+
+		t.Run("preview collections, should not debit the wallet", func(t *testing.T) {
+			before, err := wallets.GetWalletByXID(ctx, borrower)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			at := url.QueryEscape(time.Now().AddDate(0, 2, 5).Format(time.RFC3339))
+			req := buildAuthenticatedRequest(t, http.MethodGet, baseUrl+"/api/v1/admin/loans/collections/preview?at="+at, adminToken, nil)
+			res, err := server.Client().Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.StatusCode != http.StatusOK {
+				t.Fatalf("expecting status %v, got %v", http.StatusOK, res.StatusCode)
+			}
+			var response struct {
+				Data struct {
+					Collections []struct {
+						LoanID    string `json:"loan_id"`
+						Collected int    `json:"collected"`
+						Outcome   string `json:"outcome"`
+					} `json:"collections"`
+				} `json:"data"`
+			}
+			if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+				t.Fatal(err)
+			}
+			collections := response.Data.Collections
+			if len(collections) != 1 || collections[0].LoanID != id || collections[0].Outcome != string(loan.CollectionOutcomeCollected) {
+				t.Fatalf("expecting the second installment to be collected, got %+v", collections)
+			}
+
+			req = buildAuthenticatedRequest(t, http.MethodGet, baseUrl+"/api/v1/admin/loans/collections/preview?format=text&at="+at, adminToken, nil)
+			res, err = server.Client().Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			body, _ := io.ReadAll(res.Body)
+			if !strings.Contains(string(body), id) {
+				t.Fatalf("expecting table listing loan %s, got %s", id, body)
+			}
+
+			after, err := wallets.GetWalletByXID(ctx, borrower)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if after.Balance != before.Balance {
+				t.Fatalf("expecting balance %d, got %d", before.Balance, after.Balance)
+			}
+			l, err := loans.GetLoan(ctx, loan.LoanParam{AccountXID: borrower, LoanID: id})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(l.Collections) != 0 {
+				t.Fatalf("expecting no collection recorded, got %d", len(l.Collections))
+			}
+		})
+
+		t.Run("view loan of another customer, should fail", func(t *testing.T) {
+			other := uuid.NewString()
+			err := auth.StoreSession(ctx, auth.Session{
+				Token:   other,
+				Account: account.Account{XID: uuid.NewString()},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req := buildAuthenticatedRequest(t, http.MethodGet, baseUrl+"/api/v1/loans/"+id, other, nil)
+			res, err := server.Client().Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.StatusCode != http.StatusNotFound {
+				t.Fatalf("expecting status %v, got %v", http.StatusNotFound, res.StatusCode)
+			}
+		})
+	})
+}
+
+func decodeLoan(t *testing.T, res *http.Response) map[string]interface{} {
+	var response httphelper.Response
+	err := json.NewDecoder(res.Body).Decode(&response)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := response.Data.(map[string]interface{})
+	return data["loan"].(map[string]interface{})
+}
+
+func buildAuthenticatedRequest(t *testing.T, method string, url string, token string, body io.Reader) *http.Request {
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Add("Authorization", fmt.Sprintf("Token %s", token))
+	return req
+}
@@ -0,0 +1,72 @@
+package http
+
+import (
+	httphelper "julo/internal/http"
+	"julo/internal/loan"
+	"net/http"
+	"time"
+)
+
+// PreviewCollectionsHandler is an operator endpoint telling what the daily
+// job would auto-debit, as of the RFC 3339 time in at or now, without
+// debiting anything. With format=text it answers with a plain table.
+func PreviewCollectionsHandler(loans loan.Service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var response httphelper.Response
+
+		var req previewCollectionsRequest
+		if err := httphelper.Decode(w, r, &req); err != nil {
+			httphelper.WriteRequestErrorJSON(w, err)
+			return
+		}
+		if req.At.IsZero() {
+			req.At = time.Now()
+		}
+
+		collections, err := loans.CollectDueInstallments(r.Context(), loan.CollectParam{
+			Now:    req.At,
+			DryRun: true,
+		})
+		if err != nil {
+			httphelper.WriteErrorJSON(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if req.Format == "text" {
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			loan.PrintCollections(w, collections)
+			return
+		}
+
+		data := make([]previewResponse, 0, len(collections))
+		for _, c := range collections {
+			data = append(data, previewResponse{
+				LoanID:  c.LoanID,
+				OwnedBy: c.AccountXID,
+				collectionResponse: collectionResponse{
+					Date:      c.Date,
+					Due:       c.Due,
+					Collected: c.Collected,
+					Outcome:   string(c.Outcome),
+					Reason:    c.Reason,
+				},
+			})
+		}
+		response.Status = "success"
+		response.Data = map[string]interface{}{
+			"collections": data,
+		}
+		httphelper.WriteJSON(w, http.StatusOK, response)
+	})
+}
+
+type previewResponse struct {
+	LoanID  string `json:"loan_id"`
+	OwnedBy string `json:"owned_by"`
+	collectionResponse
+}
+
+type previewCollectionsRequest struct {
+	At     time.Time `json:"at"`
+	Format string    `json:"format"`
+}
@@ -0,0 +1,59 @@
+package http
+
+import (
+	"julo/internal/auth"
+	httphelper "julo/internal/http"
+	"julo/internal/loan"
+	"julo/internal/wallet"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi"
+)
+
+func RepayLoanHandler(loans loan.Service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var response httphelper.Response
+		session := auth.SessionFromContext(r.Context())
+		if session == nil {
+			httphelper.WriteErrorJSON(w, http.StatusUnauthorized, auth.ErrSessionNotFound)
+			return
+		}
+
+		var req repayLoanRequest
+		if err := httphelper.Decode(w, r, &req); err != nil {
+			httphelper.WriteRequestErrorJSON(w, err)
+			return
+		}
+
+		l, err := loans.RepayLoan(r.Context(), loan.RepayLoanParam{
+			AccountXID:  session.Account.XID,
+			LoanID:      chi.URLParam(r, "id"),
+			ReferenceID: req.ReferenceID,
+			Amount:      req.Amount,
+		})
+		if err != nil {
+			switch err {
+			case loan.ErrLoanNotFound:
+				httphelper.WriteErrorJSON(w, http.StatusNotFound, err)
+			case loan.ErrMissingRequiredParameter, loan.ErrInvalidRepaymentAmount, loan.ErrRepaymentExceedsBalance, loan.ErrLoanNotActive,
+				wallet.ErrInsufficientBalance, wallet.ErrWalletDisabled, wallet.ErrDuplicateReference:
+				httphelper.WriteErrorJSON(w, http.StatusBadRequest, err)
+			default:
+				httphelper.WriteErrorJSON(w, http.StatusInternalServerError, err)
+			}
+			return
+		}
+
+		response.Status = "success"
+		response.Data = map[string]interface{}{
+			"loan": newLoanResponse(*l, time.Now()),
+		}
+		httphelper.WriteJSON(w, http.StatusCreated, response)
+	})
+}
+
+type repayLoanRequest struct {
+	ReferenceID string `json:"reference_id" validate:"required"`
+	Amount      int    `json:"amount" validate:"required"`
+}
@@ -0,0 +1,90 @@
+package http
+
+import (
+	"julo/internal/loan"
+	"time"
+)
+
+type installmentResponse struct {
+	Number      int       `json:"number"`
+	DueDate     time.Time `json:"due_date"`
+	Principal   int       `json:"principal"`
+	Interest    int       `json:"interest"`
+	LateFee     int       `json:"late_fee"`
+	Amount      int       `json:"amount"`
+	Paid        int       `json:"paid"`
+	Outstanding int       `json:"outstanding"`
+	Overdue     bool      `json:"overdue"`
+	PaidAt      time.Time `json:"paid_at"`
+}
+
+type collectionResponse struct {
+	Date          time.Time `json:"date"`
+	Due           int       `json:"due"`
+	Collected     int       `json:"collected"`
+	Outcome       string    `json:"outcome"`
+	TransactionID string    `json:"transaction_id,omitempty"`
+	Reason        string    `json:"reason,omitempty"`
+}
+
+type loanResponse struct {
+	ID                         string                `json:"id"`
+	OwnedBy                    string                `json:"owned_by"`
+	Principal                  int                   `json:"principal"`
+	TenorMonths                int                   `json:"tenor_months"`
+	MonthlyInterestBasisPoints int                   `json:"monthly_interest_bps"`
+	Status                     string                `json:"status"`
+	Outstanding                int                   `json:"outstanding"`
+	Overdue                    int                   `json:"overdue"`
+	LateFees                   int                   `json:"late_fees"`
+	DisbursementTransactionID  string                `json:"disbursement_transaction_id"`
+	DisbursedAt                time.Time             `json:"disbursed_at"`
+	Installments               []installmentResponse `json:"installments"`
+	Collections                []collectionResponse  `json:"collections"`
+}
+
+func newLoanResponse(l loan.Loan, now time.Time) loanResponse {
+	installments := make([]installmentResponse, 0, len(l.Installments))
+	for _, i := range l.Installments {
+		installments = append(installments, installmentResponse{
+			Number:      i.Number,
+			DueDate:     i.DueDate,
+			Principal:   i.Principal,
+			Interest:    i.Interest,
+			LateFee:     i.LateFee,
+			Amount:      i.Amount(),
+			Paid:        i.Paid,
+			Outstanding: i.Outstanding(),
+			Overdue:     i.IsOverdue(now),
+			PaidAt:      i.PaidAt,
+		})
+	}
+
+	collections := make([]collectionResponse, 0, len(l.Collections))
+	for _, c := range l.Collections {
+		collections = append(collections, collectionResponse{
+			Date:          c.Date,
+			Due:           c.Due,
+			Collected:     c.Collected,
+			Outcome:       string(c.Outcome),
+			TransactionID: c.TransactionID,
+			Reason:        c.Reason,
+		})
+	}
+
+	return loanResponse{
+		ID:                         l.ID,
+		OwnedBy:                    l.AccountXID,
+		Principal:                  l.Principal,
+		TenorMonths:                l.TenorMonths,
+		MonthlyInterestBasisPoints: l.MonthlyInterestBasisPoints,
+		Status:                     string(l.Status),
+		Outstanding:                l.Outstanding(),
+		Overdue:                    l.Overdue(now),
+		LateFees:                   l.LateFees(),
+		DisbursementTransactionID:  l.DisbursementTransactionID,
+		DisbursedAt:                l.DisbursedAt,
+		Installments:               installments,
+		Collections:                collections,
+	}
+}
@@ -0,0 +1,71 @@
+package http
+
+import (
+	"julo/internal/auth"
+	httphelper "julo/internal/http"
+	"julo/internal/loan"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi"
+)
+
+func ViewLoansHandler(loans loan.Service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var response httphelper.Response
+		session := auth.SessionFromContext(r.Context())
+		if session == nil {
+			httphelper.WriteErrorJSON(w, http.StatusUnauthorized, auth.ErrSessionNotFound)
+			return
+		}
+
+		result, err := loans.GetLoans(r.Context(), session.Account.XID)
+		if err != nil {
+			httphelper.WriteErrorJSON(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		now := time.Now()
+		data := make([]loanResponse, 0, len(result))
+		for _, l := range result {
+			data = append(data, newLoanResponse(l, now))
+		}
+
+		response.Status = "success"
+		response.Data = map[string]interface{}{
+			"loans": data,
+		}
+		httphelper.WriteJSON(w, http.StatusOK, response)
+	})
+}
+
+func ViewLoanHandler(loans loan.Service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var response httphelper.Response
+		session := auth.SessionFromContext(r.Context())
+		if session == nil {
+			httphelper.WriteErrorJSON(w, http.StatusUnauthorized, auth.ErrSessionNotFound)
+			return
+		}
+
+		l, err := loans.GetLoan(r.Context(), loan.LoanParam{
+			AccountXID: session.Account.XID,
+			LoanID:     chi.URLParam(r, "id"),
+		})
+		if err != nil {
+			switch err {
+			case loan.ErrLoanNotFound:
+				httphelper.WriteErrorJSON(w, http.StatusNotFound, err)
+			default:
+				httphelper.WriteErrorJSON(w, http.StatusInternalServerError, err)
+			}
+			return
+		}
+
+		response.Status = "success"
+		response.Data = map[string]interface{}{
+			"loan": newLoanResponse(*l, time.Now()),
+		}
+		httphelper.WriteJSON(w, http.StatusOK, response)
+	})
+}
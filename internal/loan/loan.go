@@ -0,0 +1,139 @@
+package loan
+
+import "time"
+
+type LoanStatus string
+
+var (
+	LoanStatusApproved = LoanStatus("approved")
+	LoanStatusActive   = LoanStatus("active")
+	LoanStatusPaidOff  = LoanStatus("paid_off")
+)
+
+type Installment struct {
+	Number    int
+	DueDate   time.Time
+	Principal int
+	Interest  int
+	LateFee   int
+	Paid      int
+	PaidAt    time.Time
+}
+
+func (i Installment) Amount() int {
+	return i.Principal + i.Interest + i.LateFee
+}
+
+func (i Installment) Outstanding() int {
+	return i.Amount() - i.Paid
+}
+
+func (i Installment) IsPaid() bool {
+	return i.Outstanding() <= 0
+}
+
+// IsOverdue reports whether the installment is still unpaid after its due
+// date has passed.
+func (i Installment) IsOverdue(now time.Time) bool {
+	return !i.IsPaid() && !now.Before(i.DueDate.AddDate(0, 0, 1))
+}
+
+type Repayment struct {
+	ID            string
+	ReferenceID   string
+	Amount        int
+	TransactionID string
+	PaidAt        time.Time
+}
+
+// Loan is an installment loan disbursed into the wallet of AccountXID. The
+// interest is flat: every month the borrower pays MonthlyInterestBasisPoints
+// of the principal on top of an equal share of it.
+type Loan struct {
+	ID                         string
+	AccountXID                 string
+	Principal                  int
+	TenorMonths                int
+	MonthlyInterestBasisPoints int
+	// LateFee is charged once on every installment left unpaid after its
+	// due date.
+	LateFee                   int
+	Status                    LoanStatus
+	Installments              []Installment
+	Repayments                []Repayment
+	Collections               []Collection
+	DisbursementTransactionID string
+	CreatedAt                 time.Time
+	DisbursedAt               time.Time
+}
+
+func (l Loan) Outstanding() int {
+	var total int
+	for _, i := range l.Installments {
+		total += i.Outstanding()
+	}
+	return total
+}
+
+func (l Loan) Overdue(now time.Time) int {
+	var total int
+	for _, i := range l.Installments {
+		if i.IsOverdue(now) {
+			total += i.Outstanding()
+		}
+	}
+	return total
+}
+
+// Due is the amount of the installments that have fallen due by now and are
+// not paid yet.
+func (l Loan) Due(now time.Time) int {
+	var total int
+	for _, i := range l.Installments {
+		if !now.Before(i.DueDate) {
+			total += i.Outstanding()
+		}
+	}
+	return total
+}
+
+func (l Loan) LateFees() int {
+	var total int
+	for _, i := range l.Installments {
+		total += i.LateFee
+	}
+	return total
+}
+
+// generateInstallments splits the loan into monthly installments, the first
+// one due a month after disbursedAt. Rounding leftovers of the principal go
+// to the last installment.
+func generateInstallments(l Loan, disbursedAt time.Time) []Installment {
+	installments := make([]Installment, l.TenorMonths)
+	share := l.Principal / l.TenorMonths
+	interest := l.Principal * l.MonthlyInterestBasisPoints / 10000
+	start := time.Date(disbursedAt.Year(), disbursedAt.Month(), disbursedAt.Day(), 0, 0, 0, 0, disbursedAt.Location())
+	for n := range installments {
+		installments[n] = Installment{
+			Number:    n + 1,
+			DueDate:   addMonths(start, n+1),
+			Principal: share,
+			Interest:  interest,
+		}
+	}
+	installments[len(installments)-1].Principal += l.Principal - share*l.TenorMonths
+	return installments
+}
+
+// addMonths adds months to t, keeping the day of month but clamping it to
+// the end of shorter months so a loan taken on the 31st falls due on the
+// last day of February rather than early March.
+func addMonths(t time.Time, months int) time.Time {
+	first := time.Date(t.Year(), t.Month()+time.Month(months), 1, 0, 0, 0, 0, t.Location())
+	lastDay := first.AddDate(0, 1, -1).Day()
+	day := t.Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return time.Date(first.Year(), first.Month(), day, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+}
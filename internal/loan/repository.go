@@ -0,0 +1,77 @@
+package loan
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+type Repository interface {
+	CreateLoan(ctx context.Context, l Loan) error
+	UpdateLoan(ctx context.Context, l Loan) error
+	GetLoan(ctx context.Context, id string) (*Loan, error)
+	GetLoansByAccount(ctx context.Context, accountXID string) ([]Loan, error)
+	GetActiveLoans(ctx context.Context) ([]Loan, error)
+}
+
+type InMemoryRepository struct {
+	store sync.Map
+}
+
+func NewInMemoryRepository() Repository {
+	return &InMemoryRepository{}
+}
+
+func (r *InMemoryRepository) CreateLoan(ctx context.Context, l Loan) error {
+	r.store.Store(l.ID, copyLoan(l))
+	return nil
+}
+
+func (r *InMemoryRepository) UpdateLoan(ctx context.Context, l Loan) error {
+	r.store.Store(l.ID, copyLoan(l))
+	return nil
+}
+
+func (r *InMemoryRepository) GetLoan(ctx context.Context, id string) (*Loan, error) {
+	v, ok := r.store.Load(id)
+	if !ok {
+		return nil, ErrLoanNotFound
+	}
+	return copyLoan(*v.(*Loan)), nil
+}
+
+func (r *InMemoryRepository) GetLoansByAccount(ctx context.Context, accountXID string) ([]Loan, error) {
+	return r.filter(func(l *Loan) bool {
+		return l.AccountXID == accountXID
+	}), nil
+}
+
+func (r *InMemoryRepository) GetActiveLoans(ctx context.Context) ([]Loan, error) {
+	return r.filter(func(l *Loan) bool {
+		return l.Status == LoanStatusActive
+	}), nil
+}
+
+func (r *InMemoryRepository) filter(match func(*Loan) bool) []Loan {
+	loans := []Loan{}
+	r.store.Range(func(key, value any) bool {
+		l := value.(*Loan)
+		if match(l) {
+			loans = append(loans, *copyLoan(*l))
+		}
+		return true
+	})
+	sort.Slice(loans, func(i, j int) bool {
+		return loans[i].CreatedAt.Before(loans[j].CreatedAt)
+	})
+	return loans
+}
+
+// copyLoan detaches the installments, repayments and collections of l so the
+// stored loan is not changed through the slices of a loan handed out.
+func copyLoan(l Loan) *Loan {
+	l.Installments = append([]Installment(nil), l.Installments...)
+	l.Repayments = append([]Repayment(nil), l.Repayments...)
+	l.Collections = append([]Collection(nil), l.Collections...)
+	return &l
+}
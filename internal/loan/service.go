@@ -0,0 +1,305 @@
+package loan
+
+import (
+	"context"
+	"julo/internal/wallet"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+type CreateLoanParam struct {
+	AccountXID                 string
+	Principal                  int
+	TenorMonths                int
+	MonthlyInterestBasisPoints int
+	LateFee                    int
+}
+
+func (p CreateLoanParam) Validate() error {
+	if p.AccountXID == "" {
+		return ErrMissingRequiredParameter
+	}
+	if p.Principal <= 0 {
+		return ErrInvalidPrincipal
+	}
+	if p.TenorMonths <= 0 || p.TenorMonths > p.Principal {
+		return ErrInvalidTenor
+	}
+	if p.MonthlyInterestBasisPoints < 0 || p.LateFee < 0 {
+		return ErrInvalidInterest
+	}
+	return nil
+}
+
+type LoanParam struct {
+	AccountXID string
+	LoanID     string
+}
+
+type RepayLoanParam struct {
+	AccountXID  string
+	LoanID      string
+	ReferenceID string
+	Amount      int
+}
+
+type Service interface {
+	CreateLoan(ctx context.Context, param CreateLoanParam) (*Loan, error)
+	DisburseLoan(ctx context.Context, loanID string) (*Loan, error)
+	GetLoans(ctx context.Context, accountXID string) ([]Loan, error)
+	GetLoan(ctx context.Context, param LoanParam) (*Loan, error)
+	RepayLoan(ctx context.Context, param RepayLoanParam) (*Loan, error)
+	AssessLateFees(ctx context.Context, now time.Time) error
+	CollectDueInstallments(ctx context.Context, param CollectParam) ([]Collection, error)
+}
+
+type service struct {
+	repo    Repository
+	wallets wallet.Service
+	mu      sync.Mutex
+}
+
+func NewService(repo Repository, wallets wallet.Service) Service {
+	return &service{
+		repo:    repo,
+		wallets: wallets,
+	}
+}
+
+func (s *service) CreateLoan(ctx context.Context, param CreateLoanParam) (*Loan, error) {
+	err := param.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.wallets.GetWalletByXID(ctx, param.AccountXID)
+	if err != nil && err == wallet.ErrWalletNotFound {
+		return nil, wallet.ErrWalletNotFound
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed getting wallet")
+	}
+
+	l := Loan{
+		ID:                         uuid.NewString(),
+		AccountXID:                 param.AccountXID,
+		Principal:                  param.Principal,
+		TenorMonths:                param.TenorMonths,
+		MonthlyInterestBasisPoints: param.MonthlyInterestBasisPoints,
+		LateFee:                    param.LateFee,
+		Status:                     LoanStatusApproved,
+		CreatedAt:                  time.Now(),
+	}
+	err = s.repo.CreateLoan(ctx, l)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed creating loan")
+	}
+
+	return &l, nil
+}
+
+// DisburseLoan pays the principal into the borrower's wallet and starts the
+// installment schedule from the day of disbursement.
+func (s *service) DisburseLoan(ctx context.Context, loanID string) (*Loan, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	l, err := s.getLoan(ctx, loanID)
+	if err != nil {
+		return nil, err
+	}
+	if l.Status != LoanStatusApproved {
+		return nil, ErrLoanNotApproved
+	}
+
+	result, err := s.wallets.DepositWallet(ctx, wallet.WalletTransactionParam{
+		ActorXID:    "loan:" + l.ID,
+		OwnerXID:    l.AccountXID,
+		ReferenceID: wallet.SystemReferencePrefix + "loan-" + l.ID + "-disbursement",
+		Amount:      l.Principal,
+		Type:        wallet.TransactionTypeLoanDisbursement,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	l.Status = LoanStatusActive
+	l.DisbursedAt = result.DepositedAt
+	l.DisbursementTransactionID = result.ID
+	l.Installments = generateInstallments(*l, l.DisbursedAt)
+	err = s.repo.UpdateLoan(ctx, *l)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed updating loan")
+	}
+
+	return l, nil
+}
+
+func (s *service) GetLoans(ctx context.Context, accountXID string) ([]Loan, error) {
+	loans, err := s.repo.GetLoansByAccount(ctx, accountXID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed getting loans")
+	}
+	return loans, nil
+}
+
+func (s *service) GetLoan(ctx context.Context, param LoanParam) (*Loan, error) {
+	l, err := s.getLoan(ctx, param.LoanID)
+	if err != nil {
+		return nil, err
+	}
+	if l.AccountXID != param.AccountXID {
+		return nil, ErrLoanNotFound
+	}
+	return l, nil
+}
+
+// RepayLoan debits the repayment from the borrower's wallet and applies it to
+// the installments in due order. Repeating a repayment with the same
+// reference does not debit the wallet again.
+func (s *service) RepayLoan(ctx context.Context, param RepayLoanParam) (*Loan, error) {
+	if param.ReferenceID == "" {
+		return nil, ErrMissingRequiredParameter
+	}
+	if param.Amount <= 0 {
+		return nil, ErrInvalidRepaymentAmount
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	l, err := s.getLoan(ctx, param.LoanID)
+	if err != nil {
+		return nil, err
+	}
+	if l.AccountXID != param.AccountXID {
+		return nil, ErrLoanNotFound
+	}
+	for _, r := range l.Repayments {
+		if r.ReferenceID == param.ReferenceID {
+			return l, nil
+		}
+	}
+	if l.Status != LoanStatusActive {
+		return nil, ErrLoanNotActive
+	}
+	if param.Amount > l.Outstanding() {
+		return nil, ErrRepaymentExceedsBalance
+	}
+
+	// the reference is the customer's own, it only identifies the debit
+	// within this loan
+	_, err = s.repay(ctx, l, param.AccountXID, param.ReferenceID, wallet.SystemReferencePrefix+"loan-"+l.ID+"-repayment-"+param.ReferenceID, param.Amount)
+	if err != nil {
+		return nil, err
+	}
+	err = s.repo.UpdateLoan(ctx, *l)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed updating loan")
+	}
+
+	return l, nil
+}
+
+// AssessLateFees charges the late fee of each loan on the installments that
+// became overdue. Every installment is charged at most once.
+func (s *service) AssessLateFees(ctx context.Context, now time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	loans, err := s.repo.GetActiveLoans(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed getting active loans")
+	}
+
+	for _, l := range loans {
+		if l.LateFee == 0 {
+			continue
+		}
+
+		var charged bool
+		for i := range l.Installments {
+			if l.Installments[i].IsOverdue(now) && l.Installments[i].LateFee == 0 {
+				l.Installments[i].LateFee = l.LateFee
+				charged = true
+			}
+		}
+		if !charged {
+			continue
+		}
+
+		err = s.repo.UpdateLoan(ctx, l)
+		if err != nil {
+			return errors.Wrap(err, "failed updating loan")
+		}
+	}
+	return nil
+}
+
+// repay debits amount from the borrower's wallet under walletReferenceID and
+// applies it to l as the repayment referenceID, leaving it to the caller to
+// store the loan.
+func (s *service) repay(ctx context.Context, l *Loan, actorXID string, referenceID string, walletReferenceID string, amount int) (*Repayment, error) {
+	result, err := s.wallets.WithdrawWallet(ctx, wallet.WalletTransactionParam{
+		ActorXID:    actorXID,
+		OwnerXID:    l.AccountXID,
+		ReferenceID: walletReferenceID,
+		Amount:      amount,
+		Type:        wallet.TransactionTypeLoanRepayment,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	r := Repayment{
+		ID:            uuid.NewString(),
+		ReferenceID:   referenceID,
+		Amount:        result.Amount,
+		TransactionID: result.ID,
+		PaidAt:        result.DepositedAt,
+	}
+	applyRepayment(l, r)
+	return &r, nil
+}
+
+func (s *service) getLoan(ctx context.Context, id string) (*Loan, error) {
+	l, err := s.repo.GetLoan(ctx, id)
+	if err != nil && err == ErrLoanNotFound {
+		return nil, ErrLoanNotFound
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed getting loan")
+	}
+	return l, nil
+}
+
+// applyRepayment spreads r over the unpaid installments, oldest first, and
+// closes the loan once nothing is left outstanding.
+func applyRepayment(l *Loan, r Repayment) {
+	remaining := r.Amount
+	for i := range l.Installments {
+		if remaining == 0 {
+			break
+		}
+		outstanding := l.Installments[i].Outstanding()
+		if outstanding <= 0 {
+			continue
+		}
+
+		paid := outstanding
+		if remaining < outstanding {
+			paid = remaining
+		}
+		l.Installments[i].Paid += paid
+		remaining -= paid
+		if l.Installments[i].IsPaid() {
+			l.Installments[i].PaidAt = r.PaidAt
+		}
+	}
+
+	l.Repayments = append(l.Repayments, r)
+	if l.Outstanding() <= 0 {
+		l.Status = LoanStatusPaidOff
+	}
+}
@@ -0,0 +1,397 @@
+package loan_test
+
+import (
+	"context"
+	"julo/internal/loan"
+	"julo/internal/wallet"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestLoanLifecycle(t *testing.T) {
+	ctx := context.Background()
+	wallets := wallet.NewService(wallet.NewInMemoryRepository())
+	loans := loan.NewService(loan.NewInMemoryRepository(), wallets)
+
+	borrower := uuid.NewString()
+	_, err := wallets.EnableWallet(ctx, wallet.EnableWalletParam{
+		OwnerXID: borrower,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := loans.CreateLoan(ctx, loan.CreateLoanParam{
+		AccountXID:                 borrower,
+		Principal:                  100000,
+		TenorMonths:                3,
+		MonthlyInterestBasisPoints: 200,
+		LateFee:                    5000,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.Status != loan.LoanStatusApproved {
+		t.Fatalf("expecting status %s, got %s", loan.LoanStatusApproved, l.Status)
+	}
+
+	l, err = loans.DisburseLoan(ctx, l.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(l.Installments) != 3 {
+		t.Fatalf("expecting 3 installments, got %d", len(l.Installments))
+	}
+	// 100000 over 3 months leaves 1 for the last installment, plus 2000
+	// interest each month
+	if l.Installments[0].Amount() != 35333 || l.Installments[2].Amount() != 35334 {
+		t.Fatalf("unexpected installments %+v", l.Installments)
+	}
+	if l.Outstanding() != 106000 {
+		t.Fatalf("expecting outstanding 106000, got %d", l.Outstanding())
+	}
+	if _, err = loans.DisburseLoan(ctx, l.ID); err != loan.ErrLoanNotApproved {
+		t.Fatalf("expecting error %s, got %v", loan.ErrLoanNotApproved, err)
+	}
+
+	w, err := wallets.GetWalletByXID(ctx, borrower)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w.Balance != 100000 {
+		t.Fatalf("expecting balance 100000, got %d", w.Balance)
+	}
+	transactions, err := wallets.GetWalletTransactions(ctx, wallet.GetWalletTransactionsParam{
+		WalletID: w.ID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(transactions.Transactions) != 1 || transactions.Transactions[0].Type != wallet.TransactionTypeLoanDisbursement {
+		t.Fatalf("expecting a loan disbursement transaction, got %+v", transactions.Transactions)
+	}
+
+	// the first installment is overdue two days after its due date
+	now := l.Installments[0].DueDate.AddDate(0, 0, 2)
+	if err = loans.AssessLateFees(ctx, now); err != nil {
+		t.Fatal(err)
+	}
+	if err = loans.AssessLateFees(ctx, now); err != nil {
+		t.Fatal(err)
+	}
+	l, err = loans.GetLoan(ctx, loan.LoanParam{AccountXID: borrower, LoanID: l.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.LateFees() != 5000 || l.Overdue(now) != 40333 {
+		t.Fatalf("expecting one late fee and 40333 overdue, got %d and %d", l.LateFees(), l.Overdue(now))
+	}
+
+	reference := uuid.NewString()
+	for i := 0; i < 2; i++ {
+		l, err = loans.RepayLoan(ctx, loan.RepayLoanParam{
+			AccountXID:  borrower,
+			LoanID:      l.ID,
+			ReferenceID: reference,
+			Amount:      50000,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !l.Installments[0].IsPaid() || l.Installments[1].Paid != 9667 {
+		t.Fatalf("expecting the repayment to cover the oldest installment first, got %+v", l.Installments)
+	}
+	if l.Outstanding() != 61000 {
+		t.Fatalf("expecting outstanding 61000, got %d", l.Outstanding())
+	}
+
+	_, err = loans.RepayLoan(ctx, loan.RepayLoanParam{
+		AccountXID:  borrower,
+		LoanID:      l.ID,
+		ReferenceID: uuid.NewString(),
+		Amount:      70000,
+	})
+	if err != loan.ErrRepaymentExceedsBalance {
+		t.Fatalf("expecting error %s, got %v", loan.ErrRepaymentExceedsBalance, err)
+	}
+
+	_, err = loans.RepayLoan(ctx, loan.RepayLoanParam{
+		AccountXID:  borrower,
+		LoanID:      l.ID,
+		ReferenceID: uuid.NewString(),
+		Amount:      61000,
+	})
+	if err != wallet.ErrInsufficientBalance {
+		t.Fatalf("expecting error %s, got %v", wallet.ErrInsufficientBalance, err)
+	}
+
+	_, err = wallets.DepositWallet(ctx, wallet.WalletTransactionParam{
+		ActorXID:    borrower,
+		OwnerXID:    borrower,
+		ReferenceID: uuid.NewString(),
+		Amount:      11000,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err = loans.RepayLoan(ctx, loan.RepayLoanParam{
+		AccountXID:  borrower,
+		LoanID:      l.ID,
+		ReferenceID: uuid.NewString(),
+		Amount:      61000,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.Status != loan.LoanStatusPaidOff {
+		t.Fatalf("expecting status %s, got %s", loan.LoanStatusPaidOff, l.Status)
+	}
+}
+
+func TestRepayLoansWithSameReference(t *testing.T) {
+	ctx := context.Background()
+	wallets := wallet.NewService(wallet.NewInMemoryRepository())
+	loans := loan.NewService(loan.NewInMemoryRepository(), wallets)
+
+	borrower := uuid.NewString()
+	_, err := wallets.EnableWallet(ctx, wallet.EnableWalletParam{OwnerXID: borrower})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for i := 0; i < 2; i++ {
+		l, err := loans.CreateLoan(ctx, loan.CreateLoanParam{
+			AccountXID:  borrower,
+			Principal:   50000,
+			TenorMonths: 1,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = loans.DisburseLoan(ctx, l.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, l.ID)
+	}
+
+	referenceID := uuid.NewString()
+	for _, id := range ids {
+		l, err := loans.RepayLoan(ctx, loan.RepayLoanParam{
+			AccountXID:  borrower,
+			LoanID:      id,
+			ReferenceID: referenceID,
+			Amount:      50000,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if l.Status != loan.LoanStatusPaidOff {
+			t.Fatalf("expecting status %s, got %s", loan.LoanStatusPaidOff, l.Status)
+		}
+	}
+
+	w, err := wallets.GetWalletByXID(ctx, borrower)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w.Balance != 0 {
+		t.Fatalf("expecting both loans debited to balance 0, got %d", w.Balance)
+	}
+
+	// a retry of the second repayment does not debit again
+	_, err = loans.RepayLoan(ctx, loan.RepayLoanParam{
+		AccountXID:  borrower,
+		LoanID:      ids[1],
+		ReferenceID: referenceID,
+		Amount:      50000,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err = wallets.GetWalletByXID(ctx, borrower)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w.Balance != 0 {
+		t.Fatalf("expecting balance 0, got %d", w.Balance)
+	}
+}
+
+func TestGetLoanOfAnotherAccount(t *testing.T) {
+	ctx := context.Background()
+	wallets := wallet.NewService(wallet.NewInMemoryRepository())
+	loans := loan.NewService(loan.NewInMemoryRepository(), wallets)
+
+	borrower := uuid.NewString()
+	_, err := wallets.EnableWallet(ctx, wallet.EnableWalletParam{
+		OwnerXID: borrower,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err := loans.CreateLoan(ctx, loan.CreateLoanParam{
+		AccountXID:  borrower,
+		Principal:   100000,
+		TenorMonths: 1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = loans.GetLoan(ctx, loan.LoanParam{AccountXID: uuid.NewString(), LoanID: l.ID})
+	if err != loan.ErrLoanNotFound {
+		t.Fatalf("expecting error %s, got %v", loan.ErrLoanNotFound, err)
+	}
+}
+
+func TestInstallmentDueDates(t *testing.T) {
+	ctx := context.Background()
+	wallets := wallet.NewService(wallet.NewInMemoryRepository())
+	loans := loan.NewService(loan.NewInMemoryRepository(), wallets)
+
+	borrower := uuid.NewString()
+	_, err := wallets.EnableWallet(ctx, wallet.EnableWalletParam{
+		OwnerXID: borrower,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err := loans.CreateLoan(ctx, loan.CreateLoanParam{
+		AccountXID:  borrower,
+		Principal:   120000,
+		TenorMonths: 12,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err = loans.DisburseLoan(ctx, l.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, installment := range l.Installments {
+		month := l.DisbursedAt.AddDate(0, 0, 1-l.DisbursedAt.Day()).AddDate(0, i+1, 0).Month()
+		if installment.DueDate.Month() != month {
+			t.Fatalf("expecting installment %d due in %s, got %s", installment.Number, month, installment.DueDate)
+		}
+	}
+}
+
+func TestCollectDueInstallments(t *testing.T) {
+	ctx := context.Background()
+	wallets := wallet.NewService(wallet.NewInMemoryRepository())
+	loans := loan.NewService(loan.NewInMemoryRepository(), wallets)
+
+	borrower := uuid.NewString()
+	_, err := wallets.EnableWallet(ctx, wallet.EnableWalletParam{
+		OwnerXID: borrower,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err := loans.CreateLoan(ctx, loan.CreateLoanParam{
+		AccountXID:                 borrower,
+		Principal:                  100000,
+		TenorMonths:                3,
+		MonthlyInterestBasisPoints: 200,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err = loans.DisburseLoan(ctx, l.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the borrower spends the loan and keeps 20000 in the wallet
+	_, err = wallets.WithdrawWallet(ctx, wallet.WalletTransactionParam{
+		ActorXID:    borrower,
+		OwnerXID:    borrower,
+		ReferenceID: uuid.NewString(),
+		Amount:      80000,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dueDate := l.Installments[0].DueDate
+	collections, err := loans.CollectDueInstallments(ctx, loan.CollectParam{Now: dueDate.Add(-time.Hour)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(collections) != 0 {
+		t.Fatalf("expecting nothing due before the due date, got %+v", collections)
+	}
+
+	collections, err = loans.CollectDueInstallments(ctx, loan.CollectParam{Now: dueDate, DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(collections) != 1 || collections[0].Collected != 20000 || collections[0].Outcome != loan.CollectionOutcomePartial {
+		t.Fatalf("expecting a partial collection of 20000, got %+v", collections)
+	}
+	w, err := wallets.GetWalletByXID(ctx, borrower)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w.Balance != 20000 {
+		t.Fatalf("expecting dry run to keep balance 20000, got %d", w.Balance)
+	}
+
+	for _, day := range []int{0, 1} {
+		collections, err = loans.CollectDueInstallments(ctx, loan.CollectParam{Now: dueDate.AddDate(0, 0, day)})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(collections) != 1 || collections[0].Outcome != loan.CollectionOutcomeFailed || collections[0].Reason != wallet.ErrInsufficientBalance.Error() {
+		t.Fatalf("expecting a failed collection on the empty wallet, got %+v", collections)
+	}
+
+	_, err = wallets.DepositWallet(ctx, wallet.WalletTransactionParam{
+		ActorXID:    borrower,
+		OwnerXID:    borrower,
+		ReferenceID: uuid.NewString(),
+		Amount:      50000,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	collections, err = loans.CollectDueInstallments(ctx, loan.CollectParam{Now: dueDate.AddDate(0, 0, 2)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(collections) != 1 || collections[0].Collected != 15333 || collections[0].Outcome != loan.CollectionOutcomeCollected {
+		t.Fatalf("expecting the rest of the installment to be collected, got %+v", collections)
+	}
+
+	l, err = loans.GetLoan(ctx, loan.LoanParam{AccountXID: borrower, LoanID: l.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !l.Installments[0].IsPaid() || l.Installments[1].Paid != 0 {
+		t.Fatalf("expecting only the first installment paid, got %+v", l.Installments)
+	}
+	if len(l.Collections) != 3 || len(l.Repayments) != 2 {
+		t.Fatalf("expecting 3 collections and 2 repayments, got %d and %d", len(l.Collections), len(l.Repayments))
+	}
+
+	_, err = wallets.FreezeWallet(ctx, wallet.FreezeWalletParam{
+		OwnerXID: borrower,
+		ActorXID: "operator",
+		Reason:   wallet.FreezeReasonFraud,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	collections, err = loans.CollectDueInstallments(ctx, loan.CollectParam{Now: l.Installments[1].DueDate})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(collections) != 1 || collections[0].Outcome != loan.CollectionOutcomeFailed || collections[0].Reason != wallet.ErrWalletFrozen.Error() {
+		t.Fatalf("expecting a failed collection on the frozen wallet, got %+v", collections)
+	}
+}
@@ -0,0 +1,54 @@
+package loan
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Worker runs the daily housekeeping of loans: collecting due installments
+// from the wallets and charging late fees on what is left overdue.
+type Worker struct {
+	loans    Service
+	interval time.Duration
+}
+
+// NewWorker creates a worker running every interval.
+func NewWorker(loans Service, interval time.Duration) *Worker {
+	return &Worker{
+		loans:    loans,
+		interval: interval,
+	}
+}
+
+// Run blocks, running once straight away and then every interval until ctx
+// is done.
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	w.run(ctx, time.Now())
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			w.run(ctx, now)
+		}
+	}
+}
+
+func (w *Worker) run(ctx context.Context, now time.Time) {
+	collections, err := w.loans.CollectDueInstallments(ctx, CollectParam{Now: now})
+	if err != nil {
+		log.Println(err)
+	}
+	for _, c := range collections {
+		if c.Outcome != CollectionOutcomeCollected {
+			log.Printf("collected %d of %d due on loan %s: %s %s", c.Collected, c.Due, c.LoanID, c.Outcome, c.Reason)
+		}
+	}
+	if err := w.loans.AssessLateFees(ctx, now); err != nil {
+		log.Println(err)
+	}
+}
@@ -0,0 +1,23 @@
+package merchant
+
+import "context"
+
+type key string
+
+const (
+	merchantKey = key("merchant-key")
+)
+
+func MerchantIntoContext(ctx context.Context, m *Merchant) context.Context {
+	return context.WithValue(ctx, merchantKey, m)
+}
+
+func MerchantFromContext(ctx context.Context) *Merchant {
+	v := ctx.Value(merchantKey)
+	m, ok := v.(*Merchant)
+	if !ok {
+		return nil
+	}
+
+	return m
+}
@@ -0,0 +1,16 @@
+package merchant
+
+import "errors"
+
+var (
+	ErrMerchantNotFound         = errors.New("merchant not found")
+	ErrOrderNotFound            = errors.New("order not found")
+	ErrMissingRequiredParameter = errors.New("missing required parameter")
+	ErrInvalidAmount            = errors.New("invalid amount")
+	ErrInvalidCallbackURL       = errors.New("invalid callback url")
+	ErrInvalidExpiry            = errors.New("expiry must be in the future")
+	ErrDuplicateOrder           = errors.New("order reference already used")
+	ErrOrderNotPayable          = errors.New("order is not awaiting payment")
+	ErrOrderNotRefundable       = errors.New("order is not paid")
+	ErrRefundExceedsPayment     = errors.New("refund exceeds the amount paid")
+)
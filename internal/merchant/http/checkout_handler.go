@@ -0,0 +1,76 @@
+package http
+
+import (
+	"julo/internal/auth"
+	httphelper "julo/internal/http"
+	"julo/internal/merchant"
+	"julo/internal/wallet"
+	"net/http"
+
+	"github.com/go-chi/chi"
+)
+
+// ViewCheckoutHandler shows the customer the order identified by the "id"
+// url parameter before they pay it.
+func ViewCheckoutHandler(merchants merchant.Service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var response httphelper.Response
+		session := auth.SessionFromContext(r.Context())
+		if session == nil {
+			httphelper.WriteErrorJSON(w, http.StatusUnauthorized, auth.ErrSessionNotFound)
+			return
+		}
+
+		o, err := merchants.GetCheckout(r.Context(), chi.URLParam(r, "id"))
+		if err != nil {
+			switch err {
+			case merchant.ErrOrderNotFound:
+				httphelper.WriteErrorJSON(w, http.StatusNotFound, err)
+			default:
+				httphelper.WriteErrorJSON(w, http.StatusInternalServerError, err)
+			}
+			return
+		}
+
+		response.Status = "success"
+		response.Data = map[string]interface{}{
+			"order": newOrderResponse(*o),
+		}
+		httphelper.WriteJSON(w, http.StatusOK, response)
+	})
+}
+
+// PayCheckoutHandler pays the order identified by the "id" url parameter
+// from the wallet of the authenticated customer.
+func PayCheckoutHandler(merchants merchant.Service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var response httphelper.Response
+		session := auth.SessionFromContext(r.Context())
+		if session == nil {
+			httphelper.WriteErrorJSON(w, http.StatusUnauthorized, auth.ErrSessionNotFound)
+			return
+		}
+
+		o, err := merchants.PayOrder(r.Context(), merchant.PayOrderParam{
+			CustomerXID: session.Account.XID,
+			OrderID:     chi.URLParam(r, "id"),
+		})
+		if err != nil {
+			switch err {
+			case merchant.ErrOrderNotFound:
+				httphelper.WriteErrorJSON(w, http.StatusNotFound, err)
+			case merchant.ErrOrderNotPayable, wallet.ErrInsufficientBalance, wallet.ErrWalletDisabled, wallet.ErrWalletNotFound:
+				httphelper.WriteErrorJSON(w, http.StatusBadRequest, err)
+			default:
+				httphelper.WriteErrorJSON(w, http.StatusInternalServerError, err)
+			}
+			return
+		}
+
+		response.Status = "success"
+		response.Data = map[string]interface{}{
+			"order": newOrderResponse(*o),
+		}
+		httphelper.WriteJSON(w, http.StatusOK, response)
+	})
+}
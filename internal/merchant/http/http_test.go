@@ -0,0 +1,179 @@
+package http_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"julo/internal/account"
+	"julo/internal/auth"
+	authhttp "julo/internal/auth/http"
+	httphelper "julo/internal/http"
+	"julo/internal/merchant"
+	merchanthttp "julo/internal/merchant/http"
+	"julo/internal/wallet"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/google/uuid"
+)
+
+type nopNotifier struct{}
+
+func (nopNotifier) NotifyOrder(ctx context.Context, m merchant.Merchant, o merchant.Order) {}
+
+func TestCheckout(t *testing.T) {
+	ctx := context.Background()
+	wallets := wallet.NewService(wallet.NewInMemoryRepository())
+	merchants := merchant.NewService(merchant.NewInMemoryRepository(), wallets, nopNotifier{})
+	adminToken := uuid.NewString()
+
+	router := chi.NewRouter()
+	router.Mount("/api/v1", router.Group(func(r chi.Router) {
+		r.Mount("/wallet", r.Group(func(r chi.Router) {
+			r.Use(authhttp.Middleware)
+			r.Get("/checkout/{id}", merchanthttp.ViewCheckoutHandler(merchants).ServeHTTP)
+			r.Post("/checkout/{id}/pay", merchanthttp.PayCheckoutHandler(merchants).ServeHTTP)
+		}))
+		r.Mount("/merchant", r.Group(func(r chi.Router) {
+			r.Use(merchanthttp.Middleware(merchants))
+			r.Post("/orders", merchanthttp.CreateOrderHandler(merchants).ServeHTTP)
+			r.Get("/orders/{id}", merchanthttp.ViewOrderHandler(merchants).ServeHTTP)
+			r.Post("/orders/{id}/refunds", merchanthttp.RefundOrderHandler(merchants).ServeHTTP)
+			r.Get("/settlements", merchanthttp.ViewSettlementHandler(merchants).ServeHTTP)
+		}))
+		r.Mount("/admin", r.Group(func(r chi.Router) {
+			r.Use(authhttp.AdminMiddleware(adminToken))
+			r.Post("/merchants", merchanthttp.RegisterMerchantHandler(merchants).ServeHTTP)
+		}))
+	}))
+	server := httptest.NewServer(router)
+	defer server.Close()
+	baseUrl := server.URL
+
+	customer, customerToken := uuid.NewString(), uuid.NewString()
+	_, err := wallets.EnableWallet(ctx, wallet.EnableWalletParam{OwnerXID: customer})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = wallets.DepositWallet(ctx, wallet.WalletTransactionParam{
+		ActorXID:    customer,
+		OwnerXID:    customer,
+		ReferenceID: uuid.NewString(),
+		Amount:      50000,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = auth.StoreSession(ctx, auth.Session{
+		Token:   customerToken,
+		Account: account.Account{XID: customer},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	form := url.Values{}
+	form.Set("name", "coffee shop")
+	res := do(t, server, http.MethodPost, baseUrl+"/api/v1/admin/merchants", adminToken, form)
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("expecting status %v, got %v", http.StatusCreated, res.StatusCode)
+	}
+	apiKey := decode(t, res, "merchant")["api_key"].(string)
+
+	t.Run("create order with customer token, should fail", func(t *testing.T) {
+		form := url.Values{}
+		form.Set("reference", "INV-0")
+		form.Set("amount", "1000")
+		res := do(t, server, http.MethodPost, baseUrl+"/api/v1/merchant/orders", customerToken, form)
+		if res.StatusCode != http.StatusUnauthorized {
+			t.Fatalf("expecting status %v, got %v", http.StatusUnauthorized, res.StatusCode)
+		}
+	})
+
+	form = url.Values{}
+	form.Set("reference", "INV-1")
+	form.Set("amount", "30000")
+	res = do(t, server, http.MethodPost, baseUrl+"/api/v1/merchant/orders", apiKey, form)
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("expecting status %v, got %v", http.StatusCreated, res.StatusCode)
+	}
+	id := decode(t, res, "order")["id"].(string)
+
+	t.Run("pay order, should move money to the merchant", func(t *testing.T) {
+		res := do(t, server, http.MethodPost, baseUrl+"/api/v1/wallet/checkout/"+id+"/pay", customerToken, nil)
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("expecting status %v, got %v", http.StatusOK, res.StatusCode)
+		}
+
+		res = do(t, server, http.MethodGet, baseUrl+"/api/v1/merchant/orders/"+id, apiKey, nil)
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("expecting status %v, got %v", http.StatusOK, res.StatusCode)
+		}
+		o := decode(t, res, "order")
+		if o["status"] != string(merchant.OrderStatusPaid) || o["paid_by"] != customer {
+			t.Fatalf("expecting order paid by %s, got %v", customer, o)
+		}
+	})
+
+	t.Run("pay order twice, should fail", func(t *testing.T) {
+		res := do(t, server, http.MethodPost, baseUrl+"/api/v1/wallet/checkout/"+id+"/pay", customerToken, nil)
+		if res.StatusCode != http.StatusBadRequest {
+			t.Fatalf("expecting status %v, got %v", http.StatusBadRequest, res.StatusCode)
+		}
+	})
+
+	t.Run("refund order, should show in the settlement", func(t *testing.T) {
+		form := url.Values{}
+		form.Set("reference_id", "r1")
+		form.Set("amount", "5000")
+		res := do(t, server, http.MethodPost, baseUrl+"/api/v1/merchant/orders/"+id+"/refunds", apiKey, form)
+		if res.StatusCode != http.StatusCreated {
+			t.Fatalf("expecting status %v, got %v", http.StatusCreated, res.StatusCode)
+		}
+
+		res = do(t, server, http.MethodGet, baseUrl+"/api/v1/merchant/settlements", apiKey, nil)
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("expecting status %v, got %v", http.StatusOK, res.StatusCode)
+		}
+		s := decode(t, res, "settlement")
+		if s["gross"] != float64(30000) || s["refunds"] != float64(5000) || s["net"] != float64(25000) {
+			t.Fatalf("unexpected settlement %v", s)
+		}
+	})
+}
+
+func do(t *testing.T, server *httptest.Server, method string, url string, token string, form url.Values) *http.Response {
+	var body io.Reader
+	if form != nil {
+		body = bytes.NewBufferString(form.Encode())
+	}
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Add("Authorization", fmt.Sprintf("Token %s", token))
+	if form != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+
+	res, err := server.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
+func decode(t *testing.T, res *http.Response, key string) map[string]interface{} {
+	defer res.Body.Close()
+	var response httphelper.Response
+	err := json.NewDecoder(res.Body).Decode(&response)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return response.Data.(map[string]interface{})[key].(map[string]interface{})
+}
@@ -0,0 +1,39 @@
+package http
+
+import (
+	httphelper "julo/internal/http"
+	"julo/internal/merchant"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// Middleware authenticates merchants presenting "Authorization: Token <api
+// key>" and puts the merchant in the request context.
+func Middleware(merchants merchant.Service) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header := r.Header.Get("Authorization")
+			_, key, found := strings.Cut(header, "Token ")
+			if !found || key == "" {
+				httphelper.WriteErrorJSON(w, http.StatusUnauthorized, merchant.ErrMerchantNotFound)
+				return
+			}
+
+			m, err := merchants.AuthenticateMerchant(r.Context(), key)
+			if err != nil {
+				switch err {
+				case merchant.ErrMerchantNotFound:
+					httphelper.WriteErrorJSON(w, http.StatusUnauthorized, err)
+				default:
+					log.Println(err)
+					httphelper.WriteErrorJSON(w, http.StatusInternalServerError, err)
+				}
+				return
+			}
+
+			c := merchant.MerchantIntoContext(r.Context(), m)
+			next.ServeHTTP(w, r.WithContext(c))
+		})
+	}
+}
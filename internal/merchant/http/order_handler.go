@@ -0,0 +1,146 @@
+package http
+
+import (
+	httphelper "julo/internal/http"
+	"julo/internal/merchant"
+	"julo/internal/wallet"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi"
+)
+
+// CreateOrderHandler opens a checkout session for the authenticated
+// merchant. The customer pays it through the checkout endpoints of their
+// wallet, and callback_url is told once it is paid or refunded.
+func CreateOrderHandler(merchants merchant.Service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var response httphelper.Response
+		m := merchant.MerchantFromContext(r.Context())
+		if m == nil {
+			httphelper.WriteErrorJSON(w, http.StatusUnauthorized, merchant.ErrMerchantNotFound)
+			return
+		}
+
+		var req createOrderRequest
+		if err := httphelper.Decode(w, r, &req); err != nil {
+			httphelper.WriteRequestErrorJSON(w, err)
+			return
+		}
+
+		o, err := merchants.CreateOrder(r.Context(), merchant.CreateOrderParam{
+			MerchantID:  m.ID,
+			Reference:   req.Reference,
+			Amount:      req.Amount,
+			Description: req.Description,
+			CallbackURL: req.CallbackURL,
+			ExpiresAt:   req.ExpiresAt,
+		})
+		if err != nil {
+			switch err {
+			case merchant.ErrMissingRequiredParameter, merchant.ErrInvalidAmount, merchant.ErrInvalidCallbackURL,
+				merchant.ErrInvalidExpiry, merchant.ErrDuplicateOrder:
+				httphelper.WriteErrorJSON(w, http.StatusBadRequest, err)
+			default:
+				httphelper.WriteErrorJSON(w, http.StatusInternalServerError, err)
+			}
+			return
+		}
+
+		response.Status = "success"
+		response.Data = map[string]interface{}{
+			"order": newOrderResponse(*o),
+		}
+		httphelper.WriteJSON(w, http.StatusCreated, response)
+	})
+}
+
+// ViewOrderHandler shows the merchant the status of the order identified by
+// the "id" url parameter.
+func ViewOrderHandler(merchants merchant.Service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var response httphelper.Response
+		m := merchant.MerchantFromContext(r.Context())
+		if m == nil {
+			httphelper.WriteErrorJSON(w, http.StatusUnauthorized, merchant.ErrMerchantNotFound)
+			return
+		}
+
+		o, err := merchants.GetOrder(r.Context(), merchant.OrderParam{
+			MerchantID: m.ID,
+			OrderID:    chi.URLParam(r, "id"),
+		})
+		if err != nil {
+			switch err {
+			case merchant.ErrOrderNotFound:
+				httphelper.WriteErrorJSON(w, http.StatusNotFound, err)
+			default:
+				httphelper.WriteErrorJSON(w, http.StatusInternalServerError, err)
+			}
+			return
+		}
+
+		response.Status = "success"
+		response.Data = map[string]interface{}{
+			"order": newOrderResponse(*o),
+		}
+		httphelper.WriteJSON(w, http.StatusOK, response)
+	})
+}
+
+// RefundOrderHandler gives amount of a paid order back to the customer.
+// Retrying with the same reference_id does not refund twice.
+func RefundOrderHandler(merchants merchant.Service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var response httphelper.Response
+		m := merchant.MerchantFromContext(r.Context())
+		if m == nil {
+			httphelper.WriteErrorJSON(w, http.StatusUnauthorized, merchant.ErrMerchantNotFound)
+			return
+		}
+
+		var req refundOrderRequest
+		if err := httphelper.Decode(w, r, &req); err != nil {
+			httphelper.WriteRequestErrorJSON(w, err)
+			return
+		}
+
+		o, err := merchants.RefundOrder(r.Context(), merchant.RefundOrderParam{
+			MerchantID:  m.ID,
+			OrderID:     chi.URLParam(r, "id"),
+			ReferenceID: req.ReferenceID,
+			Amount:      req.Amount,
+		})
+		if err != nil {
+			switch err {
+			case merchant.ErrOrderNotFound:
+				httphelper.WriteErrorJSON(w, http.StatusNotFound, err)
+			case merchant.ErrMissingRequiredParameter, merchant.ErrInvalidAmount, merchant.ErrOrderNotRefundable,
+				merchant.ErrRefundExceedsPayment, wallet.ErrInsufficientBalance, wallet.ErrWalletDisabled:
+				httphelper.WriteErrorJSON(w, http.StatusBadRequest, err)
+			default:
+				httphelper.WriteErrorJSON(w, http.StatusInternalServerError, err)
+			}
+			return
+		}
+
+		response.Status = "success"
+		response.Data = map[string]interface{}{
+			"order": newOrderResponse(*o),
+		}
+		httphelper.WriteJSON(w, http.StatusCreated, response)
+	})
+}
+
+type createOrderRequest struct {
+	Reference   string    `json:"reference" validate:"required"`
+	Amount      int       `json:"amount" validate:"required"`
+	Description string    `json:"description"`
+	CallbackURL string    `json:"callback_url"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+type refundOrderRequest struct {
+	ReferenceID string `json:"reference_id" validate:"required"`
+	Amount      int    `json:"amount" validate:"required"`
+}
@@ -0,0 +1,44 @@
+package http
+
+import (
+	httphelper "julo/internal/http"
+	"julo/internal/merchant"
+	"net/http"
+)
+
+// RegisterMerchantHandler lets operators onboard a merchant, answering with
+// the api key the merchant authenticates with.
+func RegisterMerchantHandler(merchants merchant.Service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var response httphelper.Response
+
+		var req registerMerchantRequest
+		if err := httphelper.Decode(w, r, &req); err != nil {
+			httphelper.WriteRequestErrorJSON(w, err)
+			return
+		}
+
+		m, err := merchants.RegisterMerchant(r.Context(), merchant.RegisterMerchantParam{
+			Name: req.Name,
+		})
+		if err != nil {
+			switch err {
+			case merchant.ErrMissingRequiredParameter:
+				httphelper.WriteErrorJSON(w, http.StatusBadRequest, err)
+			default:
+				httphelper.WriteErrorJSON(w, http.StatusInternalServerError, err)
+			}
+			return
+		}
+
+		response.Status = "success"
+		response.Data = map[string]interface{}{
+			"merchant": newMerchantResponse(*m),
+		}
+		httphelper.WriteJSON(w, http.StatusCreated, response)
+	})
+}
+
+type registerMerchantRequest struct {
+	Name string `json:"name" validate:"required"`
+}
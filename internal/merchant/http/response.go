@@ -0,0 +1,97 @@
+package http
+
+import (
+	"julo/internal/merchant"
+	"time"
+)
+
+type merchantResponse struct {
+	ID            string    `json:"id"`
+	Name          string    `json:"name"`
+	SettlementXID string    `json:"settlement_xid"`
+	APIKey        string    `json:"api_key"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+func newMerchantResponse(m merchant.Merchant) merchantResponse {
+	return merchantResponse{
+		ID:            m.ID,
+		Name:          m.Name,
+		SettlementXID: m.SettlementXID,
+		APIKey:        m.APIKey,
+		CreatedAt:     m.CreatedAt,
+	}
+}
+
+type refundResponse struct {
+	ID            string    `json:"id"`
+	ReferenceID   string    `json:"reference_id"`
+	Amount        int       `json:"amount"`
+	TransactionID string    `json:"transaction_id"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type orderResponse struct {
+	ID                   string           `json:"id"`
+	MerchantID           string           `json:"merchant_id"`
+	Reference            string           `json:"reference"`
+	Amount               int              `json:"amount"`
+	Description          string           `json:"description"`
+	Status               string           `json:"status"`
+	PaidBy               string           `json:"paid_by,omitempty"`
+	PaymentTransactionID string           `json:"payment_transaction_id,omitempty"`
+	Refunded             int              `json:"refunded"`
+	Refunds              []refundResponse `json:"refunds"`
+	ExpiresAt            time.Time        `json:"expires_at"`
+	PaidAt               time.Time        `json:"paid_at"`
+	CreatedAt            time.Time        `json:"created_at"`
+}
+
+func newOrderResponse(o merchant.Order) orderResponse {
+	refunds := make([]refundResponse, 0, len(o.Refunds))
+	for _, r := range o.Refunds {
+		refunds = append(refunds, refundResponse{
+			ID:            r.ID,
+			ReferenceID:   r.ReferenceID,
+			Amount:        r.Amount,
+			TransactionID: r.TransactionID,
+			CreatedAt:     r.CreatedAt,
+		})
+	}
+
+	return orderResponse{
+		ID:                   o.ID,
+		MerchantID:           o.MerchantID,
+		Reference:            o.Reference,
+		Amount:               o.Amount,
+		Description:          o.Description,
+		Status:               string(o.Status),
+		PaidBy:               o.CustomerXID,
+		PaymentTransactionID: o.PaymentTransactionID,
+		Refunded:             o.Refunded(),
+		Refunds:              refunds,
+		ExpiresAt:            o.ExpiresAt,
+		PaidAt:               o.PaidAt,
+		CreatedAt:            o.CreatedAt,
+	}
+}
+
+type settlementResponse struct {
+	MerchantID string `json:"merchant_id"`
+	Date       string `json:"date"`
+	Orders     int    `json:"orders"`
+	Gross      int    `json:"gross"`
+	Refunds    int    `json:"refunds"`
+	Net        int    `json:"net"`
+}
+
+func newSettlementResponse(s merchant.Settlement) settlementResponse {
+	return settlementResponse{
+		MerchantID: s.MerchantID,
+		Date:       s.Date.Format(dateLayout),
+		Orders:     s.Orders,
+		Gross:      s.Gross,
+		Refunds:    s.Refunds,
+		Net:        s.Net,
+	}
+}
@@ -0,0 +1,48 @@
+package http
+
+import (
+	httphelper "julo/internal/http"
+	"julo/internal/merchant"
+	"net/http"
+	"time"
+)
+
+const dateLayout = "2006-01-02"
+
+// ViewSettlementHandler summarizes the payments and refunds of the merchant
+// on ?date=YYYY-MM-DD, today when omitted. Days are counted in UTC.
+func ViewSettlementHandler(merchants merchant.Service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var response httphelper.Response
+		m := merchant.MerchantFromContext(r.Context())
+		if m == nil {
+			httphelper.WriteErrorJSON(w, http.StatusUnauthorized, merchant.ErrMerchantNotFound)
+			return
+		}
+
+		date := time.Now().UTC()
+		if v := r.URL.Query().Get("date"); v != "" {
+			var err error
+			date, err = time.Parse(dateLayout, v)
+			if err != nil {
+				httphelper.WriteErrorJSON(w, http.StatusBadRequest, err)
+				return
+			}
+		}
+
+		settlement, err := merchants.GetSettlement(r.Context(), merchant.SettlementParam{
+			MerchantID: m.ID,
+			Date:       date,
+		})
+		if err != nil {
+			httphelper.WriteErrorJSON(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		response.Status = "success"
+		response.Data = map[string]interface{}{
+			"settlement": newSettlementResponse(*settlement),
+		}
+		httphelper.WriteJSON(w, http.StatusOK, response)
+	})
+}
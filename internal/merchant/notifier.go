@@ -0,0 +1,163 @@
+package merchant
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// SignatureHeader carries the hex encoded HMAC-SHA256 of a callback body,
+// keyed with the merchant's API key.
+const SignatureHeader = "X-Signature"
+
+// Notifier tells merchants about changes to their orders.
+type Notifier interface {
+	NotifyOrder(ctx context.Context, m Merchant, o Order)
+}
+
+// OrderCallback is the body posted to the callback url of an order.
+type OrderCallback struct {
+	OrderID   string `json:"order_id"`
+	Reference string `json:"reference"`
+	Status    string `json:"status"`
+	Amount    int    `json:"amount"`
+	Refunded  int    `json:"refunded"`
+}
+
+// ValidateCallbackURL accepts http and https urls to hosts on the internet.
+// Loopback, private and link-local addresses are refused so merchants cannot
+// have the api call into the network it runs in.
+func ValidateCallbackURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return ErrInvalidCallbackURL
+	}
+
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") || strings.HasSuffix(host, ".internal") {
+		return ErrInvalidCallbackURL
+	}
+	if ip := net.ParseIP(host); ip != nil && !isPublicIP(ip) {
+		return ErrInvalidCallbackURL
+	}
+	return nil
+}
+
+// carrierGradeNAT is the shared address space of RFC 6598.
+var carrierGradeNAT = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+func isPublicIP(ip net.IP) bool {
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() ||
+		carrierGradeNAT.Contains(ip))
+}
+
+// dialPublic refuses connections to addresses that are not public, which
+// catches host names resolving to internal addresses and redirects to them.
+func dialPublic(network string, address string, c syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || !isPublicIP(ip) {
+		return errors.Errorf("callback address %s is not public", host)
+	}
+	return nil
+}
+
+// publicClient only connects to public addresses, straight rather than
+// through a proxy so the address checked is the one called.
+var publicClient = &http.Client{
+	Timeout: 10 * time.Second,
+	Transport: &http.Transport{
+		DialContext: (&net.Dialer{Timeout: 10 * time.Second, Control: dialPublic}).DialContext,
+	},
+}
+
+// HTTPNotifier posts a signed OrderCallback to the callback url of the order,
+// in the background and with a few attempts. Without a Client, callbacks
+// can only reach public addresses.
+type HTTPNotifier struct {
+	Client   *http.Client
+	Attempts int
+}
+
+func (n HTTPNotifier) NotifyOrder(ctx context.Context, m Merchant, o Order) {
+	if o.CallbackURL == "" {
+		return
+	}
+	if err := ValidateCallbackURL(o.CallbackURL); err != nil {
+		log.Printf("not notifying merchant %s of order %s: %s", m.ID, o.ID, err)
+		return
+	}
+
+	body, err := json.Marshal(OrderCallback{
+		OrderID:   o.ID,
+		Reference: o.Reference,
+		Status:    string(o.Status),
+		Amount:    o.Amount,
+		Refunded:  o.Refunded(),
+	})
+	if err != nil {
+		log.Println(err)
+		return
+	}
+
+	go func() {
+		attempts := n.Attempts
+		if attempts <= 0 {
+			attempts = 3
+		}
+		for i := 0; i < attempts; i++ {
+			err = n.post(o.CallbackURL, m.APIKey, body)
+			if err == nil {
+				return
+			}
+			time.Sleep(time.Duration(i+1) * time.Second)
+		}
+		log.Printf("failed notifying merchant %s of order %s: %s", m.ID, o.ID, err)
+	}()
+}
+
+func (n HTTPNotifier) post(url string, key string, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, Sign(key, body))
+
+	client := n.Client
+	if client == nil {
+		client = publicClient
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		return errors.Errorf("callback answered %s", res.Status)
+	}
+	return nil
+}
+
+func Sign(key string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
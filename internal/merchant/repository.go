@@ -0,0 +1,140 @@
+package merchant
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Merchant sells to customers, collecting the payments of its orders in the
+// settlement wallet owned by SettlementXID.
+type Merchant struct {
+	ID            string
+	Name          string
+	SettlementXID string
+	// APIKey authenticates the merchant on the merchant endpoints and signs
+	// the order callbacks.
+	APIKey    string
+	CreatedAt time.Time
+}
+
+type OrderStatus string
+
+var (
+	OrderStatusPending  = OrderStatus("pending")
+	OrderStatusPaid     = OrderStatus("paid")
+	OrderStatusExpired  = OrderStatus("expired")
+	OrderStatusRefunded = OrderStatus("refunded")
+)
+
+type Refund struct {
+	ID            string
+	ReferenceID   string
+	Amount        int
+	TransactionID string
+	CreatedAt     time.Time
+}
+
+// Order is a checkout session: the merchant asks for Amount and the customer
+// pays it from their wallet.
+type Order struct {
+	ID         string
+	MerchantID string
+	// Reference is the merchant's own identifier of the order.
+	Reference            string
+	Amount               int
+	Description          string
+	CallbackURL          string
+	Status               OrderStatus
+	CustomerXID          string
+	PaymentTransactionID string
+	Refunds              []Refund
+	ExpiresAt            time.Time
+	PaidAt               time.Time
+	CreatedAt            time.Time
+}
+
+func (o Order) Refunded() int {
+	var total int
+	for _, r := range o.Refunds {
+		total += r.Amount
+	}
+	return total
+}
+
+type Repository interface {
+	CreateMerchant(ctx context.Context, m Merchant) error
+	GetMerchant(ctx context.Context, id string) (*Merchant, error)
+	GetMerchantByAPIKey(ctx context.Context, key string) (*Merchant, error)
+	SaveOrder(ctx context.Context, o Order) error
+	GetOrder(ctx context.Context, id string) (*Order, error)
+	GetOrders(ctx context.Context, merchantID string) ([]Order, error)
+}
+
+type InMemoryRepository struct {
+	merchants sync.Map
+	apiKeys   sync.Map
+	orders    sync.Map
+}
+
+func NewInMemoryRepository() Repository {
+	return &InMemoryRepository{}
+}
+
+func (r *InMemoryRepository) CreateMerchant(ctx context.Context, m Merchant) error {
+	r.merchants.Store(m.ID, &m)
+	r.apiKeys.Store(m.APIKey, m.ID)
+	return nil
+}
+
+func (r *InMemoryRepository) GetMerchant(ctx context.Context, id string) (*Merchant, error) {
+	v, ok := r.merchants.Load(id)
+	if !ok {
+		return nil, ErrMerchantNotFound
+	}
+
+	m := *v.(*Merchant)
+	return &m, nil
+}
+
+func (r *InMemoryRepository) GetMerchantByAPIKey(ctx context.Context, key string) (*Merchant, error) {
+	id, ok := r.apiKeys.Load(key)
+	if !ok {
+		return nil, ErrMerchantNotFound
+	}
+	return r.GetMerchant(ctx, id.(string))
+}
+
+func (r *InMemoryRepository) SaveOrder(ctx context.Context, o Order) error {
+	o.Refunds = append([]Refund(nil), o.Refunds...)
+	r.orders.Store(o.ID, &o)
+	return nil
+}
+
+func (r *InMemoryRepository) GetOrder(ctx context.Context, id string) (*Order, error) {
+	v, ok := r.orders.Load(id)
+	if !ok {
+		return nil, ErrOrderNotFound
+	}
+
+	o := *v.(*Order)
+	o.Refunds = append([]Refund(nil), o.Refunds...)
+	return &o, nil
+}
+
+func (r *InMemoryRepository) GetOrders(ctx context.Context, merchantID string) ([]Order, error) {
+	orders := []Order{}
+	r.orders.Range(func(key, value any) bool {
+		o := *value.(*Order)
+		if o.MerchantID == merchantID {
+			o.Refunds = append([]Refund(nil), o.Refunds...)
+			orders = append(orders, o)
+		}
+		return true
+	})
+	sort.Slice(orders, func(i, j int) bool {
+		return orders[i].CreatedAt.Before(orders[j].CreatedAt)
+	})
+	return orders, nil
+}
@@ -0,0 +1,396 @@
+package merchant
+
+import (
+	"context"
+	"julo/internal/account"
+	"julo/internal/wallet"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+const defaultOrderExpiry = 30 * time.Minute
+
+type RegisterMerchantParam struct {
+	Name string
+}
+
+type CreateOrderParam struct {
+	MerchantID  string
+	Reference   string
+	Amount      int
+	Description string
+	CallbackURL string
+	// ExpiresAt defaults to half an hour after the order is created.
+	ExpiresAt time.Time
+}
+
+func (p CreateOrderParam) Validate() error {
+	if p.MerchantID == "" || p.Reference == "" {
+		return ErrMissingRequiredParameter
+	}
+	if p.Amount <= 0 {
+		return ErrInvalidAmount
+	}
+	if p.CallbackURL != "" {
+		if err := ValidateCallbackURL(p.CallbackURL); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type OrderParam struct {
+	MerchantID string
+	OrderID    string
+}
+
+type PayOrderParam struct {
+	CustomerXID string
+	OrderID     string
+}
+
+type RefundOrderParam struct {
+	MerchantID  string
+	OrderID     string
+	ReferenceID string
+	Amount      int
+}
+
+func (p RefundOrderParam) Validate() error {
+	if p.MerchantID == "" || p.OrderID == "" || p.ReferenceID == "" {
+		return ErrMissingRequiredParameter
+	}
+	if p.Amount <= 0 {
+		return ErrInvalidAmount
+	}
+	return nil
+}
+
+type SettlementParam struct {
+	MerchantID string
+	// Date is any time in the day to summarize, in the location the day is
+	// counted in.
+	Date time.Time
+}
+
+// Settlement summarizes what moved through the settlement wallet of a
+// merchant during a day.
+type Settlement struct {
+	MerchantID string
+	Date       time.Time
+	Orders     int
+	Gross      int
+	Refunds    int
+	Net        int
+}
+
+type Service interface {
+	RegisterMerchant(ctx context.Context, param RegisterMerchantParam) (*Merchant, error)
+	AuthenticateMerchant(ctx context.Context, apiKey string) (*Merchant, error)
+	CreateOrder(ctx context.Context, param CreateOrderParam) (*Order, error)
+	GetOrder(ctx context.Context, param OrderParam) (*Order, error)
+	GetCheckout(ctx context.Context, orderID string) (*Order, error)
+	PayOrder(ctx context.Context, param PayOrderParam) (*Order, error)
+	RefundOrder(ctx context.Context, param RefundOrderParam) (*Order, error)
+	GetSettlement(ctx context.Context, param SettlementParam) (*Settlement, error)
+}
+
+type service struct {
+	repo     Repository
+	wallets  wallet.Service
+	notifier Notifier
+	mu       sync.Mutex
+}
+
+func NewService(repo Repository, wallets wallet.Service, notifier Notifier) Service {
+	return &service{
+		repo:     repo,
+		wallets:  wallets,
+		notifier: notifier,
+	}
+}
+
+// RegisterMerchant creates the merchant along with its enabled settlement
+// wallet.
+func (s *service) RegisterMerchant(ctx context.Context, param RegisterMerchantParam) (*Merchant, error) {
+	if param.Name == "" {
+		return nil, ErrMissingRequiredParameter
+	}
+
+	id := uuid.NewString()
+	m := Merchant{
+		ID:            id,
+		Name:          param.Name,
+		SettlementXID: account.MerchantXIDPrefix + id,
+		APIKey:        uuid.NewString(),
+		CreatedAt:     time.Now(),
+	}
+	_, err := s.wallets.EnableWallet(ctx, wallet.EnableWalletParam{OwnerXID: m.SettlementXID})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed enabling settlement wallet")
+	}
+	err = s.repo.CreateMerchant(ctx, m)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed creating merchant")
+	}
+
+	return &m, nil
+}
+
+func (s *service) AuthenticateMerchant(ctx context.Context, apiKey string) (*Merchant, error) {
+	m, err := s.repo.GetMerchantByAPIKey(ctx, apiKey)
+	if err != nil && err == ErrMerchantNotFound {
+		return nil, ErrMerchantNotFound
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed getting merchant")
+	}
+	return m, nil
+}
+
+func (s *service) CreateOrder(ctx context.Context, param CreateOrderParam) (*Order, error) {
+	err := param.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if param.ExpiresAt.IsZero() {
+		param.ExpiresAt = now.Add(defaultOrderExpiry)
+	} else if !param.ExpiresAt.After(now) {
+		return nil, ErrInvalidExpiry
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err = s.getMerchant(ctx, param.MerchantID)
+	if err != nil {
+		return nil, err
+	}
+	orders, err := s.repo.GetOrders(ctx, param.MerchantID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed getting orders")
+	}
+	for _, o := range orders {
+		if o.Reference == param.Reference {
+			return nil, ErrDuplicateOrder
+		}
+	}
+
+	o := Order{
+		ID:          uuid.NewString(),
+		MerchantID:  param.MerchantID,
+		Reference:   param.Reference,
+		Amount:      param.Amount,
+		Description: param.Description,
+		CallbackURL: param.CallbackURL,
+		Status:      OrderStatusPending,
+		ExpiresAt:   param.ExpiresAt,
+		CreatedAt:   now,
+	}
+	err = s.repo.SaveOrder(ctx, o)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed saving order")
+	}
+
+	return &o, nil
+}
+
+// GetOrder returns the order to the merchant that created it.
+func (s *service) GetOrder(ctx context.Context, param OrderParam) (*Order, error) {
+	o, err := s.getOrder(ctx, param.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	if o.MerchantID != param.MerchantID {
+		return nil, ErrOrderNotFound
+	}
+	return o, nil
+}
+
+// GetCheckout returns the order a customer is about to pay. Who paid it is
+// only disclosed to the merchant.
+func (s *service) GetCheckout(ctx context.Context, orderID string) (*Order, error) {
+	o, err := s.getOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	o.CustomerXID = ""
+	o.PaymentTransactionID = ""
+	o.Refunds = nil
+	return o, nil
+}
+
+// PayOrder transfers the amount of a pending order from the customer's
+// wallet to the merchant's settlement wallet.
+func (s *service) PayOrder(ctx context.Context, param PayOrderParam) (*Order, error) {
+	if param.CustomerXID == "" || param.OrderID == "" {
+		return nil, ErrMissingRequiredParameter
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	o, err := s.getOrder(ctx, param.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	if o.Status != OrderStatusPending {
+		return nil, ErrOrderNotPayable
+	}
+	m, err := s.getMerchant(ctx, o.MerchantID)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.wallets.TransferWallet(ctx, wallet.TransferWalletParam{
+		ActorXID:    param.CustomerXID,
+		FromXID:     param.CustomerXID,
+		ToXID:       m.SettlementXID,
+		ReferenceID: wallet.SystemReferencePrefix + "order-" + o.ID,
+		Amount:      o.Amount,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	o.Status = OrderStatusPaid
+	o.CustomerXID = param.CustomerXID
+	o.PaymentTransactionID = result.Debit.ID
+	o.PaidAt = result.Debit.SettledAt
+	err = s.repo.SaveOrder(ctx, *o)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed saving order")
+	}
+
+	s.notifier.NotifyOrder(ctx, *m, *o)
+	return o, nil
+}
+
+// RefundOrder gives back part or all of a paid order to the customer, from
+// the merchant's settlement wallet. A refund is identified by its reference,
+// repeating one returns the order unchanged.
+func (s *service) RefundOrder(ctx context.Context, param RefundOrderParam) (*Order, error) {
+	err := param.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	o, err := s.GetOrder(ctx, OrderParam{MerchantID: param.MerchantID, OrderID: param.OrderID})
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range o.Refunds {
+		if r.ReferenceID == param.ReferenceID {
+			return o, nil
+		}
+	}
+	if o.Status != OrderStatusPaid {
+		return nil, ErrOrderNotRefundable
+	}
+	if o.Refunded()+param.Amount > o.Amount {
+		return nil, ErrRefundExceedsPayment
+	}
+	m, err := s.getMerchant(ctx, o.MerchantID)
+	if err != nil {
+		return nil, err
+	}
+
+	id := uuid.NewString()
+	result, err := s.wallets.TransferWallet(ctx, wallet.TransferWalletParam{
+		ActorXID:    m.SettlementXID,
+		FromXID:     m.SettlementXID,
+		ToXID:       o.CustomerXID,
+		ReferenceID: wallet.SystemReferencePrefix + "order-" + o.ID + "-refund-" + param.ReferenceID,
+		Amount:      param.Amount,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	o.Refunds = append(o.Refunds, Refund{
+		ID:            id,
+		ReferenceID:   param.ReferenceID,
+		Amount:        param.Amount,
+		TransactionID: result.Debit.ID,
+		CreatedAt:     result.Debit.SettledAt,
+	})
+	if o.Refunded() == o.Amount {
+		o.Status = OrderStatusRefunded
+	}
+	err = s.repo.SaveOrder(ctx, *o)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed saving order")
+	}
+
+	s.notifier.NotifyOrder(ctx, *m, *o)
+	return o, nil
+}
+
+// GetSettlement sums the orders paid and the refunds made during the day of
+// param.Date.
+func (s *service) GetSettlement(ctx context.Context, param SettlementParam) (*Settlement, error) {
+	_, err := s.getMerchant(ctx, param.MerchantID)
+	if err != nil {
+		return nil, err
+	}
+	orders, err := s.repo.GetOrders(ctx, param.MerchantID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed getting orders")
+	}
+
+	y, m, d := param.Date.Date()
+	start := time.Date(y, m, d, 0, 0, 0, 0, param.Date.Location())
+	end := start.AddDate(0, 0, 1)
+	inDay := func(t time.Time) bool {
+		return !t.Before(start) && t.Before(end)
+	}
+
+	settlement := Settlement{
+		MerchantID: param.MerchantID,
+		Date:       start,
+	}
+	for _, o := range orders {
+		if o.PaymentTransactionID != "" && inDay(o.PaidAt) {
+			settlement.Orders++
+			settlement.Gross += o.Amount
+		}
+		for _, r := range o.Refunds {
+			if inDay(r.CreatedAt) {
+				settlement.Refunds += r.Amount
+			}
+		}
+	}
+	settlement.Net = settlement.Gross - settlement.Refunds
+
+	return &settlement, nil
+}
+
+func (s *service) getMerchant(ctx context.Context, id string) (*Merchant, error) {
+	m, err := s.repo.GetMerchant(ctx, id)
+	if err != nil && err == ErrMerchantNotFound {
+		return nil, ErrMerchantNotFound
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed getting merchant")
+	}
+	return m, nil
+}
+
+func (s *service) getOrder(ctx context.Context, id string) (*Order, error) {
+	o, err := s.repo.GetOrder(ctx, id)
+	if err != nil && err == ErrOrderNotFound {
+		return nil, ErrOrderNotFound
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed getting order")
+	}
+	if o.Status == OrderStatusPending && !time.Now().Before(o.ExpiresAt) {
+		o.Status = OrderStatusExpired
+	}
+	return o, nil
+}
@@ -0,0 +1,190 @@
+package merchant_test
+
+import (
+	"context"
+	"julo/internal/merchant"
+	"julo/internal/wallet"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type recordingNotifier struct {
+	mu     sync.Mutex
+	orders []merchant.Order
+}
+
+func (n *recordingNotifier) NotifyOrder(ctx context.Context, m merchant.Merchant, o merchant.Order) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.orders = append(n.orders, o)
+}
+
+func TestCheckout(t *testing.T) {
+	ctx := context.Background()
+	wallets := wallet.NewService(wallet.NewInMemoryRepository())
+	notifier := &recordingNotifier{}
+	merchants := merchant.NewService(merchant.NewInMemoryRepository(), wallets, notifier)
+
+	m, err := merchants.RegisterMerchant(ctx, merchant.RegisterMerchantParam{Name: "coffee shop"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	authenticated, err := merchants.AuthenticateMerchant(ctx, m.APIKey)
+	if err != nil || authenticated.ID != m.ID {
+		t.Fatalf("expecting merchant %s, got %+v, %v", m.ID, authenticated, err)
+	}
+
+	customer := uuid.NewString()
+	_, err = wallets.EnableWallet(ctx, wallet.EnableWalletParam{OwnerXID: customer})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = wallets.DepositWallet(ctx, wallet.WalletTransactionParam{
+		ActorXID:    customer,
+		OwnerXID:    customer,
+		ReferenceID: uuid.NewString(),
+		Amount:      50000,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	o, err := merchants.CreateOrder(ctx, merchant.CreateOrderParam{
+		MerchantID:  m.ID,
+		Reference:   "INV-1",
+		Amount:      30000,
+		CallbackURL: "https://shop.example/callbacks",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = merchants.CreateOrder(ctx, merchant.CreateOrderParam{MerchantID: m.ID, Reference: "INV-1", Amount: 1000})
+	if err != merchant.ErrDuplicateOrder {
+		t.Fatalf("expecting error %s, got %v", merchant.ErrDuplicateOrder, err)
+	}
+
+	o, err = merchants.PayOrder(ctx, merchant.PayOrderParam{CustomerXID: customer, OrderID: o.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o.Status != merchant.OrderStatusPaid || o.CustomerXID != customer {
+		t.Fatalf("expecting order paid by %s, got %+v", customer, o)
+	}
+	_, err = merchants.PayOrder(ctx, merchant.PayOrderParam{CustomerXID: customer, OrderID: o.ID})
+	if err != merchant.ErrOrderNotPayable {
+		t.Fatalf("expecting error %s, got %v", merchant.ErrOrderNotPayable, err)
+	}
+
+	_, err = merchants.RefundOrder(ctx, merchant.RefundOrderParam{MerchantID: m.ID, OrderID: o.ID, ReferenceID: "r1", Amount: 40000})
+	if err != merchant.ErrRefundExceedsPayment {
+		t.Fatalf("expecting error %s, got %v", merchant.ErrRefundExceedsPayment, err)
+	}
+	for i := 0; i < 2; i++ {
+		o, err = merchants.RefundOrder(ctx, merchant.RefundOrderParam{MerchantID: m.ID, OrderID: o.ID, ReferenceID: "r1", Amount: 10000})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if o.Refunded() != 10000 || o.Status != merchant.OrderStatusPaid {
+		t.Fatalf("expecting 10000 refunded once, got %+v", o)
+	}
+	o, err = merchants.RefundOrder(ctx, merchant.RefundOrderParam{MerchantID: m.ID, OrderID: o.ID, ReferenceID: "r2", Amount: 20000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o.Status != merchant.OrderStatusRefunded {
+		t.Fatalf("expecting order refunded, got %s", o.Status)
+	}
+
+	_, err = merchants.GetOrder(ctx, merchant.OrderParam{MerchantID: uuid.NewString(), OrderID: o.ID})
+	if err != merchant.ErrOrderNotFound {
+		t.Fatalf("expecting error %s, got %v", merchant.ErrOrderNotFound, err)
+	}
+
+	settlementWallet, err := wallets.GetWalletByXID(ctx, m.SettlementXID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	customerWallet, err := wallets.GetWalletByXID(ctx, customer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if settlementWallet.Balance != 0 || customerWallet.Balance != 50000 {
+		t.Fatalf("expecting everything refunded, got settlement %d and customer %d", settlementWallet.Balance, customerWallet.Balance)
+	}
+
+	settlement, err := merchants.GetSettlement(ctx, merchant.SettlementParam{MerchantID: m.ID, Date: time.Now()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if settlement.Orders != 1 || settlement.Gross != 30000 || settlement.Refunds != 30000 || settlement.Net != 0 {
+		t.Fatalf("unexpected settlement %+v", settlement)
+	}
+
+	if len(notifier.orders) != 3 {
+		t.Fatalf("expecting a callback for the payment and each refund, got %d", len(notifier.orders))
+	}
+}
+
+func TestExpiredOrder(t *testing.T) {
+	ctx := context.Background()
+	wallets := wallet.NewService(wallet.NewInMemoryRepository())
+	merchants := merchant.NewService(merchant.NewInMemoryRepository(), wallets, &recordingNotifier{})
+
+	m, err := merchants.RegisterMerchant(ctx, merchant.RegisterMerchantParam{Name: "bakery"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	o, err := merchants.CreateOrder(ctx, merchant.CreateOrderParam{
+		MerchantID: m.ID,
+		Reference:  "INV-1",
+		Amount:     1000,
+		ExpiresAt:  time.Now().Add(10 * time.Millisecond),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	o, err = merchants.GetCheckout(ctx, o.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o.Status != merchant.OrderStatusExpired {
+		t.Fatalf("expecting order expired, got %s", o.Status)
+	}
+	_, err = merchants.PayOrder(ctx, merchant.PayOrderParam{CustomerXID: uuid.NewString(), OrderID: o.ID})
+	if err != merchant.ErrOrderNotPayable {
+		t.Fatalf("expecting error %s, got %v", merchant.ErrOrderNotPayable, err)
+	}
+}
+
+func TestValidateCallbackURL(t *testing.T) {
+	for _, raw := range []string{
+		"https://shop.example/callbacks",
+		"http://203.0.113.7:8080/orders",
+	} {
+		if err := merchant.ValidateCallbackURL(raw); err != nil {
+			t.Fatalf("expecting %s accepted, got %v", raw, err)
+		}
+	}
+	for _, raw := range []string{
+		"ftp://shop.example/callbacks",
+		"https:///callbacks",
+		"http://localhost:8080/callbacks",
+		"http://127.0.0.1/callbacks",
+		"http://10.0.0.5/callbacks",
+		"http://192.168.1.1/callbacks",
+		"http://169.254.169.254/latest/meta-data",
+		"http://[::1]/callbacks",
+		"http://0.0.0.0/callbacks",
+		"http://metadata.google.internal/callbacks",
+	} {
+		if err := merchant.ValidateCallbackURL(raw); err != merchant.ErrInvalidCallbackURL {
+			t.Fatalf("expecting %s refused with %s, got %v", raw, merchant.ErrInvalidCallbackURL, err)
+		}
+	}
+}
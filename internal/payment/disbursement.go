@@ -0,0 +1,29 @@
+package payment
+
+import (
+	"context"
+	"julo/internal/disbursement"
+)
+
+// DisbursementCallback adapts the payout callbacks of a disbursement provider
+// so they settle or fail the withdrawal that requested the payout.
+func DisbursementCallback(processor CallbackProcessor) disbursement.CallbackFunc {
+	return func(ctx context.Context, cb disbursement.Callback) error {
+		var status Status
+		switch cb.Status {
+		case disbursement.PayoutStatusSuccess:
+			status = StatusSuccess
+		case disbursement.PayoutStatusFailed:
+			status = StatusFailed
+		default:
+			return ErrUnknownStatus
+		}
+
+		_, err := processor.Process(ctx, Callback{
+			TransactionID: cb.ReferenceID,
+			Status:        status,
+			Reason:        cb.Reason,
+		})
+		return err
+	}
+}
@@ -0,0 +1,7 @@
+package payment
+
+import "errors"
+
+var (
+	ErrUnknownStatus = errors.New("unknown payment status")
+)
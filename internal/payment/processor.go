@@ -0,0 +1,66 @@
+package payment
+
+import (
+	"context"
+	"julo/internal/wallet"
+
+	"github.com/pkg/errors"
+)
+
+// Status is the outcome of a payment as reported by a payment provider.
+type Status string
+
+var (
+	StatusSuccess = Status("success")
+	StatusFailed  = Status("failed")
+	StatusExpired = Status("expired")
+)
+
+// Callback is a payment provider notification about a transaction that was
+// left pending in the wallet.
+type Callback struct {
+	TransactionID string
+	Status        Status
+	Reason        string
+}
+
+// CallbackProcessor drives wallet transactions out of their pending state
+// based on the callbacks of a payment provider.
+type CallbackProcessor interface {
+	Process(ctx context.Context, cb Callback) (*wallet.WalletTransaction, error)
+}
+
+type callbackProcessor struct {
+	wallets wallet.Service
+}
+
+func NewCallbackProcessor(wallets wallet.Service) CallbackProcessor {
+	return &callbackProcessor{
+		wallets: wallets,
+	}
+}
+
+func (p *callbackProcessor) Process(ctx context.Context, cb Callback) (*wallet.WalletTransaction, error) {
+	param := wallet.TransitionTransactionParam{
+		TransactionID: cb.TransactionID,
+		Reason:        cb.Reason,
+	}
+
+	var trx *wallet.WalletTransaction
+	var err error
+	switch cb.Status {
+	case StatusSuccess:
+		trx, err = p.wallets.SettleTransaction(ctx, param)
+	case StatusFailed:
+		trx, err = p.wallets.FailTransaction(ctx, param)
+	case StatusExpired:
+		trx, err = p.wallets.ExpireTransaction(ctx, param)
+	default:
+		return nil, ErrUnknownStatus
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed processing %s callback", cb.Status)
+	}
+
+	return trx, nil
+}
@@ -0,0 +1,180 @@
+package payment_test
+
+import (
+	"context"
+	"julo/internal/disbursement"
+	"julo/internal/payment"
+	"julo/internal/wallet"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestProcessCallback(t *testing.T) {
+	ctx := context.Background()
+	wallets := wallet.NewService(wallet.NewInMemoryRepository())
+	processor := payment.NewCallbackProcessor(wallets)
+
+	xid := uuid.NewString()
+	_, err := wallets.EnableWallet(ctx, wallet.EnableWalletParam{
+		OwnerXID: xid,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deposit := func(t *testing.T) string {
+		result, err := wallets.DepositWallet(ctx, wallet.WalletTransactionParam{
+			ActorXID:    xid,
+			OwnerXID:    xid,
+			ReferenceID: uuid.NewString(),
+			Amount:      10000,
+			Pending:     true,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result.ID
+	}
+
+	t.Run("success callback, should settle transaction", func(t *testing.T) {
+		trx, err := processor.Process(ctx, payment.Callback{
+			TransactionID: deposit(t),
+			Status:        payment.StatusSuccess,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if trx.Status != wallet.TransactionStatusSuccess {
+			t.Fatalf("expecting status %s, got %s", wallet.TransactionStatusSuccess, trx.Status)
+		}
+	})
+
+	t.Run("expired callback, should expire transaction", func(t *testing.T) {
+		trx, err := processor.Process(ctx, payment.Callback{
+			TransactionID: deposit(t),
+			Status:        payment.StatusExpired,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if trx.Status != wallet.TransactionStatusExpired {
+			t.Fatalf("expecting status %s, got %s", wallet.TransactionStatusExpired, trx.Status)
+		}
+	})
+
+	t.Run("unknown status, should fail", func(t *testing.T) {
+		_, err := processor.Process(ctx, payment.Callback{
+			TransactionID: deposit(t),
+			Status:        payment.Status("refunded"),
+		})
+		if err != payment.ErrUnknownStatus {
+			t.Fatalf("expecting error %s, got %s", payment.ErrUnknownStatus, err)
+		}
+	})
+}
+
+func TestDisbursementCallback(t *testing.T) {
+	ctx := context.Background()
+	payouts := disbursement.NewSimulator(disbursement.SimulatorConfig{
+		Latency: time.Hour,
+	})
+	defer payouts.Close()
+	wallets := wallet.NewService(wallet.NewInMemoryRepository(), wallet.WithDisbursementProvider(payouts))
+	callback := payment.DisbursementCallback(payment.NewCallbackProcessor(wallets))
+
+	xid := uuid.NewString()
+	_, err := wallets.EnableWallet(ctx, wallet.EnableWalletParam{
+		OwnerXID: xid,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = wallets.DepositWallet(ctx, wallet.WalletTransactionParam{
+		ActorXID:    xid,
+		OwnerXID:    xid,
+		ReferenceID: uuid.NewString(),
+		Amount:      100000,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	withdraw := func(t *testing.T) *wallet.WalletTransactionResult {
+		result, err := wallets.WithdrawWallet(ctx, wallet.WalletTransactionParam{
+			ActorXID:    xid,
+			OwnerXID:    xid,
+			ReferenceID: uuid.NewString(),
+			Amount:      30000,
+			Destination: &disbursement.BankAccount{
+				BankCode:      "BCA",
+				AccountNumber: "1234567890",
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Status != wallet.TransactionStatusPending {
+			t.Fatalf("expecting status %s, got %s", wallet.TransactionStatusPending, result.Status)
+		}
+		return result
+	}
+
+	balance := func(t *testing.T) int {
+		wal, err := wallets.GetWalletByXID(ctx, xid)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return wal.Balance
+	}
+
+	t.Run("successful payout, should debit balance", func(t *testing.T) {
+		result := withdraw(t)
+		err := callback(ctx, disbursement.Callback{
+			ReferenceID: result.ID,
+			Status:      disbursement.PayoutStatusSuccess,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b := balance(t); b != 70000 {
+			t.Fatalf("expecting balance %d, got %d", 70000, b)
+		}
+	})
+
+	t.Run("failed payout, should keep balance", func(t *testing.T) {
+		result := withdraw(t)
+		err := callback(ctx, disbursement.Callback{
+			ReferenceID: result.ID,
+			Status:      disbursement.PayoutStatusFailed,
+			Reason:      "account closed",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b := balance(t); b != 70000 {
+			t.Fatalf("expecting balance %d, got %d", 70000, b)
+		}
+
+		trx, err := wallets.GetTransaction(ctx, result.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if trx.Status != wallet.TransactionStatusFailed {
+			t.Fatalf("expecting status %s, got %s", wallet.TransactionStatusFailed, trx.Status)
+		}
+	})
+
+	t.Run("withdraw without bank account, should fail", func(t *testing.T) {
+		_, err := wallets.WithdrawWallet(ctx, wallet.WalletTransactionParam{
+			ActorXID:    xid,
+			OwnerXID:    xid,
+			ReferenceID: uuid.NewString(),
+			Amount:      30000,
+		})
+		if _, ok := err.(wallet.ValidationError); !ok {
+			t.Fatalf("expecting validation error, got %v", err)
+		}
+	})
+}
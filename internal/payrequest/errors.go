@@ -0,0 +1,14 @@
+package payrequest
+
+import "errors"
+
+var (
+	ErrRequestNotFound          = errors.New("payment request not found")
+	ErrMissingRequiredParameter = errors.New("missing required parameter")
+	ErrInvalidAmount            = errors.New("invalid amount")
+	ErrInvalidShares            = errors.New("shares must be positive and add up to the amount")
+	ErrDuplicatePayer           = errors.New("payer listed more than once")
+	ErrSelfRequest              = errors.New("cannot request payment from yourself")
+	ErrInvalidExpiry            = errors.New("expiry must be in the future")
+	ErrNotPending               = errors.New("payment is no longer pending")
+)
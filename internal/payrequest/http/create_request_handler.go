@@ -0,0 +1,62 @@
+package http
+
+import (
+	"julo/internal/auth"
+	httphelper "julo/internal/http"
+	"julo/internal/payrequest"
+	"julo/internal/wallet"
+	"net/http"
+	"time"
+)
+
+// CreateRequestHandler asks the customers in the repeated payer_xid field to
+// pay amount, split equally unless a share is given for every payer.
+func CreateRequestHandler(requests payrequest.Service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var response httphelper.Response
+		session := auth.SessionFromContext(r.Context())
+		if session == nil {
+			httphelper.WriteErrorJSON(w, http.StatusUnauthorized, auth.ErrSessionNotFound)
+			return
+		}
+
+		var req createRequestRequest
+		if err := httphelper.Decode(w, r, &req); err != nil {
+			httphelper.WriteRequestErrorJSON(w, err)
+			return
+		}
+
+		created, err := requests.CreateRequest(r.Context(), payrequest.CreateRequestParam{
+			RequesterXID: session.Account.XID,
+			Amount:       req.Amount,
+			Description:  req.Description,
+			PayerXIDs:    req.PayerXIDs,
+			Shares:       req.Shares,
+			ExpiresAt:    req.ExpiresAt,
+		})
+		if err != nil {
+			switch err {
+			case payrequest.ErrMissingRequiredParameter, payrequest.ErrInvalidAmount, payrequest.ErrInvalidShares, payrequest.ErrDuplicatePayer,
+				payrequest.ErrSelfRequest, payrequest.ErrInvalidExpiry, wallet.ErrWalletNotFound:
+				httphelper.WriteErrorJSON(w, http.StatusBadRequest, err)
+			default:
+				httphelper.WriteErrorJSON(w, http.StatusInternalServerError, err)
+			}
+			return
+		}
+
+		response.Status = "success"
+		response.Data = map[string]interface{}{
+			"payment_request": newRequestResponse(*created),
+		}
+		httphelper.WriteJSON(w, http.StatusCreated, response)
+	})
+}
+
+type createRequestRequest struct {
+	Amount      int       `json:"amount" validate:"required"`
+	Description string    `json:"description"`
+	PayerXIDs   []string  `json:"payer_xid" validate:"required"`
+	Shares      []int     `json:"share"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
@@ -0,0 +1,167 @@
+package http_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"julo/internal/account"
+	"julo/internal/auth"
+	authhttp "julo/internal/auth/http"
+	httphelper "julo/internal/http"
+	"julo/internal/payrequest"
+	payrequesthttp "julo/internal/payrequest/http"
+	"julo/internal/wallet"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/google/uuid"
+)
+
+func TestPaymentRequests(t *testing.T) {
+	ctx := context.Background()
+	wallets := wallet.NewService(wallet.NewInMemoryRepository())
+	requests := payrequest.NewService(payrequest.NewInMemoryRepository(), wallets)
+
+	router := chi.NewRouter()
+	router.Mount("/api/v1", router.Group(func(r chi.Router) {
+		r.Mount("/wallet", r.Group(func(r chi.Router) {
+			r.Use(authhttp.Middleware)
+			r.Get("/payment-requests", payrequesthttp.ViewRequestsHandler(requests).ServeHTTP)
+			r.Post("/payment-requests", payrequesthttp.CreateRequestHandler(requests).ServeHTTP)
+			r.Get("/payment-requests/{id}", payrequesthttp.ViewRequestHandler(requests).ServeHTTP)
+			r.Post("/payment-requests/{id}/pay", payrequesthttp.PayRequestHandler(requests).ServeHTTP)
+			r.Post("/payment-requests/{id}/decline", payrequesthttp.DeclineRequestHandler(requests).ServeHTTP)
+		}))
+	}))
+	server := httptest.NewServer(router)
+	defer server.Close()
+	baseUrl := server.URL
+
+	tokens := map[string]string{}
+	requester, payer := uuid.NewString(), uuid.NewString()
+	for _, xid := range []string{requester, payer} {
+		_, err := wallets.EnableWallet(ctx, wallet.EnableWalletParam{
+			OwnerXID: xid,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = wallets.DepositWallet(ctx, wallet.WalletTransactionParam{
+			ActorXID:    xid,
+			OwnerXID:    xid,
+			ReferenceID: uuid.NewString(),
+			Amount:      50000,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		tokens[xid] = uuid.NewString()
+		err = auth.StoreSession(ctx, auth.Session{
+			Token:   tokens[xid],
+			Account: account.Account{XID: xid},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Run("create request, should success", func(t *testing.T) {
+		form := url.Values{}
+		form.Set("amount", "20000")
+		form.Set("description", "movie tickets")
+		form.Add("payer_xid", payer)
+		req := buildAuthenticatedRequest(t, http.MethodPost, baseUrl+"/api/v1/wallet/payment-requests", tokens[requester], bytes.NewBufferString(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		res, err := server.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.StatusCode != http.StatusCreated {
+			t.Fatalf("expecting status %v, got %v", http.StatusCreated, res.StatusCode)
+		}
+		id := decodeRequest(t, res)["id"].(string)
+
+		t.Run("pay request, should transfer share", func(t *testing.T) {
+			req := buildAuthenticatedRequest(t, http.MethodPost, baseUrl+"/api/v1/wallet/payment-requests/"+id+"/pay", tokens[payer], nil)
+			res, err := server.Client().Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.StatusCode != http.StatusOK {
+				t.Fatalf("expecting status %v, got %v", http.StatusOK, res.StatusCode)
+			}
+			wal, _ := wallets.GetWalletByXID(ctx, requester)
+			if wal.Balance != 70000 {
+				t.Fatalf("expecting balance 70000, got %d", wal.Balance)
+			}
+		})
+
+		t.Run("view request as requester, should show payer status", func(t *testing.T) {
+			req := buildAuthenticatedRequest(t, http.MethodGet, baseUrl+"/api/v1/wallet/payment-requests/"+id, tokens[requester], nil)
+			res, err := server.Client().Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.StatusCode != http.StatusOK {
+				t.Fatalf("expecting status %v, got %v", http.StatusOK, res.StatusCode)
+			}
+			r := decodeRequest(t, res)
+			payers := r["payers"].([]interface{})
+			if r["open"] != false || payers[0].(map[string]interface{})["status"] != string(payrequest.PayerStatusPaid) {
+				t.Fatalf("expecting paid request, got %v", r)
+			}
+		})
+
+		t.Run("decline paid request, should fail", func(t *testing.T) {
+			req := buildAuthenticatedRequest(t, http.MethodPost, baseUrl+"/api/v1/wallet/payment-requests/"+id+"/decline", tokens[payer], nil)
+			res, err := server.Client().Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.StatusCode != http.StatusBadRequest {
+				t.Fatalf("expecting status %v, got %v", http.StatusBadRequest, res.StatusCode)
+			}
+		})
+	})
+
+	t.Run("create request with uneven shares, should fail", func(t *testing.T) {
+		form := url.Values{}
+		form.Set("amount", "20000")
+		form.Add("payer_xid", payer)
+		form.Add("share", "15000")
+		req := buildAuthenticatedRequest(t, http.MethodPost, baseUrl+"/api/v1/wallet/payment-requests", tokens[requester], bytes.NewBufferString(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		res, err := server.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.StatusCode != http.StatusBadRequest {
+			t.Fatalf("expecting status %v, got %v", http.StatusBadRequest, res.StatusCode)
+		}
+	})
+}
+
+func decodeRequest(t *testing.T, res *http.Response) map[string]interface{} {
+	var response httphelper.Response
+	err := json.NewDecoder(res.Body).Decode(&response)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return response.Data.(map[string]interface{})["payment_request"].(map[string]interface{})
+}
+
+func buildAuthenticatedRequest(t *testing.T, method string, url string, token string, body io.Reader) *http.Request {
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Add("Authorization", fmt.Sprintf("Token %s", token))
+	return req
+}
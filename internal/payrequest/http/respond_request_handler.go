@@ -0,0 +1,57 @@
+package http
+
+import (
+	"context"
+	"julo/internal/auth"
+	httphelper "julo/internal/http"
+	"julo/internal/payrequest"
+	"julo/internal/wallet"
+	"net/http"
+
+	"github.com/go-chi/chi"
+)
+
+type requestAnswer func(context.Context, payrequest.RequestParam) (*payrequest.PaymentRequest, error)
+
+// PayRequestHandler and DeclineRequestHandler answer, for the authenticated
+// payer, the payment request identified by the "id" url parameter.
+func PayRequestHandler(requests payrequest.Service) http.Handler {
+	return respondRequestHandler(requests.PayRequest)
+}
+
+func DeclineRequestHandler(requests payrequest.Service) http.Handler {
+	return respondRequestHandler(requests.DeclineRequest)
+}
+
+func respondRequestHandler(answer requestAnswer) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var response httphelper.Response
+		session := auth.SessionFromContext(r.Context())
+		if session == nil {
+			httphelper.WriteErrorJSON(w, http.StatusUnauthorized, auth.ErrSessionNotFound)
+			return
+		}
+
+		req, err := answer(r.Context(), payrequest.RequestParam{
+			XID:       session.Account.XID,
+			RequestID: chi.URLParam(r, "id"),
+		})
+		if err != nil {
+			switch err {
+			case payrequest.ErrRequestNotFound:
+				httphelper.WriteErrorJSON(w, http.StatusNotFound, err)
+			case payrequest.ErrNotPending, wallet.ErrInsufficientBalance, wallet.ErrWalletDisabled, wallet.ErrWalletNotFound:
+				httphelper.WriteErrorJSON(w, http.StatusBadRequest, err)
+			default:
+				httphelper.WriteErrorJSON(w, http.StatusInternalServerError, err)
+			}
+			return
+		}
+
+		response.Status = "success"
+		response.Data = map[string]interface{}{
+			"payment_request": newRequestResponse(*req),
+		}
+		httphelper.WriteJSON(w, http.StatusOK, response)
+	})
+}
@@ -0,0 +1,59 @@
+package http
+
+import (
+	"julo/internal/payrequest"
+	"time"
+)
+
+type payerResponse struct {
+	XID           string    `json:"xid"`
+	Amount        int       `json:"amount"`
+	Status        string    `json:"status"`
+	TransactionID string    `json:"transaction_id,omitempty"`
+	RespondedAt   time.Time `json:"responded_at"`
+}
+
+type requestResponse struct {
+	ID          string          `json:"id"`
+	RequestedBy string          `json:"requested_by"`
+	Amount      int             `json:"amount"`
+	Paid        int             `json:"paid"`
+	Description string          `json:"description"`
+	Open        bool            `json:"open"`
+	Payers      []payerResponse `json:"payers"`
+	ExpiresAt   time.Time       `json:"expires_at"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+func newRequestResponse(r payrequest.PaymentRequest) requestResponse {
+	payers := make([]payerResponse, 0, len(r.Payers))
+	for _, p := range r.Payers {
+		payers = append(payers, payerResponse{
+			XID:           p.XID,
+			Amount:        p.Amount,
+			Status:        string(p.Status),
+			TransactionID: p.TransactionID,
+			RespondedAt:   p.RespondedAt,
+		})
+	}
+
+	return requestResponse{
+		ID:          r.ID,
+		RequestedBy: r.RequesterXID,
+		Amount:      r.Amount,
+		Paid:        r.Paid(),
+		Description: r.Description,
+		Open:        r.IsOpen(),
+		Payers:      payers,
+		ExpiresAt:   r.ExpiresAt,
+		CreatedAt:   r.CreatedAt,
+	}
+}
+
+func newRequestsResponse(requests []payrequest.PaymentRequest) []requestResponse {
+	data := make([]requestResponse, 0, len(requests))
+	for _, r := range requests {
+		data = append(data, newRequestResponse(r))
+	}
+	return data
+}
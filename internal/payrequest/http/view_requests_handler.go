@@ -0,0 +1,72 @@
+package http
+
+import (
+	"julo/internal/auth"
+	httphelper "julo/internal/http"
+	"julo/internal/payrequest"
+	"net/http"
+
+	"github.com/go-chi/chi"
+)
+
+// ViewRequestsHandler lists the payment requests the customer sent and the
+// ones addressed to them.
+func ViewRequestsHandler(requests payrequest.Service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var response httphelper.Response
+		session := auth.SessionFromContext(r.Context())
+		if session == nil {
+			httphelper.WriteErrorJSON(w, http.StatusUnauthorized, auth.ErrSessionNotFound)
+			return
+		}
+
+		sent, err := requests.GetSentRequests(r.Context(), session.Account.XID)
+		if err != nil {
+			httphelper.WriteErrorJSON(w, http.StatusInternalServerError, err)
+			return
+		}
+		received, err := requests.GetReceivedRequests(r.Context(), session.Account.XID)
+		if err != nil {
+			httphelper.WriteErrorJSON(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		response.Status = "success"
+		response.Data = map[string]interface{}{
+			"sent":     newRequestsResponse(sent),
+			"received": newRequestsResponse(received),
+		}
+		httphelper.WriteJSON(w, http.StatusOK, response)
+	})
+}
+
+func ViewRequestHandler(requests payrequest.Service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var response httphelper.Response
+		session := auth.SessionFromContext(r.Context())
+		if session == nil {
+			httphelper.WriteErrorJSON(w, http.StatusUnauthorized, auth.ErrSessionNotFound)
+			return
+		}
+
+		req, err := requests.GetRequest(r.Context(), payrequest.RequestParam{
+			XID:       session.Account.XID,
+			RequestID: chi.URLParam(r, "id"),
+		})
+		if err != nil {
+			switch err {
+			case payrequest.ErrRequestNotFound:
+				httphelper.WriteErrorJSON(w, http.StatusNotFound, err)
+			default:
+				httphelper.WriteErrorJSON(w, http.StatusInternalServerError, err)
+			}
+			return
+		}
+
+		response.Status = "success"
+		response.Data = map[string]interface{}{
+			"payment_request": newRequestResponse(*req),
+		}
+		httphelper.WriteJSON(w, http.StatusOK, response)
+	})
+}
@@ -0,0 +1,125 @@
+package payrequest
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+type PayerStatus string
+
+var (
+	PayerStatusPending  = PayerStatus("pending")
+	PayerStatusPaid     = PayerStatus("paid")
+	PayerStatusDeclined = PayerStatus("declined")
+	PayerStatusExpired  = PayerStatus("expired")
+)
+
+type Payer struct {
+	XID    string
+	Amount int
+	Status PayerStatus
+	// TransactionID is the transfer_out leg of the payer's payment.
+	TransactionID string
+	RespondedAt   time.Time
+}
+
+// PaymentRequest asks one or more payers to pay their share of Amount to the
+// requester.
+type PaymentRequest struct {
+	ID           string
+	RequesterXID string
+	Amount       int
+	Description  string
+	Payers       []Payer
+	ExpiresAt    time.Time
+	CreatedAt    time.Time
+}
+
+// IsOpen reports whether some payer has yet to respond.
+func (r PaymentRequest) IsOpen() bool {
+	for _, p := range r.Payers {
+		if p.Status == PayerStatusPending {
+			return true
+		}
+	}
+	return false
+}
+
+// Paid is the part of Amount paid so far.
+func (r PaymentRequest) Paid() int {
+	var paid int
+	for _, p := range r.Payers {
+		if p.Status == PayerStatusPaid {
+			paid += p.Amount
+		}
+	}
+	return paid
+}
+
+type Repository interface {
+	SaveRequest(ctx context.Context, r PaymentRequest) error
+	GetRequest(ctx context.Context, id string) (*PaymentRequest, error)
+	GetRequestsByRequester(ctx context.Context, xid string) ([]PaymentRequest, error)
+	GetRequestsByPayer(ctx context.Context, xid string) ([]PaymentRequest, error)
+}
+
+type InMemoryRepository struct {
+	store sync.Map
+}
+
+func NewInMemoryRepository() Repository {
+	return &InMemoryRepository{}
+}
+
+func (r *InMemoryRepository) SaveRequest(ctx context.Context, req PaymentRequest) error {
+	req.Payers = append([]Payer(nil), req.Payers...)
+	r.store.Store(req.ID, &req)
+	return nil
+}
+
+func (r *InMemoryRepository) GetRequest(ctx context.Context, id string) (*PaymentRequest, error) {
+	v, ok := r.store.Load(id)
+	if !ok {
+		return nil, ErrRequestNotFound
+	}
+	return copyRequest(*v.(*PaymentRequest)), nil
+}
+
+func (r *InMemoryRepository) GetRequestsByRequester(ctx context.Context, xid string) ([]PaymentRequest, error) {
+	return r.filter(func(req *PaymentRequest) bool {
+		return req.RequesterXID == xid
+	}), nil
+}
+
+func (r *InMemoryRepository) GetRequestsByPayer(ctx context.Context, xid string) ([]PaymentRequest, error) {
+	return r.filter(func(req *PaymentRequest) bool {
+		for _, p := range req.Payers {
+			if p.XID == xid {
+				return true
+			}
+		}
+		return false
+	}), nil
+}
+
+func (r *InMemoryRepository) filter(match func(*PaymentRequest) bool) []PaymentRequest {
+	requests := []PaymentRequest{}
+	r.store.Range(func(key, value any) bool {
+		req := value.(*PaymentRequest)
+		if match(req) {
+			requests = append(requests, *copyRequest(*req))
+		}
+		return true
+	})
+	sort.Slice(requests, func(i, j int) bool {
+		return requests[i].CreatedAt.Before(requests[j].CreatedAt)
+	})
+	return requests
+}
+
+func copyRequest(r PaymentRequest) *PaymentRequest {
+	r.Payers = append([]Payer(nil), r.Payers...)
+	return &r
+}
@@ -0,0 +1,282 @@
+package payrequest
+
+import (
+	"context"
+	"julo/internal/wallet"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+const defaultExpiry = 7 * 24 * time.Hour
+
+type CreateRequestParam struct {
+	RequesterXID string
+	Amount       int
+	Description  string
+	PayerXIDs    []string
+	// Shares are the amounts each payer owes, in the order of PayerXIDs.
+	// Without shares the amount is split equally.
+	Shares []int
+	// ExpiresAt defaults to a week after the request is made.
+	ExpiresAt time.Time
+}
+
+func (p CreateRequestParam) Validate() error {
+	if p.RequesterXID == "" || len(p.PayerXIDs) == 0 {
+		return ErrMissingRequiredParameter
+	}
+	if p.Amount < len(p.PayerXIDs) {
+		return ErrInvalidAmount
+	}
+
+	seen := map[string]bool{}
+	for _, xid := range p.PayerXIDs {
+		if xid == "" {
+			return ErrMissingRequiredParameter
+		}
+		if xid == p.RequesterXID {
+			return ErrSelfRequest
+		}
+		if seen[xid] {
+			return ErrDuplicatePayer
+		}
+		seen[xid] = true
+	}
+
+	if len(p.Shares) > 0 {
+		if len(p.Shares) != len(p.PayerXIDs) {
+			return ErrInvalidShares
+		}
+		var total int
+		for _, share := range p.Shares {
+			if share <= 0 {
+				return ErrInvalidShares
+			}
+			total += share
+		}
+		if total != p.Amount {
+			return ErrInvalidShares
+		}
+	}
+	return nil
+}
+
+type RequestParam struct {
+	XID       string
+	RequestID string
+}
+
+type Service interface {
+	CreateRequest(ctx context.Context, param CreateRequestParam) (*PaymentRequest, error)
+	GetSentRequests(ctx context.Context, xid string) ([]PaymentRequest, error)
+	GetReceivedRequests(ctx context.Context, xid string) ([]PaymentRequest, error)
+	GetRequest(ctx context.Context, param RequestParam) (*PaymentRequest, error)
+	PayRequest(ctx context.Context, param RequestParam) (*PaymentRequest, error)
+	DeclineRequest(ctx context.Context, param RequestParam) (*PaymentRequest, error)
+}
+
+type service struct {
+	repo    Repository
+	wallets wallet.Service
+	mu      sync.Mutex
+}
+
+func NewService(repo Repository, wallets wallet.Service) Service {
+	return &service{
+		repo:    repo,
+		wallets: wallets,
+	}
+}
+
+func (s *service) CreateRequest(ctx context.Context, param CreateRequestParam) (*PaymentRequest, error) {
+	err := param.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if param.ExpiresAt.IsZero() {
+		param.ExpiresAt = now.Add(defaultExpiry)
+	} else if !param.ExpiresAt.After(now) {
+		return nil, ErrInvalidExpiry
+	}
+
+	for _, xid := range append([]string{param.RequesterXID}, param.PayerXIDs...) {
+		_, err = s.wallets.GetWalletByXID(ctx, xid)
+		if err != nil && err == wallet.ErrWalletNotFound {
+			return nil, wallet.ErrWalletNotFound
+		} else if err != nil {
+			return nil, errors.Wrap(err, "failed getting wallet")
+		}
+	}
+
+	shares := param.Shares
+	if len(shares) == 0 {
+		shares = splitEqually(param.Amount, len(param.PayerXIDs))
+	}
+	payers := make([]Payer, len(param.PayerXIDs))
+	for i, xid := range param.PayerXIDs {
+		payers[i] = Payer{
+			XID:    xid,
+			Amount: shares[i],
+			Status: PayerStatusPending,
+		}
+	}
+
+	r := PaymentRequest{
+		ID:           uuid.NewString(),
+		RequesterXID: param.RequesterXID,
+		Amount:       param.Amount,
+		Description:  param.Description,
+		Payers:       payers,
+		ExpiresAt:    param.ExpiresAt,
+		CreatedAt:    now,
+	}
+	err = s.repo.SaveRequest(ctx, r)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed saving payment request")
+	}
+
+	return &r, nil
+}
+
+func (s *service) GetSentRequests(ctx context.Context, xid string) ([]PaymentRequest, error) {
+	requests, err := s.repo.GetRequestsByRequester(ctx, xid)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed getting payment requests")
+	}
+	return expireAll(requests, time.Now()), nil
+}
+
+func (s *service) GetReceivedRequests(ctx context.Context, xid string) ([]PaymentRequest, error) {
+	requests, err := s.repo.GetRequestsByPayer(ctx, xid)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed getting payment requests")
+	}
+	return expireAll(requests, time.Now()), nil
+}
+
+// GetRequest returns the request to its requester or any of its payers.
+func (s *service) GetRequest(ctx context.Context, param RequestParam) (*PaymentRequest, error) {
+	r, err := s.getRequest(ctx, param.RequestID)
+	if err != nil {
+		return nil, err
+	}
+	if r.RequesterXID != param.XID && payer(r, param.XID) == nil {
+		return nil, ErrRequestNotFound
+	}
+	return r, nil
+}
+
+// PayRequest transfers the payer's share to the requester.
+func (s *service) PayRequest(ctx context.Context, param RequestParam) (*PaymentRequest, error) {
+	return s.respond(ctx, param, func(r *PaymentRequest, p *Payer) error {
+		result, err := s.wallets.TransferWallet(ctx, wallet.TransferWalletParam{
+			ActorXID:    p.XID,
+			FromXID:     p.XID,
+			ToXID:       r.RequesterXID,
+			ReferenceID: wallet.SystemReferencePrefix + "payreq-" + r.ID,
+			Amount:      p.Amount,
+		})
+		if err != nil {
+			return err
+		}
+
+		p.Status = PayerStatusPaid
+		p.TransactionID = result.Debit.ID
+		return nil
+	})
+}
+
+func (s *service) DeclineRequest(ctx context.Context, param RequestParam) (*PaymentRequest, error) {
+	return s.respond(ctx, param, func(r *PaymentRequest, p *Payer) error {
+		p.Status = PayerStatusDeclined
+		return nil
+	})
+}
+
+// respond applies the answer of the payer in param to the request, as long
+// as the payer's share is still pending.
+func (s *service) respond(ctx context.Context, param RequestParam, answer func(*PaymentRequest, *Payer) error) (*PaymentRequest, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, err := s.getRequest(ctx, param.RequestID)
+	if err != nil {
+		return nil, err
+	}
+	p := payer(r, param.XID)
+	if p == nil {
+		return nil, ErrRequestNotFound
+	}
+	if p.Status != PayerStatusPending {
+		return nil, ErrNotPending
+	}
+
+	err = answer(r, p)
+	if err != nil {
+		return nil, err
+	}
+	p.RespondedAt = time.Now()
+
+	err = s.repo.SaveRequest(ctx, *r)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed saving payment request")
+	}
+	return r, nil
+}
+
+func (s *service) getRequest(ctx context.Context, id string) (*PaymentRequest, error) {
+	r, err := s.repo.GetRequest(ctx, id)
+	if err != nil && err == ErrRequestNotFound {
+		return nil, ErrRequestNotFound
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed getting payment request")
+	}
+	expire(r, time.Now())
+	return r, nil
+}
+
+// expire marks the shares still pending past the expiry of r as expired.
+func expire(r *PaymentRequest, now time.Time) {
+	if now.Before(r.ExpiresAt) {
+		return
+	}
+	for i := range r.Payers {
+		if r.Payers[i].Status == PayerStatusPending {
+			r.Payers[i].Status = PayerStatusExpired
+		}
+	}
+}
+
+func expireAll(requests []PaymentRequest, now time.Time) []PaymentRequest {
+	for i := range requests {
+		expire(&requests[i], now)
+	}
+	return requests
+}
+
+func payer(r *PaymentRequest, xid string) *Payer {
+	for i := range r.Payers {
+		if r.Payers[i].XID == xid {
+			return &r.Payers[i]
+		}
+	}
+	return nil
+}
+
+// splitEqually splits amount in n shares, the first ones taking one more
+// minor unit each when it does not divide evenly.
+func splitEqually(amount int, n int) []int {
+	shares := make([]int, n)
+	for i := range shares {
+		shares[i] = amount / n
+		if i < amount%n {
+			shares[i]++
+		}
+	}
+	return shares
+}
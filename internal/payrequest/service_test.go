@@ -0,0 +1,149 @@
+package payrequest_test
+
+import (
+	"context"
+	"julo/internal/payrequest"
+	"julo/internal/wallet"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestPaymentRequest(t *testing.T) {
+	ctx := context.Background()
+	wallets := wallet.NewService(wallet.NewInMemoryRepository())
+	requests := payrequest.NewService(payrequest.NewInMemoryRepository(), wallets)
+
+	requester, alice, bob, carol := uuid.NewString(), uuid.NewString(), uuid.NewString(), uuid.NewString()
+	for _, xid := range []string{requester, alice, bob, carol} {
+		_, err := wallets.EnableWallet(ctx, wallet.EnableWalletParam{
+			OwnerXID: xid,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = wallets.DepositWallet(ctx, wallet.WalletTransactionParam{
+			ActorXID:    xid,
+			OwnerXID:    xid,
+			ReferenceID: uuid.NewString(),
+			Amount:      50000,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	r, err := requests.CreateRequest(ctx, payrequest.CreateRequestParam{
+		RequesterXID: requester,
+		Amount:       100000,
+		Description:  "dinner",
+		PayerXIDs:    []string{alice, bob, carol},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Payers[0].Amount != 33334 || r.Payers[1].Amount != 33333 || r.Payers[2].Amount != 33333 {
+		t.Fatalf("expecting an equal split, got %+v", r.Payers)
+	}
+
+	r, err = requests.PayRequest(ctx, payrequest.RequestParam{XID: alice, RequestID: r.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = requests.PayRequest(ctx, payrequest.RequestParam{XID: alice, RequestID: r.ID})
+	if err != payrequest.ErrNotPending {
+		t.Fatalf("expecting error %s, got %v", payrequest.ErrNotPending, err)
+	}
+	_, err = requests.DeclineRequest(ctx, payrequest.RequestParam{XID: bob, RequestID: r.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = requests.PayRequest(ctx, payrequest.RequestParam{XID: uuid.NewString(), RequestID: r.ID})
+	if err != payrequest.ErrRequestNotFound {
+		t.Fatalf("expecting error %s, got %v", payrequest.ErrRequestNotFound, err)
+	}
+
+	r, err = requests.GetRequest(ctx, payrequest.RequestParam{XID: requester, RequestID: r.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	statuses := []payrequest.PayerStatus{payrequest.PayerStatusPaid, payrequest.PayerStatusDeclined, payrequest.PayerStatusPending}
+	for i, status := range statuses {
+		if r.Payers[i].Status != status {
+			t.Fatalf("expecting payer %d %s, got %s", i, status, r.Payers[i].Status)
+		}
+	}
+	if !r.IsOpen() || r.Paid() != 33334 {
+		t.Fatalf("expecting open request with 33334 paid, got %+v", r)
+	}
+
+	for xid, expected := range map[string]int{requester: 83334, alice: 16666, bob: 50000} {
+		wal, _ := wallets.GetWalletByXID(ctx, xid)
+		if wal.Balance != expected {
+			t.Fatalf("expecting balance %d, got %d", expected, wal.Balance)
+		}
+	}
+}
+
+func TestPaymentRequestShares(t *testing.T) {
+	ctx := context.Background()
+	wallets := wallet.NewService(wallet.NewInMemoryRepository())
+	requests := payrequest.NewService(payrequest.NewInMemoryRepository(), wallets)
+
+	requester, payer := uuid.NewString(), uuid.NewString()
+	for _, xid := range []string{requester, payer} {
+		_, err := wallets.EnableWallet(ctx, wallet.EnableWalletParam{
+			OwnerXID: xid,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	_, err := requests.CreateRequest(ctx, payrequest.CreateRequestParam{
+		RequesterXID: requester,
+		Amount:       10000,
+		PayerXIDs:    []string{payer, requester},
+	})
+	if err != payrequest.ErrSelfRequest {
+		t.Fatalf("expecting error %s, got %v", payrequest.ErrSelfRequest, err)
+	}
+	_, err = requests.CreateRequest(ctx, payrequest.CreateRequestParam{
+		RequesterXID: requester,
+		Amount:       10000,
+		PayerXIDs:    []string{payer},
+		Shares:       []int{9000},
+	})
+	if err != payrequest.ErrInvalidShares {
+		t.Fatalf("expecting error %s, got %v", payrequest.ErrInvalidShares, err)
+	}
+
+	r, err := requests.CreateRequest(ctx, payrequest.CreateRequestParam{
+		RequesterXID: requester,
+		Amount:       10000,
+		PayerXIDs:    []string{payer},
+		Shares:       []int{10000},
+		ExpiresAt:    time.Now().Add(50 * time.Millisecond),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = requests.PayRequest(ctx, payrequest.RequestParam{XID: payer, RequestID: r.ID})
+	if err != wallet.ErrInsufficientBalance {
+		t.Fatalf("expecting error %s, got %v", wallet.ErrInsufficientBalance, err)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	received, err := requests.GetReceivedRequests(ctx, payer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(received) != 1 || received[0].Payers[0].Status != payrequest.PayerStatusExpired {
+		t.Fatalf("expecting the request expired, got %+v", received)
+	}
+	_, err = requests.DeclineRequest(ctx, payrequest.RequestParam{XID: payer, RequestID: r.ID})
+	if err != payrequest.ErrNotPending {
+		t.Fatalf("expecting error %s, got %v", payrequest.ErrNotPending, err)
+	}
+}
@@ -0,0 +1,13 @@
+package pocket
+
+import "errors"
+
+var (
+	ErrPocketNotFound           = errors.New("pocket not found")
+	ErrPocketClosed             = errors.New("pocket is closed")
+	ErrDuplicatePocketName      = errors.New("pocket name already in use")
+	ErrMissingRequiredParameter = errors.New("missing required parameter")
+	ErrInvalidAmount            = errors.New("invalid amount")
+	ErrInvalidTarget            = errors.New("invalid target amount")
+	ErrInsufficientBalance      = errors.New("insufficient pocket balance")
+)
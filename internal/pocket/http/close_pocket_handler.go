@@ -0,0 +1,46 @@
+package http
+
+import (
+	"julo/internal/auth"
+	httphelper "julo/internal/http"
+	"julo/internal/pocket"
+	"julo/internal/wallet"
+	"net/http"
+
+	"github.com/go-chi/chi"
+)
+
+// ClosePocketHandler closes the pocket identified by the "id" url parameter,
+// sweeping its balance back to the main wallet.
+func ClosePocketHandler(pockets pocket.Service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var response httphelper.Response
+		session := auth.SessionFromContext(r.Context())
+		if session == nil {
+			httphelper.WriteErrorJSON(w, http.StatusUnauthorized, auth.ErrSessionNotFound)
+			return
+		}
+
+		p, err := pockets.ClosePocket(r.Context(), pocket.PocketParam{
+			OwnerXID: session.Account.XID,
+			PocketID: chi.URLParam(r, "id"),
+		})
+		if err != nil {
+			switch err {
+			case pocket.ErrPocketNotFound:
+				httphelper.WriteErrorJSON(w, http.StatusNotFound, err)
+			case pocket.ErrPocketClosed, wallet.ErrWalletDisabled:
+				httphelper.WriteErrorJSON(w, http.StatusBadRequest, err)
+			default:
+				httphelper.WriteErrorJSON(w, http.StatusInternalServerError, err)
+			}
+			return
+		}
+
+		response.Status = "success"
+		response.Data = map[string]interface{}{
+			"pocket": newPocketResponse(*p),
+		}
+		httphelper.WriteJSON(w, http.StatusOK, response)
+	})
+}
@@ -0,0 +1,52 @@
+package http
+
+import (
+	"julo/internal/auth"
+	httphelper "julo/internal/http"
+	"julo/internal/pocket"
+	"julo/internal/wallet"
+	"net/http"
+)
+
+func CreatePocketHandler(pockets pocket.Service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var response httphelper.Response
+		session := auth.SessionFromContext(r.Context())
+		if session == nil {
+			httphelper.WriteErrorJSON(w, http.StatusUnauthorized, auth.ErrSessionNotFound)
+			return
+		}
+
+		var req createPocketRequest
+		if err := httphelper.Decode(w, r, &req); err != nil {
+			httphelper.WriteRequestErrorJSON(w, err)
+			return
+		}
+
+		p, err := pockets.CreatePocket(r.Context(), pocket.CreatePocketParam{
+			OwnerXID: session.Account.XID,
+			Name:     req.Name,
+			Target:   req.Target,
+		})
+		if err != nil {
+			switch err {
+			case pocket.ErrMissingRequiredParameter, pocket.ErrInvalidTarget, pocket.ErrDuplicatePocketName, wallet.ErrWalletNotFound:
+				httphelper.WriteErrorJSON(w, http.StatusBadRequest, err)
+			default:
+				httphelper.WriteErrorJSON(w, http.StatusInternalServerError, err)
+			}
+			return
+		}
+
+		response.Status = "success"
+		response.Data = map[string]interface{}{
+			"pocket": newPocketResponse(*p),
+		}
+		httphelper.WriteJSON(w, http.StatusCreated, response)
+	})
+}
+
+type createPocketRequest struct {
+	Name   string `json:"name" validate:"required"`
+	Target int    `json:"target"`
+}
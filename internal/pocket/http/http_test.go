@@ -0,0 +1,193 @@
+package http_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"julo/internal/account"
+	"julo/internal/auth"
+	authhttp "julo/internal/auth/http"
+	httphelper "julo/internal/http"
+	"julo/internal/pocket"
+	pockethttp "julo/internal/pocket/http"
+	"julo/internal/wallet"
+	wallethttp "julo/internal/wallet/http"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/google/uuid"
+)
+
+func TestPockets(t *testing.T) {
+	ctx := context.Background()
+	wallets := wallet.NewService(wallet.NewInMemoryRepository())
+	pockets := pocket.NewService(pocket.NewInMemoryRepository(), wallets)
+
+	router := chi.NewRouter()
+	router.Mount("/api/v1", router.Group(func(r chi.Router) {
+		r.Mount("/wallet", r.Group(func(r chi.Router) {
+			r.Use(authhttp.Middleware)
+			r.Get("/transactions", wallethttp.ViewWalletTransactionsHandler(wallets).ServeHTTP)
+			r.Get("/pockets", pockethttp.ViewPocketsHandler(pockets).ServeHTTP)
+			r.Post("/pockets", pockethttp.CreatePocketHandler(pockets).ServeHTTP)
+			r.Post("/pockets/{id}/deposits", pockethttp.MoveToPocketHandler(pockets).ServeHTTP)
+			r.Post("/pockets/{id}/withdrawals", pockethttp.MoveFromPocketHandler(pockets).ServeHTTP)
+			r.Delete("/pockets/{id}", pockethttp.ClosePocketHandler(pockets).ServeHTTP)
+		}))
+	}))
+	server := httptest.NewServer(router)
+	defer server.Close()
+	baseUrl := server.URL
+
+	owner := uuid.NewString()
+	_, err := wallets.EnableWallet(ctx, wallet.EnableWalletParam{
+		OwnerXID: owner,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = wallets.DepositWallet(ctx, wallet.WalletTransactionParam{
+		ActorXID:    owner,
+		OwnerXID:    owner,
+		ReferenceID: uuid.NewString(),
+		Amount:      100000,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := uuid.NewString()
+	err = auth.StoreSession(ctx, auth.Session{
+		Token:   token,
+		Account: account.Account{XID: owner},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("create pocket, should success", func(t *testing.T) {
+		form := url.Values{}
+		form.Set("name", "holiday")
+		form.Set("target", "500000")
+		res := do(t, server, buildAuthenticatedRequest(t, http.MethodPost, baseUrl+"/api/v1/wallet/pockets", token, form))
+		if res.StatusCode != http.StatusCreated {
+			t.Fatalf("expecting status %v, got %v", http.StatusCreated, res.StatusCode)
+		}
+		id := decode(t, res)["pocket"].(map[string]interface{})["id"].(string)
+
+		t.Run("move to pocket, should success", func(t *testing.T) {
+			form := url.Values{}
+			form.Set("amount", "30000")
+			form.Set("reference_id", uuid.NewString())
+			res := do(t, server, buildAuthenticatedRequest(t, http.MethodPost, baseUrl+"/api/v1/wallet/pockets/"+id+"/deposits", token, form))
+			if res.StatusCode != http.StatusCreated {
+				t.Fatalf("expecting status %v, got %v", http.StatusCreated, res.StatusCode)
+			}
+			p := decode(t, res)["pocket"].(map[string]interface{})
+			if p["balance"] != float64(30000) {
+				t.Fatalf("expecting pocket balance 30000, got %v", p["balance"])
+			}
+		})
+
+		t.Run("move to pocket with json, should decode like a form", func(t *testing.T) {
+			move := func(body string) *http.Response {
+				req := buildAuthenticatedRequest(t, http.MethodPost, baseUrl+"/api/v1/wallet/pockets/"+id+"/deposits", token, nil)
+				req.Body = io.NopCloser(bytes.NewBufferString(body))
+				req.Header.Set("Content-Type", "application/json")
+				return do(t, server, req)
+			}
+
+			res := move(`{"reference_id": "` + uuid.NewString() + `", "amount": null}`)
+			if res.StatusCode != http.StatusBadRequest {
+				t.Fatalf("expecting status %v, got %v", http.StatusBadRequest, res.StatusCode)
+			}
+			errs := decode(t, res)["error"].(map[string]interface{})
+			if errs["amount"] != httphelper.MessageRequired {
+				t.Fatalf("expecting amount required, got %v", errs)
+			}
+
+			res = move(`{"reference_id": "` + uuid.NewString() + `", "amount": 5000}`)
+			if res.StatusCode != http.StatusCreated {
+				t.Fatalf("expecting status %v, got %v", http.StatusCreated, res.StatusCode)
+			}
+			p := decode(t, res)["pocket"].(map[string]interface{})
+			if p["balance"] != float64(35000) {
+				t.Fatalf("expecting pocket balance 35000, got %v", p["balance"])
+			}
+		})
+
+		t.Run("view pocket transactions, should list moves", func(t *testing.T) {
+			res := do(t, server, buildAuthenticatedRequest(t, http.MethodGet, baseUrl+"/api/v1/wallet/transactions?pocket_id="+id, token, nil))
+			if res.StatusCode != http.StatusOK {
+				t.Fatalf("expecting status %v, got %v", http.StatusOK, res.StatusCode)
+			}
+			transactions := decode(t, res)["transactions"].([]interface{})
+			if len(transactions) != 2 || transactions[0].(map[string]interface{})["pocket_id"] != id {
+				t.Fatalf("expecting the moves to the pocket, got %v", transactions)
+			}
+		})
+
+		t.Run("close pocket, should sweep balance", func(t *testing.T) {
+			res := do(t, server, buildAuthenticatedRequest(t, http.MethodDelete, baseUrl+"/api/v1/wallet/pockets/"+id, token, nil))
+			if res.StatusCode != http.StatusOK {
+				t.Fatalf("expecting status %v, got %v", http.StatusOK, res.StatusCode)
+			}
+			wal, _ := wallets.GetWalletByXID(ctx, owner)
+			if wal.Balance != 100000 {
+				t.Fatalf("expecting balance 100000, got %d", wal.Balance)
+			}
+
+			res = do(t, server, buildAuthenticatedRequest(t, http.MethodDelete, baseUrl+"/api/v1/wallet/pockets/"+id, token, nil))
+			if res.StatusCode != http.StatusBadRequest {
+				t.Fatalf("expecting status %v, got %v", http.StatusBadRequest, res.StatusCode)
+			}
+		})
+	})
+
+	t.Run("move to unknown pocket, should fail", func(t *testing.T) {
+		form := url.Values{}
+		form.Set("amount", "30000")
+		form.Set("reference_id", uuid.NewString())
+		res := do(t, server, buildAuthenticatedRequest(t, http.MethodPost, baseUrl+"/api/v1/wallet/pockets/"+uuid.NewString()+"/deposits", token, form))
+		if res.StatusCode != http.StatusNotFound {
+			t.Fatalf("expecting status %v, got %v", http.StatusNotFound, res.StatusCode)
+		}
+	})
+}
+
+func do(t *testing.T, server *httptest.Server, req *http.Request) *http.Response {
+	res, err := server.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
+func decode(t *testing.T, res *http.Response) map[string]interface{} {
+	var response httphelper.Response
+	err := json.NewDecoder(res.Body).Decode(&response)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return response.Data.(map[string]interface{})
+}
+
+func buildAuthenticatedRequest(t *testing.T, method string, url string, token string, form url.Values) *http.Request {
+	var body io.Reader
+	if form != nil {
+		body = bytes.NewBufferString(form.Encode())
+	}
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Add("Authorization", fmt.Sprintf("Token %s", token))
+	if form != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	return req
+}
@@ -0,0 +1,71 @@
+package http
+
+import (
+	"context"
+	"julo/internal/auth"
+	httphelper "julo/internal/http"
+	"julo/internal/pocket"
+	"julo/internal/wallet"
+	"net/http"
+
+	"github.com/go-chi/chi"
+)
+
+type pocketMove func(context.Context, pocket.MovePocketParam) (*pocket.Pocket, error)
+
+// MoveToPocketHandler and MoveFromPocketHandler move amount between the main
+// wallet balance and the pocket identified by the "id" url parameter.
+func MoveToPocketHandler(pockets pocket.Service) http.Handler {
+	return movePocketHandler(pockets.MoveToPocket)
+}
+
+func MoveFromPocketHandler(pockets pocket.Service) http.Handler {
+	return movePocketHandler(pockets.MoveFromPocket)
+}
+
+func movePocketHandler(move pocketMove) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var response httphelper.Response
+		session := auth.SessionFromContext(r.Context())
+		if session == nil {
+			httphelper.WriteErrorJSON(w, http.StatusUnauthorized, auth.ErrSessionNotFound)
+			return
+		}
+
+		var req movePocketRequest
+		if err := httphelper.Decode(w, r, &req); err != nil {
+			httphelper.WriteRequestErrorJSON(w, err)
+			return
+		}
+
+		p, err := move(r.Context(), pocket.MovePocketParam{
+			OwnerXID:    session.Account.XID,
+			PocketID:    chi.URLParam(r, "id"),
+			ReferenceID: req.ReferenceID,
+			Amount:      req.Amount,
+		})
+		if err != nil {
+			switch err {
+			case pocket.ErrPocketNotFound:
+				httphelper.WriteErrorJSON(w, http.StatusNotFound, err)
+			case pocket.ErrMissingRequiredParameter, pocket.ErrInvalidAmount, pocket.ErrPocketClosed, pocket.ErrInsufficientBalance,
+				wallet.ErrInsufficientBalance, wallet.ErrWalletDisabled, wallet.ErrWalletNotFound, wallet.ErrDuplicateReference, wallet.ErrReservedReference:
+				httphelper.WriteErrorJSON(w, http.StatusBadRequest, err)
+			default:
+				httphelper.WriteErrorJSON(w, http.StatusInternalServerError, err)
+			}
+			return
+		}
+
+		response.Status = "success"
+		response.Data = map[string]interface{}{
+			"pocket": newPocketResponse(*p),
+		}
+		httphelper.WriteJSON(w, http.StatusCreated, response)
+	})
+}
+
+type movePocketRequest struct {
+	ReferenceID string `json:"reference_id" validate:"required"`
+	Amount      int    `json:"amount" validate:"required"`
+}
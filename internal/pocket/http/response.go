@@ -0,0 +1,30 @@
+package http
+
+import (
+	"julo/internal/pocket"
+	"time"
+)
+
+type pocketResponse struct {
+	ID        string    `json:"id"`
+	OwnedBy   string    `json:"owned_by"`
+	Name      string    `json:"name"`
+	Target    int       `json:"target"`
+	Balance   int       `json:"balance"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+	ClosedAt  time.Time `json:"closed_at"`
+}
+
+func newPocketResponse(p pocket.Pocket) pocketResponse {
+	return pocketResponse{
+		ID:        p.ID,
+		OwnedBy:   p.OwnerXID,
+		Name:      p.Name,
+		Target:    p.Target,
+		Balance:   p.Balance,
+		Status:    string(p.Status),
+		CreatedAt: p.CreatedAt,
+		ClosedAt:  p.ClosedAt,
+	}
+}
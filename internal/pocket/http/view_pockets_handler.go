@@ -0,0 +1,40 @@
+package http
+
+import (
+	"julo/internal/auth"
+	httphelper "julo/internal/http"
+	"julo/internal/pocket"
+	"julo/internal/wallet"
+	"net/http"
+)
+
+func ViewPocketsHandler(pockets pocket.Service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var response httphelper.Response
+		session := auth.SessionFromContext(r.Context())
+		if session == nil {
+			httphelper.WriteErrorJSON(w, http.StatusUnauthorized, auth.ErrSessionNotFound)
+			return
+		}
+
+		result, err := pockets.GetPockets(r.Context(), session.Account.XID)
+		if err != nil && err == wallet.ErrWalletNotFound {
+			httphelper.WriteErrorJSON(w, http.StatusBadRequest, wallet.ErrWalletDisabled)
+			return
+		} else if err != nil {
+			httphelper.WriteErrorJSON(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		data := make([]pocketResponse, 0, len(result))
+		for _, p := range result {
+			data = append(data, newPocketResponse(p))
+		}
+
+		response.Status = "success"
+		response.Data = map[string]interface{}{
+			"pockets": data,
+		}
+		httphelper.WriteJSON(w, http.StatusOK, response)
+	})
+}
@@ -0,0 +1,80 @@
+package pocket
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+type PocketStatus string
+
+var (
+	PocketStatusActive = PocketStatus("active")
+	PocketStatusClosed = PocketStatus("closed")
+)
+
+// Pocket is money set aside from the main wallet of OwnerXID under a name,
+// optionally towards a target amount.
+type Pocket struct {
+	ID       string
+	OwnerXID string
+	Name     string
+	Target   int
+	// Balance is derived from the wallet transactions moving money to and
+	// from the pocket, it is not stored.
+	Balance   int
+	Status    PocketStatus
+	CreatedAt time.Time
+	ClosedAt  time.Time
+}
+
+type Repository interface {
+	CreatePocket(ctx context.Context, p Pocket) error
+	UpdatePocket(ctx context.Context, p Pocket) error
+	GetPocket(ctx context.Context, id string) (*Pocket, error)
+	GetPocketsByOwner(ctx context.Context, ownerXID string) ([]Pocket, error)
+}
+
+type InMemoryRepository struct {
+	store sync.Map
+}
+
+func NewInMemoryRepository() Repository {
+	return &InMemoryRepository{}
+}
+
+func (r *InMemoryRepository) CreatePocket(ctx context.Context, p Pocket) error {
+	r.store.Store(p.ID, &p)
+	return nil
+}
+
+func (r *InMemoryRepository) UpdatePocket(ctx context.Context, p Pocket) error {
+	r.store.Store(p.ID, &p)
+	return nil
+}
+
+func (r *InMemoryRepository) GetPocket(ctx context.Context, id string) (*Pocket, error) {
+	v, ok := r.store.Load(id)
+	if !ok {
+		return nil, ErrPocketNotFound
+	}
+
+	p := *v.(*Pocket)
+	return &p, nil
+}
+
+func (r *InMemoryRepository) GetPocketsByOwner(ctx context.Context, ownerXID string) ([]Pocket, error) {
+	pockets := []Pocket{}
+	r.store.Range(func(key, value any) bool {
+		p := value.(*Pocket)
+		if p.OwnerXID == ownerXID {
+			pockets = append(pockets, *p)
+		}
+		return true
+	})
+	sort.Slice(pockets, func(i, j int) bool {
+		return pockets[i].CreatedAt.Before(pockets[j].CreatedAt)
+	})
+	return pockets, nil
+}
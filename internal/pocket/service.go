@@ -0,0 +1,319 @@
+package pocket
+
+import (
+	"context"
+	"julo/internal/wallet"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+type CreatePocketParam struct {
+	OwnerXID string
+	Name     string
+	Target   int
+}
+
+func (p CreatePocketParam) Validate() error {
+	if p.OwnerXID == "" || strings.TrimSpace(p.Name) == "" {
+		return ErrMissingRequiredParameter
+	}
+	if p.Target < 0 {
+		return ErrInvalidTarget
+	}
+	return nil
+}
+
+type PocketParam struct {
+	OwnerXID string
+	PocketID string
+}
+
+type MovePocketParam struct {
+	OwnerXID    string
+	PocketID    string
+	ReferenceID string
+	Amount      int
+}
+
+func (p MovePocketParam) Validate() error {
+	if p.OwnerXID == "" || p.PocketID == "" || p.ReferenceID == "" {
+		return ErrMissingRequiredParameter
+	}
+	if wallet.IsSystemReference(p.ReferenceID) {
+		return wallet.ErrReservedReference
+	}
+	if p.Amount <= 0 {
+		return ErrInvalidAmount
+	}
+	return nil
+}
+
+type Service interface {
+	CreatePocket(ctx context.Context, param CreatePocketParam) (*Pocket, error)
+	GetPockets(ctx context.Context, ownerXID string) ([]Pocket, error)
+	GetPocket(ctx context.Context, param PocketParam) (*Pocket, error)
+	MoveToPocket(ctx context.Context, param MovePocketParam) (*Pocket, error)
+	MoveFromPocket(ctx context.Context, param MovePocketParam) (*Pocket, error)
+	ClosePocket(ctx context.Context, param PocketParam) (*Pocket, error)
+}
+
+type service struct {
+	repo    Repository
+	wallets wallet.Service
+	// mu serializes moves out of pockets so two of them cannot both spend
+	// the same pocket balance.
+	mu sync.Mutex
+}
+
+func NewService(repo Repository, wallets wallet.Service) Service {
+	return &service{
+		repo:    repo,
+		wallets: wallets,
+	}
+}
+
+func (s *service) CreatePocket(ctx context.Context, param CreatePocketParam) (*Pocket, error) {
+	err := param.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.wallets.GetWalletByXID(ctx, param.OwnerXID)
+	if err != nil && err == wallet.ErrWalletNotFound {
+		return nil, wallet.ErrWalletNotFound
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed getting wallet")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pockets, err := s.repo.GetPocketsByOwner(ctx, param.OwnerXID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed getting pockets")
+	}
+	name := strings.TrimSpace(param.Name)
+	for _, p := range pockets {
+		if p.Status == PocketStatusActive && strings.EqualFold(p.Name, name) {
+			return nil, ErrDuplicatePocketName
+		}
+	}
+
+	p := Pocket{
+		ID:        uuid.NewString(),
+		OwnerXID:  param.OwnerXID,
+		Name:      name,
+		Target:    param.Target,
+		Status:    PocketStatusActive,
+		CreatedAt: time.Now(),
+	}
+	err = s.repo.CreatePocket(ctx, p)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed creating pocket")
+	}
+
+	return &p, nil
+}
+
+func (s *service) GetPockets(ctx context.Context, ownerXID string) ([]Pocket, error) {
+	pockets, err := s.repo.GetPocketsByOwner(ctx, ownerXID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed getting pockets")
+	}
+
+	balances, err := s.balances(ctx, ownerXID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range pockets {
+		pockets[i].Balance = balances[pockets[i].ID]
+	}
+	return pockets, nil
+}
+
+func (s *service) GetPocket(ctx context.Context, param PocketParam) (*Pocket, error) {
+	return s.getPocket(ctx, param)
+}
+
+// MoveToPocket sets money aside from the main wallet balance into the pocket.
+func (s *service) MoveToPocket(ctx context.Context, param MovePocketParam) (*Pocket, error) {
+	err := param.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, err := s.getPocket(ctx, PocketParam{OwnerXID: param.OwnerXID, PocketID: param.PocketID})
+	if err != nil {
+		return nil, err
+	}
+	if p.Status != PocketStatusActive {
+		return nil, ErrPocketClosed
+	}
+
+	_, err = s.wallets.WithdrawWallet(ctx, wallet.WalletTransactionParam{
+		ActorXID:    param.OwnerXID,
+		OwnerXID:    param.OwnerXID,
+		ReferenceID: param.ReferenceID,
+		Amount:      param.Amount,
+		Type:        wallet.TransactionTypeToPocket,
+		PocketID:    p.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.getPocket(ctx, PocketParam{OwnerXID: param.OwnerXID, PocketID: param.PocketID})
+}
+
+// MoveFromPocket brings money in the pocket back to the main wallet balance.
+func (s *service) MoveFromPocket(ctx context.Context, param MovePocketParam) (*Pocket, error) {
+	err := param.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, err := s.getPocket(ctx, PocketParam{OwnerXID: param.OwnerXID, PocketID: param.PocketID})
+	if err != nil {
+		return nil, err
+	}
+	if p.Status != PocketStatusActive {
+		return nil, ErrPocketClosed
+	}
+
+	err = s.moveFromPocket(ctx, p, param.ReferenceID, param.Amount)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.getPocket(ctx, PocketParam{OwnerXID: param.OwnerXID, PocketID: param.PocketID})
+}
+
+// ClosePocket sweeps what is left in the pocket back to the main wallet
+// balance and closes it for good.
+func (s *service) ClosePocket(ctx context.Context, param PocketParam) (*Pocket, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, err := s.getPocket(ctx, param)
+	if err != nil {
+		return nil, err
+	}
+	if p.Status != PocketStatusActive {
+		return nil, ErrPocketClosed
+	}
+
+	if p.Balance > 0 {
+		err = s.moveFromPocket(ctx, p, wallet.SystemReferencePrefix+"pocket-"+p.ID+"-close", p.Balance)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	p.Balance = 0
+	p.Status = PocketStatusClosed
+	p.ClosedAt = time.Now()
+	err = s.repo.UpdatePocket(ctx, *p)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed updating pocket")
+	}
+
+	return p, nil
+}
+
+func (s *service) moveFromPocket(ctx context.Context, p *Pocket, referenceID string, amount int) error {
+	// a retried move was taken out of the pocket already, checking the
+	// balance again would reject it instead of replaying it
+	previous, err := s.findMove(ctx, p.OwnerXID, referenceID)
+	if err != nil && err != wallet.ErrTransactionNotFound {
+		return err
+	}
+	if previous == nil && amount > p.Balance {
+		return ErrInsufficientBalance
+	}
+
+	_, err = s.wallets.DepositWallet(ctx, wallet.WalletTransactionParam{
+		ActorXID:    p.OwnerXID,
+		OwnerXID:    p.OwnerXID,
+		ReferenceID: referenceID,
+		Amount:      amount,
+		Type:        wallet.TransactionTypeFromPocket,
+		PocketID:    p.ID,
+	})
+	return err
+}
+
+func (s *service) getPocket(ctx context.Context, param PocketParam) (*Pocket, error) {
+	p, err := s.repo.GetPocket(ctx, param.PocketID)
+	if err != nil && err == ErrPocketNotFound {
+		return nil, ErrPocketNotFound
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed getting pocket")
+	}
+	if p.OwnerXID != param.OwnerXID {
+		return nil, ErrPocketNotFound
+	}
+
+	balances, err := s.balances(ctx, p.OwnerXID)
+	if err != nil {
+		return nil, err
+	}
+	p.Balance = balances[p.ID]
+	return p, nil
+}
+
+// balances sums the pocket movements of the owner's wallet by pocket.
+func (s *service) balances(ctx context.Context, ownerXID string) (map[string]int, error) {
+	transactions, err := s.transactions(ctx, ownerXID)
+	if err != nil {
+		return nil, err
+	}
+
+	balances := map[string]int{}
+	for _, t := range transactions {
+		if t.PocketID != "" && t.Status == wallet.TransactionStatusSuccess {
+			// what leaves the main balance goes into the pocket
+			balances[t.PocketID] -= t.SignedAmount()
+		}
+	}
+	return balances, nil
+}
+
+func (s *service) findMove(ctx context.Context, ownerXID string, referenceID string) (*wallet.WalletTransaction, error) {
+	transactions, err := s.transactions(ctx, ownerXID)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range transactions {
+		if t.ReferenceID == referenceID {
+			return &t, nil
+		}
+	}
+	return nil, wallet.ErrTransactionNotFound
+}
+
+func (s *service) transactions(ctx context.Context, ownerXID string) ([]wallet.WalletTransaction, error) {
+	wal, err := s.wallets.GetWalletByXID(ctx, ownerXID)
+	if err != nil && err == wallet.ErrWalletNotFound {
+		return nil, wallet.ErrWalletNotFound
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed getting wallet")
+	}
+
+	result, err := s.wallets.GetWalletTransactions(ctx, wallet.GetWalletTransactionsParam{
+		WalletID: wal.ID,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed getting wallet transactions")
+	}
+	return result.Transactions, nil
+}
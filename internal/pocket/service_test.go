@@ -0,0 +1,153 @@
+package pocket_test
+
+import (
+	"context"
+	"julo/internal/pocket"
+	"julo/internal/wallet"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestPockets(t *testing.T) {
+	ctx := context.Background()
+	wallets := wallet.NewService(wallet.NewInMemoryRepository())
+	pockets := pocket.NewService(pocket.NewInMemoryRepository(), wallets)
+
+	owner := uuid.NewString()
+	_, err := wallets.EnableWallet(ctx, wallet.EnableWalletParam{
+		OwnerXID: owner,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = wallets.DepositWallet(ctx, wallet.WalletTransactionParam{
+		ActorXID:    owner,
+		OwnerXID:    owner,
+		ReferenceID: uuid.NewString(),
+		Amount:      100000,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rent, err := pockets.CreatePocket(ctx, pocket.CreatePocketParam{
+		OwnerXID: owner,
+		Name:     "rent",
+		Target:   300000,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = pockets.CreatePocket(ctx, pocket.CreatePocketParam{
+		OwnerXID: owner,
+		Name:     "Rent",
+	})
+	if err != pocket.ErrDuplicatePocketName {
+		t.Fatalf("expecting error %s, got %v", pocket.ErrDuplicatePocketName, err)
+	}
+
+	reference := uuid.NewString()
+	for i := 0; i < 2; i++ {
+		rent, err = pockets.MoveToPocket(ctx, pocket.MovePocketParam{
+			OwnerXID:    owner,
+			PocketID:    rent.ID,
+			ReferenceID: reference,
+			Amount:      60000,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if rent.Balance != 60000 {
+		t.Fatalf("expecting pocket balance 60000, got %d", rent.Balance)
+	}
+	if b := balance(t, wallets, owner); b != 40000 {
+		t.Fatalf("expecting main balance 40000, got %d", b)
+	}
+
+	_, err = pockets.MoveToPocket(ctx, pocket.MovePocketParam{
+		OwnerXID:    owner,
+		PocketID:    rent.ID,
+		ReferenceID: uuid.NewString(),
+		Amount:      50000,
+	})
+	if err != wallet.ErrInsufficientBalance {
+		t.Fatalf("expecting error %s, got %v", wallet.ErrInsufficientBalance, err)
+	}
+	_, err = pockets.MoveToPocket(ctx, pocket.MovePocketParam{
+		OwnerXID:    owner,
+		PocketID:    rent.ID,
+		ReferenceID: wallet.SystemReferencePrefix + "pocket-" + rent.ID + "-close",
+		Amount:      10000,
+	})
+	if err != wallet.ErrReservedReference {
+		t.Fatalf("expecting error %s, got %v", wallet.ErrReservedReference, err)
+	}
+	_, err = pockets.MoveFromPocket(ctx, pocket.MovePocketParam{
+		OwnerXID:    owner,
+		PocketID:    rent.ID,
+		ReferenceID: uuid.NewString(),
+		Amount:      70000,
+	})
+	if err != pocket.ErrInsufficientBalance {
+		t.Fatalf("expecting error %s, got %v", pocket.ErrInsufficientBalance, err)
+	}
+
+	rent, err = pockets.MoveFromPocket(ctx, pocket.MovePocketParam{
+		OwnerXID:    owner,
+		PocketID:    rent.ID,
+		ReferenceID: uuid.NewString(),
+		Amount:      10000,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rent.Balance != 50000 || balance(t, wallets, owner) != 50000 {
+		t.Fatalf("expecting 50000 in the pocket and the wallet, got %d and %d", rent.Balance, balance(t, wallets, owner))
+	}
+
+	_, err = pockets.ClosePocket(ctx, pocket.PocketParam{OwnerXID: uuid.NewString(), PocketID: rent.ID})
+	if err != pocket.ErrPocketNotFound {
+		t.Fatalf("expecting error %s, got %v", pocket.ErrPocketNotFound, err)
+	}
+	rent, err = pockets.ClosePocket(ctx, pocket.PocketParam{OwnerXID: owner, PocketID: rent.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rent.Status != pocket.PocketStatusClosed || balance(t, wallets, owner) != 100000 {
+		t.Fatalf("expecting closed pocket swept back to the wallet, got %s and balance %d", rent.Status, balance(t, wallets, owner))
+	}
+
+	wal, _ := wallets.GetWalletByXID(ctx, owner)
+	result, err := wallets.GetWalletTransactions(ctx, wallet.GetWalletTransactionsParam{WalletID: wal.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var moves int
+	for _, trx := range result.Transactions {
+		if trx.PocketID == rent.ID {
+			moves++
+		}
+	}
+	if moves != 3 {
+		t.Fatalf("expecting 3 transactions of the pocket, got %d", moves)
+	}
+
+	// a closed pocket frees its name
+	_, err = pockets.CreatePocket(ctx, pocket.CreatePocketParam{
+		OwnerXID: owner,
+		Name:     "rent",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func balance(t *testing.T, wallets wallet.Service, xid string) int {
+	wal, err := wallets.GetWalletByXID(context.Background(), xid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return wal.Balance
+}
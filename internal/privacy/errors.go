@@ -0,0 +1,8 @@
+package privacy
+
+import "errors"
+
+var (
+	ErrMissingRequiredParameter = errors.New("missing required parameter")
+	ErrPendingTransactions      = errors.New("wallet has transactions still pending")
+)
@@ -0,0 +1,228 @@
+package privacy
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"io"
+	"julo/internal/account"
+	"julo/internal/audit"
+	"julo/internal/auth"
+	"julo/internal/kyc"
+	"julo/internal/wallet"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// The files of an export, each holding one JSON document.
+const (
+	fileAccount      = "account.json"
+	fileWallet       = "wallet.json"
+	fileTransactions = "transactions.json"
+	fileSessions     = "sessions.json"
+	fileKYC          = "kyc.json"
+	fileAudit        = "audit.json"
+)
+
+type accountExport struct {
+	CustomerXID string     `json:"customer_xid"`
+	KYCStatus   string     `json:"kyc_status"`
+	Status      string     `json:"status"`
+	Phone       string     `json:"phone,omitempty"`
+	Email       string     `json:"email,omitempty"`
+	IDNumber    string     `json:"id_number,omitempty"`
+	ClosedAt    *time.Time `json:"closed_at,omitempty"`
+	ErasedAt    *time.Time `json:"erased_at,omitempty"`
+}
+
+func newAccountExport(a account.Account) accountExport {
+	status := a.Status
+	if status == "" {
+		status = account.StatusActive
+	}
+	return accountExport{
+		CustomerXID: a.XID,
+		KYCStatus:   string(a.Verification()),
+		Status:      string(status),
+		Phone:       a.Phone,
+		Email:       a.Email,
+		IDNumber:    a.IDNumber,
+		ClosedAt:    optionalTime(a.ClosedAt),
+		ErasedAt:    optionalTime(a.ErasedAt),
+	}
+}
+
+type walletExport struct {
+	ID          string     `json:"id"`
+	Status      string     `json:"status"`
+	Balance     int        `json:"balance"`
+	Held        int        `json:"held"`
+	CreditLimit int        `json:"credit_limit"`
+	EnabledAt   *time.Time `json:"enabled_at,omitempty"`
+	ClosedAt    *time.Time `json:"closed_at,omitempty"`
+}
+
+func newWalletExport(w wallet.Wallet) walletExport {
+	return walletExport{
+		ID:          w.ID,
+		Status:      string(w.Status),
+		Balance:     w.Balance,
+		Held:        w.Held,
+		CreditLimit: w.CreditLimit,
+		EnabledAt:   optionalTime(w.EnabledAt),
+		ClosedAt:    optionalTime(w.ClosedAt),
+	}
+}
+
+type bankAccountExport struct {
+	BankCode      string `json:"bank_code"`
+	AccountNumber string `json:"account_number"`
+	AccountName   string `json:"account_name,omitempty"`
+}
+
+type transactionExport struct {
+	ID           string             `json:"id"`
+	ReferenceID  string             `json:"reference_id"`
+	Type         string             `json:"type"`
+	Status       string             `json:"status"`
+	Amount       int                `json:"amount"`
+	TransactedAt time.Time          `json:"transacted_at"`
+	SettledAt    *time.Time         `json:"settled_at,omitempty"`
+	Reason       string             `json:"reason,omitempty"`
+	RelatedID    string             `json:"related_id,omitempty"`
+	PocketID     string             `json:"pocket_id,omitempty"`
+	Destination  *bankAccountExport `json:"destination,omitempty"`
+}
+
+func newTransactionExport(t wallet.WalletTransaction) transactionExport {
+	res := transactionExport{
+		ID:           t.ID,
+		ReferenceID:  t.ReferenceID,
+		Type:         string(t.Type),
+		Status:       string(t.Status),
+		Amount:       t.Amount,
+		TransactedAt: t.Date,
+		SettledAt:    optionalTime(t.SettledAt),
+		Reason:       t.Reason,
+		RelatedID:    t.RelatedID,
+		PocketID:     t.PocketID,
+	}
+	if t.Destination != nil {
+		res.Destination = &bankAccountExport{
+			BankCode:      t.Destination.BankCode,
+			AccountNumber: t.Destination.AccountNumber,
+			AccountName:   t.Destination.AccountName,
+		}
+	}
+	return res
+}
+
+// sessionExport leaves out all but the end of the token, the export must
+// not be usable to sign in.
+type sessionExport struct {
+	Token     string    `json:"token"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func newSessionExport(s auth.Session) sessionExport {
+	token := s.Token
+	if len(token) > 4 {
+		token = "..." + token[len(token)-4:]
+	}
+	return sessionExport{
+		Token:     token,
+		CreatedAt: s.CreatedAt,
+	}
+}
+
+type documentExport struct {
+	Kind        string `json:"kind"`
+	ContentType string `json:"content_type"`
+	Size        int    `json:"size"`
+}
+
+type submissionExport struct {
+	ID          string           `json:"id"`
+	Tier        string           `json:"tier"`
+	FullName    string           `json:"full_name"`
+	IDNumber    string           `json:"id_number,omitempty"`
+	DateOfBirth *time.Time       `json:"date_of_birth,omitempty"`
+	Address     string           `json:"address,omitempty"`
+	Documents   []documentExport `json:"documents"`
+	Status      string           `json:"status"`
+	Reason      string           `json:"reason,omitempty"`
+	CreatedAt   time.Time        `json:"created_at"`
+	ReviewedAt  *time.Time       `json:"reviewed_at,omitempty"`
+	ErasedAt    *time.Time       `json:"erased_at,omitempty"`
+}
+
+func newSubmissionExport(s kyc.Submission) submissionExport {
+	documents := make([]documentExport, len(s.Documents))
+	for i, d := range s.Documents {
+		documents[i] = documentExport{
+			Kind:        string(d.Kind),
+			ContentType: d.ContentType,
+			Size:        d.Size,
+		}
+	}
+	return submissionExport{
+		ID:          s.ID,
+		Tier:        string(s.Tier),
+		FullName:    s.FullName,
+		IDNumber:    s.IDNumber,
+		DateOfBirth: optionalTime(s.DateOfBirth),
+		Address:     s.Address,
+		Documents:   documents,
+		Status:      string(s.Status),
+		Reason:      s.Reason,
+		CreatedAt:   s.CreatedAt,
+		ReviewedAt:  optionalTime(s.ReviewedAt),
+		ErasedAt:    optionalTime(s.ErasedAt),
+	}
+}
+
+// export is the data of one customer, written out as a ZIP archive with a
+// JSON file per part. A customer without a wallet has null in wallet.json.
+type export struct {
+	Account      accountExport
+	Wallet       *walletExport
+	Transactions []transactionExport
+	Sessions     []sessionExport
+	Submissions  []submissionExport
+	Audit        []audit.Entry
+}
+
+func (e export) writeZip(w io.Writer) error {
+	zw := zip.NewWriter(w)
+	files := []struct {
+		name string
+		v    interface{}
+	}{
+		{fileAccount, e.Account},
+		{fileWallet, e.Wallet},
+		{fileTransactions, e.Transactions},
+		{fileSessions, e.Sessions},
+		{fileKYC, e.Submissions},
+		{fileAudit, e.Audit},
+	}
+	for _, f := range files {
+		fw, err := zw.Create(f.name)
+		if err != nil {
+			return errors.Wrapf(err, "failed creating %s", f.name)
+		}
+		enc := json.NewEncoder(fw)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(f.v)
+		if err != nil {
+			return errors.Wrapf(err, "failed writing %s", f.name)
+		}
+	}
+	return errors.Wrap(zw.Close(), "failed closing export")
+}
+
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
@@ -0,0 +1,168 @@
+package http_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"julo/internal/account"
+	"julo/internal/audit"
+	"julo/internal/auth"
+	authhttp "julo/internal/auth/http"
+	"julo/internal/closure"
+	closurehttp "julo/internal/closure/http"
+	httphelper "julo/internal/http"
+	"julo/internal/kyc"
+	"julo/internal/loan"
+	"julo/internal/payrequest"
+	"julo/internal/pocket"
+	"julo/internal/privacy"
+	privacyhttp "julo/internal/privacy/http"
+	"julo/internal/schedule"
+	"julo/internal/wallet"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/google/uuid"
+)
+
+func TestPrivacy(t *testing.T) {
+	accounts := account.NewService(account.NewInMemoryRepository())
+	initializer := auth.NewInitializer(accounts)
+	wallets := wallet.NewService(wallet.NewInMemoryRepository())
+	kycs := kyc.NewService(kyc.NewInMemoryRepository(), accounts, kyc.DiskStore{Dir: t.TempDir()}, kyc.Config{})
+	audits := audit.NewService(audit.NewInMemoryRepository())
+	closures := closure.NewService(
+		accounts,
+		wallets,
+		pocket.NewService(pocket.NewInMemoryRepository(), wallets),
+		loan.NewService(loan.NewInMemoryRepository(), wallets),
+		schedule.NewService(schedule.NewInMemoryRepository(), wallets, schedule.LogNotifier{}, schedule.Config{}),
+		payrequest.NewService(payrequest.NewInMemoryRepository(), wallets),
+		audits,
+	)
+	privacies := privacy.NewService(accounts, wallets, kycs, audits)
+	adminToken := uuid.NewString()
+
+	router := chi.NewRouter()
+	router.Mount("/api/v1", router.Group(func(r chi.Router) {
+		r.Post("/init", authhttp.InitHandler(initializer).ServeHTTP)
+		r.Mount("/account", r.Group(func(r chi.Router) {
+			r.Use(authhttp.Middleware)
+			r.Post("/close", closurehttp.CloseAccountHandler(closures).ServeHTTP)
+			r.Get("/export", privacyhttp.ExportHandler(privacies).ServeHTTP)
+		}))
+		r.Mount("/admin", r.Group(func(r chi.Router) {
+			r.Use(authhttp.AdminMiddleware(adminToken))
+			r.Get("/accounts/{xid}/export", privacyhttp.ExportAccountHandler(privacies).ServeHTTP)
+			r.Post("/accounts/{xid}/erase", privacyhttp.EraseAccountHandler(privacies).ServeHTTP)
+		}))
+	}))
+	server := httptest.NewServer(router)
+	defer server.Close()
+	baseUrl := server.URL
+
+	xid := uuid.NewString()
+	initForm := url.Values{}
+	initForm.Set("customer_xid", xid)
+	req := buildAuthenticatedRequest(t, http.MethodPost, baseUrl+"/api/v1/init", "", bytes.NewBufferString(initForm.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	res, err := server.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := decode(t, res)["token"].(string)
+
+	t.Run("export own data, should send archive", func(t *testing.T) {
+		req := buildAuthenticatedRequest(t, http.MethodGet, baseUrl+"/api/v1/account/export", token, nil)
+		res, err := server.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("expecting status %v, got %v", http.StatusOK, res.StatusCode)
+		}
+		if ct := res.Header.Get("Content-Type"); ct != "application/zip" {
+			t.Fatalf("expecting zip, got %s", ct)
+		}
+		archive, err := io.ReadAll(res.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		zr, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(zr.File) != 6 {
+			t.Fatalf("expecting 6 files, got %d", len(zr.File))
+		}
+	})
+
+	t.Run("admin export without actor, should fail", func(t *testing.T) {
+		req := buildAuthenticatedRequest(t, http.MethodGet, baseUrl+"/api/v1/admin/accounts/"+xid+"/export", adminToken, nil)
+		res, err := server.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.StatusCode != http.StatusBadRequest {
+			t.Fatalf("expecting status %v, got %v", http.StatusBadRequest, res.StatusCode)
+		}
+	})
+
+	t.Run("erase open account, should fail", func(t *testing.T) {
+		req := buildAuthenticatedRequest(t, http.MethodPost, baseUrl+"/api/v1/admin/accounts/"+xid+"/erase?actor=ops-1", adminToken, nil)
+		res, err := server.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.StatusCode != http.StatusBadRequest {
+			t.Fatalf("expecting status %v, got %v", http.StatusBadRequest, res.StatusCode)
+		}
+	})
+
+	t.Run("erase closed account, should success", func(t *testing.T) {
+		req := buildAuthenticatedRequest(t, http.MethodPost, baseUrl+"/api/v1/account/close", token, nil)
+		res, err := server.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("expecting status %v, got %v", http.StatusOK, res.StatusCode)
+		}
+
+		req = buildAuthenticatedRequest(t, http.MethodPost, baseUrl+"/api/v1/admin/accounts/"+xid+"/erase?actor=ops-1", adminToken, nil)
+		res, err = server.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("expecting status %v, got %v", http.StatusOK, res.StatusCode)
+		}
+		erasure := decode(t, res)["erasure"].(map[string]interface{})
+		if erasure["account"].(map[string]interface{})["erased_at"] == nil {
+			t.Fatalf("expecting account erased, got %v", erasure)
+		}
+	})
+}
+
+func decode(t *testing.T, res *http.Response) map[string]interface{} {
+	var response httphelper.Response
+	err := json.NewDecoder(res.Body).Decode(&response)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return response.Data.(map[string]interface{})
+}
+
+func buildAuthenticatedRequest(t *testing.T, method string, url string, token string, body io.Reader) *http.Request {
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Add("Authorization", fmt.Sprintf("Token %s", token))
+	return req
+}
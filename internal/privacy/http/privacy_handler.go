@@ -0,0 +1,132 @@
+package http
+
+import (
+	"bytes"
+	"fmt"
+	"julo/internal/account"
+	"julo/internal/auth"
+	httphelper "julo/internal/http"
+	"julo/internal/privacy"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi"
+)
+
+type accountResponse struct {
+	CustomerXID string     `json:"customer_xid"`
+	Status      string     `json:"status"`
+	ErasedAt    *time.Time `json:"erased_at,omitempty"`
+}
+
+type erasureResponse struct {
+	Account      accountResponse `json:"account"`
+	Pseudonym    string          `json:"pseudonym"`
+	Submissions  int             `json:"erased_submissions"`
+	Transactions int             `json:"redacted_transactions"`
+}
+
+// ExportHandler sends the customer of the session a ZIP archive of their
+// data.
+func ExportHandler(privacies privacy.Service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		session := auth.SessionFromContext(r.Context())
+		if session == nil {
+			httphelper.WriteErrorJSON(w, http.StatusUnauthorized, auth.ErrSessionNotFound)
+			return
+		}
+
+		writeExport(w, r, privacies, privacy.ExportParam{
+			CustomerXID: session.Account.XID,
+			ActorXID:    session.Account.XID,
+		})
+	})
+}
+
+// ExportAccountHandler sends the data of the customer of the "xid" url
+// parameter, exported by the operator in actor.
+func ExportAccountHandler(privacies privacy.Service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req actorRequest
+		if err := httphelper.Decode(w, r, &req); err != nil {
+			httphelper.WriteRequestErrorJSON(w, err)
+			return
+		}
+
+		writeExport(w, r, privacies, privacy.ExportParam{
+			CustomerXID: chi.URLParam(r, "xid"),
+			ActorXID:    req.Actor,
+		})
+	})
+}
+
+func writeExport(w http.ResponseWriter, r *http.Request, privacies privacy.Service, param privacy.ExportParam) {
+	var buf bytes.Buffer
+	err := privacies.Export(r.Context(), param, &buf)
+	if err != nil {
+		switch err {
+		case account.ErrAccountNotFound:
+			httphelper.WriteErrorJSON(w, http.StatusNotFound, err)
+		case privacy.ErrMissingRequiredParameter:
+			httphelper.WriteErrorJSON(w, http.StatusBadRequest, err)
+		default:
+			httphelper.WriteErrorJSON(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", param.CustomerXID+"-export.zip"))
+	w.WriteHeader(http.StatusOK)
+	_, _ = buf.WriteTo(w)
+}
+
+// EraseAccountHandler erases the personal data of the closed account of the
+// "xid" url parameter on behalf of the operator in actor.
+func EraseAccountHandler(privacies privacy.Service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var response httphelper.Response
+
+		var req actorRequest
+		if err := httphelper.Decode(w, r, &req); err != nil {
+			httphelper.WriteRequestErrorJSON(w, err)
+			return
+		}
+
+		erasure, err := privacies.Erase(r.Context(), privacy.EraseParam{
+			CustomerXID: chi.URLParam(r, "xid"),
+			ActorXID:    req.Actor,
+		})
+		if err != nil {
+			switch err {
+			case account.ErrAccountNotFound:
+				httphelper.WriteErrorJSON(w, http.StatusNotFound, err)
+			case privacy.ErrMissingRequiredParameter, privacy.ErrPendingTransactions,
+				account.ErrAccountNotClosed, account.ErrAccountErased:
+				httphelper.WriteErrorJSON(w, http.StatusBadRequest, err)
+			default:
+				httphelper.WriteErrorJSON(w, http.StatusInternalServerError, err)
+			}
+			return
+		}
+
+		response.Status = "success"
+		response.Data = map[string]interface{}{
+			"erasure": erasureResponse{
+				Account: accountResponse{
+					CustomerXID: erasure.Account.XID,
+					Status:      string(erasure.Account.Status),
+					ErasedAt:    &erasure.Account.ErasedAt,
+				},
+				Pseudonym:    erasure.Pseudonym,
+				Submissions:  erasure.Submissions,
+				Transactions: erasure.Transactions,
+			},
+		}
+		httphelper.WriteJSON(w, http.StatusOK, response)
+	})
+}
+
+type actorRequest struct {
+	Actor string `json:"actor" validate:"required"`
+}
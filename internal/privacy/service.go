@@ -0,0 +1,217 @@
+package privacy
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"julo/internal/account"
+	"julo/internal/audit"
+	"julo/internal/auth"
+	"julo/internal/kyc"
+	"julo/internal/wallet"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+type ExportParam struct {
+	CustomerXID string
+	// ActorXID is who asked for the export, the customer or an operator.
+	ActorXID string
+}
+
+type EraseParam struct {
+	CustomerXID string
+	// ActorXID is the operator carrying out the erasure.
+	ActorXID string
+}
+
+type Erasure struct {
+	Account account.Account
+	// Pseudonym replaced the names of the customer.
+	Pseudonym    string
+	Submissions  int
+	Transactions int
+}
+
+type Service interface {
+	Export(ctx context.Context, param ExportParam, w io.Writer) error
+	Erase(ctx context.Context, param EraseParam) (*Erasure, error)
+}
+
+type service struct {
+	accounts account.Service
+	wallets  wallet.Service
+	kycs     kyc.Service
+	audits   audit.Service
+	mu       sync.Mutex
+}
+
+func NewService(accounts account.Service, wallets wallet.Service, kycs kyc.Service, audits audit.Service) Service {
+	return &service{
+		accounts: accounts,
+		wallets:  wallets,
+		kycs:     kycs,
+		audits:   audits,
+	}
+}
+
+// Export writes everything kept about the customer to w as a ZIP archive of
+// JSON files: the account, wallet, transactions, sessions, KYC submissions
+// and audit trail. The export itself is recorded in the trail first so it
+// shows up in the archive. Nothing is written to w when gathering the data
+// fails.
+func (s *service) Export(ctx context.Context, param ExportParam, w io.Writer) error {
+	if param.CustomerXID == "" || param.ActorXID == "" {
+		return ErrMissingRequiredParameter
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	acc, err := s.accounts.GetAccount(ctx, param.CustomerXID)
+	if err != nil && err == account.ErrAccountNotFound {
+		return account.ErrAccountNotFound
+	} else if err != nil {
+		return errors.Wrap(err, "failed getting account")
+	}
+	_, err = s.audits.Record(ctx, audit.Entry{
+		SubjectXID: param.CustomerXID,
+		ActorXID:   param.ActorXID,
+		Action:     audit.ActionDataExported,
+	})
+	if err != nil {
+		return err
+	}
+
+	e := export{
+		Account:      newAccountExport(*acc),
+		Transactions: []transactionExport{},
+		Sessions:     []sessionExport{},
+		Submissions:  []submissionExport{},
+	}
+
+	wal, err := s.wallets.GetWalletByXID(ctx, param.CustomerXID)
+	if err != nil && err != wallet.ErrWalletNotFound {
+		return errors.Wrap(err, "failed getting wallet")
+	}
+	if wal != nil {
+		we := newWalletExport(*wal)
+		e.Wallet = &we
+		result, err := s.wallets.GetWalletTransactions(ctx, wallet.GetWalletTransactionsParam{WalletID: wal.ID})
+		if err != nil {
+			return err
+		}
+		for _, t := range result.Transactions {
+			e.Transactions = append(e.Transactions, newTransactionExport(t))
+		}
+	}
+
+	sessions, err := auth.GetSessions(ctx, param.CustomerXID)
+	if err != nil {
+		return errors.Wrap(err, "failed getting sessions")
+	}
+	for _, session := range sessions {
+		e.Sessions = append(e.Sessions, newSessionExport(session))
+	}
+
+	submissions, err := s.kycs.GetSubmissions(ctx, param.CustomerXID)
+	if err != nil {
+		return err
+	}
+	for _, sub := range submissions {
+		e.Submissions = append(e.Submissions, newSubmissionExport(sub))
+	}
+
+	e.Audit, err = s.audits.GetEntries(ctx, param.CustomerXID)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	err = e.writeZip(&buf)
+	if err != nil {
+		return err
+	}
+	_, err = buf.WriteTo(w)
+	return errors.Wrap(err, "failed writing export")
+}
+
+// Erase pseudonymizes the personal data of a closed account: KYC identity
+// data and documents, and the bank accounts payouts went to. The ledger is
+// left as booked, wallet, transactions and amounts stay so balances still
+// add up, and the customer xid stays as the key they are booked under. The
+// account cannot be reopened afterwards.
+func (s *service) Erase(ctx context.Context, param EraseParam) (*Erasure, error) {
+	if param.CustomerXID == "" || param.ActorXID == "" {
+		return nil, ErrMissingRequiredParameter
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	acc, err := s.accounts.GetAccount(ctx, param.CustomerXID)
+	if err != nil && err == account.ErrAccountNotFound {
+		return nil, account.ErrAccountNotFound
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed getting account")
+	}
+	switch {
+	case acc.Status != account.StatusClosed:
+		return nil, account.ErrAccountNotClosed
+	case acc.IsErased():
+		return nil, account.ErrAccountErased
+	}
+
+	wal, err := s.wallets.GetWalletByXID(ctx, param.CustomerXID)
+	if err != nil && err != wallet.ErrWalletNotFound {
+		return nil, errors.Wrap(err, "failed getting wallet")
+	}
+	if wal != nil && wal.Held > 0 {
+		return nil, ErrPendingTransactions
+	}
+
+	erasure := Erasure{Pseudonym: "erased-" + uuid.NewString()[:8]}
+	submissions, err := s.kycs.EraseSubmissions(ctx, kyc.EraseParam{
+		CustomerXID: param.CustomerXID,
+		Pseudonym:   erasure.Pseudonym,
+	})
+	if err != nil {
+		return nil, err
+	}
+	erasure.Submissions = len(submissions)
+
+	if wal != nil {
+		erasure.Transactions, err = s.wallets.RedactDestinations(ctx, wallet.RedactDestinationsParam{
+			OwnerXID:  param.CustomerXID,
+			Pseudonym: erasure.Pseudonym,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = auth.RevokeSessions(ctx, param.CustomerXID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed revoking sessions")
+	}
+	// the account is marked last so an erasure failing halfway can be run
+	// again
+	erased, err := s.accounts.EraseAccount(ctx, param.CustomerXID)
+	if err != nil {
+		return nil, err
+	}
+	erasure.Account = *erased
+
+	_, err = s.audits.Record(ctx, audit.Entry{
+		SubjectXID: param.CustomerXID,
+		ActorXID:   param.ActorXID,
+		Action:     audit.ActionDataErased,
+		Detail:     "pseudonym " + erasure.Pseudonym,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &erasure, nil
+}
@@ -0,0 +1,217 @@
+package privacy_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"image"
+	"image/png"
+	"julo/internal/account"
+	"julo/internal/audit"
+	"julo/internal/auth"
+	"julo/internal/closure"
+	"julo/internal/disbursement"
+	"julo/internal/kyc"
+	"julo/internal/loan"
+	"julo/internal/payrequest"
+	"julo/internal/pocket"
+	"julo/internal/privacy"
+	"julo/internal/schedule"
+	"julo/internal/wallet"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestExportAndErase(t *testing.T) {
+	ctx := context.Background()
+	accounts := account.NewService(account.NewInMemoryRepository())
+	initializer := auth.NewInitializer(accounts)
+	wallets := wallet.NewService(wallet.NewInMemoryRepository())
+	kycs := kyc.NewService(kyc.NewInMemoryRepository(), accounts, kyc.DiskStore{Dir: t.TempDir()}, kyc.Config{})
+	audits := audit.NewService(audit.NewInMemoryRepository())
+	closures := closure.NewService(
+		accounts,
+		wallets,
+		pocket.NewService(pocket.NewInMemoryRepository(), wallets),
+		loan.NewService(loan.NewInMemoryRepository(), wallets),
+		schedule.NewService(schedule.NewInMemoryRepository(), wallets, schedule.LogNotifier{}, schedule.Config{}),
+		payrequest.NewService(payrequest.NewInMemoryRepository(), wallets),
+		audits,
+	)
+	privacies := privacy.NewService(accounts, wallets, kycs, audits)
+
+	xid := uuid.NewString()
+	_, err := initializer.Init(ctx, auth.InitParam{CustomerXID: xid})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = wallets.EnableWallet(ctx, wallet.EnableWalletParam{OwnerXID: xid})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = wallets.DepositWallet(ctx, wallet.WalletTransactionParam{
+		ActorXID:    xid,
+		OwnerXID:    xid,
+		ReferenceID: uuid.NewString(),
+		Amount:      30000,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var idCard bytes.Buffer
+	if err := png.Encode(&idCard, image.NewGray(image.Rect(0, 0, 8, 8))); err != nil {
+		t.Fatal(err)
+	}
+	sub, err := kycs.Submit(ctx, kyc.SubmitParam{
+		CustomerXID: xid,
+		Tier:        account.KYCStatusBasic,
+		FullName:    "Budi Santoso",
+		IDNumber:    "3171234567890123",
+		DateOfBirth: time.Date(1990, 1, 2, 0, 0, 0, 0, time.UTC),
+		Documents:   []kyc.Upload{{Kind: kyc.DocumentKindIDCard, Content: bytes.NewReader(idCard.Bytes())}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("export, should hold every part", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := privacies.Export(ctx, privacy.ExportParam{CustomerXID: xid, ActorXID: xid}, &buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files := readExport(t, buf.Bytes())
+		for _, name := range []string{"account.json", "wallet.json", "transactions.json", "sessions.json", "kyc.json", "audit.json"} {
+			if _, ok := files[name]; !ok {
+				t.Fatalf("expecting %s in export, got %v", name, files)
+			}
+		}
+		if transactions := files["transactions.json"].([]interface{}); len(transactions) != 1 {
+			t.Fatalf("expecting 1 transaction, got %v", transactions)
+		}
+		sessions := files["sessions.json"].([]interface{})
+		if len(sessions) != 1 || len(sessions[0].(map[string]interface{})["token"].(string)) != 7 {
+			t.Fatalf("expecting 1 session with a masked token, got %v", sessions)
+		}
+		entries := files["audit.json"].([]interface{})
+		if len(entries) != 1 || entries[0].(map[string]interface{})["action"] != string(audit.ActionDataExported) {
+			t.Fatalf("expecting the export audited, got %v", entries)
+		}
+	})
+
+	t.Run("export unknown customer, should fail", func(t *testing.T) {
+		err := privacies.Export(ctx, privacy.ExportParam{CustomerXID: uuid.NewString(), ActorXID: "ops-1"}, &bytes.Buffer{})
+		if err != account.ErrAccountNotFound {
+			t.Fatalf("expecting error %s, got %v", account.ErrAccountNotFound, err)
+		}
+	})
+
+	t.Run("erase active account, should fail", func(t *testing.T) {
+		_, err := privacies.Erase(ctx, privacy.EraseParam{CustomerXID: xid, ActorXID: "ops-1"})
+		if err != account.ErrAccountNotClosed {
+			t.Fatalf("expecting error %s, got %v", account.ErrAccountNotClosed, err)
+		}
+	})
+
+	t.Run("erase closed account, should keep the ledger", func(t *testing.T) {
+		_, err := closures.CloseAccount(ctx, closure.CloseAccountParam{
+			CustomerXID: xid,
+			Destination: &disbursement.BankAccount{BankCode: "BCA", AccountNumber: "1234567890", AccountName: "Budi Santoso"},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		erasure, err := privacies.Erase(ctx, privacy.EraseParam{CustomerXID: xid, ActorXID: "ops-1"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !erasure.Account.IsErased() || erasure.Submissions != 1 || erasure.Transactions != 1 {
+			t.Fatalf("expecting 1 submission and 1 transaction erased, got %+v", erasure)
+		}
+
+		subs, err := kycs.GetSubmissions(ctx, xid)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if subs[0].FullName != erasure.Pseudonym || subs[0].IDNumber != "" || !subs[0].DateOfBirth.IsZero() {
+			t.Fatalf("expecting identity pseudonymized, got %+v", subs[0])
+		}
+		if _, err := os.Stat(sub.Documents[0].Path); !os.IsNotExist(err) {
+			t.Fatalf("expecting document deleted, got %v", err)
+		}
+
+		wal, err := wallets.GetWalletByXID(ctx, xid)
+		if err != nil {
+			t.Fatal(err)
+		}
+		result, err := wallets.GetWalletTransactions(ctx, wallet.GetWalletTransactionsParam{WalletID: wal.ID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var total int
+		for _, trx := range result.Transactions {
+			total += trx.SignedAmount()
+			if d := trx.Destination; d != nil && (d.AccountName != erasure.Pseudonym || d.AccountNumber != "******7890") {
+				t.Fatalf("expecting destination pseudonymized, got %+v", d)
+			}
+		}
+		if len(result.Transactions) != 2 || total != wal.Balance {
+			t.Fatalf("expecting ledger adding up to %d, got %+v", wal.Balance, result.Transactions)
+		}
+
+		t.Run("erase again, should fail", func(t *testing.T) {
+			_, err := privacies.Erase(ctx, privacy.EraseParam{CustomerXID: xid, ActorXID: "ops-1"})
+			if err != account.ErrAccountErased {
+				t.Fatalf("expecting error %s, got %v", account.ErrAccountErased, err)
+			}
+		})
+
+		t.Run("reopen erased account, should fail", func(t *testing.T) {
+			_, err := closures.ReopenAccount(ctx, closure.ReopenAccountParam{CustomerXID: xid, ActorXID: "ops-1"})
+			if err != account.ErrAccountErased {
+				t.Fatalf("expecting error %s, got %v", account.ErrAccountErased, err)
+			}
+		})
+
+		t.Run("audit trail, should record closure and erasure", func(t *testing.T) {
+			entries, err := audits.GetEntries(ctx, xid)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var actions []audit.Action
+			for _, e := range entries {
+				actions = append(actions, e.Action)
+			}
+			if len(actions) != 3 || actions[1] != audit.ActionAccountClosed || actions[2] != audit.ActionDataErased {
+				t.Fatalf("expecting export, closure and erasure, got %v", actions)
+			}
+		})
+	})
+}
+
+func readExport(t *testing.T, archive []byte) map[string]interface{} {
+	zr, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]interface{}{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var v interface{}
+		err = json.NewDecoder(rc).Decode(&v)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[f.Name] = v
+	}
+	return files
+}
@@ -0,0 +1,13 @@
+package promotion
+
+import "errors"
+
+var (
+	ErrCampaignNotFound         = errors.New("campaign not found")
+	ErrMissingRequiredParameter = errors.New("missing required parameter")
+	ErrInvalidTrigger           = errors.New("campaigns can only reward deposits, withdrawals and transfers")
+	ErrInvalidReward            = errors.New("invalid reward")
+	ErrCustomerCapRequired      = errors.New("campaigns rewarding transfers need a customer cap")
+	ErrInvalidBudget            = errors.New("invalid budget")
+	ErrInvalidWindow            = errors.New("campaign must end after it starts")
+)
@@ -0,0 +1,85 @@
+package http
+
+import (
+	httphelper "julo/internal/http"
+	"julo/internal/promotion"
+	"julo/internal/wallet"
+	"net/http"
+	"time"
+)
+
+// CreateCampaignHandler is an operator endpoint launching a cashback
+// campaign. reward_bps of the amount of each transaction of the trigger type
+// is given back, within max_reward, customer_cap and budget.
+func CreateCampaignHandler(promotions promotion.Service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var response httphelper.Response
+
+		var req createCampaignRequest
+		if err := httphelper.Decode(w, r, &req); err != nil {
+			httphelper.WriteRequestErrorJSON(w, err)
+			return
+		}
+
+		c, err := promotions.CreateCampaign(r.Context(), promotion.CreateCampaignParam{
+			Name:              req.Name,
+			Trigger:           wallet.TransactionType(req.Trigger),
+			FirstOnly:         req.FirstOnly,
+			MinAmount:         req.MinAmount,
+			RewardBasisPoints: req.RewardBasisPoints,
+			MaxReward:         req.MaxReward,
+			CustomerCap:       req.CustomerCap,
+			Budget:            req.Budget,
+			StartsAt:          req.StartsAt,
+			EndsAt:            req.EndsAt,
+		})
+		if err != nil {
+			switch err {
+			case promotion.ErrMissingRequiredParameter, promotion.ErrInvalidTrigger, promotion.ErrInvalidReward,
+				promotion.ErrCustomerCapRequired, promotion.ErrInvalidBudget, promotion.ErrInvalidWindow:
+				httphelper.WriteErrorJSON(w, http.StatusBadRequest, err)
+			default:
+				httphelper.WriteErrorJSON(w, http.StatusInternalServerError, err)
+			}
+			return
+		}
+
+		response.Status = "success"
+		response.Data = map[string]interface{}{
+			"campaign": newCampaignResponse(*c),
+		}
+		httphelper.WriteJSON(w, http.StatusCreated, response)
+	})
+}
+
+// ViewCampaignsHandler lists the campaigns along with the budget they spent.
+func ViewCampaignsHandler(promotions promotion.Service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var response httphelper.Response
+
+		campaigns, err := promotions.GetCampaigns(r.Context())
+		if err != nil {
+			httphelper.WriteErrorJSON(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		response.Status = "success"
+		response.Data = map[string]interface{}{
+			"campaigns": newCampaignsResponse(campaigns),
+		}
+		httphelper.WriteJSON(w, http.StatusOK, response)
+	})
+}
+
+type createCampaignRequest struct {
+	Name              string    `json:"name" validate:"required"`
+	Trigger           string    `json:"trigger" validate:"required"`
+	FirstOnly         bool      `json:"first_only"`
+	MinAmount         int       `json:"min_amount"`
+	RewardBasisPoints int       `json:"reward_bps" validate:"required"`
+	MaxReward         int       `json:"max_reward"`
+	CustomerCap       int       `json:"customer_cap"`
+	Budget            int       `json:"budget" validate:"required"`
+	StartsAt          time.Time `json:"starts_at"`
+	EndsAt            time.Time `json:"ends_at" validate:"required"`
+}
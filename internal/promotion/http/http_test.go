@@ -0,0 +1,136 @@
+package http_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"julo/internal/account"
+	"julo/internal/auth"
+	authhttp "julo/internal/auth/http"
+	httphelper "julo/internal/http"
+	"julo/internal/promotion"
+	promotionhttp "julo/internal/promotion/http"
+	"julo/internal/wallet"
+	wallethttp "julo/internal/wallet/http"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/google/uuid"
+)
+
+func TestCampaign(t *testing.T) {
+	ctx := context.Background()
+	wallets := wallet.NewService(wallet.NewInMemoryRepository())
+	promotions := promotion.NewService(promotion.NewInMemoryRepository(), wallets)
+	wallets.OnTransactionCompleted(promotion.TransactionListener(promotions))
+	adminToken := uuid.NewString()
+
+	router := chi.NewRouter()
+	router.Mount("/api/v1", router.Group(func(r chi.Router) {
+		r.Mount("/wallet", r.Group(func(r chi.Router) {
+			r.Use(authhttp.Middleware)
+			r.Post("/deposits", wallethttp.DepositWalletHandler(wallets).ServeHTTP)
+			r.Get("/rewards", promotionhttp.ViewRewardsHandler(promotions).ServeHTTP)
+		}))
+		r.Mount("/admin", r.Group(func(r chi.Router) {
+			r.Use(authhttp.AdminMiddleware(adminToken))
+			r.Get("/campaigns", promotionhttp.ViewCampaignsHandler(promotions).ServeHTTP)
+			r.Post("/campaigns", promotionhttp.CreateCampaignHandler(promotions).ServeHTTP)
+		}))
+	}))
+	server := httptest.NewServer(router)
+	defer server.Close()
+	baseUrl := server.URL
+
+	owner := uuid.NewString()
+	_, err := wallets.EnableWallet(ctx, wallet.EnableWalletParam{
+		OwnerXID: owner,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := uuid.NewString()
+	err = auth.StoreSession(ctx, auth.Session{
+		Token:   token,
+		Account: account.Account{XID: owner},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("create campaign with invalid trigger, should fail", func(t *testing.T) {
+		form := url.Values{}
+		form.Set("name", "cashback on cashback")
+		form.Set("trigger", string(wallet.TransactionTypeCashback))
+		form.Set("reward_bps", "1000")
+		form.Set("budget", "100000")
+		form.Set("ends_at", time.Now().Add(time.Hour).Format(time.RFC3339))
+		res := post(t, server, baseUrl+"/api/v1/admin/campaigns", adminToken, form)
+		if res.StatusCode != http.StatusBadRequest {
+			t.Fatalf("expecting status %v, got %v", http.StatusBadRequest, res.StatusCode)
+		}
+	})
+
+	t.Run("deposit during campaign, should be rewarded", func(t *testing.T) {
+		form := url.Values{}
+		form.Set("name", "first deposit")
+		form.Set("trigger", string(wallet.TransactionTypeDeposit))
+		form.Set("first_only", "true")
+		form.Set("reward_bps", "1000")
+		form.Set("max_reward", "20000")
+		form.Set("budget", "1000000")
+		form.Set("ends_at", time.Now().Add(time.Hour).Format(time.RFC3339))
+		res := post(t, server, baseUrl+"/api/v1/admin/campaigns", adminToken, form)
+		if res.StatusCode != http.StatusCreated {
+			t.Fatalf("expecting status %v, got %v", http.StatusCreated, res.StatusCode)
+		}
+
+		form = url.Values{}
+		form.Set("amount", "50000")
+		form.Set("reference_id", uuid.NewString())
+		res = post(t, server, baseUrl+"/api/v1/wallet/deposits", token, form)
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("expecting status %v, got %v", http.StatusOK, res.StatusCode)
+		}
+
+		req := buildAuthenticatedRequest(t, http.MethodGet, baseUrl+"/api/v1/wallet/rewards", token, nil)
+		res, err := server.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var response httphelper.Response
+		err = json.NewDecoder(res.Body).Decode(&response)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rewards := response.Data.(map[string]interface{})["rewards"].([]interface{})
+		if len(rewards) != 1 || rewards[0].(map[string]interface{})["amount"] != float64(5000) {
+			t.Fatalf("expecting a reward of 5000, got %v", rewards)
+		}
+	})
+}
+
+func post(t *testing.T, server *httptest.Server, url string, token string, form url.Values) *http.Response {
+	req := buildAuthenticatedRequest(t, http.MethodPost, url, token, bytes.NewBufferString(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	res, err := server.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
+func buildAuthenticatedRequest(t *testing.T, method string, url string, token string, body io.Reader) *http.Request {
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Add("Authorization", fmt.Sprintf("Token %s", token))
+	return req
+}
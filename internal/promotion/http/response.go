@@ -0,0 +1,72 @@
+package http
+
+import (
+	"julo/internal/promotion"
+	"time"
+)
+
+type campaignResponse struct {
+	ID                string    `json:"id"`
+	Name              string    `json:"name"`
+	Trigger           string    `json:"trigger"`
+	FirstOnly         bool      `json:"first_only"`
+	MinAmount         int       `json:"min_amount"`
+	RewardBasisPoints int       `json:"reward_bps"`
+	MaxReward         int       `json:"max_reward"`
+	CustomerCap       int       `json:"customer_cap"`
+	Budget            int       `json:"budget"`
+	Spent             int       `json:"spent"`
+	StartsAt          time.Time `json:"starts_at"`
+	EndsAt            time.Time `json:"ends_at"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+func newCampaignResponse(c promotion.Campaign) campaignResponse {
+	return campaignResponse{
+		ID:                c.ID,
+		Name:              c.Name,
+		Trigger:           string(c.Trigger),
+		FirstOnly:         c.FirstOnly,
+		MinAmount:         c.MinAmount,
+		RewardBasisPoints: c.RewardBasisPoints,
+		MaxReward:         c.MaxReward,
+		CustomerCap:       c.CustomerCap,
+		Budget:            c.Budget,
+		Spent:             c.Spent,
+		StartsAt:          c.StartsAt,
+		EndsAt:            c.EndsAt,
+		CreatedAt:         c.CreatedAt,
+	}
+}
+
+func newCampaignsResponse(campaigns []promotion.Campaign) []campaignResponse {
+	data := make([]campaignResponse, 0, len(campaigns))
+	for _, c := range campaigns {
+		data = append(data, newCampaignResponse(c))
+	}
+	return data
+}
+
+type rewardResponse struct {
+	ID                    string    `json:"id"`
+	CampaignID            string    `json:"campaign_id"`
+	TransactionID         string    `json:"transaction_id"`
+	CashbackTransactionID string    `json:"cashback_transaction_id"`
+	Amount                int       `json:"amount"`
+	CreatedAt             time.Time `json:"created_at"`
+}
+
+func newRewardsResponse(rewards []promotion.Reward) []rewardResponse {
+	data := make([]rewardResponse, 0, len(rewards))
+	for _, r := range rewards {
+		data = append(data, rewardResponse{
+			ID:                    r.ID,
+			CampaignID:            r.CampaignID,
+			TransactionID:         r.TransactionID,
+			CashbackTransactionID: r.CashbackTransactionID,
+			Amount:                r.Amount,
+			CreatedAt:             r.CreatedAt,
+		})
+	}
+	return data
+}
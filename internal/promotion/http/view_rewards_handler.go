@@ -0,0 +1,32 @@
+package http
+
+import (
+	"julo/internal/auth"
+	httphelper "julo/internal/http"
+	"julo/internal/promotion"
+	"net/http"
+)
+
+// ViewRewardsHandler lists the cashbacks the customer was granted.
+func ViewRewardsHandler(promotions promotion.Service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var response httphelper.Response
+		session := auth.SessionFromContext(r.Context())
+		if session == nil {
+			httphelper.WriteErrorJSON(w, http.StatusUnauthorized, auth.ErrSessionNotFound)
+			return
+		}
+
+		rewards, err := promotions.GetRewards(r.Context(), session.Account.XID)
+		if err != nil {
+			httphelper.WriteErrorJSON(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		response.Status = "success"
+		response.Data = map[string]interface{}{
+			"rewards": newRewardsResponse(rewards),
+		}
+		httphelper.WriteJSON(w, http.StatusOK, response)
+	})
+}
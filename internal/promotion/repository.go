@@ -0,0 +1,120 @@
+package promotion
+
+import (
+	"context"
+	"julo/internal/wallet"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Campaign rewards the transactions matching its rules with a cashback of
+// RewardBasisPoints of their amount, e.g. 10% cashback up to 20k on the
+// first deposit.
+type Campaign struct {
+	ID   string
+	Name string
+	// Trigger is the type of transaction the campaign rewards.
+	Trigger wallet.TransactionType
+	// FirstOnly restricts the reward to the customer's first transaction of
+	// the trigger type.
+	FirstOnly bool
+	MinAmount int
+	// RewardBasisPoints of the transaction amount are given back, up to
+	// MaxReward per transaction when set.
+	RewardBasisPoints int
+	MaxReward         int
+	// CustomerCap caps the rewards a customer gets from the campaign, zero
+	// means no cap.
+	CustomerCap int
+	// Budget caps the rewards of all customers together, Spent is what was
+	// given so far.
+	Budget    int
+	Spent     int
+	StartsAt  time.Time
+	EndsAt    time.Time
+	CreatedAt time.Time
+}
+
+// IsRunning reports whether transactions completed at t fall in the window
+// of the campaign.
+func (c Campaign) IsRunning(t time.Time) bool {
+	return !t.Before(c.StartsAt) && t.Before(c.EndsAt)
+}
+
+// Reward is a cashback granted by a campaign for a transaction.
+type Reward struct {
+	ID          string
+	CampaignID  string
+	CustomerXID string
+	// TransactionID is the rewarded transaction, CashbackTransactionID the
+	// transaction crediting the reward.
+	TransactionID         string
+	CashbackTransactionID string
+	Amount                int
+	CreatedAt             time.Time
+}
+
+type Repository interface {
+	SaveCampaign(ctx context.Context, c Campaign) error
+	GetCampaign(ctx context.Context, id string) (*Campaign, error)
+	GetCampaigns(ctx context.Context) ([]Campaign, error)
+	CreateReward(ctx context.Context, r Reward) error
+	GetRewardsByCustomer(ctx context.Context, xid string) ([]Reward, error)
+}
+
+type InMemoryRepository struct {
+	campaigns sync.Map
+	rewards   sync.Map
+}
+
+func NewInMemoryRepository() Repository {
+	return &InMemoryRepository{}
+}
+
+func (r *InMemoryRepository) SaveCampaign(ctx context.Context, c Campaign) error {
+	r.campaigns.Store(c.ID, &c)
+	return nil
+}
+
+func (r *InMemoryRepository) GetCampaign(ctx context.Context, id string) (*Campaign, error) {
+	v, ok := r.campaigns.Load(id)
+	if !ok {
+		return nil, ErrCampaignNotFound
+	}
+
+	c := *v.(*Campaign)
+	return &c, nil
+}
+
+func (r *InMemoryRepository) GetCampaigns(ctx context.Context) ([]Campaign, error) {
+	campaigns := []Campaign{}
+	r.campaigns.Range(func(key, value any) bool {
+		campaigns = append(campaigns, *value.(*Campaign))
+		return true
+	})
+	sort.Slice(campaigns, func(i, j int) bool {
+		return campaigns[i].CreatedAt.Before(campaigns[j].CreatedAt)
+	})
+	return campaigns, nil
+}
+
+func (r *InMemoryRepository) CreateReward(ctx context.Context, reward Reward) error {
+	r.rewards.Store(reward.ID, &reward)
+	return nil
+}
+
+func (r *InMemoryRepository) GetRewardsByCustomer(ctx context.Context, xid string) ([]Reward, error) {
+	rewards := []Reward{}
+	r.rewards.Range(func(key, value any) bool {
+		reward := *value.(*Reward)
+		if reward.CustomerXID == xid {
+			rewards = append(rewards, reward)
+		}
+		return true
+	})
+	sort.Slice(rewards, func(i, j int) bool {
+		return rewards[i].CreatedAt.Before(rewards[j].CreatedAt)
+	})
+	return rewards, nil
+}
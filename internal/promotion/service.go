@@ -0,0 +1,267 @@
+package promotion
+
+import (
+	"context"
+	"julo/internal/wallet"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+type CreateCampaignParam struct {
+	Name              string
+	Trigger           wallet.TransactionType
+	FirstOnly         bool
+	MinAmount         int
+	RewardBasisPoints int
+	MaxReward         int
+	CustomerCap       int
+	Budget            int
+	// StartsAt defaults to the creation of the campaign.
+	StartsAt time.Time
+	EndsAt   time.Time
+}
+
+func (p CreateCampaignParam) Validate() error {
+	if p.Name == "" || p.Trigger == "" || p.EndsAt.IsZero() {
+		return ErrMissingRequiredParameter
+	}
+	switch p.Trigger {
+	case wallet.TransactionTypeDeposit, wallet.TransactionTypeWithdrawal, wallet.TransactionTypeTransferIn, wallet.TransactionTypeTransferOut:
+	default:
+		return ErrInvalidTrigger
+	}
+	if p.RewardBasisPoints <= 0 || p.RewardBasisPoints > 10000 || p.MaxReward < 0 || p.CustomerCap < 0 || p.MinAmount < 0 {
+		return ErrInvalidReward
+	}
+	// two customers passing the same money back and forth would otherwise
+	// earn cashback until the budget is spent
+	if (p.Trigger == wallet.TransactionTypeTransferIn || p.Trigger == wallet.TransactionTypeTransferOut) && p.CustomerCap == 0 {
+		return ErrCustomerCapRequired
+	}
+	if p.Budget <= 0 {
+		return ErrInvalidBudget
+	}
+	if !p.StartsAt.IsZero() && !p.EndsAt.After(p.StartsAt) {
+		return ErrInvalidWindow
+	}
+	return nil
+}
+
+type Service interface {
+	CreateCampaign(ctx context.Context, param CreateCampaignParam) (*Campaign, error)
+	GetCampaigns(ctx context.Context) ([]Campaign, error)
+	GetRewards(ctx context.Context, xid string) ([]Reward, error)
+	// Reward grants the cashbacks the completed transaction t of the wallet
+	// of ownerXID is eligible to.
+	Reward(ctx context.Context, ownerXID string, t wallet.WalletTransaction) ([]Reward, error)
+}
+
+type service struct {
+	repo    Repository
+	wallets wallet.Service
+	mu      sync.Mutex
+}
+
+func NewService(repo Repository, wallets wallet.Service) Service {
+	return &service{
+		repo:    repo,
+		wallets: wallets,
+	}
+}
+
+// TransactionListener rewards the transactions completed in the wallet
+// service, to be registered with wallet.Service.OnTransactionCompleted.
+func TransactionListener(promotions Service) wallet.TransactionListener {
+	return func(ctx context.Context, ownerXID string, t wallet.WalletTransaction) {
+		_, err := promotions.Reward(ctx, ownerXID, t)
+		if err != nil {
+			log.Printf("failed rewarding transaction %s: %s", t.ID, err)
+		}
+	}
+}
+
+func (s *service) CreateCampaign(ctx context.Context, param CreateCampaignParam) (*Campaign, error) {
+	err := param.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if param.StartsAt.IsZero() {
+		param.StartsAt = now
+		if !param.EndsAt.After(now) {
+			return nil, ErrInvalidWindow
+		}
+	}
+
+	c := Campaign{
+		ID:                uuid.NewString(),
+		Name:              param.Name,
+		Trigger:           param.Trigger,
+		FirstOnly:         param.FirstOnly,
+		MinAmount:         param.MinAmount,
+		RewardBasisPoints: param.RewardBasisPoints,
+		MaxReward:         param.MaxReward,
+		CustomerCap:       param.CustomerCap,
+		Budget:            param.Budget,
+		StartsAt:          param.StartsAt,
+		EndsAt:            param.EndsAt,
+		CreatedAt:         now,
+	}
+	err = s.repo.SaveCampaign(ctx, c)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed saving campaign")
+	}
+
+	return &c, nil
+}
+
+func (s *service) GetCampaigns(ctx context.Context) ([]Campaign, error) {
+	campaigns, err := s.repo.GetCampaigns(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed getting campaigns")
+	}
+	return campaigns, nil
+}
+
+func (s *service) GetRewards(ctx context.Context, xid string) ([]Reward, error) {
+	rewards, err := s.repo.GetRewardsByCustomer(ctx, xid)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed getting rewards")
+	}
+	return rewards, nil
+}
+
+func (s *service) Reward(ctx context.Context, ownerXID string, t wallet.WalletTransaction) ([]Reward, error) {
+	// cashbacks are completed while a reward is granted, under s.mu
+	if t.Status != wallet.TransactionStatusSuccess || t.Type == wallet.TransactionTypeCashback {
+		return nil, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	campaigns, err := s.repo.GetCampaigns(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed getting campaigns")
+	}
+	previous, err := s.repo.GetRewardsByCustomer(ctx, ownerXID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed getting rewards")
+	}
+
+	rewards := []Reward{}
+	for _, c := range campaigns {
+		if c.Trigger != t.Type || t.Amount < c.MinAmount || !c.IsRunning(t.SettledAt) {
+			continue
+		}
+
+		var rewarded int
+		var replay bool
+		for _, r := range previous {
+			if r.CampaignID != c.ID {
+				continue
+			}
+			rewarded += r.Amount
+			replay = replay || r.TransactionID == t.ID
+		}
+		if replay {
+			continue
+		}
+		if c.FirstOnly {
+			first, err := s.isFirst(ctx, t)
+			if err != nil {
+				return nil, err
+			}
+			if !first {
+				continue
+			}
+		}
+
+		amount := cashback(c, t.Amount, rewarded)
+		if amount <= 0 {
+			continue
+		}
+		r, err := s.grant(ctx, c, ownerXID, t, amount)
+		if err != nil {
+			return nil, err
+		}
+		rewards = append(rewards, *r)
+	}
+
+	return rewards, nil
+}
+
+// cashback is the reward of an amount, within the caps of the campaign when
+// the customer already got rewarded from it.
+func cashback(c Campaign, amount int, rewarded int) int {
+	reward := amount * c.RewardBasisPoints / 10000
+	if c.MaxReward > 0 && reward > c.MaxReward {
+		reward = c.MaxReward
+	}
+	if c.CustomerCap > 0 && reward > c.CustomerCap-rewarded {
+		reward = c.CustomerCap - rewarded
+	}
+	if reward > c.Budget-c.Spent {
+		reward = c.Budget - c.Spent
+	}
+	return reward
+}
+
+// isFirst reports whether no transaction of the type of t completed in the
+// wallet before it.
+func (s *service) isFirst(ctx context.Context, t wallet.WalletTransaction) (bool, error) {
+	result, err := s.wallets.GetWalletTransactions(ctx, wallet.GetWalletTransactionsParam{WalletID: t.WalletID})
+	if err != nil {
+		return false, errors.Wrap(err, "failed getting wallet transactions")
+	}
+	for _, other := range result.Transactions {
+		if other.ID != t.ID && other.Type == t.Type && other.Status == wallet.TransactionStatusSuccess && !other.SettledAt.After(t.SettledAt) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// grant credits the cashback to the customer and charges it to the budget of
+// the campaign.
+func (s *service) grant(ctx context.Context, c Campaign, ownerXID string, t wallet.WalletTransaction, amount int) (*Reward, error) {
+	result, err := s.wallets.DepositWallet(ctx, wallet.WalletTransactionParam{
+		ActorXID:    "promotion:" + c.ID,
+		OwnerXID:    ownerXID,
+		ReferenceID: wallet.SystemReferencePrefix + "cashback-" + c.ID + "-" + t.ID,
+		Amount:      amount,
+		Type:        wallet.TransactionTypeCashback,
+		RelatedID:   t.ID,
+		CampaignID:  c.ID,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed crediting cashback")
+	}
+
+	c.Spent += amount
+	err = s.repo.SaveCampaign(ctx, c)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed saving campaign")
+	}
+
+	r := Reward{
+		ID:                    uuid.NewString(),
+		CampaignID:            c.ID,
+		CustomerXID:           ownerXID,
+		TransactionID:         t.ID,
+		CashbackTransactionID: result.ID,
+		Amount:                amount,
+		CreatedAt:             time.Now(),
+	}
+	err = s.repo.CreateReward(ctx, r)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed saving reward")
+	}
+
+	return &r, nil
+}
@@ -0,0 +1,170 @@
+package promotion_test
+
+import (
+	"context"
+	"julo/internal/promotion"
+	"julo/internal/wallet"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestFirstDepositCashback(t *testing.T) {
+	ctx := context.Background()
+	wallets := wallet.NewService(wallet.NewInMemoryRepository())
+	promotions := promotion.NewService(promotion.NewInMemoryRepository(), wallets)
+	wallets.OnTransactionCompleted(promotion.TransactionListener(promotions))
+
+	c, err := promotions.CreateCampaign(ctx, promotion.CreateCampaignParam{
+		Name:              "10% cashback up to 20k on first deposit",
+		Trigger:           wallet.TransactionTypeDeposit,
+		FirstOnly:         true,
+		RewardBasisPoints: 1000,
+		MaxReward:         20000,
+		Budget:            30000,
+		EndsAt:            time.Now().Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deposit := func(xid string, amount int) {
+		_, err := wallets.DepositWallet(ctx, wallet.WalletTransactionParam{
+			ActorXID:    xid,
+			OwnerXID:    xid,
+			ReferenceID: uuid.NewString(),
+			Amount:      amount,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	alice, bob, carol := uuid.NewString(), uuid.NewString(), uuid.NewString()
+	for _, xid := range []string{alice, bob, carol} {
+		_, err := wallets.EnableWallet(ctx, wallet.EnableWalletParam{OwnerXID: xid})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// capped at 20k, and the second deposit is not the first anymore
+	deposit(alice, 500000)
+	deposit(alice, 100000)
+	// 10% of 50k
+	deposit(bob, 50000)
+	// only 5k of budget left
+	deposit(carol, 100000)
+
+	for xid, expected := range map[string]int{alice: 620000, bob: 55000, carol: 105000} {
+		wal, err := wallets.GetWalletByXID(ctx, xid)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if wal.Balance != expected {
+			t.Fatalf("expecting balance %d, got %d", expected, wal.Balance)
+		}
+	}
+
+	rewards, err := promotions.GetRewards(ctx, alice)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rewards) != 1 || rewards[0].Amount != 20000 || rewards[0].CampaignID != c.ID {
+		t.Fatalf("expecting one reward of 20000, got %+v", rewards)
+	}
+
+	wal, err := wallets.GetWalletByXID(ctx, alice)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := wallets.GetWalletTransactions(ctx, wallet.GetWalletTransactionsParam{WalletID: wal.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var cashback *wallet.WalletTransaction
+	for i, trx := range result.Transactions {
+		if trx.Type == wallet.TransactionTypeCashback {
+			cashback = &result.Transactions[i]
+		}
+	}
+	if cashback == nil || cashback.RelatedID != rewards[0].TransactionID || cashback.CampaignID != c.ID {
+		t.Fatalf("expecting cashback linked to the deposit and campaign, got %+v", cashback)
+	}
+
+	campaigns, err := promotions.GetCampaigns(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if campaigns[0].Spent != campaigns[0].Budget {
+		t.Fatalf("expecting budget spent, got %d of %d", campaigns[0].Spent, campaigns[0].Budget)
+	}
+}
+
+func TestCustomerCap(t *testing.T) {
+	ctx := context.Background()
+	wallets := wallet.NewService(wallet.NewInMemoryRepository())
+	promotions := promotion.NewService(promotion.NewInMemoryRepository(), wallets)
+
+	_, err := promotions.CreateCampaign(ctx, promotion.CreateCampaignParam{
+		Name:              "5% back on transfers",
+		Trigger:           wallet.TransactionTypeTransferOut,
+		MinAmount:         10000,
+		RewardBasisPoints: 500,
+		CustomerCap:       1200,
+		Budget:            1000000,
+		EndsAt:            time.Now().Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = promotions.CreateCampaign(ctx, promotion.CreateCampaignParam{
+		Name:              "uncapped transfers",
+		Trigger:           wallet.TransactionTypeTransferIn,
+		RewardBasisPoints: 500,
+		Budget:            1000000,
+		EndsAt:            time.Now().Add(time.Hour),
+	})
+	if err != promotion.ErrCustomerCapRequired {
+		t.Fatalf("expecting error %s, got %v", promotion.ErrCustomerCapRequired, err)
+	}
+	_, err = promotions.CreateCampaign(ctx, promotion.CreateCampaignParam{
+		Name:              "not started yet",
+		Trigger:           wallet.TransactionTypeTransferOut,
+		RewardBasisPoints: 500,
+		CustomerCap:       1200,
+		Budget:            1000000,
+		StartsAt:          time.Now().Add(time.Hour),
+		EndsAt:            time.Now().Add(2 * time.Hour),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	customer := uuid.NewString()
+	_, err = wallets.EnableWallet(ctx, wallet.EnableWalletParam{OwnerXID: customer})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var total int
+	for _, amount := range []int{5000, 10000, 10000, 10000} {
+		rewards, err := promotions.Reward(ctx, customer, wallet.WalletTransaction{
+			ID:        uuid.NewString(),
+			Type:      wallet.TransactionTypeTransferOut,
+			Amount:    amount,
+			Status:    wallet.TransactionStatusSuccess,
+			SettledAt: time.Now(),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, r := range rewards {
+			total += r.Amount
+		}
+	}
+	// 500 twice then the 200 left under the cap, nothing below the minimum
+	if total != 1200 {
+		t.Fatalf("expecting rewards capped at 1200, got %d", total)
+	}
+}
@@ -0,0 +1,15 @@
+package qr
+
+import "errors"
+
+var (
+	ErrMissingRequiredParameter = errors.New("missing required parameter")
+	ErrInvalidAmount            = errors.New("invalid amount")
+	ErrInvalidPayload           = errors.New("invalid qr payload")
+	ErrInvalidChecksum          = errors.New("qr payload checksum mismatch")
+	ErrUnsupportedPayee         = errors.New("qr payload is not payable from the wallet")
+	ErrUnsupportedCurrency      = errors.New("unsupported currency")
+	ErrFieldTooLong             = errors.New("qr payload field too long")
+	ErrContentTooLong           = errors.New("content too long for a qr code")
+	ErrAmountMismatch           = errors.New("amount differs from the qr payload")
+)
@@ -0,0 +1,143 @@
+package http_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"julo/internal/account"
+	"julo/internal/auth"
+	authhttp "julo/internal/auth/http"
+	httphelper "julo/internal/http"
+	"julo/internal/qr"
+	qrhttp "julo/internal/qr/http"
+	"julo/internal/wallet"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/google/uuid"
+)
+
+func TestQRPayment(t *testing.T) {
+	ctx := context.Background()
+	wallets := wallet.NewService(wallet.NewInMemoryRepository())
+	codes := qr.NewService(wallets)
+
+	router := chi.NewRouter()
+	router.Mount("/api/v1", router.Group(func(r chi.Router) {
+		r.Mount("/wallet", r.Group(func(r chi.Router) {
+			r.Use(authhttp.Middleware)
+			r.Get("/qr", qrhttp.ViewQRHandler(codes).ServeHTTP)
+			r.Get("/qr.png", qrhttp.ViewQRImageHandler(codes).ServeHTTP)
+			r.Post("/qr/payments", qrhttp.PayQRHandler(codes).ServeHTTP)
+		}))
+	}))
+	server := httptest.NewServer(router)
+	defer server.Close()
+	baseUrl := server.URL
+
+	tokens := map[string]string{}
+	payer, payee := uuid.NewString(), uuid.NewString()
+	for _, xid := range []string{payer, payee} {
+		_, err := wallets.EnableWallet(ctx, wallet.EnableWalletParam{
+			OwnerXID: xid,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		tokens[xid] = uuid.NewString()
+		err = auth.StoreSession(ctx, auth.Session{
+			Token:   tokens[xid],
+			Account: account.Account{XID: xid},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err := wallets.DepositWallet(ctx, wallet.WalletTransactionParam{
+		ActorXID:    payer,
+		OwnerXID:    payer,
+		ReferenceID: uuid.NewString(),
+		Amount:      50000,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("view qr image, should answer png", func(t *testing.T) {
+		req := buildAuthenticatedRequest(t, http.MethodGet, baseUrl+"/api/v1/wallet/qr.png", tokens[payee], nil)
+		res, err := server.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.StatusCode != http.StatusOK || res.Header.Get("Content-Type") != "image/png" {
+			t.Fatalf("expecting png image, got status %v and %s", res.StatusCode, res.Header.Get("Content-Type"))
+		}
+	})
+
+	req := buildAuthenticatedRequest(t, http.MethodGet, baseUrl+"/api/v1/wallet/qr?amount=12000&reference=INV-9", tokens[payee], nil)
+	res, err := server.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expecting status %v, got %v", http.StatusOK, res.StatusCode)
+	}
+	var response httphelper.Response
+	err = json.NewDecoder(res.Body).Decode(&response)
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload := response.Data.(map[string]interface{})["payload"].(string)
+
+	t.Run("pay scanned payload, should transfer to payee", func(t *testing.T) {
+		form := url.Values{}
+		form.Set("payload", payload)
+		req := buildAuthenticatedRequest(t, http.MethodPost, baseUrl+"/api/v1/wallet/qr/payments", tokens[payer], bytes.NewBufferString(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		res, err := server.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("expecting status %v, got %v", http.StatusOK, res.StatusCode)
+		}
+		wal, _ := wallets.GetWalletByXID(ctx, payee)
+		if wal.Balance != 12000 {
+			t.Fatalf("expecting balance 12000, got %d", wal.Balance)
+		}
+	})
+
+	t.Run("pay corrupted payload, should fail", func(t *testing.T) {
+		form := url.Values{}
+		corrupted := "0"
+		if payload[len(payload)-1] == '0' {
+			corrupted = "1"
+		}
+		form.Set("payload", payload[:len(payload)-1]+corrupted)
+		req := buildAuthenticatedRequest(t, http.MethodPost, baseUrl+"/api/v1/wallet/qr/payments", tokens[payer], bytes.NewBufferString(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		res, err := server.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.StatusCode != http.StatusBadRequest {
+			t.Fatalf("expecting status %v, got %v", http.StatusBadRequest, res.StatusCode)
+		}
+	})
+}
+
+func buildAuthenticatedRequest(t *testing.T, method string, url string, token string, body io.Reader) *http.Request {
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Add("Authorization", fmt.Sprintf("Token %s", token))
+	return req
+}
@@ -0,0 +1,86 @@
+package http
+
+import (
+	"julo/internal/auth"
+	httphelper "julo/internal/http"
+	"julo/internal/qr"
+	"julo/internal/wallet"
+	"net/http"
+	"time"
+)
+
+// PayQRHandler pays the scanned payload from the wallet of the customer. The
+// amount is required for static codes only.
+func PayQRHandler(codes qr.Service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var response httphelper.Response
+		session := auth.SessionFromContext(r.Context())
+		if session == nil {
+			httphelper.WriteErrorJSON(w, http.StatusUnauthorized, auth.ErrSessionNotFound)
+			return
+		}
+
+		var req payQRRequest
+		if err := httphelper.Decode(w, r, &req); err != nil {
+			httphelper.WriteRequestErrorJSON(w, err)
+			return
+		}
+
+		result, err := codes.Pay(r.Context(), qr.PayParam{
+			PayerXID:    session.Account.XID,
+			Payload:     req.Payload,
+			Amount:      req.Amount,
+			ReferenceID: req.ReferenceID,
+		})
+		if err != nil {
+			ve, ok := err.(wallet.ValidationError)
+			if ok {
+				response.Status = "failed"
+				response.Data = ve.GetErrors()
+				httphelper.WriteJSON(w, http.StatusBadRequest, response)
+				return
+			}
+			switch err {
+			case qr.ErrMissingRequiredParameter, qr.ErrInvalidAmount, qr.ErrInvalidPayload, qr.ErrInvalidChecksum,
+				qr.ErrUnsupportedPayee, qr.ErrUnsupportedCurrency, qr.ErrAmountMismatch,
+				wallet.ErrInsufficientBalance, wallet.ErrWalletDisabled, wallet.ErrWalletNotFound, wallet.ErrReservedReference:
+				httphelper.WriteErrorJSON(w, http.StatusBadRequest, err)
+			default:
+				httphelper.WriteErrorJSON(w, http.StatusInternalServerError, err)
+			}
+			return
+		}
+
+		var fee int
+		if result.Fee != nil {
+			fee = result.Fee.Amount
+		}
+		response.Status = "success"
+		response.Data = map[string]interface{}{
+			"payment": struct {
+				ID          string    `json:"id"`
+				PaidTo      string    `json:"paid_to"`
+				Status      string    `json:"status"`
+				PaidAt      time.Time `json:"paid_at"`
+				Amount      int       `json:"amount"`
+				Fee         int       `json:"fee"`
+				ReferenceID string    `json:"reference_id"`
+			}{
+				ID:          result.Debit.ID,
+				PaidTo:      result.Payload.PayeeXID,
+				Status:      string(result.Debit.Status),
+				PaidAt:      result.Debit.SettledAt,
+				Amount:      result.Debit.Amount,
+				Fee:         fee,
+				ReferenceID: result.Debit.ReferenceID,
+			},
+		}
+		httphelper.WriteJSON(w, http.StatusOK, response)
+	})
+}
+
+type payQRRequest struct {
+	Payload     string `json:"payload" validate:"required"`
+	Amount      int    `json:"amount"`
+	ReferenceID string `json:"reference_id"`
+}
@@ -0,0 +1,85 @@
+package http
+
+import (
+	"julo/internal/auth"
+	httphelper "julo/internal/http"
+	"julo/internal/qr"
+	"julo/internal/wallet"
+	"net/http"
+	"strconv"
+)
+
+const pngScale = 8
+
+// ViewQRHandler answers with the payload of a code paying into the wallet of
+// the customer, for ?amount= when given and otherwise static, with an
+// optional ?reference=.
+func ViewQRHandler(codes qr.Service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var response httphelper.Response
+		payload, status, err := generatePayload(codes, r)
+		if err != nil {
+			httphelper.WriteErrorJSON(w, status, err)
+			return
+		}
+
+		response.Status = "success"
+		response.Data = map[string]interface{}{
+			"payload": payload,
+		}
+		httphelper.WriteJSON(w, http.StatusOK, response)
+	})
+}
+
+// ViewQRImageHandler draws the code of ViewQRHandler as a PNG image.
+func ViewQRImageHandler(codes qr.Service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		payload, status, err := generatePayload(codes, r)
+		if err != nil {
+			httphelper.WriteErrorJSON(w, status, err)
+			return
+		}
+
+		code, err := qr.NewCode(payload)
+		if err != nil {
+			httphelper.WriteErrorJSON(w, http.StatusBadRequest, err)
+			return
+		}
+		w.Header().Set("Content-Type", "image/png")
+		err = code.WritePNG(w, pngScale)
+		if err != nil {
+			httphelper.WriteErrorJSON(w, http.StatusInternalServerError, err)
+		}
+	})
+}
+
+func generatePayload(codes qr.Service, r *http.Request) (string, int, error) {
+	session := auth.SessionFromContext(r.Context())
+	if session == nil {
+		return "", http.StatusUnauthorized, auth.ErrSessionNotFound
+	}
+
+	var amount int64
+	if v := r.URL.Query().Get("amount"); v != "" {
+		var err error
+		amount, err = strconv.ParseInt(v, 10, 32)
+		if err != nil {
+			return "", http.StatusBadRequest, err
+		}
+	}
+
+	payload, err := codes.GeneratePayload(r.Context(), qr.GenerateParam{
+		PayeeXID:  session.Account.XID,
+		Amount:    int(amount),
+		Reference: r.URL.Query().Get("reference"),
+	})
+	if err != nil {
+		switch err {
+		case qr.ErrInvalidAmount, qr.ErrFieldTooLong, wallet.ErrWalletNotFound:
+			return "", http.StatusBadRequest, err
+		default:
+			return "", http.StatusInternalServerError, err
+		}
+	}
+	return payload, http.StatusOK, nil
+}
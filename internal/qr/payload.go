@@ -0,0 +1,223 @@
+package qr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Wallet payloads follow the EMVCo merchant presented mode: a sequence of
+// tag, two digit length and value fields closed by a CRC-16 field.
+const (
+	tagFormatIndicator = "00"
+	tagInitiation      = "01"
+	tagPayeeAccount    = "26"
+	tagCurrency        = "53"
+	tagAmount          = "54"
+	tagCountry         = "58"
+	tagName            = "59"
+	tagAdditionalData  = "62"
+	tagCRC             = "63"
+
+	// sub tags of the payee account and additional data templates
+	tagGUI       = "00"
+	tagPayeeID   = "01"
+	tagReference = "05"
+
+	formatIndicator   = "01"
+	initiationStatic  = "11"
+	initiationDynamic = "12"
+
+	// GUI identifies the payee account template of this wallet among the
+	// ones other schemes put in the same payload.
+	GUI = "ID.CO.JULO.WALLET"
+	// CurrencyIDR is the ISO 4217 numeric code of the wallet currency.
+	CurrencyIDR = "360"
+	countryCode = "ID"
+)
+
+// Payload is what a QR code asks the payer to pay. A zero Amount makes a
+// static code, the payer then enters the amount.
+type Payload struct {
+	PayeeXID  string
+	Name      string
+	Amount    int
+	Currency  string
+	Reference string
+}
+
+// IsStatic reports whether the payer chooses the amount.
+func (p Payload) IsStatic() bool {
+	return p.Amount == 0
+}
+
+// Encode serializes p into the text carried by the QR code.
+func (p Payload) Encode() (string, error) {
+	if p.PayeeXID == "" {
+		return "", ErrMissingRequiredParameter
+	}
+	if p.Amount < 0 {
+		return "", ErrInvalidAmount
+	}
+	if p.Currency == "" {
+		p.Currency = CurrencyIDR
+	}
+
+	account, err := tlv(tagGUI, GUI)
+	if err != nil {
+		return "", err
+	}
+	payee, err := tlv(tagPayeeID, p.PayeeXID)
+	if err != nil {
+		return "", err
+	}
+
+	var fields [][2]string
+	initiation := initiationDynamic
+	if p.IsStatic() {
+		initiation = initiationStatic
+	}
+	fields = append(fields,
+		[2]string{tagFormatIndicator, formatIndicator},
+		[2]string{tagInitiation, initiation},
+		[2]string{tagPayeeAccount, account + payee},
+		[2]string{tagCurrency, p.Currency},
+	)
+	if !p.IsStatic() {
+		fields = append(fields, [2]string{tagAmount, strconv.Itoa(p.Amount)})
+	}
+	fields = append(fields, [2]string{tagCountry, countryCode})
+	if p.Name != "" {
+		fields = append(fields, [2]string{tagName, p.Name})
+	}
+	if p.Reference != "" {
+		reference, err := tlv(tagReference, p.Reference)
+		if err != nil {
+			return "", err
+		}
+		fields = append(fields, [2]string{tagAdditionalData, reference})
+	}
+
+	var b strings.Builder
+	for _, f := range fields {
+		field, err := tlv(f[0], f[1])
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(field)
+	}
+	b.WriteString(tagCRC + "04")
+	b.WriteString(fmt.Sprintf("%04X", crc16(b.String())))
+	return b.String(), nil
+}
+
+// ParsePayload reads back a scanned payload, checking its CRC and that it
+// pays a wallet.
+func ParsePayload(s string) (*Payload, error) {
+	if len(s) < 8 || s[len(s)-8:len(s)-4] != tagCRC+"04" {
+		return nil, ErrInvalidPayload
+	}
+	crc, err := strconv.ParseUint(s[len(s)-4:], 16, 16)
+	if err != nil {
+		return nil, ErrInvalidPayload
+	}
+	if uint16(crc) != crc16(s[:len(s)-4]) {
+		return nil, ErrInvalidChecksum
+	}
+
+	fields, err := parseTLV(s[:len(s)-8])
+	if err != nil {
+		return nil, err
+	}
+	if fields[tagFormatIndicator] != formatIndicator {
+		return nil, ErrInvalidPayload
+	}
+
+	var p Payload
+	for tag, value := range fields {
+		if tag < "26" || tag > "51" {
+			continue
+		}
+		account, err := parseTLV(value)
+		if err != nil {
+			return nil, err
+		}
+		if account[tagGUI] == GUI {
+			p.PayeeXID = account[tagPayeeID]
+		}
+	}
+	if p.PayeeXID == "" {
+		return nil, ErrUnsupportedPayee
+	}
+
+	p.Currency = fields[tagCurrency]
+	p.Name = fields[tagName]
+	if v, ok := fields[tagAmount]; ok {
+		p.Amount, err = parseAmount(v)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if v, ok := fields[tagAdditionalData]; ok {
+		additional, err := parseTLV(v)
+		if err != nil {
+			return nil, err
+		}
+		p.Reference = additional[tagReference]
+	}
+
+	return &p, nil
+}
+
+func tlv(tag string, value string) (string, error) {
+	if len(value) > 99 {
+		return "", ErrFieldTooLong
+	}
+	return fmt.Sprintf("%s%02d%s", tag, len(value), value), nil
+}
+
+func parseTLV(s string) (map[string]string, error) {
+	fields := map[string]string{}
+	for len(s) > 0 {
+		if len(s) < 4 {
+			return nil, ErrInvalidPayload
+		}
+		n, err := strconv.Atoi(s[2:4])
+		if err != nil || n < 0 || len(s) < 4+n {
+			return nil, ErrInvalidPayload
+		}
+		fields[s[:2]] = s[4 : 4+n]
+		s = s[4+n:]
+	}
+	return fields, nil
+}
+
+// parseAmount reads the amount field, which other issuers may write with
+// decimals. The wallet has no minor unit so they must be zero.
+func parseAmount(v string) (int, error) {
+	whole, decimals, _ := strings.Cut(v, ".")
+	if strings.Trim(decimals, "0") != "" {
+		return 0, ErrInvalidAmount
+	}
+	amount, err := strconv.Atoi(whole)
+	if err != nil || amount <= 0 {
+		return 0, ErrInvalidAmount
+	}
+	return amount, nil
+}
+
+// crc16 is the CRC-16/CCITT-FALSE checksum EMVCo payloads end with.
+func crc16(s string) uint16 {
+	crc := uint16(0xFFFF)
+	for i := 0; i < len(s); i++ {
+		crc ^= uint16(s[i]) << 8
+		for j := 0; j < 8; j++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
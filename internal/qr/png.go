@@ -0,0 +1,37 @@
+package qr
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+)
+
+// quietZone is the light border, in modules, scanners need around a code.
+const quietZone = 4
+
+// Image draws the code with each module scale pixels wide.
+func (c *Code) Image(scale int) image.Image {
+	if scale < 1 {
+		scale = 1
+	}
+	size := (c.Size + 2*quietZone) * scale
+	img := image.NewGray(image.Rect(0, 0, size, size))
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			mx, my := x/scale-quietZone, y/scale-quietZone
+			dark := mx >= 0 && my >= 0 && mx < c.Size && my < c.Size && c.Dark(mx, my)
+			if dark {
+				img.SetGray(x, y, color.Gray{Y: 0})
+			} else {
+				img.SetGray(x, y, color.Gray{Y: 255})
+			}
+		}
+	}
+	return img
+}
+
+// WritePNG encodes the image of the code as PNG to w.
+func (c *Code) WritePNG(w io.Writer, scale int) error {
+	return png.Encode(w, c.Image(scale))
+}
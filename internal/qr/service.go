@@ -0,0 +1,120 @@
+package qr
+
+import (
+	"context"
+	"julo/internal/wallet"
+)
+
+type GenerateParam struct {
+	PayeeXID  string
+	Name      string
+	Amount    int
+	Reference string
+}
+
+type PayParam struct {
+	PayerXID string
+	Payload  string
+	// Amount is entered by the payer for static codes. It may be left out
+	// for dynamic codes, which carry their own amount.
+	Amount int
+	// ReferenceID makes the payment idempotent. Dynamic codes with a
+	// reference default to one derived from it, so a code is paid once.
+	ReferenceID string
+}
+
+// Payment is a transfer made by paying a scanned payload.
+type Payment struct {
+	Payload Payload
+	wallet.TransferWalletResult
+}
+
+type Service interface {
+	GeneratePayload(ctx context.Context, param GenerateParam) (string, error)
+	Pay(ctx context.Context, param PayParam) (*Payment, error)
+}
+
+type service struct {
+	wallets wallet.Service
+}
+
+func NewService(wallets wallet.Service) Service {
+	return &service{
+		wallets: wallets,
+	}
+}
+
+// GeneratePayload builds the payload of a code paying into the wallet of
+// param.PayeeXID.
+func (s *service) GeneratePayload(ctx context.Context, param GenerateParam) (string, error) {
+	if param.PayeeXID == "" {
+		return "", ErrMissingRequiredParameter
+	}
+	if param.Amount < 0 {
+		return "", ErrInvalidAmount
+	}
+	_, err := s.wallets.GetWalletByXID(ctx, param.PayeeXID)
+	if err != nil {
+		return "", err
+	}
+
+	return Payload{
+		PayeeXID:  param.PayeeXID,
+		Name:      param.Name,
+		Amount:    param.Amount,
+		Currency:  CurrencyIDR,
+		Reference: param.Reference,
+	}.Encode()
+}
+
+// Pay transfers the amount of a scanned payload from the payer's wallet to
+// the payee's.
+func (s *service) Pay(ctx context.Context, param PayParam) (*Payment, error) {
+	if param.PayerXID == "" || param.Payload == "" {
+		return nil, ErrMissingRequiredParameter
+	}
+	if wallet.IsSystemReference(param.ReferenceID) {
+		return nil, wallet.ErrReservedReference
+	}
+	p, err := ParsePayload(param.Payload)
+	if err != nil {
+		return nil, err
+	}
+	if p.Currency != CurrencyIDR {
+		return nil, ErrUnsupportedCurrency
+	}
+
+	amount := p.Amount
+	if p.IsStatic() {
+		amount = param.Amount
+	} else if param.Amount != 0 && param.Amount != p.Amount {
+		return nil, ErrAmountMismatch
+	}
+	if amount <= 0 {
+		return nil, ErrInvalidAmount
+	}
+
+	reference := param.ReferenceID
+	if reference == "" && !p.IsStatic() && p.Reference != "" {
+		reference = wallet.SystemReferencePrefix + "qr-" + p.PayeeXID + "-" + p.Reference
+	}
+	if reference == "" {
+		return nil, ErrMissingRequiredParameter
+	}
+
+	result, err := s.wallets.TransferWallet(ctx, wallet.TransferWalletParam{
+		ActorXID:    param.PayerXID,
+		FromXID:     param.PayerXID,
+		ToXID:       p.PayeeXID,
+		ReferenceID: reference,
+		Amount:      amount,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Payment{
+		Payload:              *p,
+		TransferWalletResult: *result,
+	}, nil
+}
@@ -0,0 +1,146 @@
+package qr_test
+
+import (
+	"bytes"
+	"context"
+	"image/png"
+	"julo/internal/qr"
+	"julo/internal/wallet"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestPayload(t *testing.T) {
+	payload, err := qr.Payload{
+		PayeeXID:  "merchant:1234",
+		Name:      "Coffee Shop",
+		Amount:    25000,
+		Reference: "INV-1",
+	}.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(payload, "000201010212") || !strings.Contains(payload, "5303360") || !strings.Contains(payload, "540525000") {
+		t.Fatalf("unexpected payload %s", payload)
+	}
+
+	p, err := qr.ParsePayload(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.PayeeXID != "merchant:1234" || p.Name != "Coffee Shop" || p.Amount != 25000 || p.Currency != qr.CurrencyIDR || p.Reference != "INV-1" {
+		t.Fatalf("unexpected parsed payload %+v", p)
+	}
+
+	tampered := strings.Replace(payload, "540525000", "540515000", 1)
+	_, err = qr.ParsePayload(tampered)
+	if err != qr.ErrInvalidChecksum {
+		t.Fatalf("expecting error %s, got %v", qr.ErrInvalidChecksum, err)
+	}
+	_, err = qr.ParsePayload("not a payload")
+	if err != qr.ErrInvalidPayload {
+		t.Fatalf("expecting error %s, got %v", qr.ErrInvalidPayload, err)
+	}
+}
+
+func TestCode(t *testing.T) {
+	payload, err := qr.Payload{PayeeXID: uuid.NewString()}.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, err := qr.NewCode(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code.Size != 17+4*code.Version {
+		t.Fatalf("expecting size of version %d, got %d", code.Version, code.Size)
+	}
+	// top left finder pattern
+	for i := 0; i < 7; i++ {
+		if !code.Dark(i, 0) || !code.Dark(0, i) || code.Dark(7, i) {
+			t.Fatalf("expecting finder pattern in the top left corner")
+		}
+	}
+
+	var buf bytes.Buffer
+	err = code.WritePNG(&buf, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Bounds().Dx() != (code.Size+8)*4 {
+		t.Fatalf("expecting %d pixels wide image, got %d", (code.Size+8)*4, img.Bounds().Dx())
+	}
+
+	_, err = qr.NewCode(strings.Repeat("x", 214))
+	if err != qr.ErrContentTooLong {
+		t.Fatalf("expecting error %s, got %v", qr.ErrContentTooLong, err)
+	}
+}
+
+func TestPay(t *testing.T) {
+	ctx := context.Background()
+	wallets := wallet.NewService(wallet.NewInMemoryRepository())
+	codes := qr.NewService(wallets)
+
+	payer, payee := uuid.NewString(), uuid.NewString()
+	for _, xid := range []string{payer, payee} {
+		_, err := wallets.EnableWallet(ctx, wallet.EnableWalletParam{OwnerXID: xid})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err := wallets.DepositWallet(ctx, wallet.WalletTransactionParam{
+		ActorXID:    payer,
+		OwnerXID:    payer,
+		ReferenceID: uuid.NewString(),
+		Amount:      50000,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	static, err := codes.GeneratePayload(ctx, qr.GenerateParam{PayeeXID: payee})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = codes.Pay(ctx, qr.PayParam{PayerXID: payer, Payload: static, ReferenceID: uuid.NewString()})
+	if err != qr.ErrInvalidAmount {
+		t.Fatalf("expecting error %s, got %v", qr.ErrInvalidAmount, err)
+	}
+	_, err = codes.Pay(ctx, qr.PayParam{PayerXID: payer, Payload: static, Amount: 10000, ReferenceID: uuid.NewString()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dynamic, err := codes.GeneratePayload(ctx, qr.GenerateParam{PayeeXID: payee, Amount: 5000, Reference: "table-7"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = codes.Pay(ctx, qr.PayParam{PayerXID: payer, Payload: dynamic, Amount: 6000})
+	if err != qr.ErrAmountMismatch {
+		t.Fatalf("expecting error %s, got %v", qr.ErrAmountMismatch, err)
+	}
+	for i := 0; i < 2; i++ {
+		payment, err := codes.Pay(ctx, qr.PayParam{PayerXID: payer, Payload: dynamic})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if payment.Payload.PayeeXID != payee || payment.Debit.Amount != 5000 {
+			t.Fatalf("unexpected payment %+v", payment)
+		}
+	}
+
+	wal, err := wallets.GetWalletByXID(ctx, payee)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wal.Balance != 15000 {
+		t.Fatalf("expecting the dynamic code paid once, got balance %d", wal.Balance)
+	}
+}
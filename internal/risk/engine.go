@@ -0,0 +1,177 @@
+package risk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"julo/internal/auth"
+	"julo/internal/wallet"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Config holds the rules movements are screened with. A rule left at zero is
+// not applied.
+type Config struct {
+	// VelocityCount withdrawals within VelocityWindowMinutes are allowed,
+	// one more is denied.
+	VelocityCount         int `json:"velocity_count"`
+	VelocityWindowMinutes int `json:"velocity_window_minutes"`
+	// AnomalyFactor sends to review a movement over that many times the
+	// average of the customer's past movements of the same type, once the
+	// customer made at least AnomalyMinHistory of them.
+	AnomalyFactor     int `json:"anomaly_factor"`
+	AnomalyMinHistory int `json:"anomaly_min_history"`
+	// LargeWithdrawal sends to review a withdrawal of at least that amount
+	// made within NewSessionMinutes of the session being opened.
+	LargeWithdrawal   int `json:"large_withdrawal"`
+	NewSessionMinutes int `json:"new_session_minutes"`
+	// Blocklist denies any movement of the listed customers.
+	Blocklist []string `json:"blocklist"`
+}
+
+// LoadConfig reads a JSON config from path.
+func LoadConfig(path string) (*Config, error) {
+	f, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed reading risk config")
+	}
+
+	var c Config
+	err = json.Unmarshal(f, &c)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed parsing risk config")
+	}
+
+	err = c.Validate()
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (c Config) Validate() error {
+	if c.VelocityCount < 0 || c.VelocityWindowMinutes < 0 || c.AnomalyFactor < 0 || c.AnomalyMinHistory < 0 || c.LargeWithdrawal < 0 || c.NewSessionMinutes < 0 {
+		return ErrInvalidConfig
+	}
+	// a velocity rule needs both the count and the window
+	if (c.VelocityCount == 0) != (c.VelocityWindowMinutes == 0) {
+		return ErrInvalidConfig
+	}
+	if (c.LargeWithdrawal == 0) != (c.NewSessionMinutes == 0) {
+		return ErrInvalidConfig
+	}
+	return nil
+}
+
+// Engine screens deposits and withdrawals with the rules of a Config.
+// Transfers between customers, which cannot wait for review, are only
+// checked against the blocklist, on both legs. Other movements, such as
+// cashback, are allowed.
+type Engine struct {
+	config    Config
+	blocklist map[string]bool
+}
+
+func NewEngine(c Config) (*Engine, error) {
+	err := c.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	blocklist := map[string]bool{}
+	for _, xid := range c.Blocklist {
+		blocklist[xid] = true
+	}
+	return &Engine{
+		config:    c,
+		blocklist: blocklist,
+	}, nil
+}
+
+// Assess runs every rule and returns the strictest decision, with the reasons
+// of all rules that did not allow the movement.
+func (e *Engine) Assess(ctx context.Context, check wallet.RiskCheck) wallet.RiskAssessment {
+	assessment := wallet.RiskAssessment{Decision: wallet.RiskDecisionAllow}
+	flag := func(decision wallet.RiskDecision, reason string) {
+		if decision == wallet.RiskDecisionDeny || assessment.Decision == wallet.RiskDecisionAllow {
+			assessment.Decision = decision
+		}
+		assessment.Reasons = append(assessment.Reasons, reason)
+	}
+
+	switch check.Type {
+	case wallet.TransactionTypeDeposit, wallet.TransactionTypeWithdrawal:
+	case wallet.TransactionTypeTransferOut, wallet.TransactionTypeTransferIn:
+		if e.blocklist[check.OwnerXID] || e.blocklist[check.ActorXID] {
+			flag(wallet.RiskDecisionDeny, "blocklisted customer")
+		}
+		return assessment
+	default:
+		return assessment
+	}
+
+	if e.blocklist[check.OwnerXID] || e.blocklist[check.ActorXID] {
+		flag(wallet.RiskDecisionDeny, "blocklisted customer")
+	}
+	if e.exceedsVelocity(check) {
+		flag(wallet.RiskDecisionDeny, fmt.Sprintf("more than %d withdrawals in %d minutes", e.config.VelocityCount, e.config.VelocityWindowMinutes))
+	}
+	if e.isAnomalous(check) {
+		flag(wallet.RiskDecisionReview, "amount unusual for the customer")
+	}
+	if e.isLargeOnNewSession(ctx, check) {
+		flag(wallet.RiskDecisionReview, "large withdrawal on a new session")
+	}
+	return assessment
+}
+
+func (e *Engine) exceedsVelocity(check wallet.RiskCheck) bool {
+	if e.config.VelocityCount == 0 || check.Type != wallet.TransactionTypeWithdrawal {
+		return false
+	}
+
+	since := check.At.Add(-time.Duration(e.config.VelocityWindowMinutes) * time.Minute)
+	count := 0
+	for _, t := range check.History {
+		// failed and rejected attempts count too, they are attempts all the same
+		if t.Type == wallet.TransactionTypeWithdrawal && t.Date.After(since) {
+			count++
+		}
+	}
+	return count >= e.config.VelocityCount
+}
+
+func (e *Engine) isAnomalous(check wallet.RiskCheck) bool {
+	if e.config.AnomalyFactor == 0 {
+		return false
+	}
+
+	var count, total int
+	for _, t := range check.History {
+		if t.Type == check.Type && t.Status == wallet.TransactionStatusSuccess {
+			count++
+			total += t.Amount
+		}
+	}
+	if count == 0 || count < e.config.AnomalyMinHistory {
+		return false
+	}
+	return check.Amount*count > total*e.config.AnomalyFactor
+}
+
+func (e *Engine) isLargeOnNewSession(ctx context.Context, check wallet.RiskCheck) bool {
+	if e.config.LargeWithdrawal == 0 || check.Type != wallet.TransactionTypeWithdrawal || check.Amount < e.config.LargeWithdrawal {
+		return false
+	}
+
+	// movements made outside of a customer session, by jobs or operators,
+	// are not tied to a new device
+	session := auth.SessionFromContext(ctx)
+	if session == nil || session.CreatedAt.IsZero() {
+		return false
+	}
+	return check.At.Sub(session.CreatedAt) < time.Duration(e.config.NewSessionMinutes)*time.Minute
+}
@@ -0,0 +1,147 @@
+package risk_test
+
+import (
+	"context"
+	"julo/internal/account"
+	"julo/internal/auth"
+	"julo/internal/risk"
+	"julo/internal/wallet"
+	"testing"
+	"time"
+)
+
+func TestAssess(t *testing.T) {
+	engine, err := risk.NewEngine(risk.Config{
+		VelocityCount:         3,
+		VelocityWindowMinutes: 10,
+		AnomalyFactor:         5,
+		AnomalyMinHistory:     2,
+		LargeWithdrawal:       1000000,
+		NewSessionMinutes:     30,
+		Blocklist:             []string{"blocked"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	history := func(t wallet.TransactionType, amount int, ages ...time.Duration) []wallet.WalletTransaction {
+		transactions := []wallet.WalletTransaction{}
+		for _, age := range ages {
+			transactions = append(transactions, wallet.WalletTransaction{
+				Type:   t,
+				Amount: amount,
+				Status: wallet.TransactionStatusSuccess,
+				Date:   now.Add(-age),
+			})
+		}
+		return transactions
+	}
+	newSession := auth.SessionIntoContext(context.Background(), &auth.Session{
+		Account:   account.Account{XID: "customer"},
+		CreatedAt: now.Add(-5 * time.Minute),
+	})
+	oldSession := auth.SessionIntoContext(context.Background(), &auth.Session{
+		Account:   account.Account{XID: "customer"},
+		CreatedAt: now.Add(-2 * time.Hour),
+	})
+
+	cases := []struct {
+		name     string
+		ctx      context.Context
+		check    wallet.RiskCheck
+		decision wallet.RiskDecision
+	}{
+		{
+			name:     "usual withdrawal",
+			ctx:      oldSession,
+			check:    wallet.RiskCheck{OwnerXID: "customer", Type: wallet.TransactionTypeWithdrawal, Amount: 20000, History: history(wallet.TransactionTypeWithdrawal, 10000, time.Hour, 2*time.Hour)},
+			decision: wallet.RiskDecisionAllow,
+		},
+		{
+			name:     "blocklisted customer",
+			ctx:      oldSession,
+			check:    wallet.RiskCheck{OwnerXID: "blocked", Type: wallet.TransactionTypeDeposit, Amount: 10000},
+			decision: wallet.RiskDecisionDeny,
+		},
+		{
+			name:     "too many withdrawals",
+			ctx:      oldSession,
+			check:    wallet.RiskCheck{OwnerXID: "customer", Type: wallet.TransactionTypeWithdrawal, Amount: 10000, History: history(wallet.TransactionTypeWithdrawal, 10000, time.Minute, 2*time.Minute, 3*time.Minute)},
+			decision: wallet.RiskDecisionDeny,
+		},
+		{
+			name:     "withdrawals spread out",
+			ctx:      oldSession,
+			check:    wallet.RiskCheck{OwnerXID: "customer", Type: wallet.TransactionTypeWithdrawal, Amount: 10000, History: history(wallet.TransactionTypeWithdrawal, 10000, time.Minute, 2*time.Minute, time.Hour)},
+			decision: wallet.RiskDecisionAllow,
+		},
+		{
+			name:     "unusual deposit",
+			ctx:      oldSession,
+			check:    wallet.RiskCheck{OwnerXID: "customer", Type: wallet.TransactionTypeDeposit, Amount: 60000, History: history(wallet.TransactionTypeDeposit, 10000, time.Hour, 2*time.Hour)},
+			decision: wallet.RiskDecisionReview,
+		},
+		{
+			name:     "unusual deposit without enough history",
+			ctx:      oldSession,
+			check:    wallet.RiskCheck{OwnerXID: "customer", Type: wallet.TransactionTypeDeposit, Amount: 60000, History: history(wallet.TransactionTypeDeposit, 10000, time.Hour)},
+			decision: wallet.RiskDecisionAllow,
+		},
+		{
+			name:     "large withdrawal on new session",
+			ctx:      newSession,
+			check:    wallet.RiskCheck{OwnerXID: "customer", Type: wallet.TransactionTypeWithdrawal, Amount: 1000000},
+			decision: wallet.RiskDecisionReview,
+		},
+		{
+			name:     "large withdrawal on old session",
+			ctx:      oldSession,
+			check:    wallet.RiskCheck{OwnerXID: "customer", Type: wallet.TransactionTypeWithdrawal, Amount: 1000000},
+			decision: wallet.RiskDecisionAllow,
+		},
+		{
+			name:     "transfer from blocklisted customer",
+			ctx:      oldSession,
+			check:    wallet.RiskCheck{OwnerXID: "blocked", Type: wallet.TransactionTypeTransferOut, Amount: 10000},
+			decision: wallet.RiskDecisionDeny,
+		},
+		{
+			name:     "transfer to blocklisted customer",
+			ctx:      oldSession,
+			check:    wallet.RiskCheck{OwnerXID: "blocked", ActorXID: "customer", Type: wallet.TransactionTypeTransferIn, Amount: 10000},
+			decision: wallet.RiskDecisionDeny,
+		},
+		{
+			name:     "large transfer on new session",
+			ctx:      newSession,
+			check:    wallet.RiskCheck{OwnerXID: "customer", Type: wallet.TransactionTypeTransferOut, Amount: 1000000},
+			decision: wallet.RiskDecisionAllow,
+		},
+		{
+			name:     "cashback to blocklisted customer",
+			ctx:      oldSession,
+			check:    wallet.RiskCheck{OwnerXID: "blocked", Type: wallet.TransactionTypeCashback, Amount: 10000},
+			decision: wallet.RiskDecisionAllow,
+		},
+	}
+	for _, c := range cases {
+		c.check.At = now
+		if got := engine.Assess(c.ctx, c.check); got.Decision != c.decision {
+			t.Fatalf("%s: expecting %s, got %s (%v)", c.name, c.decision, got.Decision, got.Reasons)
+		}
+	}
+}
+
+func TestInvalidConfig(t *testing.T) {
+	configs := []risk.Config{
+		{VelocityCount: -1},
+		{VelocityCount: 3},
+		{LargeWithdrawal: 1000000},
+	}
+	for _, c := range configs {
+		if _, err := risk.NewEngine(c); err != risk.ErrInvalidConfig {
+			t.Fatalf("expecting error %s for %+v, got %v", risk.ErrInvalidConfig, c, err)
+		}
+	}
+}
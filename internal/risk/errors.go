@@ -0,0 +1,7 @@
+package risk
+
+import "errors"
+
+var (
+	ErrInvalidConfig = errors.New("invalid risk config")
+)
@@ -0,0 +1,14 @@
+package schedule
+
+import "errors"
+
+var (
+	ErrScheduleNotFound         = errors.New("schedule not found")
+	ErrScheduleCancelled        = errors.New("schedule is cancelled")
+	ErrSchedulePaused           = errors.New("schedule is paused")
+	ErrScheduleActive           = errors.New("schedule is active")
+	ErrScheduleCompleted        = errors.New("schedule is completed")
+	ErrInvalidRecurrence        = errors.New("invalid recurrence")
+	ErrMissingRequiredParameter = errors.New("missing required parameter")
+	ErrInvalidAmount            = errors.New("invalid amount")
+)
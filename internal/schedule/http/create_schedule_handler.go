@@ -0,0 +1,59 @@
+package http
+
+import (
+	"julo/internal/auth"
+	httphelper "julo/internal/http"
+	"julo/internal/schedule"
+	"julo/internal/wallet"
+	"net/http"
+	"time"
+)
+
+func CreateScheduleHandler(schedules schedule.Service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var response httphelper.Response
+		session := auth.SessionFromContext(r.Context())
+		if session == nil {
+			httphelper.WriteErrorJSON(w, http.StatusUnauthorized, auth.ErrSessionNotFound)
+			return
+		}
+
+		var req createScheduleRequest
+		if err := httphelper.Decode(w, r, &req); err != nil {
+			httphelper.WriteRequestErrorJSON(w, err)
+			return
+		}
+
+		s, err := schedules.CreateSchedule(r.Context(), schedule.CreateScheduleParam{
+			OwnerXID:     session.Account.XID,
+			RecipientXID: req.RecipientXID,
+			Amount:       req.Amount,
+			Recurrence:   req.Recurrence,
+			Description:  req.Description,
+			StartsAt:     req.StartsAt,
+		})
+		if err != nil {
+			switch err {
+			case schedule.ErrMissingRequiredParameter, schedule.ErrInvalidAmount, schedule.ErrInvalidRecurrence, wallet.ErrSameWalletTransfer, wallet.ErrWalletNotFound:
+				httphelper.WriteErrorJSON(w, http.StatusBadRequest, err)
+			default:
+				httphelper.WriteErrorJSON(w, http.StatusInternalServerError, err)
+			}
+			return
+		}
+
+		response.Status = "success"
+		response.Data = map[string]interface{}{
+			"schedule": newScheduleResponse(*s),
+		}
+		httphelper.WriteJSON(w, http.StatusCreated, response)
+	})
+}
+
+type createScheduleRequest struct {
+	RecipientXID string    `json:"recipient_xid" validate:"required"`
+	Amount       int       `json:"amount" validate:"required"`
+	Recurrence   string    `json:"recurrence" validate:"required"`
+	Description  string    `json:"description"`
+	StartsAt     time.Time `json:"starts_at"`
+}
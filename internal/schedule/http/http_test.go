@@ -0,0 +1,181 @@
+package http_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"julo/internal/account"
+	"julo/internal/auth"
+	authhttp "julo/internal/auth/http"
+	httphelper "julo/internal/http"
+	"julo/internal/schedule"
+	schedulehttp "julo/internal/schedule/http"
+	"julo/internal/wallet"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/google/uuid"
+)
+
+func TestSchedules(t *testing.T) {
+	ctx := context.Background()
+	wallets := wallet.NewService(wallet.NewInMemoryRepository())
+	schedules := schedule.NewService(schedule.NewInMemoryRepository(), wallets, schedule.LogNotifier{}, schedule.Config{})
+
+	router := chi.NewRouter()
+	router.Mount("/api/v1", router.Group(func(r chi.Router) {
+		r.Mount("/wallet", r.Group(func(r chi.Router) {
+			r.Use(authhttp.Middleware)
+			r.Get("/schedules", schedulehttp.ViewSchedulesHandler(schedules).ServeHTTP)
+			r.Post("/schedules", schedulehttp.CreateScheduleHandler(schedules).ServeHTTP)
+			r.Post("/schedules/{id}/pause", schedulehttp.PauseScheduleHandler(schedules).ServeHTTP)
+			r.Post("/schedules/{id}/resume", schedulehttp.ResumeScheduleHandler(schedules).ServeHTTP)
+			r.Delete("/schedules/{id}", schedulehttp.CancelScheduleHandler(schedules).ServeHTTP)
+		}))
+	}))
+	server := httptest.NewServer(router)
+	defer server.Close()
+	baseUrl := server.URL
+
+	owner, recipient := uuid.NewString(), uuid.NewString()
+	for _, xid := range []string{owner, recipient} {
+		_, err := wallets.EnableWallet(ctx, wallet.EnableWalletParam{
+			OwnerXID: xid,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	token := uuid.NewString()
+	err := auth.StoreSession(ctx, auth.Session{
+		Token:   token,
+		Account: account.Account{XID: owner},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("create schedule, should success", func(t *testing.T) {
+		form := url.Values{}
+		form.Set("recipient_xid", recipient)
+		form.Set("amount", "10000")
+		form.Set("recurrence", "0 9 1 * *")
+		form.Set("description", "rent")
+		req := buildAuthenticatedRequest(t, http.MethodPost, baseUrl+"/api/v1/wallet/schedules", token, bytes.NewBufferString(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		res, err := server.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.StatusCode != http.StatusCreated {
+			t.Fatalf("expecting status %v, got %v", http.StatusCreated, res.StatusCode)
+		}
+
+		var response httphelper.Response
+		err = json.NewDecoder(res.Body).Decode(&response)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data := response.Data.(map[string]interface{})
+		s := data["schedule"].(map[string]interface{})
+		id := s["id"].(string)
+		nextRunAt, err := time.Parse(time.RFC3339, s["next_run_at"].(string))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if nextRunAt.Day() != 1 || nextRunAt.Hour() != 9 {
+			t.Fatalf("expecting next run on the 1st at 9, got %s", nextRunAt)
+		}
+
+		t.Run("pause schedule, should success", func(t *testing.T) {
+			req := buildAuthenticatedRequest(t, http.MethodPost, baseUrl+"/api/v1/wallet/schedules/"+id+"/pause", token, nil)
+			res, err := server.Client().Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.StatusCode != http.StatusOK {
+				t.Fatalf("expecting status %v, got %v", http.StatusOK, res.StatusCode)
+			}
+
+			t.Run("pause paused schedule, should fail", func(t *testing.T) {
+				req := buildAuthenticatedRequest(t, http.MethodPost, baseUrl+"/api/v1/wallet/schedules/"+id+"/pause", token, nil)
+				res, err := server.Client().Do(req)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if res.StatusCode != http.StatusBadRequest {
+					t.Fatalf("expecting status %v, got %v", http.StatusBadRequest, res.StatusCode)
+				}
+			})
+		})
+
+		t.Run("cancel schedule, should success", func(t *testing.T) {
+			req := buildAuthenticatedRequest(t, http.MethodDelete, baseUrl+"/api/v1/wallet/schedules/"+id, token, nil)
+			res, err := server.Client().Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.StatusCode != http.StatusOK {
+				t.Fatalf("expecting status %v, got %v", http.StatusOK, res.StatusCode)
+			}
+		})
+
+		t.Run("list schedules, should return cancelled schedule", func(t *testing.T) {
+			req := buildAuthenticatedRequest(t, http.MethodGet, baseUrl+"/api/v1/wallet/schedules", token, nil)
+			res, err := server.Client().Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.StatusCode != http.StatusOK {
+				t.Fatalf("expecting status %v, got %v", http.StatusOK, res.StatusCode)
+			}
+
+			var response httphelper.Response
+			err = json.NewDecoder(res.Body).Decode(&response)
+			if err != nil {
+				t.Fatal(err)
+			}
+			data := response.Data.(map[string]interface{})
+			list := data["schedules"].([]interface{})
+			if len(list) != 1 {
+				t.Fatalf("expecting %d schedule, got %d", 1, len(list))
+			}
+			if status := list[0].(map[string]interface{})["status"]; status != "cancelled" {
+				t.Fatalf("expecting status %s, got %s", "cancelled", status)
+			}
+		})
+	})
+
+	t.Run("create schedule with invalid recurrence, should fail", func(t *testing.T) {
+		form := url.Values{}
+		form.Set("recipient_xid", recipient)
+		form.Set("amount", "10000")
+		form.Set("recurrence", "every month")
+		req := buildAuthenticatedRequest(t, http.MethodPost, baseUrl+"/api/v1/wallet/schedules", token, bytes.NewBufferString(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		res, err := server.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.StatusCode != http.StatusBadRequest {
+			t.Fatalf("expecting status %v, got %v", http.StatusBadRequest, res.StatusCode)
+		}
+	})
+}
+
+func buildAuthenticatedRequest(t *testing.T, method string, url string, token string, body io.Reader) *http.Request {
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Add("Authorization", fmt.Sprintf("Token %s", token))
+	return req
+}
@@ -0,0 +1,38 @@
+package http
+
+import (
+	"julo/internal/schedule"
+	"time"
+)
+
+type scheduleResponse struct {
+	ID                string    `json:"id"`
+	OwnedBy           string    `json:"owned_by"`
+	RecipientXID      string    `json:"recipient_xid"`
+	Amount            int       `json:"amount"`
+	Description       string    `json:"description"`
+	Recurrence        string    `json:"recurrence"`
+	Status            string    `json:"status"`
+	NextRunAt         time.Time `json:"next_run_at"`
+	LastRunAt         time.Time `json:"last_run_at"`
+	LastTransactionID string    `json:"last_transaction_id"`
+	LastError         string    `json:"last_error"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+func newScheduleResponse(s schedule.Schedule) scheduleResponse {
+	return scheduleResponse{
+		ID:                s.ID,
+		OwnedBy:           s.OwnerXID,
+		RecipientXID:      s.RecipientXID,
+		Amount:            s.Amount,
+		Description:       s.Description,
+		Recurrence:        s.Recurrence,
+		Status:            string(s.Status),
+		NextRunAt:         s.NextRunAt,
+		LastRunAt:         s.LastRunAt,
+		LastTransactionID: s.LastTransactionID,
+		LastError:         s.LastError,
+		CreatedAt:         s.CreatedAt,
+	}
+}
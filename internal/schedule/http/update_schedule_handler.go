@@ -0,0 +1,60 @@
+package http
+
+import (
+	"context"
+	"julo/internal/auth"
+	httphelper "julo/internal/http"
+	"julo/internal/schedule"
+	"net/http"
+
+	"github.com/go-chi/chi"
+)
+
+type scheduleAction func(context.Context, schedule.ScheduleParam) (*schedule.Schedule, error)
+
+// PauseScheduleHandler, ResumeScheduleHandler and CancelScheduleHandler act
+// on the schedule identified by the "id" url parameter.
+func PauseScheduleHandler(schedules schedule.Service) http.Handler {
+	return updateScheduleHandler(schedules.PauseSchedule)
+}
+
+func ResumeScheduleHandler(schedules schedule.Service) http.Handler {
+	return updateScheduleHandler(schedules.ResumeSchedule)
+}
+
+func CancelScheduleHandler(schedules schedule.Service) http.Handler {
+	return updateScheduleHandler(schedules.CancelSchedule)
+}
+
+func updateScheduleHandler(action scheduleAction) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var response httphelper.Response
+		session := auth.SessionFromContext(r.Context())
+		if session == nil {
+			httphelper.WriteErrorJSON(w, http.StatusUnauthorized, auth.ErrSessionNotFound)
+			return
+		}
+
+		s, err := action(r.Context(), schedule.ScheduleParam{
+			OwnerXID:   session.Account.XID,
+			ScheduleID: chi.URLParam(r, "id"),
+		})
+		if err != nil {
+			switch err {
+			case schedule.ErrScheduleNotFound:
+				httphelper.WriteErrorJSON(w, http.StatusNotFound, err)
+			case schedule.ErrSchedulePaused, schedule.ErrScheduleActive, schedule.ErrScheduleCancelled, schedule.ErrScheduleCompleted:
+				httphelper.WriteErrorJSON(w, http.StatusBadRequest, err)
+			default:
+				httphelper.WriteErrorJSON(w, http.StatusInternalServerError, err)
+			}
+			return
+		}
+
+		response.Status = "success"
+		response.Data = map[string]interface{}{
+			"schedule": newScheduleResponse(*s),
+		}
+		httphelper.WriteJSON(w, http.StatusOK, response)
+	})
+}
@@ -0,0 +1,36 @@
+package http
+
+import (
+	"julo/internal/auth"
+	httphelper "julo/internal/http"
+	"julo/internal/schedule"
+	"net/http"
+)
+
+func ViewSchedulesHandler(schedules schedule.Service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var response httphelper.Response
+		session := auth.SessionFromContext(r.Context())
+		if session == nil {
+			httphelper.WriteErrorJSON(w, http.StatusUnauthorized, auth.ErrSessionNotFound)
+			return
+		}
+
+		result, err := schedules.GetSchedules(r.Context(), session.Account.XID)
+		if err != nil {
+			httphelper.WriteErrorJSON(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		data := make([]scheduleResponse, 0, len(result))
+		for _, s := range result {
+			data = append(data, newScheduleResponse(s))
+		}
+
+		response.Status = "success"
+		response.Data = map[string]interface{}{
+			"schedules": data,
+		}
+		httphelper.WriteJSON(w, http.StatusOK, response)
+	})
+}
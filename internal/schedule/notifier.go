@@ -0,0 +1,18 @@
+package schedule
+
+import (
+	"context"
+	"log"
+)
+
+// Notifier is told about schedules whose execution kept failing after all
+// retries, so the owner can be warned that a transfer did not happen.
+type Notifier interface {
+	NotifyFailure(ctx context.Context, s Schedule, err error)
+}
+
+type LogNotifier struct{}
+
+func (LogNotifier) NotifyFailure(ctx context.Context, s Schedule, err error) {
+	log.Printf("schedule %s of %s failed after %d attempts: %s", s.ID, s.OwnerXID, s.FailedAttempts, err)
+}
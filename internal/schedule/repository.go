@@ -0,0 +1,102 @@
+package schedule
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+type ScheduleStatus string
+
+var (
+	ScheduleStatusActive    = ScheduleStatus("active")
+	ScheduleStatusPaused    = ScheduleStatus("paused")
+	ScheduleStatusCancelled = ScheduleStatus("cancelled")
+	// ScheduleStatusCompleted is a schedule whose recurrence has no
+	// occurrence left.
+	ScheduleStatusCompleted = ScheduleStatus("completed")
+)
+
+// Schedule is a standing instruction to transfer Amount from the wallet of
+// OwnerXID to the wallet of RecipientXID every time Recurrence is due.
+type Schedule struct {
+	ID           string
+	OwnerXID     string
+	RecipientXID string
+	Amount       int
+	Description  string
+	Recurrence   string
+	Status       ScheduleStatus
+	// DueAt is the occurrence to be executed next, NextRunAt is when it is
+	// attempted; they differ while a failed execution is being retried.
+	DueAt             time.Time
+	NextRunAt         time.Time
+	LastRunAt         time.Time
+	LastTransactionID string
+	FailedAttempts    int
+	LastError         string
+	CreatedAt         time.Time
+}
+
+type Repository interface {
+	CreateSchedule(ctx context.Context, s Schedule) error
+	UpdateSchedule(ctx context.Context, s Schedule) error
+	GetSchedule(ctx context.Context, id string) (*Schedule, error)
+	GetSchedulesByOwner(ctx context.Context, ownerXID string) ([]Schedule, error)
+	GetDueSchedules(ctx context.Context, now time.Time) ([]Schedule, error)
+}
+
+type InMemoryRepository struct {
+	store sync.Map
+}
+
+func NewInMemoryRepository() Repository {
+	return &InMemoryRepository{}
+}
+
+func (r *InMemoryRepository) CreateSchedule(ctx context.Context, s Schedule) error {
+	r.store.Store(s.ID, &s)
+	return nil
+}
+
+func (r *InMemoryRepository) UpdateSchedule(ctx context.Context, s Schedule) error {
+	r.store.Store(s.ID, &s)
+	return nil
+}
+
+func (r *InMemoryRepository) GetSchedule(ctx context.Context, id string) (*Schedule, error) {
+	v, ok := r.store.Load(id)
+	if !ok {
+		return nil, ErrScheduleNotFound
+	}
+	s := *v.(*Schedule)
+	return &s, nil
+}
+
+func (r *InMemoryRepository) GetSchedulesByOwner(ctx context.Context, ownerXID string) ([]Schedule, error) {
+	return r.filter(func(s *Schedule) bool {
+		return s.OwnerXID == ownerXID
+	}), nil
+}
+
+func (r *InMemoryRepository) GetDueSchedules(ctx context.Context, now time.Time) ([]Schedule, error) {
+	return r.filter(func(s *Schedule) bool {
+		return s.Status == ScheduleStatusActive && !s.NextRunAt.After(now)
+	}), nil
+}
+
+func (r *InMemoryRepository) filter(match func(*Schedule) bool) []Schedule {
+	schedules := []Schedule{}
+	r.store.Range(func(key, value any) bool {
+		s := value.(*Schedule)
+		if match(s) {
+			schedules = append(schedules, *s)
+		}
+		return true
+	})
+	sort.Slice(schedules, func(i, j int) bool {
+		return schedules[i].CreatedAt.Before(schedules[j].CreatedAt)
+	})
+	return schedules
+}
@@ -0,0 +1,293 @@
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"julo/internal/wallet"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+type CreateScheduleParam struct {
+	OwnerXID     string
+	RecipientXID string
+	Amount       int
+	Recurrence   string
+	Description  string
+	// StartsAt is the first occurrence, when zero the first occurrence is
+	// the next one of the recurrence.
+	StartsAt time.Time
+}
+
+type ScheduleParam struct {
+	OwnerXID   string
+	ScheduleID string
+}
+
+type Config struct {
+	// MaxAttempts is how many times an occurrence is executed before it is
+	// given up and the failure notified.
+	MaxAttempts int
+	// RetryDelay is the wait before the first retry, doubled on each
+	// following one.
+	RetryDelay time.Duration
+}
+
+type Service interface {
+	CreateSchedule(ctx context.Context, param CreateScheduleParam) (*Schedule, error)
+	GetSchedules(ctx context.Context, ownerXID string) ([]Schedule, error)
+	PauseSchedule(ctx context.Context, param ScheduleParam) (*Schedule, error)
+	ResumeSchedule(ctx context.Context, param ScheduleParam) (*Schedule, error)
+	CancelSchedule(ctx context.Context, param ScheduleParam) (*Schedule, error)
+	RunDue(ctx context.Context, now time.Time) error
+}
+
+type service struct {
+	repo     Repository
+	wallets  wallet.Service
+	notifier Notifier
+	config   Config
+	mu       sync.Mutex
+}
+
+func NewService(repo Repository, wallets wallet.Service, notifier Notifier, config Config) Service {
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = 1
+	}
+	return &service{
+		repo:     repo,
+		wallets:  wallets,
+		notifier: notifier,
+		config:   config,
+	}
+}
+
+func (s *service) CreateSchedule(ctx context.Context, param CreateScheduleParam) (*Schedule, error) {
+	if param.OwnerXID == "" || param.RecipientXID == "" {
+		return nil, ErrMissingRequiredParameter
+	}
+	if param.RecipientXID == param.OwnerXID {
+		return nil, wallet.ErrSameWalletTransfer
+	}
+	if param.Amount <= 0 {
+		return nil, ErrInvalidAmount
+	}
+	rec, err := ParseRecurrence(param.Recurrence)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.wallets.GetWalletByXID(ctx, param.RecipientXID)
+	if err != nil && err == wallet.ErrWalletNotFound {
+		return nil, wallet.ErrWalletNotFound
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed getting recipient wallet")
+	}
+
+	// a recurrence matching no real date, like "0 0 31 2 *", would never
+	// run again after its first occurrence
+	now := time.Now()
+	next := rec.Next(now)
+	if next.IsZero() {
+		return nil, ErrInvalidRecurrence
+	}
+	due := param.StartsAt
+	if due.IsZero() {
+		due = next
+	}
+
+	schedule := Schedule{
+		ID:           uuid.NewString(),
+		OwnerXID:     param.OwnerXID,
+		RecipientXID: param.RecipientXID,
+		Amount:       param.Amount,
+		Description:  param.Description,
+		Recurrence:   param.Recurrence,
+		Status:       ScheduleStatusActive,
+		DueAt:        due,
+		NextRunAt:    due,
+		CreatedAt:    now,
+	}
+	err = s.repo.CreateSchedule(ctx, schedule)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed creating schedule")
+	}
+
+	return &schedule, nil
+}
+
+func (s *service) GetSchedules(ctx context.Context, ownerXID string) ([]Schedule, error) {
+	schedules, err := s.repo.GetSchedulesByOwner(ctx, ownerXID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed getting schedules")
+	}
+	return schedules, nil
+}
+
+func (s *service) PauseSchedule(ctx context.Context, param ScheduleParam) (*Schedule, error) {
+	return s.update(ctx, param, func(schedule *Schedule) error {
+		switch schedule.Status {
+		case ScheduleStatusPaused:
+			return ErrSchedulePaused
+		case ScheduleStatusCancelled:
+			return ErrScheduleCancelled
+		case ScheduleStatusCompleted:
+			return ErrScheduleCompleted
+		}
+		schedule.Status = ScheduleStatusPaused
+		return nil
+	})
+}
+
+// ResumeSchedule reactivates a paused schedule. Occurrences that fell due
+// while it was paused are skipped rather than executed all at once.
+func (s *service) ResumeSchedule(ctx context.Context, param ScheduleParam) (*Schedule, error) {
+	return s.update(ctx, param, func(schedule *Schedule) error {
+		switch schedule.Status {
+		case ScheduleStatusActive:
+			return ErrScheduleActive
+		case ScheduleStatusCancelled:
+			return ErrScheduleCancelled
+		case ScheduleStatusCompleted:
+			return ErrScheduleCompleted
+		}
+
+		schedule.Status = ScheduleStatusActive
+		schedule.NextRunAt = schedule.DueAt
+		schedule.FailedAttempts = 0
+		now := time.Now()
+		if schedule.DueAt.Before(now) {
+			rec, err := ParseRecurrence(schedule.Recurrence)
+			if err != nil {
+				return err
+			}
+			advance(schedule, rec, now)
+		}
+		return nil
+	})
+}
+
+func (s *service) CancelSchedule(ctx context.Context, param ScheduleParam) (*Schedule, error) {
+	return s.update(ctx, param, func(schedule *Schedule) error {
+		switch schedule.Status {
+		case ScheduleStatusCancelled:
+			return ErrScheduleCancelled
+		case ScheduleStatusCompleted:
+			return ErrScheduleCompleted
+		}
+		schedule.Status = ScheduleStatusCancelled
+		return nil
+	})
+}
+
+func (s *service) update(ctx context.Context, param ScheduleParam, fn func(*Schedule) error) (*Schedule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	schedule, err := s.repo.GetSchedule(ctx, param.ScheduleID)
+	if err != nil && err == ErrScheduleNotFound {
+		return nil, ErrScheduleNotFound
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed getting schedule")
+	}
+	// other customers' schedules are reported as not found so their ids
+	// cannot be probed
+	if schedule.OwnerXID != param.OwnerXID {
+		return nil, ErrScheduleNotFound
+	}
+
+	err = fn(schedule)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.repo.UpdateSchedule(ctx, *schedule)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed updating schedule")
+	}
+	return schedule, nil
+}
+
+// RunDue executes every active schedule due at now. A failed execution is
+// retried with an exponential backoff, once it ran out of attempts the
+// failure is notified and the schedule moves on to its next occurrence.
+func (s *service) RunDue(ctx context.Context, now time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	schedules, err := s.repo.GetDueSchedules(ctx, now)
+	if err != nil {
+		return errors.Wrap(err, "failed getting due schedules")
+	}
+
+	for _, schedule := range schedules {
+		err := s.execute(ctx, &schedule, now)
+		if err != nil {
+			log.Printf("failed executing schedule %s: %s", schedule.ID, err)
+		}
+	}
+	return nil
+}
+
+func (s *service) execute(ctx context.Context, schedule *Schedule, now time.Time) error {
+	rec, err := ParseRecurrence(schedule.Recurrence)
+	if err != nil {
+		return err
+	}
+
+	// the reference is derived from the occurrence so retrying it never
+	// transfers twice
+	result, terr := s.wallets.TransferWallet(ctx, wallet.TransferWalletParam{
+		ActorXID:    schedule.OwnerXID,
+		FromXID:     schedule.OwnerXID,
+		ToXID:       schedule.RecipientXID,
+		ReferenceID: fmt.Sprintf("%sschedule-%s-%d", wallet.SystemReferencePrefix, schedule.ID, schedule.DueAt.Unix()),
+		Amount:      schedule.Amount,
+	})
+	if terr == nil {
+		schedule.LastRunAt = now
+		schedule.LastTransactionID = result.Debit.ID
+		schedule.LastError = ""
+		advance(schedule, rec, now)
+	} else {
+		schedule.FailedAttempts++
+		schedule.LastError = terr.Error()
+		if schedule.FailedAttempts < s.config.MaxAttempts {
+			schedule.NextRunAt = now.Add(s.config.RetryDelay << (schedule.FailedAttempts - 1))
+		} else {
+			s.notifier.NotifyFailure(ctx, *schedule, terr)
+			advance(schedule, rec, now)
+		}
+	}
+
+	err = s.repo.UpdateSchedule(ctx, *schedule)
+	if err != nil {
+		return errors.Wrap(err, "failed updating schedule")
+	}
+	return terr
+}
+
+// advance moves schedule on to its next occurrence after now, completing it
+// when the recurrence has none left.
+func advance(schedule *Schedule, rec Recurrence, now time.Time) {
+	schedule.FailedAttempts = 0
+	schedule.DueAt = nextOccurrence(rec, schedule.DueAt, now)
+	schedule.NextRunAt = schedule.DueAt
+	if schedule.DueAt.IsZero() {
+		schedule.Status = ScheduleStatusCompleted
+	}
+}
+
+// nextOccurrence is the first occurrence of rec after due that is still in
+// the future, so occurrences missed while the worker was down are skipped.
+func nextOccurrence(rec Recurrence, due time.Time, now time.Time) time.Time {
+	next := rec.Next(due)
+	for !next.IsZero() && !next.After(now) {
+		next = rec.Next(next)
+	}
+	return next
+}
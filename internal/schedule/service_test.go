@@ -0,0 +1,254 @@
+package schedule_test
+
+import (
+	"context"
+	"julo/internal/schedule"
+	"julo/internal/wallet"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type notifierFunc func(context.Context, schedule.Schedule, error)
+
+func (f notifierFunc) NotifyFailure(ctx context.Context, s schedule.Schedule, err error) {
+	f(ctx, s, err)
+}
+
+func TestParseRecurrence(t *testing.T) {
+	from := time.Date(2023, time.January, 31, 10, 30, 0, 0, time.UTC)
+	cases := []struct {
+		spec string
+		next time.Time
+	}{
+		{"@every 1h", from.Add(time.Hour)},
+		{"@daily", time.Date(2023, time.February, 1, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2023, time.February, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 9 25 * *", time.Date(2023, time.February, 25, 9, 0, 0, 0, time.UTC)},
+		{"*/15 10-11 * * *", time.Date(2023, time.January, 31, 10, 45, 0, 0, time.UTC)},
+		{"0 8 * * 1,5", time.Date(2023, time.February, 3, 8, 0, 0, 0, time.UTC)},
+	}
+	for _, c := range cases {
+		rec, err := schedule.ParseRecurrence(c.spec)
+		if err != nil {
+			t.Fatalf("%s: %s", c.spec, err)
+		}
+		if next := rec.Next(from); !next.Equal(c.next) {
+			t.Fatalf("%s: expecting next %s, got %s", c.spec, c.next, next)
+		}
+	}
+
+	for _, spec := range []string{"", "* * *", "60 * * * *", "@every 1s", "0 0 31 2 x"} {
+		_, err := schedule.ParseRecurrence(spec)
+		if err != schedule.ErrInvalidRecurrence {
+			t.Fatalf("%q: expecting error %s, got %v", spec, schedule.ErrInvalidRecurrence, err)
+		}
+	}
+}
+
+func TestRunDue(t *testing.T) {
+	ctx := context.Background()
+	wallets := wallet.NewService(wallet.NewInMemoryRepository())
+	failures := []error{}
+	notifier := notifierFunc(func(ctx context.Context, s schedule.Schedule, err error) {
+		failures = append(failures, err)
+	})
+	schedules := schedule.NewService(schedule.NewInMemoryRepository(), wallets, notifier, schedule.Config{
+		MaxAttempts: 2,
+		RetryDelay:  time.Minute,
+	})
+
+	owner, recipient := uuid.NewString(), uuid.NewString()
+	for _, xid := range []string{owner, recipient} {
+		_, err := wallets.EnableWallet(ctx, wallet.EnableWalletParam{
+			OwnerXID: xid,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err := wallets.DepositWallet(ctx, wallet.WalletTransactionParam{
+		ActorXID:    owner,
+		OwnerXID:    owner,
+		ReferenceID: uuid.NewString(),
+		Amount:      15000,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	s, err := schedules.CreateSchedule(ctx, schedule.CreateScheduleParam{
+		OwnerXID:     owner,
+		RecipientXID: recipient,
+		Amount:       10000,
+		Recurrence:   "@every 24h",
+		StartsAt:     now,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	balance := func(t *testing.T, xid string) int {
+		wal, err := wallets.GetWalletByXID(ctx, xid)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return wal.Balance
+	}
+
+	t.Run("run due schedule, should transfer", func(t *testing.T) {
+		err := schedules.RunDue(ctx, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b := balance(t, recipient); b != 10000 {
+			t.Fatalf("expecting balance %d, got %d", 10000, b)
+		}
+
+		t.Run("run again before next occurrence, should not transfer", func(t *testing.T) {
+			err := schedules.RunDue(ctx, now.Add(time.Hour))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if b := balance(t, recipient); b != 10000 {
+				t.Fatalf("expecting balance %d, got %d", 10000, b)
+			}
+		})
+	})
+
+	t.Run("run with insufficient balance, should retry then notify", func(t *testing.T) {
+		next := now.Add(24 * time.Hour)
+		err := schedules.RunDue(ctx, next)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(failures) != 0 {
+			t.Fatalf("expecting no failure notified before retries, got %d", len(failures))
+		}
+
+		err = schedules.RunDue(ctx, next.Add(time.Minute))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(failures) != 1 {
+			t.Fatalf("expecting %d failure notified, got %d", 1, len(failures))
+		}
+
+		result, err := schedules.GetSchedules(ctx, owner)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !result[0].NextRunAt.Equal(s.DueAt.Add(48 * time.Hour)) {
+			t.Fatalf("expecting next run at %s, got %s", s.DueAt.Add(48*time.Hour), result[0].NextRunAt)
+		}
+	})
+
+	t.Run("pause schedule, should not run", func(t *testing.T) {
+		_, err := schedules.PauseSchedule(ctx, schedule.ScheduleParam{
+			OwnerXID:   owner,
+			ScheduleID: s.ID,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = schedules.RunDue(ctx, now.Add(72*time.Hour))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b := balance(t, recipient); b != 10000 {
+			t.Fatalf("expecting balance %d, got %d", 10000, b)
+		}
+	})
+
+	t.Run("cancel schedule of another owner, should fail", func(t *testing.T) {
+		_, err := schedules.CancelSchedule(ctx, schedule.ScheduleParam{
+			OwnerXID:   recipient,
+			ScheduleID: s.ID,
+		})
+		if err != schedule.ErrScheduleNotFound {
+			t.Fatalf("expecting error %s, got %s", schedule.ErrScheduleNotFound, err)
+		}
+	})
+}
+
+func TestScheduleWithoutNextOccurrence(t *testing.T) {
+	ctx := context.Background()
+	wallets := wallet.NewService(wallet.NewInMemoryRepository())
+	repo := schedule.NewInMemoryRepository()
+	schedules := schedule.NewService(repo, wallets, schedule.LogNotifier{}, schedule.Config{})
+
+	owner, recipient := uuid.NewString(), uuid.NewString()
+	for _, xid := range []string{owner, recipient} {
+		_, err := wallets.EnableWallet(ctx, wallet.EnableWalletParam{OwnerXID: xid})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err := wallets.DepositWallet(ctx, wallet.WalletTransactionParam{
+		ActorXID:    owner,
+		OwnerXID:    owner,
+		ReferenceID: uuid.NewString(),
+		Amount:      10000,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("create with a recurrence that never fires, should fail", func(t *testing.T) {
+		_, err := schedules.CreateSchedule(ctx, schedule.CreateScheduleParam{
+			OwnerXID:     owner,
+			RecipientXID: recipient,
+			Amount:       10000,
+			Recurrence:   "0 0 31 2 *",
+			StartsAt:     time.Now(),
+		})
+		if err != schedule.ErrInvalidRecurrence {
+			t.Fatalf("expecting error %s, got %v", schedule.ErrInvalidRecurrence, err)
+		}
+	})
+
+	t.Run("run the last occurrence, should complete the schedule", func(t *testing.T) {
+		now := time.Now()
+		err := repo.CreateSchedule(ctx, schedule.Schedule{
+			ID:           uuid.NewString(),
+			OwnerXID:     owner,
+			RecipientXID: recipient,
+			Amount:       10000,
+			Recurrence:   "0 0 31 2 *",
+			Status:       schedule.ScheduleStatusActive,
+			DueAt:        now,
+			NextRunAt:    now,
+			CreatedAt:    now,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = schedules.RunDue(ctx, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		result, err := schedules.GetSchedules(ctx, owner)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(result) != 1 || result[0].Status != schedule.ScheduleStatusCompleted {
+			t.Fatalf("expecting completed schedule, got %+v", result)
+		}
+		due, err := repo.GetDueSchedules(ctx, now.Add(time.Minute))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(due) != 0 {
+			t.Fatalf("expecting no due schedule, got %d", len(due))
+		}
+
+		_, err = schedules.ResumeSchedule(ctx, schedule.ScheduleParam{OwnerXID: owner, ScheduleID: result[0].ID})
+		if err != schedule.ErrScheduleCompleted {
+			t.Fatalf("expecting error %s, got %v", schedule.ErrScheduleCompleted, err)
+		}
+	})
+}
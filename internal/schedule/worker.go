@@ -0,0 +1,37 @@
+package schedule
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Worker periodically executes the schedules that are due.
+type Worker struct {
+	schedules Service
+	interval  time.Duration
+}
+
+func NewWorker(schedules Service, interval time.Duration) *Worker {
+	return &Worker{
+		schedules: schedules,
+		interval:  interval,
+	}
+}
+
+// Run blocks, executing due schedules every interval until ctx is done.
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := w.schedules.RunDue(ctx, now); err != nil {
+				log.Println(err)
+			}
+		}
+	}
+}
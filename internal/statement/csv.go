@@ -0,0 +1,44 @@
+package statement
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var csvHeader = []string{"date", "transaction_id", "reference_id", "type", "debit", "credit", "balance"}
+
+// WriteCSV writes s as CSV, one row per transaction between a row for the
+// opening balance and one for the closing balance. Amounts are plain
+// integers and dates RFC 3339.
+func (s Statement) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	rows := [][]string{
+		csvHeader,
+		{s.From.Format(time.RFC3339), "", "", "opening_balance", "", "", strconv.Itoa(s.OpeningBalance)},
+	}
+	for _, l := range s.Lines {
+		debit, credit := "", ""
+		if l.Transaction.Type.IsCredit() {
+			credit = strconv.Itoa(l.Transaction.Amount)
+		} else {
+			debit = strconv.Itoa(l.Transaction.Amount)
+		}
+		rows = append(rows, []string{
+			l.Transaction.SettledAt.Format(time.RFC3339),
+			l.Transaction.ID,
+			l.Transaction.ReferenceID,
+			string(l.Transaction.Type),
+			debit,
+			credit,
+			strconv.Itoa(l.Balance),
+		})
+	}
+	rows = append(rows, []string{s.To.Format(time.RFC3339), "", "", "closing_balance", "", "", strconv.Itoa(s.ClosingBalance)})
+
+	err := cw.WriteAll(rows)
+	return errors.Wrap(err, "failed writing statement csv")
+}
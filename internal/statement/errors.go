@@ -0,0 +1,9 @@
+package statement
+
+import "errors"
+
+var (
+	ErrMissingRequiredParameter = errors.New("missing required parameter")
+	ErrInvalidMonth             = errors.New("month must be a past or current month as YYYY-MM")
+	ErrInvalidFormat            = errors.New("format must be csv or pdf")
+)
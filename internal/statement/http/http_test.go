@@ -0,0 +1,112 @@
+package http_test
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"julo/internal/account"
+	"julo/internal/auth"
+	authhttp "julo/internal/auth/http"
+	"julo/internal/statement"
+	statementhttp "julo/internal/statement/http"
+	"julo/internal/wallet"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/google/uuid"
+)
+
+func TestViewStatement(t *testing.T) {
+	ctx := context.Background()
+	wallets := wallet.NewService(wallet.NewInMemoryRepository())
+	statements := statement.NewService(wallets)
+
+	router := chi.NewRouter()
+	router.Mount("/api/v1", router.Group(func(r chi.Router) {
+		r.Mount("/wallet", r.Group(func(r chi.Router) {
+			r.Use(authhttp.Middleware)
+			r.Get("/statements", statementhttp.ViewStatementHandler(statements).ServeHTTP)
+		}))
+	}))
+	server := httptest.NewServer(router)
+	defer server.Close()
+	baseUrl := server.URL
+
+	xid, token := uuid.NewString(), uuid.NewString()
+	err := auth.StoreSession(ctx, auth.Session{Token: token, Account: account.Account{XID: xid}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = wallets.EnableWallet(ctx, wallet.EnableWalletParam{OwnerXID: xid})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = wallets.DepositWallet(ctx, wallet.WalletTransactionParam{
+		ActorXID:    xid,
+		OwnerXID:    xid,
+		ReferenceID: uuid.NewString(),
+		Amount:      25000,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	month := time.Now().Format(statement.MonthLayout)
+
+	t.Run("download csv statement, should list deposit", func(t *testing.T) {
+		res := get(t, server, baseUrl+"/api/v1/wallet/statements?month="+month, token)
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("expecting status %v, got %v", http.StatusOK, res.StatusCode)
+		}
+		if ct := res.Header.Get("Content-Type"); ct != "text/csv" {
+			t.Fatalf("expecting csv, got %s", ct)
+		}
+		rows, err := csv.NewReader(res.Body).ReadAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rows) != 4 || rows[2][5] != "25000" || rows[3][6] != "25000" {
+			t.Fatalf("unexpected rows %v", rows)
+		}
+	})
+
+	t.Run("download pdf statement, should success", func(t *testing.T) {
+		res := get(t, server, baseUrl+"/api/v1/wallet/statements?format=pdf&month="+month, token)
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("expecting status %v, got %v", http.StatusOK, res.StatusCode)
+		}
+		body, err := io.ReadAll(res.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Header.Get("Content-Type") != "application/pdf" || !strings.HasPrefix(string(body), "%PDF-") {
+			t.Fatal("expecting a pdf document")
+		}
+	})
+
+	t.Run("download with invalid month, should fail", func(t *testing.T) {
+		for _, query := range []string{"month=2024-13", "", "month=" + month + "&format=xls"} {
+			res := get(t, server, baseUrl+"/api/v1/wallet/statements?"+query, token)
+			if res.StatusCode != http.StatusBadRequest {
+				t.Fatalf("expecting status %v for %q, got %v", http.StatusBadRequest, query, res.StatusCode)
+			}
+		}
+	})
+}
+
+func get(t *testing.T, server *httptest.Server, url string, token string) *http.Response {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Add("Authorization", fmt.Sprintf("Token %s", token))
+	res, err := server.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
@@ -0,0 +1,72 @@
+package http
+
+import (
+	"bytes"
+	"fmt"
+	"julo/internal/auth"
+	httphelper "julo/internal/http"
+	"julo/internal/statement"
+	"julo/internal/wallet"
+	"net/http"
+)
+
+// ViewStatementHandler sends the statement of the month query parameter, as
+// YYYY-MM, for download. The format query parameter picks csv, the default,
+// or pdf.
+func ViewStatementHandler(statements statement.Service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		session := auth.SessionFromContext(r.Context())
+		if session == nil {
+			httphelper.WriteErrorJSON(w, http.StatusUnauthorized, auth.ErrSessionNotFound)
+			return
+		}
+
+		format := r.URL.Query().Get("format")
+		if format == "" {
+			format = "csv"
+		}
+		if format != "csv" && format != "pdf" {
+			httphelper.WriteErrorJSON(w, http.StatusBadRequest, statement.ErrInvalidFormat)
+			return
+		}
+		month, err := statement.ParseMonth(r.URL.Query().Get("month"))
+		if err != nil {
+			httphelper.WriteErrorJSON(w, http.StatusBadRequest, err)
+			return
+		}
+
+		st, err := statements.Generate(r.Context(), statement.GenerateParam{
+			OwnerXID: session.Account.XID,
+			Month:    month,
+		})
+		if err != nil {
+			switch err {
+			case wallet.ErrWalletNotFound:
+				httphelper.WriteErrorJSON(w, http.StatusNotFound, err)
+			case statement.ErrMissingRequiredParameter, statement.ErrInvalidMonth:
+				httphelper.WriteErrorJSON(w, http.StatusBadRequest, err)
+			default:
+				httphelper.WriteErrorJSON(w, http.StatusInternalServerError, err)
+			}
+			return
+		}
+
+		var buf bytes.Buffer
+		contentType := "text/csv"
+		if format == "pdf" {
+			contentType = "application/pdf"
+			err = st.WritePDF(&buf)
+		} else {
+			err = st.WriteCSV(&buf)
+		}
+		if err != nil {
+			httphelper.WriteErrorJSON(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "statement-"+month.Format(statement.MonthLayout)+"."+format))
+		w.WriteHeader(http.StatusOK)
+		_, _ = buf.WriteTo(w)
+	})
+}
@@ -0,0 +1,201 @@
+package statement
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// The statement is laid out in Courier on A4 pages, a monospaced standard
+// font every PDF reader has, so columns line up by padding and nothing needs
+// embedding.
+const (
+	pageWidth    = 595
+	pageHeight   = 842
+	pageMargin   = 50
+	fontSize     = 8
+	lineHeight   = 11
+	linesPerPage = (pageHeight - 2*pageMargin) / lineHeight
+)
+
+const pdfDateLayout = "2006-01-02 15:04"
+
+// WritePDF renders s as a PDF document: a header with the period and
+// opening balance, a table of the transactions with the running balance, and
+// the totals and closing balance at the end.
+func (s Statement) WritePDF(w io.Writer) error {
+	var doc pdfDocument
+	for _, page := range paginate(s.textLines()) {
+		doc.addPage(page)
+	}
+	_, err := doc.WriteTo(w)
+	return errors.Wrap(err, "failed writing statement pdf")
+}
+
+type textLine struct {
+	text string
+	bold bool
+}
+
+func (s Statement) textLines() []textLine {
+	row := func(date, typ, ref, debit, credit, balance string) string {
+		return fmt.Sprintf("%-16s  %-17s %-22s %13s %13s %14s", date, typ, truncate(ref, 22), debit, credit, balance)
+	}
+
+	credits, debits := s.Totals()
+	lines := []textLine{
+		{text: "Wallet statement", bold: true},
+		{},
+		{text: "Customer  " + s.OwnerXID},
+		{text: "Wallet    " + s.WalletID},
+		{text: "Period    " + s.From.Format("2006-01-02") + " to " + s.To.AddDate(0, 0, -1).Format("2006-01-02")},
+		{text: "Generated " + s.GeneratedAt.Format(pdfDateLayout)},
+		{},
+		{text: row("Date", "Type", "Reference", "Debit", "Credit", "Balance"), bold: true},
+		{text: row(s.From.Format(pdfDateLayout), "opening balance", "", "", "", formatAmount(s.OpeningBalance))},
+	}
+	for _, l := range s.Lines {
+		debit, credit := "", ""
+		if l.Transaction.Type.IsCredit() {
+			credit = formatAmount(l.Transaction.Amount)
+		} else {
+			debit = formatAmount(l.Transaction.Amount)
+		}
+		lines = append(lines, textLine{text: row(
+			l.Transaction.SettledAt.Format(pdfDateLayout),
+			string(l.Transaction.Type),
+			l.Transaction.ReferenceID,
+			debit,
+			credit,
+			formatAmount(l.Balance),
+		)})
+	}
+	return append(lines,
+		textLine{text: row("", "total", "", formatAmount(debits), formatAmount(credits), ""), bold: true},
+		textLine{text: row(s.To.Format(pdfDateLayout), "closing balance", "", "", "", formatAmount(s.ClosingBalance)), bold: true},
+	)
+}
+
+// paginate splits lines into pages, numbering them at the bottom.
+func paginate(lines []textLine) [][]textLine {
+	perPage := linesPerPage - 2
+	var pages [][]textLine
+	for len(lines) > perPage {
+		pages = append(pages, lines[:perPage])
+		lines = lines[perPage:]
+	}
+	pages = append(pages, lines)
+
+	for i := range pages {
+		pages[i] = append(append([]textLine(nil), pages[i]...), textLine{}, textLine{text: fmt.Sprintf("Page %d of %d", i+1, len(pages))})
+	}
+	return pages
+}
+
+// formatAmount groups the digits of v by thousands.
+func formatAmount(v int) string {
+	digits := strconv.Itoa(v)
+	sign := ""
+	if v < 0 {
+		sign, digits = "-", digits[1:]
+	}
+	var b strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(d)
+	}
+	return sign + b.String()
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n-3] + "..."
+}
+
+// pdfDocument writes a minimal PDF 1.4 file of text pages: a catalog, the
+// page tree, the two fonts and a page object and content stream per page.
+type pdfDocument struct {
+	pages [][]byte
+}
+
+func (d *pdfDocument) addPage(lines []textLine) {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "BT\n%d TL\n%d %d Td\n", lineHeight, pageMargin, pageHeight-pageMargin)
+	font := ""
+	for _, l := range lines {
+		f := "/F1"
+		if l.bold {
+			f = "/F2"
+		}
+		if f != font {
+			fmt.Fprintf(&b, "%s %d Tf\n", f, fontSize)
+			font = f
+		}
+		fmt.Fprintf(&b, "(%s) Tj T*\n", escapePDF(l.text))
+	}
+	b.WriteString("ET\n")
+	d.pages = append(d.pages, b.Bytes())
+}
+
+func (d *pdfDocument) WriteTo(w io.Writer) (int64, error) {
+	// objects 1 to 4 are the catalog, page tree and fonts, then a page and
+	// its content for each page
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"", // the page tree, once the page numbers are known
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Courier-Bold /Encoding /WinAnsiEncoding >>",
+	}
+	kids := make([]string, len(d.pages))
+	for i, content := range d.pages {
+		page := len(objects) + 1
+		kids[i] = fmt.Sprintf("%d 0 R", page)
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+				pageWidth, pageHeight, page+1),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", len(content), content),
+		)
+	}
+	objects[1] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages))
+
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = b.Len()
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return b.WriteTo(w)
+}
+
+// escapePDF makes s safe inside a PDF string, characters outside printable
+// ASCII are replaced since the fonts are not embedded.
+func escapePDF(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 0x20 || r > 0x7e:
+			b.WriteByte('?')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
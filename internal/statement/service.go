@@ -0,0 +1,129 @@
+package statement
+
+import (
+	"context"
+	"julo/internal/wallet"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// MonthLayout is how statement months are written, such as 2024-01.
+const MonthLayout = "2006-01"
+
+type GenerateParam struct {
+	OwnerXID string
+	// Month is any time in the month the statement covers.
+	Month time.Time
+}
+
+// Line is a transaction of the statement with the balance right after it.
+type Line struct {
+	Transaction wallet.WalletTransaction
+	Balance     int
+}
+
+// Statement is the movement of a wallet over the period From, inclusive, to
+// To, exclusive. It holds the transactions that succeeded in the period, by
+// the time they settled; movements still pending show up on the statement
+// of the month they settle in.
+type Statement struct {
+	OwnerXID       string
+	WalletID       string
+	From           time.Time
+	To             time.Time
+	OpeningBalance int
+	ClosingBalance int
+	Lines          []Line
+	GeneratedAt    time.Time
+}
+
+// Totals are the credits and debits moved in and out of the wallet over the
+// period.
+func (s Statement) Totals() (credits int, debits int) {
+	for _, l := range s.Lines {
+		if l.Transaction.Type.IsCredit() {
+			credits += l.Transaction.Amount
+		} else {
+			debits += l.Transaction.Amount
+		}
+	}
+	return credits, debits
+}
+
+type Service interface {
+	Generate(ctx context.Context, param GenerateParam) (*Statement, error)
+}
+
+type service struct {
+	wallets wallet.Service
+}
+
+func NewService(wallets wallet.Service) Service {
+	return &service{
+		wallets: wallets,
+	}
+}
+
+// ParseMonth reads a month written as YYYY-MM in local time.
+func ParseMonth(v string) (time.Time, error) {
+	month, err := time.ParseInLocation(MonthLayout, v, time.Local)
+	if err != nil {
+		return time.Time{}, ErrInvalidMonth
+	}
+	return month, nil
+}
+
+// Generate builds the monthly statement of the wallet of the owner. The
+// statement of the current month runs up to now, months yet to come have
+// none.
+func (s *service) Generate(ctx context.Context, param GenerateParam) (*Statement, error) {
+	if param.OwnerXID == "" || param.Month.IsZero() {
+		return nil, ErrMissingRequiredParameter
+	}
+
+	now := time.Now()
+	from := time.Date(param.Month.Year(), param.Month.Month(), 1, 0, 0, 0, 0, param.Month.Location())
+	if from.After(now) {
+		return nil, ErrInvalidMonth
+	}
+	to := from.AddDate(0, 1, 0)
+
+	wal, err := s.wallets.GetWalletByXID(ctx, param.OwnerXID)
+	if err != nil {
+		return nil, err
+	}
+	result, err := s.wallets.GetWalletTransactions(ctx, wallet.GetWalletTransactionsParam{WalletID: wal.ID})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed getting wallet transactions")
+	}
+
+	st := Statement{
+		OwnerXID:       wal.OwnerXID,
+		WalletID:       wal.ID,
+		From:           from,
+		To:             to,
+		OpeningBalance: wallet.BalanceAt(result.Transactions, from),
+		ClosingBalance: wallet.BalanceAt(result.Transactions, to),
+		Lines:          []Line{},
+		GeneratedAt:    now,
+	}
+
+	transactions := []wallet.WalletTransaction{}
+	for _, t := range result.Transactions {
+		if t.Status == wallet.TransactionStatusSuccess && !t.SettledAt.Before(from) && t.SettledAt.Before(to) {
+			transactions = append(transactions, t)
+		}
+	}
+	sort.SliceStable(transactions, func(i, j int) bool {
+		return transactions[i].SettledAt.Before(transactions[j].SettledAt)
+	})
+
+	balance := st.OpeningBalance
+	for _, t := range transactions {
+		balance += t.SignedAmount()
+		st.Lines = append(st.Lines, Line{Transaction: t, Balance: balance})
+	}
+	return &st, nil
+}
@@ -0,0 +1,137 @@
+package statement_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"julo/internal/statement"
+	"julo/internal/wallet"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// ledger serves a fixed wallet and transactions, the rest of the wallet
+// service is left unimplemented.
+type ledger struct {
+	wallet.Service
+	transactions []wallet.WalletTransaction
+}
+
+func (l ledger) GetWalletByXID(ctx context.Context, xid string) (*wallet.Wallet, error) {
+	if xid != "owner" {
+		return nil, wallet.ErrWalletNotFound
+	}
+	return &wallet.Wallet{ID: "wallet-1", OwnerXID: xid}, nil
+}
+
+func (l ledger) GetWalletTransactions(ctx context.Context, param wallet.GetWalletTransactionsParam) (*wallet.GetWalletTransactionsResult, error) {
+	return &wallet.GetWalletTransactionsResult{Transactions: l.transactions}, nil
+}
+
+func TestGenerate(t *testing.T) {
+	ctx := context.Background()
+	at := func(month time.Month, day int) time.Time {
+		return time.Date(2024, month, day, 10, 0, 0, 0, time.Local)
+	}
+	trx := func(id string, typ wallet.TransactionType, amount int, status wallet.TransactionStatus, settledAt time.Time) wallet.WalletTransaction {
+		return wallet.WalletTransaction{ID: id, ReferenceID: "ref-" + id, Type: typ, Amount: amount, Status: status, Date: settledAt, SettledAt: settledAt}
+	}
+	statements := statement.NewService(ledger{transactions: []wallet.WalletTransaction{
+		trx("1", wallet.TransactionTypeDeposit, 100000, wallet.TransactionStatusSuccess, at(1, 15)),
+		trx("4", wallet.TransactionTypeTransferIn, 5000, wallet.TransactionStatusSuccess, at(2, 20)),
+		trx("2", wallet.TransactionTypeWithdrawal, 30000, wallet.TransactionStatusSuccess, at(2, 3)),
+		trx("3", wallet.TransactionTypeWithdrawal, 50000, wallet.TransactionStatusFailed, at(2, 10)),
+		trx("5", wallet.TransactionTypeDeposit, 7000, wallet.TransactionStatusSuccess, at(3, 1)),
+	}})
+
+	st, err := statements.Generate(ctx, statement.GenerateParam{OwnerXID: "owner", Month: at(2, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("generate, should carry balances over the period", func(t *testing.T) {
+		if st.OpeningBalance != 100000 || st.ClosingBalance != 75000 {
+			t.Fatalf("expecting balances 100000 to 75000, got %d to %d", st.OpeningBalance, st.ClosingBalance)
+		}
+		if len(st.Lines) != 2 || st.Lines[0].Transaction.ID != "2" || st.Lines[0].Balance != 70000 || st.Lines[1].Balance != 75000 {
+			t.Fatalf("expecting withdrawal then transfer, got %+v", st.Lines)
+		}
+		if credits, debits := st.Totals(); credits != 5000 || debits != 30000 {
+			t.Fatalf("expecting 5000 in and 30000 out, got %d and %d", credits, debits)
+		}
+	})
+
+	t.Run("write csv, should list opening, transactions and closing", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := st.WriteCSV(&buf); err != nil {
+			t.Fatal(err)
+		}
+		rows, err := csv.NewReader(&buf).ReadAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rows) != 5 || rows[1][3] != "opening_balance" || rows[2][4] != "30000" || rows[3][5] != "5000" || rows[4][6] != "75000" {
+			t.Fatalf("unexpected rows %v", rows)
+		}
+	})
+
+	t.Run("write pdf, should point the xref at every object", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := st.WritePDF(&buf); err != nil {
+			t.Fatal(err)
+		}
+		doc := buf.Bytes()
+		if !bytes.HasPrefix(doc, []byte("%PDF-1.4")) || !bytes.HasSuffix(doc, []byte("%%EOF\n")) {
+			t.Fatal("expecting a pdf document")
+		}
+		if !bytes.Contains(doc, []byte("(Wallet statement)")) || !bytes.Contains(doc, []byte("75,000")) {
+			t.Fatal("expecting title and closing balance in the document")
+		}
+		startxref := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(doc)
+		if startxref == nil {
+			t.Fatal("expecting startxref")
+		}
+		xref, _ := strconv.Atoi(string(startxref[1]))
+		if !bytes.HasPrefix(doc[xref:], []byte("xref\n")) {
+			t.Fatalf("expecting xref at %d", xref)
+		}
+		entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(doc[xref:], -1)
+		for i, e := range entries {
+			offset, _ := strconv.Atoi(string(e[1]))
+			if want := fmt.Sprintf("%d 0 obj", i+1); !bytes.HasPrefix(doc[offset:], []byte(want)) {
+				t.Fatalf("expecting object %d at %d", i+1, offset)
+			}
+		}
+	})
+
+	t.Run("generate future month, should fail", func(t *testing.T) {
+		_, err := statements.Generate(ctx, statement.GenerateParam{OwnerXID: "owner", Month: time.Now().AddDate(0, 2, 0)})
+		if err != statement.ErrInvalidMonth {
+			t.Fatalf("expecting error %s, got %v", statement.ErrInvalidMonth, err)
+		}
+	})
+
+	t.Run("generate without wallet, should fail", func(t *testing.T) {
+		_, err := statements.Generate(ctx, statement.GenerateParam{OwnerXID: "other", Month: at(2, 1)})
+		if err != wallet.ErrWalletNotFound {
+			t.Fatalf("expecting error %s, got %v", wallet.ErrWalletNotFound, err)
+		}
+	})
+}
+
+func TestWritePDFPages(t *testing.T) {
+	st := statement.Statement{OwnerXID: "owner", From: time.Now(), To: time.Now()}
+	for i := 0; i < 150; i++ {
+		st.Lines = append(st.Lines, statement.Line{Transaction: wallet.WalletTransaction{Type: wallet.TransactionTypeDeposit, Amount: 1, ReferenceID: "(ref)"}, Balance: i})
+	}
+	var buf bytes.Buffer
+	if err := st.WritePDF(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("/Count 3")) || !bytes.Contains(buf.Bytes(), []byte(`\(ref\)`)) {
+		t.Fatal("expecting 3 pages with escaped references")
+	}
+}
@@ -0,0 +1,187 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"julo/internal/wallet"
+	"log"
+	"sync"
+	"time"
+)
+
+type EventType string
+
+var (
+	EventTypeTransaction = EventType("transaction")
+	EventTypeBalance     = EventType("balance")
+)
+
+// Event is a change of a wallet pushed to the streams of its owner. IDs grow
+// across all owners, so a client resumes after the last ID it saw.
+type Event struct {
+	ID       uint64
+	OwnerXID string
+	Type     EventType
+	Data     json.RawMessage
+}
+
+type transactionEvent struct {
+	ID          string `json:"id"`
+	Type        string `json:"type"`
+	Status      string `json:"status"`
+	Amount      int    `json:"amount"`
+	ReferenceID string `json:"reference_id"`
+	// SettledAt is left out while the transaction is still pending or in
+	// review.
+	SettledAt *time.Time `json:"settled_at,omitempty"`
+}
+
+type balanceEvent struct {
+	Balance int       `json:"balance"`
+	Held    int       `json:"held"`
+	At      time.Time `json:"at"`
+}
+
+// NewBalanceEvent is the data of a balance event for wal.
+func NewBalanceEvent(wal wallet.Wallet) json.RawMessage {
+	b, _ := json.Marshal(balanceEvent{Balance: wal.Balance, Held: wal.Held, At: time.Now()})
+	return b
+}
+
+// subscriberBuffer bounds how far a subscriber may fall behind before it is
+// dropped, it then reconnects and resumes from the backlog.
+const subscriberBuffer = 64
+
+type subscriber struct {
+	ownerXID string
+	events   chan Event
+}
+
+// Broker fans the events of each owner out to their open streams and keeps
+// the latest events of each owner to replay to resuming clients.
+type Broker struct {
+	backlog int
+	mu      sync.Mutex
+	lastID  uint64
+	recent  map[string][]Event
+	subs    map[*subscriber]struct{}
+	closed  bool
+}
+
+// NewBroker keeps the latest backlog events of each owner for resuming.
+func NewBroker(backlog int) *Broker {
+	return &Broker{
+		backlog: backlog,
+		recent:  map[string][]Event{},
+		subs:    map[*subscriber]struct{}{},
+	}
+}
+
+// Subscribe opens a stream of the events of the owner. It returns the
+// events after lastEventID still in the backlog, zero meaning none, and a
+// channel of the events to come that is closed when the broker shuts down or
+// the subscriber falls too far behind. cancel must be called once done.
+func (b *Broker) Subscribe(ownerXID string, lastEventID uint64) (missed []Event, events <-chan Event, cancel func(), err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return nil, nil, nil, ErrBrokerClosed
+	}
+
+	if lastEventID > 0 {
+		for _, e := range b.recent[ownerXID] {
+			if e.ID > lastEventID {
+				missed = append(missed, e)
+			}
+		}
+	}
+
+	sub := &subscriber{ownerXID: ownerXID, events: make(chan Event, subscriberBuffer)}
+	b.subs[sub] = struct{}{}
+	cancel = func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.drop(sub)
+	}
+	return missed, sub.events, cancel, nil
+}
+
+// Publish sends an event of type typ with data to the streams of the owner.
+func (b *Broker) Publish(ownerXID string, typ EventType, data json.RawMessage) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+
+	b.lastID++
+	e := Event{ID: b.lastID, OwnerXID: ownerXID, Type: typ, Data: data}
+	recent := append(b.recent[ownerXID], e)
+	if len(recent) > b.backlog {
+		recent = append([]Event(nil), recent[len(recent)-b.backlog:]...)
+	}
+	b.recent[ownerXID] = recent
+
+	for sub := range b.subs {
+		if sub.ownerXID != ownerXID {
+			continue
+		}
+		select {
+		case sub.events <- e:
+		default:
+			b.drop(sub)
+		}
+	}
+}
+
+// Close ends every stream and refuses new ones, for streams to end before
+// the server shuts down.
+func (b *Broker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	for sub := range b.subs {
+		b.drop(sub)
+	}
+}
+
+// drop closes the events of sub. The caller must hold b.mu.
+func (b *Broker) drop(sub *subscriber) {
+	if _, ok := b.subs[sub]; !ok {
+		return
+	}
+	delete(b.subs, sub)
+	close(sub.events)
+}
+
+// TransactionListener publishes each change of a transaction of a wallet
+// and the balance it left to the streams of the owner, to be registered with
+// wallet.Service.OnTransactionChanged so pending movements and the amounts
+// they hold are pushed as well.
+func TransactionListener(b *Broker, wallets wallet.Service) wallet.TransactionListener {
+	return func(ctx context.Context, ownerXID string, t wallet.WalletTransaction) {
+		e := transactionEvent{
+			ID:          t.ID,
+			Type:        string(t.Type),
+			Status:      string(t.Status),
+			Amount:      t.Amount,
+			ReferenceID: t.ReferenceID,
+		}
+		if !t.SettledAt.IsZero() {
+			e.SettledAt = &t.SettledAt
+		}
+		data, err := json.Marshal(e)
+		if err != nil {
+			log.Printf("failed encoding transaction %s: %s", t.ID, err)
+			return
+		}
+		b.Publish(ownerXID, EventTypeTransaction, data)
+
+		wal, err := wallets.GetWalletByXID(ctx, ownerXID)
+		if err != nil {
+			log.Printf("failed getting wallet of %s: %s", ownerXID, err)
+			return
+		}
+		b.Publish(ownerXID, EventTypeBalance, NewBalanceEvent(*wal))
+	}
+}
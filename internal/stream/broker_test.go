@@ -0,0 +1,73 @@
+package stream_test
+
+import (
+	"encoding/json"
+	"julo/internal/stream"
+	"testing"
+)
+
+func TestBroker(t *testing.T) {
+	broker := stream.NewBroker(2)
+	data := json.RawMessage(`{}`)
+
+	missed, events, cancel, err := broker.Subscribe("alice", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cancel()
+	if len(missed) != 0 {
+		t.Fatalf("expecting nothing missed, got %v", missed)
+	}
+
+	broker.Publish("bob", stream.EventTypeBalance, data)
+	for i := 0; i < 3; i++ {
+		broker.Publish("alice", stream.EventTypeTransaction, data)
+	}
+
+	t.Run("publish, should reach the owner only", func(t *testing.T) {
+		for _, want := range []uint64{2, 3, 4} {
+			if e := <-events; e.ID != want || e.OwnerXID != "alice" {
+				t.Fatalf("expecting event %d of alice, got %+v", want, e)
+			}
+		}
+	})
+
+	t.Run("resume, should replay the backlog after the last id", func(t *testing.T) {
+		missed, _, cancel, err := broker.Subscribe("alice", 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer cancel()
+		if len(missed) != 2 || missed[0].ID != 3 || missed[1].ID != 4 {
+			t.Fatalf("expecting events 3 and 4, got %+v", missed)
+		}
+	})
+
+	t.Run("fall behind, should be dropped", func(t *testing.T) {
+		_, slow, cancel, err := broker.Subscribe("carol", 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer cancel()
+		for i := 0; i < 100; i++ {
+			broker.Publish("carol", stream.EventTypeBalance, data)
+		}
+		var n int
+		for range slow {
+			n++
+		}
+		if n == 0 || n >= 100 {
+			t.Fatalf("expecting stream closed after its buffer filled, got %d events", n)
+		}
+	})
+
+	t.Run("close, should end streams and refuse new ones", func(t *testing.T) {
+		broker.Close()
+		if _, ok := <-events; ok {
+			t.Fatal("expecting stream closed")
+		}
+		if _, _, _, err := broker.Subscribe("alice", 0); err != stream.ErrBrokerClosed {
+			t.Fatalf("expecting error %s, got %v", stream.ErrBrokerClosed, err)
+		}
+	})
+}
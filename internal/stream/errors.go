@@ -0,0 +1,8 @@
+package stream
+
+import "errors"
+
+var (
+	ErrBrokerClosed         = errors.New("stream is shutting down")
+	ErrStreamingUnsupported = errors.New("streaming unsupported")
+)
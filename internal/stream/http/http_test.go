@@ -0,0 +1,200 @@
+package http_test
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"julo/internal/account"
+	"julo/internal/auth"
+	authhttp "julo/internal/auth/http"
+	"julo/internal/stream"
+	streamhttp "julo/internal/stream/http"
+	"julo/internal/wallet"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/google/uuid"
+)
+
+type event struct {
+	id   string
+	typ  string
+	data string
+}
+
+// readEvent reads the next event off an SSE stream, skipping comments and
+// the retry field.
+func readEvent(t *testing.T, r *bufio.Reader) event {
+	var e event
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "" && e.typ != "":
+			return e
+		case strings.HasPrefix(line, "id: "):
+			e.id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "event: "):
+			e.typ = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			e.data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
+
+func TestStream(t *testing.T) {
+	ctx := context.Background()
+	wallets := wallet.NewService(wallet.NewInMemoryRepository())
+	broker := stream.NewBroker(10)
+	wallets.OnTransactionChanged(stream.TransactionListener(broker, wallets))
+
+	router := chi.NewRouter()
+	router.Mount("/api/v1", router.Group(func(r chi.Router) {
+		r.Mount("/wallet", r.Group(func(r chi.Router) {
+			r.Use(authhttp.Middleware)
+			r.Get("/stream", streamhttp.StreamHandler(broker, wallets, 50*time.Millisecond).ServeHTTP)
+		}))
+	}))
+	server := httptest.NewServer(router)
+	defer server.Close()
+	baseUrl := server.URL
+
+	xid, token := uuid.NewString(), uuid.NewString()
+	err := auth.StoreSession(ctx, auth.Session{Token: token, Account: account.Account{XID: xid}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = wallets.EnableWallet(ctx, wallet.EnableWalletParam{OwnerXID: xid})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	open := func(t *testing.T, lastEventID string) (*http.Response, *bufio.Reader) {
+		req, err := http.NewRequest(http.MethodGet, baseUrl+"/api/v1/wallet/stream", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Add("Authorization", fmt.Sprintf("Token %s", token))
+		if lastEventID != "" {
+			req.Header.Set("Last-Event-ID", lastEventID)
+		}
+		res, err := server.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.StatusCode != http.StatusOK || res.Header.Get("Content-Type") != "text/event-stream" {
+			t.Fatalf("expecting event stream, got %v %s", res.StatusCode, res.Header.Get("Content-Type"))
+		}
+		return res, bufio.NewReader(res.Body)
+	}
+
+	res, r := open(t, "")
+	defer res.Body.Close()
+	if e := readEvent(t, r); e.typ != "balance" || !strings.Contains(e.data, `"balance":0`) {
+		t.Fatalf("expecting current balance first, got %+v", e)
+	}
+
+	deposit := func(t *testing.T, amount int) {
+		_, err := wallets.DepositWallet(ctx, wallet.WalletTransactionParam{
+			ActorXID:    xid,
+			OwnerXID:    xid,
+			ReferenceID: uuid.NewString(),
+			Amount:      amount,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var lastID string
+	t.Run("deposit, should push transaction and balance", func(t *testing.T) {
+		deposit(t, 15000)
+		trx := readEvent(t, r)
+		if trx.typ != "transaction" || !strings.Contains(trx.data, `"amount":15000`) || trx.id == "" {
+			t.Fatalf("expecting deposit, got %+v", trx)
+		}
+		b := readEvent(t, r)
+		if b.typ != "balance" || !strings.Contains(b.data, `"balance":15000`) {
+			t.Fatalf("expecting new balance, got %+v", b)
+		}
+		lastID = b.id
+	})
+
+	t.Run("pending withdrawal, should push hold and its release", func(t *testing.T) {
+		withdrawal, err := wallets.WithdrawWallet(ctx, wallet.WalletTransactionParam{
+			ActorXID:    xid,
+			OwnerXID:    xid,
+			ReferenceID: uuid.NewString(),
+			Amount:      5000,
+			Pending:     true,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if e := readEvent(t, r); e.typ != "transaction" || !strings.Contains(e.data, `"status":"pending"`) || strings.Contains(e.data, "settled_at") {
+			t.Fatalf("expecting pending withdrawal, got %+v", e)
+		}
+		if e := readEvent(t, r); e.typ != "balance" || !strings.Contains(e.data, `"held":5000`) {
+			t.Fatalf("expecting held amount, got %+v", e)
+		}
+
+		_, err = wallets.FailTransaction(ctx, wallet.TransitionTransactionParam{TransactionID: withdrawal.ID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if e := readEvent(t, r); e.typ != "transaction" || !strings.Contains(e.data, `"status":"failed"`) {
+			t.Fatalf("expecting failed withdrawal, got %+v", e)
+		}
+		b := readEvent(t, r)
+		if b.typ != "balance" || !strings.Contains(b.data, `"balance":15000`) || !strings.Contains(b.data, `"held":0`) {
+			t.Fatalf("expecting released hold, got %+v", b)
+		}
+		lastID = b.id
+	})
+
+	t.Run("idle stream, should get heartbeats", func(t *testing.T) {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		if line != ": heartbeat\n" {
+			t.Fatalf("expecting heartbeat, got %q", line)
+		}
+	})
+
+	t.Run("resume with last event id, should replay missed events", func(t *testing.T) {
+		deposit(t, 2000)
+		res, r := open(t, lastID)
+		defer res.Body.Close()
+		if e := readEvent(t, r); e.typ != "transaction" || !strings.Contains(e.data, `"amount":2000`) {
+			t.Fatalf("expecting missed deposit, got %+v", e)
+		}
+		if e := readEvent(t, r); e.typ != "balance" || !strings.Contains(e.data, `"balance":17000`) {
+			t.Fatalf("expecting missed balance, got %+v", e)
+		}
+	})
+
+	t.Run("close broker, should end stream", func(t *testing.T) {
+		broker.Close()
+		done := make(chan error, 1)
+		go func() {
+			var err error
+			for err == nil {
+				_, err = r.ReadString('\n')
+			}
+			done <- err
+		}()
+		select {
+		case <-done:
+		case <-time.After(2 * time.Second):
+			t.Fatal("expecting stream ended")
+		}
+	})
+}
@@ -0,0 +1,104 @@
+package http
+
+import (
+	"fmt"
+	"julo/internal/auth"
+	httphelper "julo/internal/http"
+	"julo/internal/stream"
+	"julo/internal/wallet"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// retryMillis is how long clients wait before reconnecting a dropped stream.
+const retryMillis = 3000
+
+// StreamHandler streams the changes of the wallet of the session as
+// Server-Sent Events: a balance event straight away, then a transaction and
+// a balance event for each completed movement. A comment is sent every
+// heartbeat to keep idle connections open. Clients resuming with the
+// Last-Event-ID header, or a last_event_id query parameter, first get the
+// events they missed that are still in the backlog.
+func StreamHandler(broker *stream.Broker, wallets wallet.Service, heartbeat time.Duration) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		session := auth.SessionFromContext(r.Context())
+		if session == nil {
+			httphelper.WriteErrorJSON(w, http.StatusUnauthorized, auth.ErrSessionNotFound)
+			return
+		}
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			httphelper.WriteErrorJSON(w, http.StatusInternalServerError, stream.ErrStreamingUnsupported)
+			return
+		}
+
+		lastEventID := r.Header.Get("Last-Event-ID")
+		if lastEventID == "" {
+			lastEventID = r.URL.Query().Get("last_event_id")
+		}
+		var after uint64
+		if lastEventID != "" {
+			var err error
+			after, err = strconv.ParseUint(lastEventID, 10, 64)
+			if err != nil {
+				httphelper.WriteErrorJSON(w, http.StatusBadRequest, err)
+				return
+			}
+		}
+
+		// subscribing before reading the balance, a movement in between
+		// shows up as an event rather than going unnoticed
+		missed, events, cancel, err := broker.Subscribe(session.Account.XID, after)
+		if err != nil {
+			httphelper.WriteErrorJSON(w, http.StatusServiceUnavailable, err)
+			return
+		}
+		defer cancel()
+
+		wal, err := wallets.GetWalletByXID(r.Context(), session.Account.XID)
+		if err != nil && err == wallet.ErrWalletNotFound {
+			httphelper.WriteErrorJSON(w, http.StatusBadRequest, wallet.ErrWalletDisabled)
+			return
+		} else if err != nil {
+			httphelper.WriteErrorJSON(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+
+		fmt.Fprintf(w, "retry: %d\n\n", retryMillis)
+		for _, e := range missed {
+			writeEvent(w, e)
+		}
+		// the current balance carries no id, it is not part of what a client
+		// resumes from
+		fmt.Fprintf(w, "event: %s\ndata: %s\n\n", stream.EventTypeBalance, stream.NewBalanceEvent(*wal))
+		flusher.Flush()
+
+		ticker := time.NewTicker(heartbeat)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case e, ok := <-events:
+				if !ok {
+					return
+				}
+				writeEvent(w, e)
+			case <-ticker.C:
+				fmt.Fprint(w, ": heartbeat\n\n")
+			}
+			flusher.Flush()
+		}
+	})
+}
+
+func writeEvent(w http.ResponseWriter, e stream.Event) {
+	fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Type, e.Data)
+}
@@ -0,0 +1,12 @@
+package topup
+
+import "errors"
+
+var (
+	ErrVirtualAccountNotFound = errors.New("virtual account not found")
+	ErrInvalidSignature       = errors.New("invalid signature")
+	ErrInvalidNotification    = errors.New("invalid payment notification")
+	ErrNotificationMismatch   = errors.New("notification does not match the payment already received with its reference")
+	ErrPaymentNotFound        = errors.New("payment not found")
+	ErrPaymentNotParked       = errors.New("payment is not parked")
+)
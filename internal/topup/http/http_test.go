@@ -0,0 +1,120 @@
+package http_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"julo/internal/topup"
+	topuphttp "julo/internal/topup/http"
+	"julo/internal/wallet"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestNotification(t *testing.T) {
+	ctx := context.Background()
+	secret := uuid.NewString()
+	wallets := wallet.NewService(wallet.NewInMemoryRepository())
+	topups := topup.NewService(topup.NewInMemoryRepository(), wallets, "SIMBANK", "8808")
+
+	server := httptest.NewServer(topuphttp.NotificationHandler(topups, secret))
+	defer server.Close()
+
+	xid := uuid.NewString()
+	_, err := wallets.EnableWallet(ctx, wallet.EnableWalletParam{
+		OwnerXID: xid,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	va, err := topups.AssignVirtualAccount(ctx, xid)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("signed notification, should credit wallet", func(t *testing.T) {
+		bank := topup.BankSimulator{
+			URL:      server.URL,
+			Secret:   secret,
+			BankCode: "SIMBANK",
+			Client:   server.Client(),
+		}
+		n, err := bank.Pay(ctx, va.Number, 50000)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		t.Run("replayed notification, should not credit again", func(t *testing.T) {
+			err := bank.Notify(ctx, *n)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			wal, err := wallets.GetWalletByXID(ctx, xid)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if wal.Balance != 50000 {
+				t.Fatalf("expecting balance %d, got %d", 50000, wal.Balance)
+			}
+		})
+	})
+
+	t.Run("notification for a frozen wallet, should be accepted and parked", func(t *testing.T) {
+		_, err := wallets.FreezeWallet(ctx, wallet.FreezeWalletParam{OwnerXID: xid, ActorXID: "ops-1", Reason: wallet.FreezeReasonLegal})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer wallets.UnfreezeWallet(ctx, wallet.UnfreezeWalletParam{OwnerXID: xid, ActorXID: "ops-1"})
+
+		body, _ := json.Marshal(topup.Notification{
+			VirtualAccountNumber: va.Number,
+			BankReference:        uuid.NewString(),
+			Amount:               50000,
+		})
+		req, err := http.NewRequest(http.MethodPost, server.URL, bytes.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set(topup.SignatureHeader, topup.Sign(secret, body))
+
+		res, err := server.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.StatusCode != http.StatusAccepted {
+			t.Fatalf("expecting status %v, got %v", http.StatusAccepted, res.StatusCode)
+		}
+		parked, err := topups.GetParkedPayments(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(parked) != 1 {
+			t.Fatalf("expecting 1 parked payment, got %d", len(parked))
+		}
+	})
+
+	t.Run("notification with invalid signature, should be rejected", func(t *testing.T) {
+		body, _ := json.Marshal(topup.Notification{
+			VirtualAccountNumber: va.Number,
+			BankReference:        uuid.NewString(),
+			Amount:               50000,
+		})
+		req, err := http.NewRequest(http.MethodPost, server.URL, bytes.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set(topup.SignatureHeader, topup.Sign("wrong-secret", body))
+
+		res, err := server.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.StatusCode != http.StatusUnauthorized {
+			t.Fatalf("expecting status %v, got %v", http.StatusUnauthorized, res.StatusCode)
+		}
+	})
+}
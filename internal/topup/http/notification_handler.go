@@ -0,0 +1,112 @@
+package http
+
+import (
+	"encoding/json"
+	"io"
+	httphelper "julo/internal/http"
+	"julo/internal/topup"
+	"net/http"
+	"time"
+)
+
+const maxNotificationSize = 64 << 10
+
+// NotificationHandler receives the payment notifications the bank sends for
+// virtual accounts. Only notifications signed with secret are accepted.
+func NotificationHandler(topups topup.Service, secret string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxNotificationSize))
+		if err != nil {
+			httphelper.WriteErrorJSON(w, http.StatusBadRequest, err)
+			return
+		}
+
+		err = topup.VerifySignature(secret, body, r.Header.Get(topup.SignatureHeader))
+		if err != nil {
+			httphelper.WriteErrorJSON(w, http.StatusUnauthorized, err)
+			return
+		}
+
+		var n topup.Notification
+		err = json.Unmarshal(body, &n)
+		if err != nil {
+			httphelper.WriteErrorJSON(w, http.StatusBadRequest, topup.ErrInvalidNotification)
+			return
+		}
+
+		p, err := topups.HandleNotification(r.Context(), n)
+		if err != nil {
+			switch err {
+			case topup.ErrInvalidNotification:
+				httphelper.WriteErrorJSON(w, http.StatusBadRequest, err)
+			case topup.ErrVirtualAccountNotFound:
+				httphelper.WriteErrorJSON(w, http.StatusNotFound, err)
+			case topup.ErrNotificationMismatch:
+				httphelper.WriteErrorJSON(w, http.StatusConflict, err)
+			default:
+				httphelper.WriteErrorJSON(w, http.StatusInternalServerError, err)
+			}
+			return
+		}
+		writePayment(w, p)
+	})
+}
+
+// writePayment answers with the deposit of a credited payment, or with 202
+// and the payment itself when it was parked: the bank need not retry it.
+func writePayment(w http.ResponseWriter, p *topup.Payment) {
+	var response httphelper.Response
+	response.Status = "success"
+	if p.Status == topup.PaymentStatusParked {
+		response.Data = map[string]interface{}{
+			"payment": newPaymentResponse(*p),
+		}
+		httphelper.WriteJSON(w, http.StatusAccepted, response)
+		return
+	}
+
+	response.Data = map[string]interface{}{
+		"deposit": struct {
+			ID          string    `json:"id"`
+			Depositedby string    `json:"deposited_by"`
+			Status      string    `json:"status"`
+			DepositedAt time.Time `json:"deposited_at"`
+			Amount      int       `json:"amount"`
+			ReferenceID string    `json:"reference_id"`
+		}{
+			ID:          p.Deposit.ID,
+			Depositedby: p.Deposit.DepositedBy,
+			Status:      string(p.Deposit.Status),
+			DepositedAt: p.Deposit.DepositedAt,
+			Amount:      p.Deposit.Amount,
+			ReferenceID: p.Deposit.ReferenceID,
+		},
+	}
+	httphelper.WriteJSON(w, http.StatusOK, response)
+}
+
+type paymentResponse struct {
+	BankCode             string    `json:"bank_code"`
+	BankReference        string    `json:"bank_reference"`
+	VirtualAccountNumber string    `json:"virtual_account_number"`
+	OwnedBy              string    `json:"owned_by"`
+	Amount               int       `json:"amount"`
+	PaidAt               time.Time `json:"paid_at"`
+	Status               string    `json:"status"`
+	Reason               string    `json:"reason,omitempty"`
+	ReceivedAt           time.Time `json:"received_at"`
+}
+
+func newPaymentResponse(p topup.Payment) paymentResponse {
+	return paymentResponse{
+		BankCode:             p.BankCode,
+		BankReference:        p.BankReference,
+		VirtualAccountNumber: p.VirtualAccountNumber,
+		OwnedBy:              p.OwnerXID,
+		Amount:               p.Amount,
+		PaidAt:               p.PaidAt,
+		Status:               string(p.Status),
+		Reason:               p.Reason,
+		ReceivedAt:           p.ReceivedAt,
+	}
+}
@@ -0,0 +1,56 @@
+package http
+
+import (
+	httphelper "julo/internal/http"
+	"julo/internal/topup"
+	"net/http"
+
+	"github.com/go-chi/chi"
+)
+
+// ViewParkedPaymentsHandler lists the payments the bank took that no wallet
+// has been credited with yet.
+func ViewParkedPaymentsHandler(topups topup.Service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var response httphelper.Response
+
+		payments, err := topups.GetParkedPayments(r.Context())
+		if err != nil {
+			httphelper.WriteErrorJSON(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		list := []paymentResponse{}
+		for _, p := range payments {
+			list = append(list, newPaymentResponse(p))
+		}
+		response.Status = "success"
+		response.Data = map[string]interface{}{
+			"payments": list,
+		}
+		httphelper.WriteJSON(w, http.StatusOK, response)
+	})
+}
+
+// RetryPaymentHandler credits the parked payment identified by the
+// "bank_code" and "reference" url parameters again.
+func RetryPaymentHandler(topups topup.Service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p, err := topups.RetryPayment(r.Context(), topup.PaymentParam{
+			BankCode:      chi.URLParam(r, "bank_code"),
+			BankReference: chi.URLParam(r, "reference"),
+		})
+		if err != nil {
+			switch err {
+			case topup.ErrPaymentNotFound:
+				httphelper.WriteErrorJSON(w, http.StatusNotFound, err)
+			case topup.ErrPaymentNotParked:
+				httphelper.WriteErrorJSON(w, http.StatusBadRequest, err)
+			default:
+				httphelper.WriteErrorJSON(w, http.StatusInternalServerError, err)
+			}
+			return
+		}
+		writePayment(w, p)
+	})
+}
@@ -0,0 +1,57 @@
+package http
+
+import (
+	"julo/internal/auth"
+	httphelper "julo/internal/http"
+	"julo/internal/topup"
+	"julo/internal/wallet"
+	"net/http"
+	"time"
+)
+
+func VirtualAccountHandler(wallets wallet.Service, topups topup.Service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var response httphelper.Response
+		session := auth.SessionFromContext(r.Context())
+		if session == nil {
+			httphelper.WriteErrorJSON(w, http.StatusUnauthorized, auth.ErrSessionNotFound)
+			return
+		}
+
+		wal, err := wallets.GetWalletByXID(r.Context(), session.Account.XID)
+		if err != nil && err == wallet.ErrWalletNotFound {
+			httphelper.WriteErrorJSON(w, http.StatusBadRequest, wallet.ErrWalletDisabled)
+			return
+		} else if err != nil {
+			httphelper.WriteErrorJSON(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if wal == nil || wal.Status == wallet.WalletStatusDisabled {
+			httphelper.WriteErrorJSON(w, http.StatusBadRequest, wallet.ErrWalletDisabled)
+			return
+		}
+
+		va, err := topups.AssignVirtualAccount(r.Context(), wal.OwnerXID)
+		if err != nil {
+			httphelper.WriteErrorJSON(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		response.Status = "success"
+		response.Data = map[string]interface{}{
+			"virtual_account": struct {
+				Number    string    `json:"number"`
+				BankCode  string    `json:"bank_code"`
+				OwnedBy   string    `json:"owned_by"`
+				CreatedAt time.Time `json:"created_at"`
+			}{
+				Number:    va.Number,
+				BankCode:  va.BankCode,
+				OwnedBy:   va.OwnerXID,
+				CreatedAt: va.CreatedAt,
+			},
+		}
+		httphelper.WriteJSON(w, http.StatusOK, response)
+	})
+}
@@ -0,0 +1,111 @@
+package topup
+
+import (
+	"context"
+	"julo/internal/wallet"
+	"sort"
+	"sync"
+	"time"
+)
+
+type VirtualAccount struct {
+	Number    string
+	BankCode  string
+	OwnerXID  string
+	CreatedAt time.Time
+}
+
+type PaymentStatus string
+
+var (
+	PaymentStatusCredited = PaymentStatus("credited")
+	// PaymentStatusParked is a payment the bank has taken but the wallet
+	// would not, left for an operator to settle.
+	PaymentStatusParked = PaymentStatus("parked")
+)
+
+// Payment is a payment into a virtual account as notified by the bank.
+type Payment struct {
+	BankCode             string
+	BankReference        string
+	VirtualAccountNumber string
+	OwnerXID             string
+	Amount               int
+	PaidAt               time.Time
+	Status               PaymentStatus
+	// Reason tells why a parked payment could not be credited.
+	Reason string
+	// Deposit is the wallet deposit of a credited payment.
+	Deposit    *wallet.WalletTransactionResult
+	ReceivedAt time.Time
+}
+
+type Repository interface {
+	CreateVirtualAccount(ctx context.Context, va VirtualAccount) error
+	GetVirtualAccountByOwner(ctx context.Context, ownerXID string) (*VirtualAccount, error)
+	GetVirtualAccountByNumber(ctx context.Context, number string) (*VirtualAccount, error)
+	SavePayment(ctx context.Context, p Payment) error
+	GetPayment(ctx context.Context, bankCode string, bankReference string) (*Payment, error)
+	GetPaymentsByStatus(ctx context.Context, status PaymentStatus) ([]Payment, error)
+}
+
+type InMemoryRepository struct {
+	byOwner  sync.Map
+	byNumber sync.Map
+	payments sync.Map
+}
+
+func NewInMemoryRepository() Repository {
+	return &InMemoryRepository{}
+}
+
+func (r *InMemoryRepository) CreateVirtualAccount(ctx context.Context, va VirtualAccount) error {
+	r.byOwner.Store(va.OwnerXID, &va)
+	r.byNumber.Store(va.Number, &va)
+	return nil
+}
+
+func (r *InMemoryRepository) GetVirtualAccountByOwner(ctx context.Context, ownerXID string) (*VirtualAccount, error) {
+	v, ok := r.byOwner.Load(ownerXID)
+	if !ok {
+		return nil, ErrVirtualAccountNotFound
+	}
+	return v.(*VirtualAccount), nil
+}
+
+func (r *InMemoryRepository) GetVirtualAccountByNumber(ctx context.Context, number string) (*VirtualAccount, error) {
+	v, ok := r.byNumber.Load(number)
+	if !ok {
+		return nil, ErrVirtualAccountNotFound
+	}
+	return v.(*VirtualAccount), nil
+}
+
+func (r *InMemoryRepository) SavePayment(ctx context.Context, p Payment) error {
+	r.payments.Store(p.BankCode+"/"+p.BankReference, &p)
+	return nil
+}
+
+func (r *InMemoryRepository) GetPayment(ctx context.Context, bankCode string, bankReference string) (*Payment, error) {
+	v, ok := r.payments.Load(bankCode + "/" + bankReference)
+	if !ok {
+		return nil, ErrPaymentNotFound
+	}
+	p := *v.(*Payment)
+	return &p, nil
+}
+
+func (r *InMemoryRepository) GetPaymentsByStatus(ctx context.Context, status PaymentStatus) ([]Payment, error) {
+	payments := []Payment{}
+	r.payments.Range(func(key, value interface{}) bool {
+		p := value.(*Payment)
+		if p.Status == status {
+			payments = append(payments, *p)
+		}
+		return true
+	})
+	sort.Slice(payments, func(i, j int) bool {
+		return payments[i].ReceivedAt.Before(payments[j].ReceivedAt)
+	})
+	return payments, nil
+}
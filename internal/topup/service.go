@@ -0,0 +1,244 @@
+package topup
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"julo/internal/wallet"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Notification is sent by the bank when money is paid into a virtual account.
+type Notification struct {
+	VirtualAccountNumber string    `json:"virtual_account_number"`
+	BankCode             string    `json:"bank_code"`
+	BankReference        string    `json:"bank_reference"`
+	Amount               int       `json:"amount"`
+	PaidAt               time.Time `json:"paid_at"`
+}
+
+func (n Notification) Validate() error {
+	if n.VirtualAccountNumber == "" || n.BankReference == "" || n.Amount <= 0 {
+		return ErrInvalidNotification
+	}
+	return nil
+}
+
+type Service interface {
+	AssignVirtualAccount(ctx context.Context, ownerXID string) (*VirtualAccount, error)
+	HandleNotification(ctx context.Context, n Notification) (*Payment, error)
+	GetParkedPayments(ctx context.Context) ([]Payment, error)
+	RetryPayment(ctx context.Context, param PaymentParam) (*Payment, error)
+}
+
+type PaymentParam struct {
+	BankCode      string
+	BankReference string
+}
+
+type service struct {
+	repo     Repository
+	wallets  wallet.Service
+	bankCode string
+	prefix   string
+	mu       sync.Mutex
+}
+
+// NewService creates virtual accounts at the bank identified by bankCode,
+// numbered with the company prefix assigned by that bank.
+func NewService(repo Repository, wallets wallet.Service, bankCode string, prefix string) Service {
+	return &service{
+		repo:     repo,
+		wallets:  wallets,
+		bankCode: bankCode,
+		prefix:   prefix,
+	}
+}
+
+// AssignVirtualAccount returns the virtual account of the wallet owned by
+// ownerXID, creating one the first time it is asked for.
+func (s *service) AssignVirtualAccount(ctx context.Context, ownerXID string) (*VirtualAccount, error) {
+	_, err := s.wallets.GetWalletByXID(ctx, ownerXID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	va, err := s.repo.GetVirtualAccountByOwner(ctx, ownerXID)
+	if err != nil && err != ErrVirtualAccountNotFound {
+		return nil, errors.Wrap(err, "failed getting virtual account")
+	}
+	if va != nil {
+		return va, nil
+	}
+
+	number, err := s.newNumber(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	va = &VirtualAccount{
+		Number:    number,
+		BankCode:  s.bankCode,
+		OwnerXID:  ownerXID,
+		CreatedAt: time.Now(),
+	}
+	err = s.repo.CreateVirtualAccount(ctx, *va)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed creating virtual account")
+	}
+
+	return va, nil
+}
+
+// HandleNotification credits the wallet behind the paid virtual account. The
+// bank reference is the idempotency key of the payment, so a notification
+// delivered more than once only credits the wallet once, and a redelivery
+// that does not match the payment received first is refused. The bank has
+// the money already: a payment the wallet does not take is parked for an
+// operator instead of failing, which would only have the bank retry.
+func (s *service) HandleNotification(ctx context.Context, n Notification) (*Payment, error) {
+	err := n.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	va, err := s.repo.GetVirtualAccountByNumber(ctx, n.VirtualAccountNumber)
+	if err != nil && err == ErrVirtualAccountNotFound {
+		return nil, ErrVirtualAccountNotFound
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed getting virtual account")
+	}
+
+	bankCode := n.BankCode
+	if bankCode == "" {
+		bankCode = va.BankCode
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, err := s.repo.GetPayment(ctx, bankCode, n.BankReference)
+	if err != nil && err != ErrPaymentNotFound {
+		return nil, errors.Wrap(err, "failed getting payment")
+	}
+	if p != nil {
+		if p.VirtualAccountNumber != n.VirtualAccountNumber || p.Amount != n.Amount || !p.PaidAt.Equal(n.PaidAt) {
+			return nil, ErrNotificationMismatch
+		}
+		return p, nil
+	}
+
+	p = &Payment{
+		BankCode:             bankCode,
+		BankReference:        n.BankReference,
+		VirtualAccountNumber: n.VirtualAccountNumber,
+		OwnerXID:             va.OwnerXID,
+		Amount:               n.Amount,
+		PaidAt:               n.PaidAt,
+		ReceivedAt:           time.Now(),
+	}
+	return s.credit(ctx, p)
+}
+
+// GetParkedPayments lists the payments waiting for an operator, oldest
+// first.
+func (s *service) GetParkedPayments(ctx context.Context) ([]Payment, error) {
+	payments, err := s.repo.GetPaymentsByStatus(ctx, PaymentStatusParked)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed getting payments")
+	}
+	return payments, nil
+}
+
+// RetryPayment tries again to credit a parked payment, once an operator has
+// sorted out the wallet. It stays parked if the wallet still does not take
+// it.
+func (s *service) RetryPayment(ctx context.Context, param PaymentParam) (*Payment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, err := s.repo.GetPayment(ctx, param.BankCode, param.BankReference)
+	if err != nil && err == ErrPaymentNotFound {
+		return nil, ErrPaymentNotFound
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed getting payment")
+	}
+	if p.Status != PaymentStatusParked {
+		return nil, ErrPaymentNotParked
+	}
+	return s.credit(ctx, p)
+}
+
+// credit deposits p into the wallet of its owner, parking it when the wallet
+// refuses it. The caller must hold s.mu.
+func (s *service) credit(ctx context.Context, p *Payment) (*Payment, error) {
+	deposit, err := s.wallets.DepositWallet(ctx, wallet.WalletTransactionParam{
+		ActorXID:    "bank:" + p.BankCode,
+		OwnerXID:    p.OwnerXID,
+		ReferenceID: Reference(p.BankCode, p.BankReference),
+		Amount:      p.Amount,
+	})
+	switch {
+	case err == nil:
+		p.Status = PaymentStatusCredited
+		p.Reason = ""
+		p.Deposit = deposit
+	case isRefusal(err):
+		p.Status = PaymentStatusParked
+		p.Reason = err.Error()
+	default:
+		return nil, err
+	}
+
+	err = s.repo.SavePayment(ctx, *p)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed saving payment")
+	}
+	return p, nil
+}
+
+// Reference is the reference of the wallet deposit of a payment, in the
+// system namespace so customers cannot take it with a deposit of their own.
+func Reference(bankCode string, bankReference string) string {
+	return wallet.SystemReferencePrefix + "topup-" + bankCode + "-" + bankReference
+}
+
+// isRefusal reports whether err is the wallet refusing the deposit, which
+// trying again as is will not change.
+func isRefusal(err error) bool {
+	if _, ok := err.(wallet.ValidationError); ok {
+		return true
+	}
+	switch err {
+	case wallet.ErrWalletNotFound, wallet.ErrWalletDisabled, wallet.ErrWalletFrozen, wallet.ErrWalletClosed,
+		wallet.ErrBalanceLimitExceeded, wallet.ErrTransactionLimitExceeded, wallet.ErrTransactionDenied,
+		wallet.ErrDuplicateReference:
+		return true
+	}
+	return false
+}
+
+func (s *service) newNumber(ctx context.Context) (string, error) {
+	max := big.NewInt(1e10)
+	for {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", errors.Wrap(err, "failed generating virtual account number")
+		}
+
+		number := fmt.Sprintf("%s%010d", s.prefix, n.Int64())
+		_, err = s.repo.GetVirtualAccountByNumber(ctx, number)
+		if err == ErrVirtualAccountNotFound {
+			return number, nil
+		} else if err != nil {
+			return "", errors.Wrap(err, "failed getting virtual account")
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package topup_test
+
+import (
+	"context"
+	"julo/internal/topup"
+	"julo/internal/wallet"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestVirtualAccountTopup(t *testing.T) {
+	ctx := context.Background()
+	wallets := wallet.NewService(wallet.NewInMemoryRepository())
+	topups := topup.NewService(topup.NewInMemoryRepository(), wallets, "SIMBANK", "8808")
+
+	xid := uuid.NewString()
+	_, err := wallets.EnableWallet(ctx, wallet.EnableWalletParam{
+		OwnerXID: xid,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("assign virtual account, should success", func(t *testing.T) {
+		va, err := topups.AssignVirtualAccount(ctx, xid)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(va.Number, "8808") {
+			t.Fatalf("expecting number with prefix %s, got %s", "8808", va.Number)
+		}
+
+		t.Run("assign again, should return the same account", func(t *testing.T) {
+			va2, err := topups.AssignVirtualAccount(ctx, xid)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if va2.Number != va.Number {
+				t.Fatalf("expecting number %s, got %s", va.Number, va2.Number)
+			}
+		})
+
+		t.Run("notification delivered twice, should credit once", func(t *testing.T) {
+			n := topup.Notification{
+				VirtualAccountNumber: va.Number,
+				BankReference:        uuid.NewString(),
+				Amount:               25000,
+			}
+			for i := 0; i < 2; i++ {
+				_, err := topups.HandleNotification(ctx, n)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			wal, err := wallets.GetWalletByXID(ctx, xid)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if wal.Balance != 25000 {
+				t.Fatalf("expecting balance %d, got %d", 25000, wal.Balance)
+			}
+		})
+	})
+
+	t.Run("assign virtual account without wallet, should fail", func(t *testing.T) {
+		_, err := topups.AssignVirtualAccount(ctx, uuid.NewString())
+		if err != wallet.ErrWalletNotFound {
+			t.Fatalf("expecting error %s, got %s", wallet.ErrWalletNotFound, err)
+		}
+	})
+
+	t.Run("notification for inexist virtual account, should fail", func(t *testing.T) {
+		_, err := topups.HandleNotification(ctx, topup.Notification{
+			VirtualAccountNumber: "0000",
+			BankReference:        uuid.NewString(),
+			Amount:               25000,
+		})
+		if err != topup.ErrVirtualAccountNotFound {
+			t.Fatalf("expecting error %s, got %s", topup.ErrVirtualAccountNotFound, err)
+		}
+	})
+}
+
+func TestParkedTopup(t *testing.T) {
+	ctx := context.Background()
+	wallets := wallet.NewService(wallet.NewInMemoryRepository())
+	topups := topup.NewService(topup.NewInMemoryRepository(), wallets, "SIMBANK", "8808")
+
+	xid := uuid.NewString()
+	_, err := wallets.EnableWallet(ctx, wallet.EnableWalletParam{OwnerXID: xid})
+	if err != nil {
+		t.Fatal(err)
+	}
+	va, err := topups.AssignVirtualAccount(ctx, xid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	balance := func(t *testing.T) int {
+		wal, err := wallets.GetWalletByXID(ctx, xid)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return wal.Balance
+	}
+
+	n := topup.Notification{
+		VirtualAccountNumber: va.Number,
+		BankCode:             "SIMBANK",
+		BankReference:        uuid.NewString(),
+		Amount:               25000,
+		PaidAt:               time.Now().Truncate(time.Second),
+	}
+
+	t.Run("customer deposit with the bank reference, should not block the top-up", func(t *testing.T) {
+		_, err := wallets.DepositWallet(ctx, wallet.WalletTransactionParam{
+			ActorXID:    xid,
+			OwnerXID:    xid,
+			ReferenceID: n.BankReference,
+			Amount:      1000,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("notification for a frozen wallet, should park the payment", func(t *testing.T) {
+		_, err := wallets.FreezeWallet(ctx, wallet.FreezeWalletParam{OwnerXID: xid, ActorXID: "ops-1", Reason: wallet.FreezeReasonLegal})
+		if err != nil {
+			t.Fatal(err)
+		}
+		p, err := topups.HandleNotification(ctx, n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p.Status != topup.PaymentStatusParked || p.Reason != wallet.ErrWalletFrozen.Error() {
+			t.Fatalf("expecting payment parked on %q, got %s %q", wallet.ErrWalletFrozen, p.Status, p.Reason)
+		}
+		parked, err := topups.GetParkedPayments(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(parked) != 1 || parked[0].BankReference != n.BankReference {
+			t.Fatalf("expecting the payment parked, got %+v", parked)
+		}
+
+		t.Run("replay with another amount, should fail", func(t *testing.T) {
+			replay := n
+			replay.Amount = 50000
+			_, err := topups.HandleNotification(ctx, replay)
+			if err != topup.ErrNotificationMismatch {
+				t.Fatalf("expecting error %s, got %v", topup.ErrNotificationMismatch, err)
+			}
+		})
+
+		t.Run("replay paid at another time, should fail", func(t *testing.T) {
+			replay := n
+			replay.PaidAt = n.PaidAt.Add(time.Hour)
+			_, err := topups.HandleNotification(ctx, replay)
+			if err != topup.ErrNotificationMismatch {
+				t.Fatalf("expecting error %s, got %v", topup.ErrNotificationMismatch, err)
+			}
+		})
+
+		t.Run("retry once unfrozen, should credit the wallet", func(t *testing.T) {
+			_, err := wallets.UnfreezeWallet(ctx, wallet.UnfreezeWalletParam{OwnerXID: xid, ActorXID: "ops-1"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			p, err := topups.RetryPayment(ctx, topup.PaymentParam{BankCode: n.BankCode, BankReference: n.BankReference})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p.Status != topup.PaymentStatusCredited || p.Deposit.ReferenceID != topup.Reference(n.BankCode, n.BankReference) {
+				t.Fatalf("expecting payment credited, got %s", p.Status)
+			}
+			if b := balance(t); b != 26000 {
+				t.Fatalf("expecting balance %d, got %d", 26000, b)
+			}
+
+			_, err = topups.RetryPayment(ctx, topup.PaymentParam{BankCode: n.BankCode, BankReference: n.BankReference})
+			if err != topup.ErrPaymentNotParked {
+				t.Fatalf("expecting error %s, got %v", topup.ErrPaymentNotParked, err)
+			}
+		})
+	})
+}
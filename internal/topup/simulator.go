@@ -0,0 +1,69 @@
+package topup
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+// BankSimulator plays the bank side of a virtual account top-up by sending
+// signed payment notifications to the notification endpoint at URL.
+type BankSimulator struct {
+	URL      string
+	Secret   string
+	BankCode string
+	Client   *http.Client
+}
+
+// Pay simulates a customer paying amount into the virtual account and
+// returns the notification that was delivered.
+func (b *BankSimulator) Pay(ctx context.Context, number string, amount int) (*Notification, error) {
+	n := Notification{
+		VirtualAccountNumber: number,
+		BankCode:             b.BankCode,
+		BankReference:        uuid.NewString(),
+		Amount:               amount,
+		PaidAt:               time.Now(),
+	}
+	return &n, b.Notify(ctx, n)
+}
+
+// Notify delivers n as the bank would, which also allows replaying a
+// notification that was already sent.
+func (b *BankSimulator) Notify(ctx context.Context, n Notification) error {
+	body, err := json.Marshal(n)
+	if err != nil {
+		return errors.Wrap(err, "failed encoding notification")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.URL, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "failed building request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, Sign(b.Secret, body))
+
+	client := b.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "failed sending notification")
+	}
+	defer res.Body.Close()
+
+	// a parked payment is accepted too, the bank has nothing left to do
+	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusAccepted {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("notification rejected with status %d: %s", res.StatusCode, bytes.TrimSpace(msg))
+	}
+	return nil
+}
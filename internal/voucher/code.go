@@ -0,0 +1,68 @@
+package voucher
+
+import (
+	"crypto/rand"
+	"math/big"
+	"strings"
+)
+
+// alphabet leaves out 0, O, 1 and I, which are easily mistaken for each
+// other when codes are typed in.
+const alphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// codeLength counts the check character, the last one.
+const codeLength = 12
+
+// newCode draws a random code and appends its check character.
+func newCode() (string, error) {
+	var b strings.Builder
+	for i := 0; i < codeLength-1; i++ {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(alphabet))))
+		if err != nil {
+			return "", err
+		}
+		b.WriteByte(alphabet[n.Int64()])
+	}
+	b.WriteByte(checkCharacter(b.String()))
+	return b.String(), nil
+}
+
+// checkCharacter computes the Luhn mod N check character of s, which catches
+// any single mistyped character and most swaps of adjacent ones.
+func checkCharacter(s string) byte {
+	n := len(alphabet)
+	factor := 2
+	sum := 0
+	for i := len(s) - 1; i >= 0; i-- {
+		addend := factor * strings.IndexByte(alphabet, s[i])
+		sum += addend/n + addend%n
+		factor = 3 - factor
+	}
+	return alphabet[(n-sum%n)%n]
+}
+
+// NormalizeCode uppercases code and strips the dashes and spaces it may be
+// written with.
+func NormalizeCode(code string) string {
+	code = strings.ToUpper(code)
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, code)
+}
+
+// ValidCode reports whether the normalized code is well formed and its check
+// character matches.
+func ValidCode(code string) bool {
+	if len(code) != codeLength {
+		return false
+	}
+	for i := 0; i < len(code); i++ {
+		if strings.IndexByte(alphabet, code[i]) < 0 {
+			return false
+		}
+	}
+	return checkCharacter(code[:codeLength-1]) == code[codeLength-1]
+}
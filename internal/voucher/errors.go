@@ -0,0 +1,16 @@
+package voucher
+
+import "errors"
+
+var (
+	ErrVoucherNotFound          = errors.New("voucher not found")
+	ErrMissingRequiredParameter = errors.New("missing required parameter")
+	ErrInvalidCode              = errors.New("invalid voucher code")
+	ErrInvalidAmount            = errors.New("invalid amount")
+	ErrInvalidCount             = errors.New("invalid number of vouchers")
+	ErrInvalidMaxRedemptions    = errors.New("invalid max redemptions")
+	ErrInvalidExpiry            = errors.New("expiry must be in the future")
+	ErrVoucherExpired           = errors.New("voucher expired")
+	ErrVoucherExhausted         = errors.New("voucher fully redeemed")
+	ErrAlreadyRedeemed          = errors.New("voucher already redeemed")
+)
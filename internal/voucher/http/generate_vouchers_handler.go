@@ -0,0 +1,53 @@
+package http
+
+import (
+	httphelper "julo/internal/http"
+	"julo/internal/voucher"
+	"net/http"
+	"time"
+)
+
+// GenerateVouchersHandler is an operator endpoint issuing count vouchers of
+// amount, each redeemable by max_redemptions customers (one by default)
+// until expires_at.
+func GenerateVouchersHandler(vouchers voucher.Service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var response httphelper.Response
+
+		req := generateVouchersRequest{Count: 1, MaxRedemptions: 1}
+		if err := httphelper.Decode(w, r, &req); err != nil {
+			httphelper.WriteRequestErrorJSON(w, err)
+			return
+		}
+
+		batch, err := vouchers.GenerateVouchers(r.Context(), voucher.GenerateParam{
+			Count:          req.Count,
+			Amount:         req.Amount,
+			MaxRedemptions: req.MaxRedemptions,
+			ExpiresAt:      req.ExpiresAt,
+		})
+		if err != nil {
+			switch err {
+			case voucher.ErrMissingRequiredParameter, voucher.ErrInvalidCount, voucher.ErrInvalidAmount,
+				voucher.ErrInvalidMaxRedemptions, voucher.ErrInvalidExpiry:
+				httphelper.WriteErrorJSON(w, http.StatusBadRequest, err)
+			default:
+				httphelper.WriteErrorJSON(w, http.StatusInternalServerError, err)
+			}
+			return
+		}
+
+		response.Status = "success"
+		response.Data = map[string]interface{}{
+			"vouchers": newVouchersResponse(batch),
+		}
+		httphelper.WriteJSON(w, http.StatusCreated, response)
+	})
+}
+
+type generateVouchersRequest struct {
+	Count          int       `json:"count"`
+	Amount         int       `json:"amount" validate:"required"`
+	MaxRedemptions int       `json:"max_redemptions"`
+	ExpiresAt      time.Time `json:"expires_at" validate:"required"`
+}
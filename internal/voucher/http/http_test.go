@@ -0,0 +1,131 @@
+package http_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"julo/internal/account"
+	"julo/internal/auth"
+	authhttp "julo/internal/auth/http"
+	httphelper "julo/internal/http"
+	"julo/internal/voucher"
+	voucherhttp "julo/internal/voucher/http"
+	"julo/internal/wallet"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/google/uuid"
+)
+
+func TestRedeemVoucher(t *testing.T) {
+	ctx := context.Background()
+	wallets := wallet.NewService(wallet.NewInMemoryRepository())
+	vouchers := voucher.NewService(voucher.NewInMemoryRepository(), wallets)
+	adminToken := uuid.NewString()
+
+	router := chi.NewRouter()
+	router.Mount("/api/v1", router.Group(func(r chi.Router) {
+		r.Mount("/wallet", r.Group(func(r chi.Router) {
+			r.Use(authhttp.Middleware)
+			r.Post("/vouchers/redeem", voucherhttp.RedeemVoucherHandler(vouchers).ServeHTTP)
+		}))
+		r.Mount("/admin", r.Group(func(r chi.Router) {
+			r.Use(authhttp.AdminMiddleware(adminToken))
+			r.Post("/vouchers", voucherhttp.GenerateVouchersHandler(vouchers).ServeHTTP)
+		}))
+	}))
+	server := httptest.NewServer(router)
+	defer server.Close()
+	baseUrl := server.URL
+
+	owner := uuid.NewString()
+	_, err := wallets.EnableWallet(ctx, wallet.EnableWalletParam{
+		OwnerXID: owner,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := uuid.NewString()
+	err = auth.StoreSession(ctx, auth.Session{
+		Token:   token,
+		Account: account.Account{XID: owner},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	form := url.Values{}
+	form.Set("count", "2")
+	form.Set("amount", "25000")
+	form.Set("expires_at", time.Now().Add(time.Hour).Format(time.RFC3339))
+	res := post(t, server, baseUrl+"/api/v1/admin/vouchers", adminToken, form)
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("expecting status %v, got %v", http.StatusCreated, res.StatusCode)
+	}
+	var response httphelper.Response
+	err = json.NewDecoder(res.Body).Decode(&response)
+	if err != nil {
+		t.Fatal(err)
+	}
+	issued := response.Data.(map[string]interface{})["vouchers"].([]interface{})
+	if len(issued) != 2 {
+		t.Fatalf("expecting 2 vouchers, got %d", len(issued))
+	}
+	code := issued[0].(map[string]interface{})["code"].(string)
+
+	t.Run("redeem voucher, should credit wallet", func(t *testing.T) {
+		form := url.Values{}
+		form.Set("code", code)
+		res := post(t, server, baseUrl+"/api/v1/wallet/vouchers/redeem", token, form)
+		if res.StatusCode != http.StatusCreated {
+			t.Fatalf("expecting status %v, got %v", http.StatusCreated, res.StatusCode)
+		}
+		wal, _ := wallets.GetWalletByXID(ctx, owner)
+		if wal.Balance != 25000 {
+			t.Fatalf("expecting balance 25000, got %d", wal.Balance)
+		}
+	})
+
+	t.Run("redeem voucher again, should conflict", func(t *testing.T) {
+		form := url.Values{}
+		form.Set("code", code)
+		res := post(t, server, baseUrl+"/api/v1/wallet/vouchers/redeem", token, form)
+		if res.StatusCode != http.StatusConflict {
+			t.Fatalf("expecting status %v, got %v", http.StatusConflict, res.StatusCode)
+		}
+	})
+
+	t.Run("redeem mistyped code, should fail", func(t *testing.T) {
+		form := url.Values{}
+		form.Set("code", "AAAA-BBBB-CCCC")
+		res := post(t, server, baseUrl+"/api/v1/wallet/vouchers/redeem", token, form)
+		if res.StatusCode != http.StatusBadRequest && res.StatusCode != http.StatusNotFound {
+			t.Fatalf("expecting status %v, got %v", http.StatusBadRequest, res.StatusCode)
+		}
+	})
+}
+
+func post(t *testing.T, server *httptest.Server, url string, token string, form url.Values) *http.Response {
+	req := buildAuthenticatedRequest(t, http.MethodPost, url, token, bytes.NewBufferString(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	res, err := server.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
+func buildAuthenticatedRequest(t *testing.T, method string, url string, token string, body io.Reader) *http.Request {
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Add("Authorization", fmt.Sprintf("Token %s", token))
+	return req
+}
@@ -0,0 +1,68 @@
+package http
+
+import (
+	"julo/internal/auth"
+	httphelper "julo/internal/http"
+	"julo/internal/voucher"
+	"julo/internal/wallet"
+	"net/http"
+	"time"
+)
+
+// RedeemVoucherHandler credits the voucher in code to the wallet of the
+// customer.
+func RedeemVoucherHandler(vouchers voucher.Service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var response httphelper.Response
+		session := auth.SessionFromContext(r.Context())
+		if session == nil {
+			httphelper.WriteErrorJSON(w, http.StatusUnauthorized, auth.ErrSessionNotFound)
+			return
+		}
+
+		var req redeemVoucherRequest
+		if err := httphelper.Decode(w, r, &req); err != nil {
+			httphelper.WriteRequestErrorJSON(w, err)
+			return
+		}
+
+		result, err := vouchers.RedeemVoucher(r.Context(), voucher.RedeemParam{
+			CustomerXID: session.Account.XID,
+			Code:        req.Code,
+		})
+		if err != nil {
+			switch err {
+			case voucher.ErrVoucherNotFound:
+				httphelper.WriteErrorJSON(w, http.StatusNotFound, err)
+			case voucher.ErrAlreadyRedeemed, voucher.ErrVoucherExhausted:
+				httphelper.WriteErrorJSON(w, http.StatusConflict, err)
+			case voucher.ErrMissingRequiredParameter, voucher.ErrInvalidCode, voucher.ErrVoucherExpired,
+				wallet.ErrWalletDisabled, wallet.ErrWalletNotFound:
+				httphelper.WriteErrorJSON(w, http.StatusBadRequest, err)
+			default:
+				httphelper.WriteErrorJSON(w, http.StatusInternalServerError, err)
+			}
+			return
+		}
+
+		response.Status = "success"
+		response.Data = map[string]interface{}{
+			"redemption": struct {
+				Code          string    `json:"code"`
+				Amount        int       `json:"amount"`
+				TransactionID string    `json:"transaction_id"`
+				RedeemedAt    time.Time `json:"redeemed_at"`
+			}{
+				Code:          result.Voucher.Code,
+				Amount:        result.Voucher.Amount,
+				TransactionID: result.Redemption.TransactionID,
+				RedeemedAt:    result.Redemption.RedeemedAt,
+			},
+		}
+		httphelper.WriteJSON(w, http.StatusCreated, response)
+	})
+}
+
+type redeemVoucherRequest struct {
+	Code string `json:"code" validate:"required"`
+}
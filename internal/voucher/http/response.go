@@ -0,0 +1,36 @@
+package http
+
+import (
+	"julo/internal/voucher"
+	"time"
+)
+
+type voucherResponse struct {
+	Code           string    `json:"code"`
+	BatchID        string    `json:"batch_id"`
+	Amount         int       `json:"amount"`
+	MaxRedemptions int       `json:"max_redemptions"`
+	Redeemed       int       `json:"redeemed"`
+	ExpiresAt      time.Time `json:"expires_at"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+func newVoucherResponse(v voucher.Voucher) voucherResponse {
+	return voucherResponse{
+		Code:           v.Code,
+		BatchID:        v.BatchID,
+		Amount:         v.Amount,
+		MaxRedemptions: v.MaxRedemptions,
+		Redeemed:       len(v.Redemptions),
+		ExpiresAt:      v.ExpiresAt,
+		CreatedAt:      v.CreatedAt,
+	}
+}
+
+func newVouchersResponse(vouchers []voucher.Voucher) []voucherResponse {
+	data := make([]voucherResponse, 0, len(vouchers))
+	for _, v := range vouchers {
+		data = append(data, newVoucherResponse(v))
+	}
+	return data
+}
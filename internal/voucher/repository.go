@@ -0,0 +1,68 @@
+package voucher
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type Redemption struct {
+	CustomerXID   string
+	TransactionID string
+	RedeemedAt    time.Time
+}
+
+// Voucher credits Amount to the wallet of each customer redeeming its code,
+// up to MaxRedemptions customers. Single-use vouchers have a maximum of one.
+type Voucher struct {
+	Code           string
+	BatchID        string
+	Amount         int
+	MaxRedemptions int
+	Redemptions    []Redemption
+	ExpiresAt      time.Time
+	CreatedAt      time.Time
+}
+
+func (v Voucher) IsExpired(now time.Time) bool {
+	return !now.Before(v.ExpiresAt)
+}
+
+func (v Voucher) RedeemedBy(xid string) *Redemption {
+	for i := range v.Redemptions {
+		if v.Redemptions[i].CustomerXID == xid {
+			return &v.Redemptions[i]
+		}
+	}
+	return nil
+}
+
+type Repository interface {
+	SaveVoucher(ctx context.Context, v Voucher) error
+	GetVoucher(ctx context.Context, code string) (*Voucher, error)
+}
+
+type InMemoryRepository struct {
+	vouchers sync.Map
+}
+
+func NewInMemoryRepository() Repository {
+	return &InMemoryRepository{}
+}
+
+func (r *InMemoryRepository) SaveVoucher(ctx context.Context, v Voucher) error {
+	v.Redemptions = append([]Redemption(nil), v.Redemptions...)
+	r.vouchers.Store(v.Code, &v)
+	return nil
+}
+
+func (r *InMemoryRepository) GetVoucher(ctx context.Context, code string) (*Voucher, error) {
+	value, ok := r.vouchers.Load(code)
+	if !ok {
+		return nil, ErrVoucherNotFound
+	}
+
+	v := *value.(*Voucher)
+	v.Redemptions = append([]Redemption(nil), v.Redemptions...)
+	return &v, nil
+}
@@ -0,0 +1,188 @@
+package voucher
+
+import (
+	"context"
+	"julo/internal/wallet"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+// maxBatch bounds the vouchers generated by a single request.
+const maxBatch = 10000
+
+type GenerateParam struct {
+	Count          int
+	Amount         int
+	MaxRedemptions int
+	ExpiresAt      time.Time
+}
+
+func (p GenerateParam) Validate() error {
+	if p.ExpiresAt.IsZero() {
+		return ErrMissingRequiredParameter
+	}
+	if p.Count <= 0 || p.Count > maxBatch {
+		return ErrInvalidCount
+	}
+	if p.Amount <= 0 {
+		return ErrInvalidAmount
+	}
+	if p.MaxRedemptions <= 0 {
+		return ErrInvalidMaxRedemptions
+	}
+	return nil
+}
+
+type RedeemParam struct {
+	CustomerXID string
+	Code        string
+}
+
+type RedeemResult struct {
+	Voucher    Voucher
+	Redemption Redemption
+}
+
+type Service interface {
+	GenerateVouchers(ctx context.Context, param GenerateParam) ([]Voucher, error)
+	GetVoucher(ctx context.Context, code string) (*Voucher, error)
+	RedeemVoucher(ctx context.Context, param RedeemParam) (*RedeemResult, error)
+}
+
+type service struct {
+	repo    Repository
+	wallets wallet.Service
+	mu      sync.Mutex
+}
+
+func NewService(repo Repository, wallets wallet.Service) Service {
+	return &service{
+		repo:    repo,
+		wallets: wallets,
+	}
+}
+
+// GenerateVouchers issues a batch of param.Count vouchers sharing the same
+// terms, each with its own code.
+func (s *service) GenerateVouchers(ctx context.Context, param GenerateParam) ([]Voucher, error) {
+	err := param.Validate()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if !param.ExpiresAt.After(now) {
+		return nil, ErrInvalidExpiry
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	batchID := uuid.NewString()
+	vouchers := make([]Voucher, 0, param.Count)
+	for len(vouchers) < param.Count {
+		code, err := newCode()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed generating voucher code")
+		}
+		_, err = s.repo.GetVoucher(ctx, code)
+		if err == nil {
+			continue
+		} else if err != ErrVoucherNotFound {
+			return nil, errors.Wrap(err, "failed getting voucher")
+		}
+
+		v := Voucher{
+			Code:           code,
+			BatchID:        batchID,
+			Amount:         param.Amount,
+			MaxRedemptions: param.MaxRedemptions,
+			ExpiresAt:      param.ExpiresAt,
+			CreatedAt:      now,
+		}
+		err = s.repo.SaveVoucher(ctx, v)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed saving voucher")
+		}
+		vouchers = append(vouchers, v)
+	}
+
+	return vouchers, nil
+}
+
+func (s *service) GetVoucher(ctx context.Context, code string) (*Voucher, error) {
+	code = NormalizeCode(code)
+	if !ValidCode(code) {
+		return nil, ErrInvalidCode
+	}
+	return s.getVoucher(ctx, code)
+}
+
+// RedeemVoucher credits the amount of the voucher to the customer's wallet.
+// A customer redeems a voucher at most once, the credit being booked with a
+// reference derived from the code so retries never credit twice.
+func (s *service) RedeemVoucher(ctx context.Context, param RedeemParam) (*RedeemResult, error) {
+	if param.CustomerXID == "" || param.Code == "" {
+		return nil, ErrMissingRequiredParameter
+	}
+	code := NormalizeCode(param.Code)
+	if !ValidCode(code) {
+		return nil, ErrInvalidCode
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	v, err := s.getVoucher(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if v.RedeemedBy(param.CustomerXID) != nil {
+		return nil, ErrAlreadyRedeemed
+	}
+	if v.IsExpired(time.Now()) {
+		return nil, ErrVoucherExpired
+	}
+	if len(v.Redemptions) >= v.MaxRedemptions {
+		return nil, ErrVoucherExhausted
+	}
+
+	result, err := s.wallets.DepositWallet(ctx, wallet.WalletTransactionParam{
+		ActorXID:    param.CustomerXID,
+		OwnerXID:    param.CustomerXID,
+		ReferenceID: wallet.SystemReferencePrefix + "voucher-" + v.Code,
+		Amount:      v.Amount,
+		Type:        wallet.TransactionTypeVoucher,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	redemption := Redemption{
+		CustomerXID:   param.CustomerXID,
+		TransactionID: result.ID,
+		RedeemedAt:    result.DepositedAt,
+	}
+	v.Redemptions = append(v.Redemptions, redemption)
+	err = s.repo.SaveVoucher(ctx, *v)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed saving voucher")
+	}
+
+	return &RedeemResult{
+		Voucher:    *v,
+		Redemption: redemption,
+	}, nil
+}
+
+func (s *service) getVoucher(ctx context.Context, code string) (*Voucher, error) {
+	v, err := s.repo.GetVoucher(ctx, code)
+	if err != nil && err == ErrVoucherNotFound {
+		return nil, ErrVoucherNotFound
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed getting voucher")
+	}
+	return v, nil
+}
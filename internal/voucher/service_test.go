@@ -0,0 +1,150 @@
+package voucher_test
+
+import (
+	"context"
+	"julo/internal/voucher"
+	"julo/internal/wallet"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestGenerateVouchers(t *testing.T) {
+	ctx := context.Background()
+	vouchers := voucher.NewService(voucher.NewInMemoryRepository(), wallet.NewService(wallet.NewInMemoryRepository()))
+
+	batch, err := vouchers.GenerateVouchers(ctx, voucher.GenerateParam{
+		Count:          100,
+		Amount:         10000,
+		MaxRedemptions: 1,
+		ExpiresAt:      time.Now().Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	codes := map[string]bool{}
+	for _, v := range batch {
+		if !voucher.ValidCode(v.Code) || codes[v.Code] {
+			t.Fatalf("expecting unique valid codes, got %s", v.Code)
+		}
+		codes[v.Code] = true
+	}
+
+	// a single mistyped character breaks the check character
+	code := []byte(batch[0].Code)
+	if code[3] == 'A' {
+		code[3] = 'B'
+	} else {
+		code[3] = 'A'
+	}
+	if voucher.ValidCode(string(code)) {
+		t.Fatalf("expecting mistyped code %s invalid", code)
+	}
+
+	// codes are accepted however they are written down
+	c := batch[0].Code
+	v, err := vouchers.GetVoucher(ctx, strings.ToLower(c[:4]+"-"+c[4:8]+" "+c[8:]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Code != c {
+		t.Fatalf("expecting voucher %s, got %s", c, v.Code)
+	}
+}
+
+func TestRedeemVoucher(t *testing.T) {
+	ctx := context.Background()
+	wallets := wallet.NewService(wallet.NewInMemoryRepository())
+	vouchers := voucher.NewService(voucher.NewInMemoryRepository(), wallets)
+
+	batch, err := vouchers.GenerateVouchers(ctx, voucher.GenerateParam{
+		Count:          1,
+		Amount:         10000,
+		MaxRedemptions: 3,
+		ExpiresAt:      time.Now().Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	code := batch[0].Code
+
+	customers := make([]string, 5)
+	for i := range customers {
+		customers[i] = uuid.NewString()
+		_, err := wallets.EnableWallet(ctx, wallet.EnableWalletParam{OwnerXID: customers[i]})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// every customer tries several times at once
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	redeemed := map[string]int{}
+	for _, xid := range customers {
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func(xid string) {
+				defer wg.Done()
+				_, err := vouchers.RedeemVoucher(ctx, voucher.RedeemParam{CustomerXID: xid, Code: code})
+				switch err {
+				case nil:
+					mu.Lock()
+					redeemed[xid]++
+					mu.Unlock()
+				case voucher.ErrAlreadyRedeemed, voucher.ErrVoucherExhausted:
+				default:
+					t.Error(err)
+				}
+			}(xid)
+		}
+	}
+	wg.Wait()
+
+	if len(redeemed) != 3 {
+		t.Fatalf("expecting 3 customers to redeem, got %d", len(redeemed))
+	}
+	for _, xid := range customers {
+		wal, err := wallets.GetWalletByXID(ctx, xid)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if redeemed[xid] > 1 || wal.Balance != redeemed[xid]*10000 {
+			t.Fatalf("expecting customer credited %d times, got balance %d", redeemed[xid], wal.Balance)
+		}
+	}
+
+	v, err := vouchers.GetVoucher(ctx, code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(v.Redemptions) != 3 {
+		t.Fatalf("expecting 3 redemptions, got %d", len(v.Redemptions))
+	}
+}
+
+func TestRedeemExpiredVoucher(t *testing.T) {
+	ctx := context.Background()
+	wallets := wallet.NewService(wallet.NewInMemoryRepository())
+	vouchers := voucher.NewService(voucher.NewInMemoryRepository(), wallets)
+
+	batch, err := vouchers.GenerateVouchers(ctx, voucher.GenerateParam{
+		Count:          1,
+		Amount:         10000,
+		MaxRedemptions: 1,
+		ExpiresAt:      time.Now().Add(10 * time.Millisecond),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	_, err = vouchers.RedeemVoucher(ctx, voucher.RedeemParam{CustomerXID: uuid.NewString(), Code: batch[0].Code})
+	if err != voucher.ErrVoucherExpired {
+		t.Fatalf("expecting error %s, got %v", voucher.ErrVoucherExpired, err)
+	}
+}
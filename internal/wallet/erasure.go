@@ -0,0 +1,72 @@
+package wallet
+
+import (
+	"context"
+	"julo/internal/disbursement"
+
+	"github.com/pkg/errors"
+)
+
+type RedactDestinationsParam struct {
+	OwnerXID string
+	// Pseudonym replaces the name of the bank account holder.
+	Pseudonym string
+}
+
+// RedactDestinations pseudonymizes the bank accounts the completed payouts
+// of the wallet went to, keeping the bank and the last digits of the account
+// number. Amounts and statuses are left as booked, payouts still in flight
+// keep their destination until they complete. It returns how many
+// transactions were redacted.
+func (s *service) RedactDestinations(ctx context.Context, param RedactDestinationsParam) (int, error) {
+	if param.OwnerXID == "" || param.Pseudonym == "" {
+		return 0, ErrMissingRequiredParameter
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	wal, err := s.getWallet(ctx, param.OwnerXID)
+	if err != nil {
+		return 0, err
+	}
+	transactions, err := s.repo.GetTransactions(ctx, wal.ID)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed getting wallet transactions")
+	}
+
+	var redacted int
+	for _, t := range transactions {
+		if t.Destination == nil || t.Status == TransactionStatusPending || t.Status == TransactionStatusReview {
+			continue
+		}
+		if t.Destination.AccountName == param.Pseudonym {
+			continue
+		}
+
+		t.Destination = &disbursement.BankAccount{
+			BankCode:      t.Destination.BankCode,
+			AccountNumber: maskAccountNumber(t.Destination.AccountNumber),
+			AccountName:   param.Pseudonym,
+		}
+		err = s.repo.UpdateTransaction(ctx, t)
+		if err != nil {
+			return 0, errors.Wrap(err, "failed updating transaction")
+		}
+		redacted++
+	}
+	return redacted, nil
+}
+
+// maskAccountNumber keeps the last four digits of number.
+func maskAccountNumber(number string) string {
+	if len(number) <= 4 {
+		return number
+	}
+	masked := make([]byte, len(number))
+	for i := range masked {
+		masked[i] = '*'
+	}
+	copy(masked[len(number)-4:], number[len(number)-4:])
+	return string(masked)
+}
@@ -6,15 +6,38 @@ var (
 	ErrWalletNotFound           = errors.New("wallet not found")
 	ErrWalletEnabled            = errors.New("wallet is enabled")
 	ErrWalletDisabled           = errors.New("wallet is disabled")
+	ErrWalletFrozen             = errors.New("wallet is frozen")
+	ErrWalletClosed             = errors.New("wallet is closed")
+	ErrWalletNotEmpty           = errors.New("wallet still holds money")
+	ErrInvalidWalletTransition  = errors.New("invalid wallet status transition")
+	ErrInvalidFreezeReason      = errors.New("invalid freeze reason")
+	ErrInvalidFreezeExpiry      = errors.New("freeze expiry must be in the future")
 	ErrMissingRequiredParameter = errors.New("missing required parameter")
 	ErrInvalidDepositAmount     = errors.New("invalid deposit amount")
 	ErrInsufficientBalance      = errors.New("insufficient balance")
+	ErrTransactionNotFound      = errors.New("transaction not found")
+	ErrInvalidTransition        = errors.New("invalid transaction status transition")
+	ErrDuplicateReference       = errors.New("reference id already used for another transaction")
+	ErrReservedReference        = errors.New("reference id is reserved")
+	ErrSameWalletTransfer       = errors.New("cannot transfer to the same wallet")
+	ErrInvalidCreditLimit       = errors.New("invalid credit limit")
+	ErrInvalidTransactionType   = errors.New("invalid transaction type")
+	ErrTransactionDenied        = errors.New("transaction denied by risk checks")
+	ErrWithdrawalNotAllowed     = errors.New("withdrawals require a verified account")
+	ErrTransactionLimitExceeded = errors.New("amount exceeds the transaction limit of the account")
+	ErrBalanceLimitExceeded     = errors.New("balance would exceed the limit of the account")
 )
 
 type ValidationError struct {
 	messages map[string]error
 }
 
+func NewValidationError() ValidationError {
+	return ValidationError{
+		messages: map[string]error{},
+	}
+}
+
 func (e ValidationError) Error() string {
 	return "validation error"
 }
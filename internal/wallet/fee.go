@@ -0,0 +1,109 @@
+package wallet
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+// FeeCalculator prices outgoing movements, kind being the transaction type
+// of the movement.
+type FeeCalculator interface {
+	Calculate(kind string, amount int) int
+}
+
+// WithFees charges the fee priced by calc on withdrawals and outgoing
+// transfers. The fee is booked as its own transaction linked to the movement
+// and credited to the revenue wallet owned by houseXID.
+func WithFees(calc FeeCalculator, houseXID string) Option {
+	return func(s *service) {
+		s.fees = calc
+		s.houseXID = houseXID
+	}
+}
+
+func (s *service) CalculateFee(ctx context.Context, t TransactionType, amount int) int {
+	return s.calculateFee(t, amount)
+}
+
+func (s *service) calculateFee(t TransactionType, amount int) int {
+	if s.fees == nil {
+		return 0
+	}
+	return s.fees.Calculate(string(t), amount)
+}
+
+// newFeeTransaction books fee for principal on the same wallet and in the
+// same status as principal.
+func newFeeTransaction(principal WalletTransaction, fee int) WalletTransaction {
+	trx := principal
+	trx.ID = uuid.NewString()
+	trx.ReferenceID = "fee-" + principal.ReferenceID
+	trx.Type = TransactionTypeFee
+	trx.Amount = fee
+	trx.ExternalID = ""
+	trx.Destination = nil
+	trx.RelatedID = principal.ID
+	return trx
+}
+
+// findFee returns the fee charged for principal, or nil when it was free.
+func (s *service) findFee(ctx context.Context, principal WalletTransaction) (*WalletTransaction, error) {
+	transactions, err := s.repo.GetTransactions(ctx, principal.WalletID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed getting wallet transactions")
+	}
+	for _, t := range transactions {
+		if t.Type == TransactionTypeFee && t.RelatedID == principal.ID {
+			return &t, nil
+		}
+	}
+	return nil, nil
+}
+
+// collectFee credits a successful fee to the house wallet. The caller must
+// hold s.mu.
+func (s *service) collectFee(ctx context.Context, fee WalletTransaction) error {
+	house, err := s.repo.GetWalletByXID(ctx, s.houseXID)
+	if err != nil && err == ErrWalletNotFound {
+		house = &Wallet{
+			ID:        uuid.NewString(),
+			OwnerXID:  s.houseXID,
+			Status:    WalletStatusEnabled,
+			EnabledAt: time.Now(),
+		}
+		err = s.repo.CreateWallet(ctx, *house)
+		if err != nil {
+			return errors.Wrap(err, "failed creating house wallet")
+		}
+	} else if err != nil {
+		return errors.Wrap(err, "failed getting house wallet")
+	}
+
+	now := time.Now()
+	income := WalletTransaction{
+		ID:          uuid.NewString(),
+		WalletID:    house.ID,
+		ActorXID:    fee.ActorXID,
+		ReferenceID: fee.ID,
+		Type:        TransactionTypeFeeIncome,
+		Date:        now,
+		Amount:      fee.Amount,
+		Status:      TransactionStatusSuccess,
+		SettledAt:   now,
+		RelatedID:   fee.ID,
+	}
+	err = s.repo.CreateTransaction(ctx, income)
+	if err != nil {
+		return errors.Wrap(err, "failed updating house wallet")
+	}
+
+	house.Balance += income.Amount
+	err = s.repo.UpdateWallet(ctx, *house)
+	if err != nil {
+		return errors.Wrap(err, "failed updating house wallet")
+	}
+	return nil
+}
@@ -5,7 +5,6 @@ import (
 	httphelper "julo/internal/http"
 	"julo/internal/wallet"
 	"net/http"
-	"strconv"
 	"time"
 )
 
@@ -18,10 +17,13 @@ func DepositWalletHandler(wallets wallet.Service) http.Handler {
 			return
 		}
 
-		refid := r.FormValue("reference_id")
-		iamount, err := strconv.ParseInt(r.FormValue("amount"), 10, 32)
-		if err != nil {
-			httphelper.WriteErrorJSON(w, http.StatusBadRequest, err)
+		var req depositRequest
+		if err := httphelper.Decode(w, r, &req); err != nil {
+			httphelper.WriteRequestErrorJSON(w, err)
+			return
+		}
+		if wallet.IsSystemReference(req.ReferenceID) {
+			httphelper.WriteRequestErrorJSON(w, httphelper.FieldErrors{"reference_id": wallet.ErrReservedReference.Error()})
 			return
 		}
 
@@ -42,15 +44,13 @@ func DepositWalletHandler(wallets wallet.Service) http.Handler {
 		result, err := wallets.DepositWallet(r.Context(), wallet.WalletTransactionParam{
 			ActorXID:    session.Account.XID,
 			OwnerXID:    wal.OwnerXID,
-			ReferenceID: refid,
-			Amount:      int(iamount),
+			ReferenceID: req.ReferenceID,
+			Amount:      req.Amount,
 		})
 		if err != nil {
 			ve, ok := err.(wallet.ValidationError)
 			if ok {
-				response.Status = "failed"
-				response.Data = ve.GetErrors()
-				httphelper.WriteJSON(w, http.StatusBadRequest, response)
+				httphelper.WriteRequestErrorJSON(w, fieldErrors(ve))
 			} else {
 				httphelper.WriteErrorJSON(w, http.StatusBadRequest, err)
 			}
@@ -69,7 +69,7 @@ func DepositWalletHandler(wallets wallet.Service) http.Handler {
 			}{
 				ID:          result.ID,
 				Depositedby: result.DepositedBy,
-				Status:      result.Status,
+				Status:      string(result.Status),
 				DepositedAt: result.DepositedAt,
 				Amount:      result.Amount,
 				ReferenceID: result.ReferenceID,
@@ -78,3 +78,8 @@ func DepositWalletHandler(wallets wallet.Service) http.Handler {
 		httphelper.WriteJSON(w, http.StatusOK, response)
 	})
 }
+
+type depositRequest struct {
+	ReferenceID string `json:"reference_id" validate:"required"`
+	Amount      int    `json:"amount" validate:"required"`
+}
@@ -5,7 +5,6 @@ import (
 	httphelper "julo/internal/http"
 	"julo/internal/wallet"
 	"net/http"
-	"strconv"
 	"time"
 )
 
@@ -21,17 +20,17 @@ func DisableWalletHandler(wallets wallet.Service) http.Handler {
 			return
 		}
 
-		isDisabled, err := strconv.ParseBool(r.FormValue("is_disabled"))
-		if err != nil {
-			httphelper.WriteErrorJSON(w, http.StatusUnprocessableEntity, err)
+		var req disableRequest
+		if err := httphelper.Decode(w, r, &req); err != nil {
+			httphelper.WriteRequestErrorJSON(w, err)
 			return
 		}
 
-		if !isDisabled {
+		if !*req.IsDisabled {
 			wal, err = wallets.EnableWallet(r.Context(), wallet.EnableWalletParam{
 				OwnerXID: session.Account.XID,
 			})
-			if err != nil && err == wallet.ErrWalletEnabled {
+			if err != nil && (err == wallet.ErrWalletEnabled || err == wallet.ErrWalletFrozen || err == wallet.ErrWalletClosed) {
 				httphelper.WriteErrorJSON(w, http.StatusBadRequest, err)
 				return
 			} else if err != nil {
@@ -45,6 +44,9 @@ func DisableWalletHandler(wallets wallet.Service) http.Handler {
 			if err != nil && err == wallet.ErrWalletDisabled || err == wallet.ErrWalletNotFound {
 				httphelper.WriteErrorJSON(w, http.StatusBadRequest, wallet.ErrWalletDisabled)
 				return
+			} else if err != nil && (err == wallet.ErrWalletFrozen || err == wallet.ErrWalletClosed) {
+				httphelper.WriteErrorJSON(w, http.StatusBadRequest, err)
+				return
 			} else if err != nil {
 				httphelper.WriteErrorJSON(w, http.StatusInternalServerError, err)
 				return
@@ -71,6 +73,10 @@ func DisableWalletHandler(wallets wallet.Service) http.Handler {
 	})
 }
 
+type disableRequest struct {
+	IsDisabled *bool `json:"is_disabled" validate:"required"`
+}
+
 type WalletResponse struct {
 	Status string              `json:"status"`
 	Data   *WalletResponseData `json:"data"`
@@ -20,7 +20,7 @@ func EnableWalletHandler(wallets wallet.Service) http.Handler {
 		wal, err := wallets.EnableWallet(r.Context(), wallet.EnableWalletParam{
 			OwnerXID: session.Account.XID,
 		})
-		if err != nil && err == wallet.ErrWalletEnabled {
+		if err != nil && (err == wallet.ErrWalletEnabled || err == wallet.ErrWalletFrozen || err == wallet.ErrWalletClosed) {
 			httphelper.WriteErrorJSON(w, http.StatusBadRequest, err)
 			return
 		} else if err != nil {
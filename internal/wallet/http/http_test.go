@@ -2,6 +2,7 @@ package http_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -14,6 +15,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"reflect"
 	"strconv"
 	"testing"
 
@@ -127,6 +129,24 @@ func TestWallet(t *testing.T) {
 					t.Fatalf("expecting status %v, got %v", http.StatusOK, res.StatusCode)
 				}
 
+				t.Run("deposit or withdraw with a system reference, should fail", func(t *testing.T) {
+					for _, path := range []string{"/deposits", "/withdrawals"} {
+						form := url.Values{}
+						form.Set("reference_id", wallet.SystemReferencePrefix+"topup-SIMBANK-"+uuid.NewString())
+						form.Set("amount", "1000")
+						req := buildAuthenticatedRequest(t, http.MethodPost, baseUrl+"/api/v1/wallet"+path, token, bytes.NewBufferString(form.Encode()))
+						req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+						res, err := server.Client().Do(req)
+						if err != nil {
+							t.Fatal(err)
+						}
+						if res.StatusCode != http.StatusBadRequest {
+							t.Fatalf("expecting status %v on %s, got %v", http.StatusBadRequest, path, res.StatusCode)
+						}
+					}
+				})
+
 				t.Run("withdraw wallet after deposit, should success", func(t *testing.T) {
 					refid := uuid.NewString()
 					amount := 50000
@@ -204,6 +224,79 @@ func TestWallet(t *testing.T) {
 
 				})
 
+				t.Run("deposit with json body, should success", func(t *testing.T) {
+					body := fmt.Sprintf(`{"reference_id": %q, "amount": 25000}`, uuid.NewString())
+					req := buildAuthenticatedRequest(t, http.MethodPost, baseUrl+"/api/v1/wallet/deposits", token, bytes.NewBufferString(body))
+					req.Header.Set("Content-Type", "application/json")
+
+					res, err := server.Client().Do(req)
+					if err != nil {
+						t.Fatal(err)
+					}
+
+					if res.StatusCode != http.StatusOK {
+						t.Fatalf("expecting status %v, got %v", http.StatusOK, res.StatusCode)
+					}
+				})
+
+				t.Run("deposit with invalid json fields, should fail per field", func(t *testing.T) {
+					body := `{"amount": "25000", "note": "rent"}`
+					req := buildAuthenticatedRequest(t, http.MethodPost, baseUrl+"/api/v1/wallet/deposits", token, bytes.NewBufferString(body))
+					req.Header.Set("Content-Type", "application/json")
+
+					res, err := server.Client().Do(req)
+					if err != nil {
+						t.Fatal(err)
+					}
+
+					if res.StatusCode != http.StatusBadRequest {
+						t.Fatalf("expecting status %v, got %v", http.StatusBadRequest, res.StatusCode)
+					}
+					var response struct {
+						Status string `json:"status"`
+						Data   struct {
+							Error map[string]string `json:"error"`
+						} `json:"data"`
+					}
+					if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+						t.Fatal(err)
+					}
+					want := map[string]string{
+						"reference_id": httphelper.MessageRequired,
+						"amount":       "not a valid integer.",
+						"note":         httphelper.MessageUnknownField,
+					}
+					if response.Status != "fail" || !reflect.DeepEqual(response.Data.Error, want) {
+						t.Fatalf("expecting field errors %v, got %s %v", want, response.Status, response.Data.Error)
+					}
+				})
+
+				t.Run("withdraw with invalid amount, should fail per field", func(t *testing.T) {
+					body := fmt.Sprintf(`{"reference_id": %q, "amount": -5}`, uuid.NewString())
+					req := buildAuthenticatedRequest(t, http.MethodPost, baseUrl+"/api/v1/wallet/withdrawals", token, bytes.NewBufferString(body))
+					req.Header.Set("Content-Type", "application/json")
+
+					res, err := server.Client().Do(req)
+					if err != nil {
+						t.Fatal(err)
+					}
+
+					if res.StatusCode != http.StatusBadRequest {
+						t.Fatalf("expecting status %v, got %v", http.StatusBadRequest, res.StatusCode)
+					}
+					var response struct {
+						Data struct {
+							Error map[string]string `json:"error"`
+						} `json:"data"`
+					}
+					if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+						t.Fatal(err)
+					}
+					if response.Data.Error["amount"] != wallet.ErrInvalidDepositAmount.Error() {
+						t.Fatalf("expecting amount error %q, got %v", wallet.ErrInvalidDepositAmount, response.Data.Error)
+					}
+				})
+
 				t.Run("get transactions, should success", func(t *testing.T) {
 					req := buildAuthenticatedRequest(t, http.MethodGet, baseUrl+"/api/v1/wallet/transactions", token, nil)
 					res, err := server.Client().Do(req)
@@ -256,3 +349,328 @@ func buildAuthenticatedRequest(t *testing.T, method string, url string, token st
 	req.Header.Add("Authorization", fmt.Sprintf("Token %s", token))
 	return req
 }
+
+// reviewWithdrawals holds every withdrawal for review.
+type reviewWithdrawals struct{}
+
+func (reviewWithdrawals) Assess(ctx context.Context, check wallet.RiskCheck) wallet.RiskAssessment {
+	if check.Type == wallet.TransactionTypeWithdrawal {
+		return wallet.RiskAssessment{Decision: wallet.RiskDecisionReview, Reasons: []string{"manual"}}
+	}
+	return wallet.RiskAssessment{Decision: wallet.RiskDecisionAllow}
+}
+
+func TestReviews(t *testing.T) {
+	ctx := context.Background()
+	wallets := wallet.NewService(wallet.NewInMemoryRepository(), wallet.WithRiskAssessor(reviewWithdrawals{}))
+	adminToken := uuid.NewString()
+
+	router := chi.NewRouter()
+	router.Mount("/api/v1", router.Group(func(r chi.Router) {
+		r.Mount("/admin", r.Group(func(r chi.Router) {
+			r.Use(authhttp.AdminMiddleware(adminToken))
+			r.Post("/wallets/{xid}/freeze", wallethttp.FreezeWalletHandler(wallets).ServeHTTP)
+			r.Get("/reviews", wallethttp.ViewReviewQueueHandler(wallets).ServeHTTP)
+			r.Post("/reviews/{id}/approve", wallethttp.ApproveReviewHandler(wallets).ServeHTTP)
+			r.Post("/reviews/{id}/reject", wallethttp.RejectReviewHandler(wallets).ServeHTTP)
+		}))
+	}))
+	server := httptest.NewServer(router)
+	defer server.Close()
+	baseUrl := server.URL
+
+	admin := func(t *testing.T, method string, path string, form url.Values) *http.Response {
+		req := buildAuthenticatedRequest(t, method, baseUrl+"/api/v1/admin"+path, adminToken, bytes.NewBufferString(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		res, err := server.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+
+	xid := uuid.NewString()
+	_, err := wallets.EnableWallet(ctx, wallet.EnableWalletParam{OwnerXID: xid})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = wallets.DepositWallet(ctx, wallet.WalletTransactionParam{
+		ActorXID:    xid,
+		OwnerXID:    xid,
+		ReferenceID: uuid.NewString(),
+		Amount:      100000,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	withdrawals := []string{}
+	for i := 0; i < 2; i++ {
+		result, err := wallets.WithdrawWallet(ctx, wallet.WalletTransactionParam{
+			ActorXID:    xid,
+			OwnerXID:    xid,
+			ReferenceID: uuid.NewString(),
+			Amount:      30000,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		withdrawals = append(withdrawals, result.ID)
+	}
+
+	balance := func(t *testing.T) *wallet.Wallet {
+		wal, err := wallets.GetWalletByXID(ctx, xid)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return wal
+	}
+
+	t.Run("view queue without admin token, should fail", func(t *testing.T) {
+		req := buildAuthenticatedRequest(t, http.MethodGet, baseUrl+"/api/v1/admin/reviews", "", nil)
+		res, err := server.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.StatusCode != http.StatusUnauthorized {
+			t.Fatalf("expecting status %v, got %v", http.StatusUnauthorized, res.StatusCode)
+		}
+	})
+
+	t.Run("view queue, should list withdrawals in review", func(t *testing.T) {
+		res := admin(t, http.MethodGet, "/reviews", nil)
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("expecting status %v, got %v", http.StatusOK, res.StatusCode)
+		}
+		var response struct {
+			Data struct {
+				Transactions []wallet.WalletTransaction `json:"transactions"`
+			} `json:"data"`
+		}
+		if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+			t.Fatal(err)
+		}
+		if len(response.Data.Transactions) != 2 || response.Data.Transactions[0].Status != wallet.TransactionStatusReview {
+			t.Fatalf("expecting 2 transactions in review, got %+v", response.Data.Transactions)
+		}
+	})
+
+	t.Run("approve, should post the withdrawal", func(t *testing.T) {
+		res := admin(t, http.MethodPost, "/reviews/"+withdrawals[0]+"/approve", nil)
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("expecting status %v, got %v", http.StatusOK, res.StatusCode)
+		}
+		if wal := balance(t); wal.Balance != 70000 || wal.Held != 30000 {
+			t.Fatalf("expecting balance 70000 holding 30000, got %d holding %d", wal.Balance, wal.Held)
+		}
+
+		t.Run("reject once approved, should fail", func(t *testing.T) {
+			res := admin(t, http.MethodPost, "/reviews/"+withdrawals[0]+"/reject", nil)
+			if res.StatusCode != http.StatusBadRequest {
+				t.Fatalf("expecting status %v, got %v", http.StatusBadRequest, res.StatusCode)
+			}
+		})
+	})
+
+	t.Run("approve unknown transaction, should fail", func(t *testing.T) {
+		res := admin(t, http.MethodPost, "/reviews/"+uuid.NewString()+"/approve", nil)
+		if res.StatusCode != http.StatusNotFound {
+			t.Fatalf("expecting status %v, got %v", http.StatusNotFound, res.StatusCode)
+		}
+	})
+
+	t.Run("approve on a wallet frozen since, should fail", func(t *testing.T) {
+		res := admin(t, http.MethodPost, "/wallets/"+xid+"/freeze", url.Values{"actor": {"ops-1"}, "reason": {"fraud"}})
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("expecting status %v, got %v", http.StatusOK, res.StatusCode)
+		}
+
+		res = admin(t, http.MethodPost, "/reviews/"+withdrawals[1]+"/approve", nil)
+		if res.StatusCode != http.StatusBadRequest {
+			t.Fatalf("expecting status %v, got %v", http.StatusBadRequest, res.StatusCode)
+		}
+		if wal := balance(t); wal.Balance != 70000 {
+			t.Fatalf("expecting balance 70000, got %d", wal.Balance)
+		}
+
+		t.Run("reject, should release the hold", func(t *testing.T) {
+			res := admin(t, http.MethodPost, "/reviews/"+withdrawals[1]+"/reject", url.Values{"reason": {"fraud"}})
+			if res.StatusCode != http.StatusOK {
+				t.Fatalf("expecting status %v, got %v", http.StatusOK, res.StatusCode)
+			}
+			if wal := balance(t); wal.Balance != 70000 || wal.Held != 0 {
+				t.Fatalf("expecting balance 70000 holding nothing, got %d holding %d", wal.Balance, wal.Held)
+			}
+		})
+	})
+}
+
+func TestWalletStatus(t *testing.T) {
+	ctx := context.Background()
+	accounts := account.NewService(account.NewInMemoryRepository())
+	initializer := auth.NewInitializer(accounts)
+	wallets := wallet.NewService(wallet.NewInMemoryRepository())
+	adminToken := uuid.NewString()
+
+	router := chi.NewRouter()
+	router.Mount("/api/v1", router.Group(func(r chi.Router) {
+		r.Mount("/wallet", r.Group(func(r chi.Router) {
+			r.Use(authhttp.Middleware)
+			r.Patch("/", wallethttp.DisableWalletHandler(wallets).ServeHTTP)
+		}))
+		r.Mount("/admin", r.Group(func(r chi.Router) {
+			r.Use(authhttp.AdminMiddleware(adminToken))
+			r.Post("/wallets/{xid}/freeze", wallethttp.FreezeWalletHandler(wallets).ServeHTTP)
+			r.Post("/wallets/{xid}/unfreeze", wallethttp.UnfreezeWalletHandler(wallets).ServeHTTP)
+			r.Post("/wallets/{xid}/close", wallethttp.CloseWalletHandler(wallets).ServeHTTP)
+		}))
+	}))
+	server := httptest.NewServer(router)
+	defer server.Close()
+	baseUrl := server.URL
+
+	do := func(t *testing.T, method string, path string, token string, form url.Values) *http.Response {
+		req := buildAuthenticatedRequest(t, method, baseUrl+"/api/v1"+path, token, bytes.NewBufferString(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		res, err := server.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+	status := func(t *testing.T, res *http.Response) string {
+		var response struct {
+			Data struct {
+				Wallet struct {
+					Status string `json:"status"`
+				} `json:"wallet"`
+			} `json:"data"`
+		}
+		if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+			t.Fatal(err)
+		}
+		return response.Data.Wallet.Status
+	}
+
+	xid := uuid.NewString()
+	result, err := initializer.Init(ctx, auth.InitParam{CustomerXID: xid})
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := result.Session.Token
+	_, err = wallets.EnableWallet(ctx, wallet.EnableWalletParam{OwnerXID: xid})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = wallets.DepositWallet(ctx, wallet.WalletTransactionParam{
+		ActorXID:    xid,
+		OwnerXID:    xid,
+		ReferenceID: uuid.NewString(),
+		Amount:      10000,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("freeze without admin token, should fail", func(t *testing.T) {
+		res := do(t, http.MethodPost, "/admin/wallets/"+xid+"/freeze", "", url.Values{"actor": {"ops-1"}, "reason": {"fraud"}})
+		if res.StatusCode != http.StatusUnauthorized {
+			t.Fatalf("expecting status %v, got %v", http.StatusUnauthorized, res.StatusCode)
+		}
+	})
+
+	t.Run("freeze with unknown reason, should fail", func(t *testing.T) {
+		res := do(t, http.MethodPost, "/admin/wallets/"+xid+"/freeze", adminToken, url.Values{"actor": {"ops-1"}, "reason": {"bored"}})
+		if res.StatusCode != http.StatusBadRequest {
+			t.Fatalf("expecting status %v, got %v", http.StatusBadRequest, res.StatusCode)
+		}
+	})
+
+	t.Run("freeze unknown wallet, should fail", func(t *testing.T) {
+		res := do(t, http.MethodPost, "/admin/wallets/"+uuid.NewString()+"/freeze", adminToken, url.Values{"actor": {"ops-1"}, "reason": {"fraud"}})
+		if res.StatusCode != http.StatusNotFound {
+			t.Fatalf("expecting status %v, got %v", http.StatusNotFound, res.StatusCode)
+		}
+	})
+
+	t.Run("freeze, should refuse the owner", func(t *testing.T) {
+		res := do(t, http.MethodPost, "/admin/wallets/"+xid+"/freeze", adminToken, url.Values{"actor": {"ops-1"}, "reason": {"fraud"}})
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("expecting status %v, got %v", http.StatusOK, res.StatusCode)
+		}
+		if st := status(t, res); st != string(wallet.WalletStatusFrozen) {
+			t.Fatalf("expecting status %s, got %s", wallet.WalletStatusFrozen, st)
+		}
+
+		for _, disabled := range []string{"true", "false"} {
+			res := do(t, http.MethodPatch, "/wallet", token, url.Values{"is_disabled": {disabled}})
+			if res.StatusCode != http.StatusBadRequest {
+				t.Fatalf("expecting status %v for is_disabled=%s, got %v", http.StatusBadRequest, disabled, res.StatusCode)
+			}
+		}
+
+		t.Run("close frozen wallet, should fail", func(t *testing.T) {
+			res := do(t, http.MethodPost, "/admin/wallets/"+xid+"/close", adminToken, url.Values{"actor": {"ops-1"}})
+			if res.StatusCode != http.StatusBadRequest {
+				t.Fatalf("expecting status %v, got %v", http.StatusBadRequest, res.StatusCode)
+			}
+		})
+
+		t.Run("unfreeze, should give the owner back control", func(t *testing.T) {
+			res := do(t, http.MethodPost, "/admin/wallets/"+xid+"/unfreeze", adminToken, url.Values{"actor": {"ops-1"}})
+			if res.StatusCode != http.StatusOK {
+				t.Fatalf("expecting status %v, got %v", http.StatusOK, res.StatusCode)
+			}
+			if st := status(t, res); st != string(wallet.WalletStatusEnabled) {
+				t.Fatalf("expecting status %s, got %s", wallet.WalletStatusEnabled, st)
+			}
+
+			res = do(t, http.MethodPatch, "/wallet", token, url.Values{"is_disabled": {"true"}})
+			if res.StatusCode != http.StatusOK {
+				t.Fatalf("expecting status %v, got %v", http.StatusOK, res.StatusCode)
+			}
+		})
+
+		t.Run("unfreeze a wallet not frozen, should fail", func(t *testing.T) {
+			res := do(t, http.MethodPost, "/admin/wallets/"+xid+"/unfreeze", adminToken, url.Values{"actor": {"ops-1"}})
+			if res.StatusCode != http.StatusBadRequest {
+				t.Fatalf("expecting status %v, got %v", http.StatusBadRequest, res.StatusCode)
+			}
+		})
+	})
+
+	t.Run("close wallet with balance, should fail", func(t *testing.T) {
+		res := do(t, http.MethodPost, "/admin/wallets/"+xid+"/close", adminToken, url.Values{"actor": {"ops-1"}})
+		if res.StatusCode != http.StatusBadRequest {
+			t.Fatalf("expecting status %v, got %v", http.StatusBadRequest, res.StatusCode)
+		}
+	})
+
+	t.Run("close empty wallet, should be terminal", func(t *testing.T) {
+		_, err := wallets.EnableWallet(ctx, wallet.EnableWalletParam{OwnerXID: xid})
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = wallets.WithdrawWallet(ctx, wallet.WalletTransactionParam{
+			ActorXID:    xid,
+			OwnerXID:    xid,
+			ReferenceID: uuid.NewString(),
+			Amount:      10000,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		res := do(t, http.MethodPost, "/admin/wallets/"+xid+"/close", adminToken, url.Values{"actor": {"ops-1"}})
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("expecting status %v, got %v", http.StatusOK, res.StatusCode)
+		}
+		if st := status(t, res); st != string(wallet.WalletStatusClosed) {
+			t.Fatalf("expecting status %s, got %s", wallet.WalletStatusClosed, st)
+		}
+
+		res = do(t, http.MethodPatch, "/wallet", token, url.Values{"is_disabled": {"false"}})
+		if res.StatusCode != http.StatusBadRequest {
+			t.Fatalf("expecting status %v, got %v", http.StatusBadRequest, res.StatusCode)
+		}
+	})
+}
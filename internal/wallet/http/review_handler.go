@@ -0,0 +1,80 @@
+package http
+
+import (
+	"context"
+	httphelper "julo/internal/http"
+	"julo/internal/wallet"
+	"net/http"
+
+	"github.com/go-chi/chi"
+)
+
+// ViewReviewQueueHandler lists the movements the risk checks held for
+// review.
+func ViewReviewQueueHandler(wallets wallet.Service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var response httphelper.Response
+
+		transactions, err := wallets.GetReviewQueue(r.Context())
+		if err != nil {
+			httphelper.WriteErrorJSON(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		response.Status = "success"
+		response.Data = map[string]interface{}{
+			"transactions": transactions,
+		}
+		httphelper.WriteJSON(w, http.StatusOK, response)
+	})
+}
+
+type reviewAction func(context.Context, wallet.TransitionTransactionParam) (*wallet.WalletTransaction, error)
+
+// ApproveReviewHandler and RejectReviewHandler decide on the movement
+// identified by the "id" url parameter, rejections taking an optional reason.
+func ApproveReviewHandler(wallets wallet.Service) http.Handler {
+	return reviewHandler(wallets.ApproveTransaction)
+}
+
+func RejectReviewHandler(wallets wallet.Service) http.Handler {
+	return reviewHandler(wallets.RejectTransaction)
+}
+
+func reviewHandler(action reviewAction) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var response httphelper.Response
+
+		var req reviewRequest
+		if err := httphelper.Decode(w, r, &req); err != nil {
+			httphelper.WriteRequestErrorJSON(w, err)
+			return
+		}
+
+		trx, err := action(r.Context(), wallet.TransitionTransactionParam{
+			TransactionID: chi.URLParam(r, "id"),
+			Reason:        req.Reason,
+		})
+		if err != nil {
+			switch err {
+			case wallet.ErrTransactionNotFound:
+				httphelper.WriteErrorJSON(w, http.StatusNotFound, err)
+			case wallet.ErrInvalidTransition, wallet.ErrWalletDisabled, wallet.ErrWalletFrozen, wallet.ErrWalletClosed:
+				httphelper.WriteErrorJSON(w, http.StatusBadRequest, err)
+			default:
+				httphelper.WriteErrorJSON(w, http.StatusInternalServerError, err)
+			}
+			return
+		}
+
+		response.Status = "success"
+		response.Data = map[string]interface{}{
+			"transaction": trx,
+		}
+		httphelper.WriteJSON(w, http.StatusOK, response)
+	})
+}
+
+type reviewRequest struct {
+	Reason string `json:"reason"`
+}
@@ -0,0 +1,16 @@
+package http
+
+import (
+	httphelper "julo/internal/http"
+	"julo/internal/wallet"
+)
+
+// fieldErrors reports the validation errors of the wallet service in the
+// same shape as those of the request.
+func fieldErrors(ve wallet.ValidationError) httphelper.FieldErrors {
+	errs := httphelper.FieldErrors{}
+	for field, err := range ve.GetErrors() {
+		errs.Add(field, err.Error())
+	}
+	return errs
+}
@@ -31,22 +31,65 @@ func ViewWalletBalanceHandler(wallets wallet.Service) http.Handler {
 			return
 		}
 
+		balance := walletBalanceResponse{
+			ID:        wal.ID,
+			OwnedBy:   wal.OwnerXID,
+			Status:    string(wal.Status),
+			EnabledAt: wal.EnabledAt,
+			Balance:   wal.Balance,
+			Freeze:    newFreezeResponse(wal.Freeze),
+		}
+		if wal.CreditLimit > 0 || wal.CreditUsed() > 0 {
+			balance.Credit = &creditBalanceResponse{
+				CashBalance:     wal.CashBalance(),
+				CreditLimit:     wal.CreditLimit,
+				CreditUsed:      wal.CreditUsed(),
+				AvailableCredit: wal.AvailableCredit(),
+			}
+		}
+
 		response.Status = "success"
 		response.Data = map[string]interface{}{
-			"wallet": struct {
-				ID        string    `json:"id"`
-				OwnedBy   string    `json:"owned_by"`
-				Status    string    `json:"status"`
-				EnabledAt time.Time `json:"enabled_at"`
-				Balance   int       `json:"balance"`
-			}{
-				ID:        wal.ID,
-				OwnedBy:   wal.OwnerXID,
-				Status:    string(wal.Status),
-				EnabledAt: wal.EnabledAt,
-				Balance:   wal.Balance,
-			},
+			"wallet": balance,
 		}
 		httphelper.WriteJSON(w, http.StatusOK, response)
 	})
 }
+
+type walletBalanceResponse struct {
+	ID        string    `json:"id"`
+	OwnedBy   string    `json:"owned_by"`
+	Status    string    `json:"status"`
+	EnabledAt time.Time `json:"enabled_at"`
+	Balance   int       `json:"balance"`
+	// Credit is only reported for wallets with a credit line.
+	Credit *creditBalanceResponse `json:"credit,omitempty"`
+	Freeze *freezeResponse        `json:"freeze,omitempty"`
+}
+
+type freezeResponse struct {
+	Reason    string     `json:"reason"`
+	FrozenAt  time.Time  `json:"frozen_at"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+func newFreezeResponse(f *wallet.Freeze) *freezeResponse {
+	if f == nil {
+		return nil
+	}
+	res := &freezeResponse{
+		Reason:   string(f.Reason),
+		FrozenAt: f.FrozenAt,
+	}
+	if !f.ExpiresAt.IsZero() {
+		res.ExpiresAt = &f.ExpiresAt
+	}
+	return res
+}
+
+type creditBalanceResponse struct {
+	CashBalance     int `json:"cash_balance"`
+	CreditLimit     int `json:"credit_limit"`
+	CreditUsed      int `json:"credit_used"`
+	AvailableCredit int `json:"available_credit"`
+}
@@ -38,9 +38,19 @@ func ViewWalletTransactionsHandler(wallets wallet.Service) http.Handler {
 			return
 		}
 
+		transactions := result.Transactions
+		if pocketID := r.URL.Query().Get("pocket_id"); pocketID != "" {
+			transactions = []wallet.WalletTransaction{}
+			for _, t := range result.Transactions {
+				if t.PocketID == pocketID {
+					transactions = append(transactions, t)
+				}
+			}
+		}
+
 		response.Status = "success"
 		response.Data = map[string]interface{}{
-			"transactions": result.Transactions,
+			"transactions": transactions,
 		}
 		httphelper.WriteJSON(w, http.StatusOK, response)
 	})
@@ -0,0 +1,106 @@
+package http
+
+import (
+	httphelper "julo/internal/http"
+	"julo/internal/wallet"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi"
+)
+
+// FreezeWalletHandler freezes the wallet of the "xid" url parameter with a
+// reason code, the operator in actor and an optional RFC 3339 expires_at.
+func FreezeWalletHandler(wallets wallet.Service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req freezeWalletRequest
+		if err := httphelper.Decode(w, r, &req); err != nil {
+			httphelper.WriteRequestErrorJSON(w, err)
+			return
+		}
+
+		wal, err := wallets.FreezeWallet(r.Context(), wallet.FreezeWalletParam{
+			OwnerXID:  chi.URLParam(r, "xid"),
+			ActorXID:  req.Actor,
+			Reason:    wallet.FreezeReason(req.Reason),
+			ExpiresAt: req.ExpiresAt,
+		})
+		writeWalletStatus(w, wal, err)
+	})
+}
+
+func UnfreezeWalletHandler(wallets wallet.Service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req actorRequest
+		if err := httphelper.Decode(w, r, &req); err != nil {
+			httphelper.WriteRequestErrorJSON(w, err)
+			return
+		}
+
+		wal, err := wallets.UnfreezeWallet(r.Context(), wallet.UnfreezeWalletParam{
+			OwnerXID: chi.URLParam(r, "xid"),
+			ActorXID: req.Actor,
+		})
+		writeWalletStatus(w, wal, err)
+	})
+}
+
+func CloseWalletHandler(wallets wallet.Service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req actorRequest
+		if err := httphelper.Decode(w, r, &req); err != nil {
+			httphelper.WriteRequestErrorJSON(w, err)
+			return
+		}
+
+		wal, err := wallets.CloseWallet(r.Context(), wallet.CloseWalletParam{
+			OwnerXID: chi.URLParam(r, "xid"),
+			ActorXID: req.Actor,
+		})
+		writeWalletStatus(w, wal, err)
+	})
+}
+
+func writeWalletStatus(w http.ResponseWriter, wal *wallet.Wallet, err error) {
+	var response httphelper.Response
+	if err != nil {
+		ve, ok := err.(wallet.ValidationError)
+		switch {
+		case ok:
+			response.Status = "failed"
+			response.Data = ve.GetErrors()
+			httphelper.WriteJSON(w, http.StatusBadRequest, response)
+		case err == wallet.ErrWalletNotFound:
+			httphelper.WriteErrorJSON(w, http.StatusNotFound, err)
+		case err == wallet.ErrMissingRequiredParameter, err == wallet.ErrInvalidWalletTransition,
+			err == wallet.ErrWalletClosed, err == wallet.ErrWalletNotEmpty:
+			httphelper.WriteErrorJSON(w, http.StatusBadRequest, err)
+		default:
+			httphelper.WriteErrorJSON(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	response.Status = "success"
+	response.Data = map[string]interface{}{
+		"wallet": walletBalanceResponse{
+			ID:        wal.ID,
+			OwnedBy:   wal.OwnerXID,
+			Status:    string(wal.Status),
+			EnabledAt: wal.EnabledAt,
+			Balance:   wal.Balance,
+			Freeze:    newFreezeResponse(wal.Freeze),
+		},
+	}
+	httphelper.WriteJSON(w, http.StatusOK, response)
+}
+
+type freezeWalletRequest struct {
+	Actor     string    `json:"actor" validate:"required"`
+	Reason    string    `json:"reason" validate:"required"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type actorRequest struct {
+	Actor string `json:"actor" validate:"required"`
+}
@@ -2,10 +2,10 @@ package http
 
 import (
 	"julo/internal/auth"
+	"julo/internal/disbursement"
 	httphelper "julo/internal/http"
 	"julo/internal/wallet"
 	"net/http"
-	"strconv"
 	"time"
 )
 
@@ -18,10 +18,13 @@ func WithdrawWalletHandler(wallets wallet.Service) http.Handler {
 			return
 		}
 
-		refid := r.FormValue("reference_id")
-		iamount, err := strconv.ParseInt(r.FormValue("amount"), 10, 32)
-		if err != nil {
-			httphelper.WriteErrorJSON(w, http.StatusBadRequest, err)
+		var req withdrawRequest
+		if err := httphelper.Decode(w, r, &req); err != nil {
+			httphelper.WriteRequestErrorJSON(w, err)
+			return
+		}
+		if wallet.IsSystemReference(req.ReferenceID) {
+			httphelper.WriteRequestErrorJSON(w, httphelper.FieldErrors{"reference_id": wallet.ErrReservedReference.Error()})
 			return
 		}
 
@@ -39,18 +42,26 @@ func WithdrawWalletHandler(wallets wallet.Service) http.Handler {
 			return
 		}
 
+		var destination *disbursement.BankAccount
+		if req.AccountNumber != "" {
+			destination = &disbursement.BankAccount{
+				BankCode:      req.BankCode,
+				AccountNumber: req.AccountNumber,
+				AccountName:   req.AccountName,
+			}
+		}
+
 		result, err := wallets.WithdrawWallet(r.Context(), wallet.WalletTransactionParam{
 			ActorXID:    session.Account.XID,
 			OwnerXID:    wal.OwnerXID,
-			ReferenceID: refid,
-			Amount:      int(iamount),
+			ReferenceID: req.ReferenceID,
+			Amount:      req.Amount,
+			Destination: destination,
 		})
 		if err != nil {
 			ve, ok := err.(wallet.ValidationError)
 			if ok {
-				response.Status = "failed"
-				response.Data = ve.GetErrors()
-				httphelper.WriteJSON(w, http.StatusBadRequest, response)
+				httphelper.WriteRequestErrorJSON(w, fieldErrors(ve))
 			} else {
 				httphelper.WriteErrorJSON(w, http.StatusBadRequest, err)
 			}
@@ -65,16 +76,28 @@ func WithdrawWalletHandler(wallets wallet.Service) http.Handler {
 				Status      string    `json:"status"`
 				DepositedAt time.Time `json:"deposited_at"`
 				Amount      int       `json:"amount"`
+				Fee         int       `json:"fee"`
 				ReferenceID string    `json:"reference_id"`
 			}{
 				ID:          result.ID,
 				Depositedby: result.DepositedBy,
-				Status:      result.Status,
+				Status:      string(result.Status),
 				DepositedAt: result.DepositedAt,
 				Amount:      result.Amount,
+				Fee:         result.Fee,
 				ReferenceID: result.ReferenceID,
 			},
 		}
 		httphelper.WriteJSON(w, http.StatusOK, response)
 	})
 }
+
+type withdrawRequest struct {
+	ReferenceID string `json:"reference_id" validate:"required"`
+	Amount      int    `json:"amount" validate:"required"`
+	// The bank account to pay out to, needed when payouts go through a
+	// disbursement provider.
+	BankCode      string `json:"bank_code"`
+	AccountNumber string `json:"account_number"`
+	AccountName   string `json:"account_name"`
+}
@@ -0,0 +1,72 @@
+package wallet
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+// Limits caps what a customer may hold and move, a zero cap meaning no cap.
+type Limits struct {
+	// MaxBalance caps the balance, pockets included, deposits and incoming
+	// transfers may bring the wallet to.
+	MaxBalance int `json:"max_balance"`
+	// MaxTransaction caps a single deposit, withdrawal or transfer.
+	MaxTransaction int `json:"max_transaction"`
+	// BlockWithdrawals keeps money from being paid out of the wallet.
+	BlockWithdrawals bool `json:"block_withdrawals"`
+}
+
+// LimitProvider tells the limits applying to the wallet of a customer, nil
+// when the wallet is not limited.
+type LimitProvider interface {
+	Limits(ctx context.Context, ownerXID string) (*Limits, error)
+}
+
+// WithLimits enforces the limits told by p on customer deposits, withdrawals
+// and transfers. Movements booked by the system, such as fees, interest or
+// loan disbursements, are not limited.
+func WithLimits(p LimitProvider) Option {
+	return func(s *service) {
+		s.limits = p
+	}
+}
+
+// checkLimits checks a movement of type t of amount on wal against the limits
+// of its owner. The caller must hold s.mu.
+func (s *service) checkLimits(ctx context.Context, wal *Wallet, t TransactionType, amount int) error {
+	switch t {
+	case TransactionTypeDeposit, TransactionTypeWithdrawal, TransactionTypeTransferIn, TransactionTypeTransferOut:
+	default:
+		return nil
+	}
+	if s.limits == nil {
+		return nil
+	}
+
+	limits, err := s.limits.Limits(ctx, wal.OwnerXID)
+	if err != nil {
+		return errors.Wrap(err, "failed getting limits")
+	}
+	if limits == nil {
+		return nil
+	}
+
+	if t == TransactionTypeWithdrawal && limits.BlockWithdrawals {
+		return ErrWithdrawalNotAllowed
+	}
+	if limits.MaxTransaction > 0 && amount > limits.MaxTransaction {
+		return ErrTransactionLimitExceeded
+	}
+	if t.IsCredit() && limits.MaxBalance > 0 {
+		// money set aside in pockets is still held by the customer
+		transactions, err := s.repo.GetTransactions(ctx, wal.ID)
+		if err != nil {
+			return errors.Wrap(err, "failed getting transactions")
+		}
+		if wal.Balance+PocketBalance(transactions)+amount > limits.MaxBalance {
+			return ErrBalanceLimitExceeded
+		}
+	}
+	return nil
+}
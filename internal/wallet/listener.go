@@ -0,0 +1,64 @@
+package wallet
+
+import "context"
+
+// TransactionListener is told about a movement of a wallet, along with the
+// owner of the wallet.
+type TransactionListener func(ctx context.Context, ownerXID string, t WalletTransaction)
+
+type change struct {
+	ownerXID string
+	trx      WalletTransaction
+}
+
+// OnTransactionCompleted registers fn to be called with each transaction
+// once it completed successfully. Fees are bookkeeping of the movement they
+// were charged for and are not reported.
+func (s *service) OnTransactionCompleted(fn TransactionListener) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.listeners = append(s.listeners, fn)
+}
+
+// OnTransactionChanged registers fn to be called with each transaction when
+// it is booked and on every change of its status, whatever the outcome. Fees
+// are not reported either.
+func (s *service) OnTransactionChanged(fn TransactionListener) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.watchers = append(s.watchers, fn)
+}
+
+// changed adds trx to the changes of the running operation. The caller must
+// hold s.mu.
+func (s *service) changed(changes []change, wal *Wallet, trx WalletTransaction) []change {
+	if len(s.listeners) == 0 && len(s.watchers) == 0 {
+		return changes
+	}
+	return append(changes, change{ownerXID: wal.OwnerXID, trx: trx})
+}
+
+// publish hands the changes of an operation to the watchers, and those that
+// succeeded to the listeners. It is deferred before s.mu is taken, so
+// listeners run once the lock is released and may call back into the service.
+func (s *service) publish(ctx context.Context, changes *[]change) {
+	if len(*changes) == 0 {
+		return
+	}
+
+	s.mu.Lock()
+	listeners, watchers := s.listeners, s.watchers
+	s.mu.Unlock()
+
+	for _, c := range *changes {
+		for _, fn := range watchers {
+			fn(ctx, c.ownerXID, c.trx)
+		}
+		if c.trx.Status != TransactionStatusSuccess {
+			continue
+		}
+		for _, fn := range listeners {
+			fn(ctx, c.ownerXID, c.trx)
+		}
+	}
+}
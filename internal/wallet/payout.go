@@ -0,0 +1,123 @@
+package wallet
+
+import (
+	"context"
+	"julo/internal/disbursement"
+	"log"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type ReconcilePayoutsParam struct {
+	// Before leaves alone payouts submitted at or after it, giving their
+	// callback time to arrive.
+	Before time.Time
+}
+
+// ReconcilePayouts polls the disbursement provider for the payouts of
+// withdrawals still pending, settling or failing them as the callback of the
+// provider would have. A payout the provider does not know of, such as one
+// it dropped on shutdown, fails its withdrawal and releases the hold. The
+// transactions that completed are returned, along with the first error met;
+// an error on one payout does not stop the others from being reconciled.
+func (s *service) ReconcilePayouts(ctx context.Context, param ReconcilePayoutsParam) ([]WalletTransaction, error) {
+	if s.payouts == nil {
+		return nil, nil
+	}
+
+	pending, err := s.repo.GetTransactionsByStatus(ctx, TransactionStatusPending)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed getting transactions")
+	}
+
+	completed := []WalletTransaction{}
+	var firstErr error
+	for _, t := range pending {
+		// payouts not yet handed to the provider have nothing to poll
+		if !t.Type.IsPayout() || t.ExternalID == "" || !t.Date.Before(param.Before) {
+			continue
+		}
+
+		trx, err := s.reconcilePayout(ctx, t)
+		if err != nil && firstErr == nil {
+			firstErr = errors.Wrapf(err, "failed reconciling payout of transaction %s", t.ID)
+		}
+		if trx != nil {
+			completed = append(completed, *trx)
+		}
+	}
+	return completed, firstErr
+}
+
+// reconcilePayout completes t according to its payout, returning nil while
+// the payout is still in flight.
+func (s *service) reconcilePayout(ctx context.Context, t WalletTransaction) (*WalletTransaction, error) {
+	param := TransitionTransactionParam{TransactionID: t.ID}
+
+	payout, err := s.payouts.GetPayout(ctx, t.ExternalID)
+	var trx *WalletTransaction
+	switch {
+	case err == disbursement.ErrPayoutNotFound:
+		param.Reason = "payout unknown to the disbursement provider"
+		trx, err = s.FailTransaction(ctx, param)
+	case err != nil:
+		return nil, err
+	case payout.Status == disbursement.PayoutStatusSuccess:
+		trx, err = s.SettleTransaction(ctx, param)
+	case payout.Status == disbursement.PayoutStatusFailed:
+		param.Reason = payout.FailureReason
+		trx, err = s.FailTransaction(ctx, param)
+	default:
+		return nil, nil
+	}
+	// the callback got there first
+	if err == ErrInvalidTransition {
+		return nil, nil
+	}
+	return trx, err
+}
+
+// PayoutWorker reconciles pending payouts every interval, so a payout whose
+// callback never arrived does not hold money on the wallet forever.
+type PayoutWorker struct {
+	wallets  Service
+	interval time.Duration
+	// grace is how long a payout is left to its callback before polling.
+	grace time.Duration
+}
+
+func NewPayoutWorker(wallets Service, interval time.Duration, grace time.Duration) *PayoutWorker {
+	return &PayoutWorker{
+		wallets:  wallets,
+		interval: interval,
+		grace:    grace,
+	}
+}
+
+// Run blocks, reconciling straight away to catch up on payouts dropped while
+// the api was down and then every interval until ctx is done.
+func (w *PayoutWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	w.reconcile(ctx, time.Now())
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			w.reconcile(ctx, now)
+		}
+	}
+}
+
+func (w *PayoutWorker) reconcile(ctx context.Context, now time.Time) {
+	completed, err := w.wallets.ReconcilePayouts(ctx, ReconcilePayoutsParam{Before: now.Add(-w.grace)})
+	if err != nil {
+		log.Println(err)
+	}
+	for _, t := range completed {
+		log.Printf("reconciled payout of transaction %s as %s", t.ID, t.Status)
+	}
+}
@@ -0,0 +1,13 @@
+package wallet
+
+import "strings"
+
+// SystemReferencePrefix starts the references of movements the system books
+// on its own, such as bank top-ups, so a reference a customer picks can never
+// take theirs.
+const SystemReferencePrefix = "system:"
+
+// IsSystemReference reports whether ref is reserved for the system.
+func IsSystemReference(ref string) bool {
+	return strings.HasPrefix(ref, SystemReferencePrefix)
+}
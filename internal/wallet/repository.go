@@ -2,6 +2,8 @@ package wallet
 
 import (
 	"context"
+	"julo/internal/disbursement"
+	"sort"
 	"sync"
 	"time"
 )
@@ -10,8 +12,83 @@ type Wallet struct {
 	ID        string
 	OwnerXID  string
 	Balance   int
+	Held      int
 	EnabledAt time.Time
 	Status    WalletStatus
+	// CreditLimit lets the balance go negative down to -CreditLimit, the
+	// negative part being credit drawn by the owner.
+	CreditLimit int
+	// Freeze tells why and until when a frozen wallet is frozen.
+	Freeze   *Freeze
+	ClosedAt time.Time
+}
+
+type FreezeReason string
+
+var (
+	FreezeReasonFraud      = FreezeReason("fraud")
+	FreezeReasonLegal      = FreezeReason("legal")
+	FreezeReasonCompliance = FreezeReason("compliance")
+	FreezeReasonDispute    = FreezeReason("dispute")
+)
+
+func (r FreezeReason) IsValid() bool {
+	switch r {
+	case FreezeReasonFraud, FreezeReasonLegal, FreezeReasonCompliance, FreezeReasonDispute:
+		return true
+	}
+	return false
+}
+
+// Freeze is an operator hold on a wallet. The wallet goes back to
+// PreviousStatus when it is lifted or once ExpiresAt passes, a zero
+// ExpiresAt keeping it frozen until lifted.
+type Freeze struct {
+	Reason         FreezeReason
+	ActorXID       string
+	FrozenAt       time.Time
+	ExpiresAt      time.Time
+	PreviousStatus WalletStatus
+}
+
+// IsExpired reports whether the freeze is over at now.
+func (f Freeze) IsExpired(now time.Time) bool {
+	return !f.ExpiresAt.IsZero() && !now.Before(f.ExpiresAt)
+}
+
+// AvailableBalance is the balance that can still be spent, excluding the
+// amount held by pending withdrawals.
+func (w Wallet) AvailableBalance() int {
+	return w.Balance - w.Held
+}
+
+// SpendableBalance is the available balance plus the credit line.
+func (w Wallet) SpendableBalance() int {
+	return w.AvailableBalance() + w.CreditLimit
+}
+
+// CashBalance is the part of the balance that is the owner's own money.
+func (w Wallet) CashBalance() int {
+	if w.Balance < 0 {
+		return 0
+	}
+	return w.Balance
+}
+
+// CreditUsed is the credit drawn, the negative part of the balance.
+func (w Wallet) CreditUsed() int {
+	if w.Balance > 0 {
+		return 0
+	}
+	return -w.Balance
+}
+
+// AvailableCredit is the credit that can still be drawn.
+func (w Wallet) AvailableCredit() int {
+	if spendable := w.SpendableBalance(); spendable < w.CreditLimit {
+		return spendable
+	}
+	return w.CreditLimit
 }
 
 type WalletStatus string
@@ -19,30 +96,189 @@ type WalletStatus string
 var (
 	WalletStatusEnabled  = WalletStatus("enabled")
 	WalletStatusDisabled = WalletStatus("disabled")
+	// Frozen is imposed by an operator and blocks movements, the owner
+	// cannot lift it.
+	WalletStatusFrozen = WalletStatus("frozen")
+	// Closed is terminal.
+	WalletStatusClosed = WalletStatus("closed")
+)
+
+// CanTransitionTo reports whether a wallet in status s may move to next.
+// Owners switch between enabled and disabled, operators freeze and lift
+// freezes, and close wallets that are not frozen.
+func (s WalletStatus) CanTransitionTo(next WalletStatus) bool {
+	switch s {
+	case WalletStatusEnabled, WalletStatusDisabled:
+		switch next {
+		case WalletStatusEnabled, WalletStatusDisabled, WalletStatusFrozen, WalletStatusClosed:
+			return next != s
+		}
+	case WalletStatusFrozen:
+		switch next {
+		case WalletStatusEnabled, WalletStatusDisabled:
+			return true
+		}
+	}
+	return false
+}
+
+type TransactionType string
+
+var (
+	TransactionTypeDeposit     = TransactionType("deposit")
+	TransactionTypeWithdrawal  = TransactionType("withdrawal")
+	TransactionTypeTransferIn  = TransactionType("transfer_in")
+	TransactionTypeTransferOut = TransactionType("transfer_out")
+	TransactionTypeFee         = TransactionType("fee")
+	TransactionTypeFeeIncome   = TransactionType("fee_income")
+	// loan principal paid into the wallet and installments paid from it
+	TransactionTypeLoanDisbursement = TransactionType("loan_disbursement")
+	TransactionTypeLoanRepayment    = TransactionType("loan_repayment")
+	// interest charged on the credit drawn from the wallet's credit line
+	TransactionTypeCreditInterest = TransactionType("credit_interest")
+	// interest earned on a positive balance
+	TransactionTypeInterest = TransactionType("interest")
+	// money set aside in a pocket of the wallet and brought back from it
+	TransactionTypeToPocket   = TransactionType("to_pocket")
+	TransactionTypeFromPocket = TransactionType("from_pocket")
+	// reward credited by a promotion campaign
+	TransactionTypeCashback = TransactionType("cashback")
+	// amount of a voucher redeemed into the wallet
+	TransactionTypeVoucher = TransactionType("voucher")
+	// remaining balance paid out when the account is closed
+	TransactionTypeClosurePayout = TransactionType("closure_payout")
+)
+
+// IsPayout reports whether transactions of type t pay money out of the
+// system to a bank account.
+func (t TransactionType) IsPayout() bool {
+	return t == TransactionTypeWithdrawal || t == TransactionTypeClosurePayout
+}
+
+// IsCredit reports whether transactions of type t add to the balance, the
+// other types take from it.
+func (t TransactionType) IsCredit() bool {
+	switch t {
+	case TransactionTypeDeposit, TransactionTypeTransferIn, TransactionTypeFeeIncome, TransactionTypeLoanDisbursement,
+		TransactionTypeInterest, TransactionTypeFromPocket, TransactionTypeCashback,
+		TransactionTypeVoucher:
+		return true
+	}
+	return false
+}
+
+type TransactionStatus string
+
+var (
+	TransactionStatusPending = TransactionStatus("pending")
+	TransactionStatusSuccess = TransactionStatus("success")
+	TransactionStatusFailed  = TransactionStatus("failed")
+	TransactionStatusExpired = TransactionStatus("expired")
+	// Review holds a movement flagged by the risk assessor until an operator
+	// approves or rejects it.
+	TransactionStatusReview = TransactionStatus("review")
 )
 
+// CanTransitionTo reports whether a transaction in status s may move to next.
+// Only pending transactions and the ones in review can change status;
+// success, failed and expired are terminal.
+func (s TransactionStatus) CanTransitionTo(next TransactionStatus) bool {
+	switch s {
+	case TransactionStatusPending:
+		switch next {
+		case TransactionStatusSuccess, TransactionStatusFailed, TransactionStatusExpired:
+			return true
+		}
+	case TransactionStatusReview:
+		switch next {
+		case TransactionStatusPending, TransactionStatusSuccess, TransactionStatusFailed:
+			return true
+		}
+	}
+	return false
+}
+
 type WalletTransaction struct {
 	ID          string `json:"id"`
 	WalletID    string
 	ActorXID    string
-	ReferenceID string    `json:"reference_id"`
-	Type        string    `json:"type"`
-	Date        time.Time `json:"transacted_at"`
-	Amount      int       `json:"amount"`
-	Status      string    `json:"status"`
+	ReferenceID string            `json:"reference_id"`
+	Type        TransactionType   `json:"type"`
+	Date        time.Time         `json:"transacted_at"`
+	Amount      int               `json:"amount"`
+	Status      TransactionStatus `json:"status"`
+	SettledAt   time.Time         `json:"settled_at"`
+	Reason      string            `json:"reason,omitempty"`
+	// ExternalID is the reference of the transaction at the payment or
+	// disbursement provider.
+	ExternalID string `json:"external_id,omitempty"`
+	// RelatedID links the transaction to the one it was booked with, such as
+	// the other leg of a transfer.
+	RelatedID string `json:"related_id,omitempty"`
+	// PocketID is the pocket money was moved to or from.
+	PocketID string `json:"pocket_id,omitempty"`
+	// CampaignID is the promotion campaign that granted a cashback.
+	CampaignID string `json:"campaign_id,omitempty"`
+	// Destination is the bank account a withdrawal is paid out to, kept
+	// for the payout of withdrawals approved after a review.
+	Destination *disbursement.BankAccount `json:"-"`
+}
+
+// SignedAmount is the effect of t on the balance once it succeeded, negative
+// for debits.
+func (t WalletTransaction) SignedAmount() int {
+	if t.Type.IsCredit() {
+		return t.Amount
+	}
+	return -t.Amount
+}
+
+// BalanceAt replays transactions into the balance just before at, counting
+// the transactions that had succeeded by then.
+func BalanceAt(transactions []WalletTransaction, at time.Time) int {
+	var balance int
+	for _, t := range transactions {
+		if t.Status == TransactionStatusSuccess && t.SettledAt.Before(at) {
+			balance += t.SignedAmount()
+		}
+	}
+	return balance
+}
+
+// PocketBalance is the money set aside in the pockets of the wallet, which
+// the balance no longer counts.
+func PocketBalance(transactions []WalletTransaction) int {
+	var balance int
+	for _, t := range transactions {
+		if t.PocketID != "" && t.Status == TransactionStatusSuccess {
+			balance -= t.SignedAmount()
+		}
+	}
+	return balance
 }
 
 type Repository interface {
 	GetWalletByXID(ctx context.Context, xid string) (*Wallet, error)
+	GetWalletByID(ctx context.Context, id string) (*Wallet, error)
+	GetWallets(ctx context.Context) ([]Wallet, error)
 	CreateWallet(ctx context.Context, wallet Wallet) error
 	UpdateWallet(ctx context.Context, wallet Wallet) error
 	CreateTransaction(ctx context.Context, t WalletTransaction) error
+	UpdateTransaction(ctx context.Context, t WalletTransaction) error
+	GetTransaction(ctx context.Context, id string) (*WalletTransaction, error)
+	GetTransactionByReference(ctx context.Context, walletID string, referenceID string) (*WalletTransaction, error)
 	GetTransactions(ctx context.Context, walletID string) ([]WalletTransaction, error)
+	GetTransactionsByStatus(ctx context.Context, status TransactionStatus) ([]WalletTransaction, error)
+	// GetSettledTransactions gets the transactions of the wallet that
+	// succeeded from from, inclusive, to to, exclusive, in settlement order.
+	GetSettledTransactions(ctx context.Context, walletID string, from time.Time, to time.Time) ([]WalletTransaction, error)
 }
 
 type InMemoryRepository struct {
-	wallets      sync.Map
-	transactions sync.Map
+	wallets            sync.Map
+	walletOwners       sync.Map
+	transactions       sync.Map
+	transactionWallets sync.Map
 }
 
 func NewInMemoryRepository() Repository {
@@ -59,11 +295,65 @@ func (r *InMemoryRepository) CreateTransaction(ctx context.Context, t WalletTran
 	} else {
 		transactions = []WalletTransaction{}
 	}
-	transactions = append(transactions, t)
+	transactions = append(transactions[:len(transactions):len(transactions)], t)
 	r.transactions.Store(t.WalletID, transactions)
+	r.transactionWallets.Store(t.ID, t.WalletID)
 	return nil
 }
 
+func (r *InMemoryRepository) UpdateTransaction(ctx context.Context, t WalletTransaction) error {
+	v, ok := r.transactions.Load(t.WalletID)
+	if !ok {
+		return ErrTransactionNotFound
+	}
+
+	current := v.([]WalletTransaction)
+	transactions := make([]WalletTransaction, len(current))
+	copy(transactions, current)
+	for i := range transactions {
+		if transactions[i].ID == t.ID {
+			transactions[i] = t
+			r.transactions.Store(t.WalletID, transactions)
+			return nil
+		}
+	}
+
+	return ErrTransactionNotFound
+}
+
+func (r *InMemoryRepository) GetTransaction(ctx context.Context, id string) (*WalletTransaction, error) {
+	walletID, ok := r.transactionWallets.Load(id)
+	if !ok {
+		return nil, ErrTransactionNotFound
+	}
+
+	transactions, err := r.GetTransactions(ctx, walletID.(string))
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range transactions {
+		if t.ID == id {
+			return &t, nil
+		}
+	}
+
+	return nil, ErrTransactionNotFound
+}
+
+func (r *InMemoryRepository) GetTransactionByReference(ctx context.Context, walletID string, referenceID string) (*WalletTransaction, error) {
+	transactions, err := r.GetTransactions(ctx, walletID)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range transactions {
+		if t.ReferenceID == referenceID {
+			return &t, nil
+		}
+	}
+
+	return nil, ErrTransactionNotFound
+}
+
 func (r *InMemoryRepository) GetTransactions(ctx context.Context, walletID string) ([]WalletTransaction, error) {
 	v, ok := r.transactions.Load(walletID)
 	if !ok {
@@ -73,8 +363,43 @@ func (r *InMemoryRepository) GetTransactions(ctx context.Context, walletID strin
 	return v.([]WalletTransaction), nil
 }
 
+func (r *InMemoryRepository) GetTransactionsByStatus(ctx context.Context, status TransactionStatus) ([]WalletTransaction, error) {
+	transactions := []WalletTransaction{}
+	r.transactions.Range(func(key, value any) bool {
+		for _, t := range value.([]WalletTransaction) {
+			if t.Status == status {
+				transactions = append(transactions, t)
+			}
+		}
+		return true
+	})
+	sort.Slice(transactions, func(i, j int) bool {
+		return transactions[i].Date.Before(transactions[j].Date)
+	})
+	return transactions, nil
+}
+
+func (r *InMemoryRepository) GetSettledTransactions(ctx context.Context, walletID string, from time.Time, to time.Time) ([]WalletTransaction, error) {
+	all, err := r.GetTransactions(ctx, walletID)
+	if err != nil {
+		return nil, err
+	}
+
+	transactions := []WalletTransaction{}
+	for _, t := range all {
+		if t.Status == TransactionStatusSuccess && !t.SettledAt.Before(from) && t.SettledAt.Before(to) {
+			transactions = append(transactions, t)
+		}
+	}
+	sort.SliceStable(transactions, func(i, j int) bool {
+		return transactions[i].SettledAt.Before(transactions[j].SettledAt)
+	})
+	return transactions, nil
+}
+
 func (r *InMemoryRepository) CreateWallet(ctx context.Context, wallet Wallet) error {
 	r.wallets.Store(wallet.OwnerXID, &wallet)
+	r.walletOwners.Store(wallet.ID, wallet.OwnerXID)
 	return nil
 }
 
@@ -89,5 +414,27 @@ func (r *InMemoryRepository) GetWalletByXID(ctx context.Context, xid string) (*W
 		return nil, ErrWalletNotFound
 	}
 
-	return v.(*Wallet), nil
+	wallet := *v.(*Wallet)
+	return &wallet, nil
+}
+
+func (r *InMemoryRepository) GetWallets(ctx context.Context) ([]Wallet, error) {
+	wallets := []Wallet{}
+	r.wallets.Range(func(key, value any) bool {
+		wallets = append(wallets, *value.(*Wallet))
+		return true
+	})
+	sort.Slice(wallets, func(i, j int) bool {
+		return wallets[i].OwnerXID < wallets[j].OwnerXID
+	})
+	return wallets, nil
+}
+
+func (r *InMemoryRepository) GetWalletByID(ctx context.Context, id string) (*Wallet, error) {
+	xid, ok := r.walletOwners.Load(id)
+	if !ok {
+		return nil, ErrWalletNotFound
+	}
+
+	return r.GetWalletByXID(ctx, xid.(string))
 }
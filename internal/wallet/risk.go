@@ -0,0 +1,201 @@
+package wallet
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type RiskDecision string
+
+var (
+	RiskDecisionAllow  = RiskDecision("allow")
+	RiskDecisionReview = RiskDecision("review")
+	RiskDecisionDeny   = RiskDecision("deny")
+)
+
+// RiskCheck describes a movement about to be posted to the wallet of
+// OwnerXID, History being the transactions of the wallet so far.
+type RiskCheck struct {
+	OwnerXID string
+	ActorXID string
+	Type     TransactionType
+	Amount   int
+	At       time.Time
+	History  []WalletTransaction
+}
+
+type RiskAssessment struct {
+	Decision RiskDecision
+	// Reasons name the rules that did not allow the movement.
+	Reasons []string
+}
+
+// RiskAssessor screens deposits, withdrawals and both legs of transfers
+// before they are posted.
+type RiskAssessor interface {
+	Assess(ctx context.Context, check RiskCheck) RiskAssessment
+}
+
+// WithRiskAssessor screens deposits and withdrawals with a. Denied movements
+// are refused, movements to review are booked in review status, holding the
+// amount of withdrawals, until an operator approves or rejects them.
+func WithRiskAssessor(a RiskAssessor) Option {
+	return func(s *service) {
+		s.risk = a
+	}
+}
+
+// assessRisk runs the risk assessor on the movement param of type t about to
+// be posted to wal. The caller must hold s.mu.
+func (s *service) assessRisk(ctx context.Context, wal *Wallet, t TransactionType, param WalletTransactionParam) (*RiskAssessment, error) {
+	if s.risk == nil {
+		return &RiskAssessment{Decision: RiskDecisionAllow}, nil
+	}
+
+	history, err := s.repo.GetTransactions(ctx, wal.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed getting wallet transactions")
+	}
+	assessment := s.risk.Assess(ctx, RiskCheck{
+		OwnerXID: wal.OwnerXID,
+		ActorXID: param.ActorXID,
+		Type:     t,
+		Amount:   param.Amount,
+		At:       time.Now(),
+		History:  history,
+	})
+	if assessment.Decision == RiskDecisionDeny {
+		return nil, ErrTransactionDenied
+	}
+	return &assessment, nil
+}
+
+// assessTransfer runs the risk assessor on both legs of a transfer. Both legs
+// are booked at once, so a transfer cannot wait for review like a withdrawal
+// and one the assessor would review is refused. The caller must hold s.mu.
+func (s *service) assessTransfer(ctx context.Context, from *Wallet, to *Wallet, movement WalletTransactionParam) error {
+	legs := []struct {
+		wal *Wallet
+		t   TransactionType
+	}{
+		{from, TransactionTypeTransferOut},
+		{to, TransactionTypeTransferIn},
+	}
+	for _, leg := range legs {
+		assessment, err := s.assessRisk(ctx, leg.wal, leg.t, movement)
+		if err != nil {
+			return err
+		}
+		if assessment.Decision == RiskDecisionReview {
+			return ErrTransactionDenied
+		}
+	}
+	return nil
+}
+
+// review books trx for review instead of posting it.
+func review(trx *WalletTransaction, assessment RiskAssessment) {
+	trx.Status = TransactionStatusReview
+	trx.SettledAt = time.Time{}
+	trx.Reason = strings.Join(assessment.Reasons, "; ")
+}
+
+// GetReviewQueue lists the movements waiting for an operator decision,
+// oldest first. Fees are decided along with their movement and left out.
+func (s *service) GetReviewQueue(ctx context.Context) ([]WalletTransaction, error) {
+	transactions, err := s.repo.GetTransactionsByStatus(ctx, TransactionStatusReview)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed getting transactions")
+	}
+
+	queue := []WalletTransaction{}
+	for _, t := range transactions {
+		if t.Type != TransactionTypeFee {
+			queue = append(queue, t)
+		}
+	}
+	return queue, nil
+}
+
+// ApproveTransaction posts a movement held for review. A withdrawal or
+// closure payout to a bank account goes on to the disbursement provider and
+// stays pending until the payout completes.
+func (s *service) ApproveTransaction(ctx context.Context, param TransitionTransactionParam) (*WalletTransaction, error) {
+	trx, err := s.GetTransaction(ctx, param.TransactionID)
+	if err != nil {
+		return nil, err
+	}
+	if trx.Status == TransactionStatusReview && trx.Type.IsPayout() && trx.Destination != nil && s.payouts != nil {
+		trx, err = s.releaseForPayout(ctx, param)
+		if err != nil {
+			return nil, err
+		}
+		return s.submitPayout(ctx, trx, *trx.Destination)
+	}
+	return s.transitionFrom(ctx, param, TransactionStatusReview, TransactionStatusSuccess)
+}
+
+// checkApproval refuses to let a movement in review go ahead to status when
+// the wallet no longer takes it, such as after an operator froze the wallet
+// while the movement waited. Rejecting is always possible and releases the
+// hold. An expired freeze on wal is lifted.
+func checkApproval(wal *Wallet, trx *WalletTransaction, status TransactionStatus) error {
+	if trx.Status != TransactionStatusReview || status == TransactionStatusFailed {
+		return nil
+	}
+	if wal.Status == WalletStatusFrozen && wal.Freeze.IsExpired(time.Now()) {
+		thaw(wal)
+	}
+	return movementError(wal, trx.Type)
+}
+
+// RejectTransaction fails a movement held for review, releasing the hold of
+// a withdrawal.
+func (s *service) RejectTransaction(ctx context.Context, param TransitionTransactionParam) (*WalletTransaction, error) {
+	return s.transitionFrom(ctx, param, TransactionStatusReview, TransactionStatusFailed)
+}
+
+// releaseForPayout moves an approved withdrawal and its fee from review to
+// pending, the amount staying on hold.
+func (s *service) releaseForPayout(ctx context.Context, param TransitionTransactionParam) (*WalletTransaction, error) {
+	var changes []change
+	defer s.publish(ctx, &changes)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	trx, err := s.repo.GetTransaction(ctx, param.TransactionID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed getting transaction")
+	}
+	if trx.Status != TransactionStatusReview {
+		return nil, ErrInvalidTransition
+	}
+	wal, err := s.repo.GetWalletByID(ctx, trx.WalletID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed getting wallet")
+	}
+	err = checkApproval(wal, trx, TransactionStatusPending)
+	if err != nil {
+		return nil, err
+	}
+	fee, err := s.findFee(ctx, *trx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, t := range []*WalletTransaction{trx, fee} {
+		if t == nil {
+			continue
+		}
+		t.Status = TransactionStatusPending
+		err = s.repo.UpdateTransaction(ctx, *t)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed updating transaction")
+		}
+	}
+	changes = s.changed(changes, wal, *trx)
+	return trx, nil
+}
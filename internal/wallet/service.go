@@ -2,6 +2,8 @@ package wallet
 
 import (
 	"context"
+	"julo/internal/disbursement"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -21,17 +23,36 @@ type WalletTransactionParam struct {
 	OwnerXID    string
 	ReferenceID string
 	Amount      int
+	// Pending creates the transaction in pending status. A pending deposit
+	// does not move the balance until it is settled, a pending withdrawal
+	// holds the amount until it is settled or failed.
+	Pending bool
+	// Destination is the bank account a withdrawal is paid out to. It is
+	// required for withdrawals when the service has a disbursement provider.
+	Destination *disbursement.BankAccount
+	// Type books the movement under a more specific type than deposit or
+	// withdrawal, such as a loan disbursement. It must move money in the
+	// same direction as the operation.
+	Type TransactionType
+	// PocketID is the pocket of the wallet a to_pocket or from_pocket
+	// movement goes to or comes from.
+	PocketID string
+	// RelatedID links the movement to the transaction it was booked for,
+	// such as the one a cashback rewards.
+	RelatedID string
+	// CampaignID is the promotion campaign a cashback was granted by.
+	CampaignID string
 }
 
 func (p WalletTransactionParam) Validate() error {
-	var ve ValidationError
+	ve := NewValidationError()
 	if p.ActorXID == "" {
 		ve.AddError("actor_xid", ErrMissingRequiredParameter)
 	}
 	if p.OwnerXID == "" {
 		ve.AddError("owner_xid", ErrMissingRequiredParameter)
 	}
-	if p.ActorXID == "" {
+	if p.ReferenceID == "" {
 		ve.AddError("reference_id", ErrMissingRequiredParameter)
 	}
 	if p.Amount <= 0 {
@@ -48,7 +69,8 @@ type WalletTransactionResult struct {
 	DepositedAt time.Time
 	DepositedBy string
 	Amount      int
-	Status      string
+	Fee         int
+	Status      TransactionStatus
 	ReferenceID string
 }
 
@@ -60,23 +82,128 @@ type GetWalletTransactionsResult struct {
 	Transactions []WalletTransaction
 }
 
+// GetSettledTransactionsParam bounds the settlement time of transactions,
+// From inclusive and To exclusive. A zero From reaches back to the first
+// transaction.
+type GetSettledTransactionsParam struct {
+	WalletID string
+	From     time.Time
+	To       time.Time
+}
+
+type TransferWalletParam struct {
+	ActorXID    string
+	FromXID     string
+	ToXID       string
+	ReferenceID string
+	Amount      int
+}
+
+func (p TransferWalletParam) Validate() error {
+	ve := NewValidationError()
+	if p.ActorXID == "" {
+		ve.AddError("actor_xid", ErrMissingRequiredParameter)
+	}
+	if p.FromXID == "" {
+		ve.AddError("from_xid", ErrMissingRequiredParameter)
+	}
+	if p.ToXID == "" {
+		ve.AddError("to_xid", ErrMissingRequiredParameter)
+	} else if p.ToXID == p.FromXID {
+		ve.AddError("to_xid", ErrSameWalletTransfer)
+	}
+	if p.ReferenceID == "" {
+		ve.AddError("reference_id", ErrMissingRequiredParameter)
+	}
+	if p.Amount <= 0 {
+		ve.AddError("amount", ErrInvalidDepositAmount)
+	}
+	if len(ve.GetErrors()) > 0 {
+		return ve
+	}
+	return nil
+}
+
+type TransferWalletResult struct {
+	Debit  WalletTransaction
+	Credit WalletTransaction
+	// Fee is the fee charged to the sender, nil when the transfer was free.
+	Fee *WalletTransaction
+}
+
+type SetCreditLimitParam struct {
+	OwnerXID string
+	Limit    int
+}
+
+type TransitionTransactionParam struct {
+	TransactionID string
+	Reason        string
+}
+
 type Service interface {
 	GetWalletByXID(ctx context.Context, xid string) (*Wallet, error)
+	GetWallets(ctx context.Context) ([]Wallet, error)
 	EnableWallet(ctx context.Context, param EnableWalletParam) (*Wallet, error)
 	DisableWallet(ctx context.Context, param DisableWalletParam) (*Wallet, error)
 	DepositWallet(ctx context.Context, param WalletTransactionParam) (*WalletTransactionResult, error)
 	WithdrawWallet(ctx context.Context, param WalletTransactionParam) (*WalletTransactionResult, error)
+	// CalculateFee prices a movement of type t of amount, zero when it is
+	// free.
+	CalculateFee(ctx context.Context, t TransactionType, amount int) int
 	GetWalletTransactions(ctx context.Context, param GetWalletTransactionsParam) (*GetWalletTransactionsResult, error)
+	GetSettledTransactions(ctx context.Context, param GetSettledTransactionsParam) ([]WalletTransaction, error)
+	GetTransaction(ctx context.Context, id string) (*WalletTransaction, error)
+	SettleTransaction(ctx context.Context, param TransitionTransactionParam) (*WalletTransaction, error)
+	FailTransaction(ctx context.Context, param TransitionTransactionParam) (*WalletTransaction, error)
+	ExpireTransaction(ctx context.Context, param TransitionTransactionParam) (*WalletTransaction, error)
+	TransferWallet(ctx context.Context, param TransferWalletParam) (*TransferWalletResult, error)
+	SetCreditLimit(ctx context.Context, param SetCreditLimitParam) (*Wallet, error)
+	FreezeWallet(ctx context.Context, param FreezeWalletParam) (*Wallet, error)
+	UnfreezeWallet(ctx context.Context, param UnfreezeWalletParam) (*Wallet, error)
+	CloseWallet(ctx context.Context, param CloseWalletParam) (*Wallet, error)
+	OnTransactionCompleted(fn TransactionListener)
+	OnTransactionChanged(fn TransactionListener)
+	GetReviewQueue(ctx context.Context) ([]WalletTransaction, error)
+	ApproveTransaction(ctx context.Context, param TransitionTransactionParam) (*WalletTransaction, error)
+	RejectTransaction(ctx context.Context, param TransitionTransactionParam) (*WalletTransaction, error)
+	RedactDestinations(ctx context.Context, param RedactDestinationsParam) (int, error)
+	ReconcilePayouts(ctx context.Context, param ReconcilePayoutsParam) ([]WalletTransaction, error)
 }
 
 type service struct {
-	repo Repository
+	repo     Repository
+	payouts  disbursement.Provider
+	fees     FeeCalculator
+	houseXID string
+	risk     RiskAssessor
+	limits   LimitProvider
+	// mu serializes balance changes so a read-modify-write of a wallet
+	// cannot interleave with another one.
+	mu        sync.Mutex
+	listeners []TransactionListener
+	watchers  []TransactionListener
 }
 
-func NewService(r Repository) Service {
-	return &service{
+type Option func(*service)
+
+// WithDisbursementProvider makes withdrawals pay out to a bank account
+// through p. Such withdrawals stay pending, holding the amount, until the
+// provider reports the payout outcome.
+func WithDisbursementProvider(p disbursement.Provider) Option {
+	return func(s *service) {
+		s.payouts = p
+	}
+}
+
+func NewService(r Repository, opts ...Option) Service {
+	s := &service{
 		repo: r,
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 func (s *service) DepositWallet(ctx context.Context, param WalletTransactionParam) (*WalletTransactionResult, error) {
@@ -85,45 +212,58 @@ func (s *service) DepositWallet(ctx context.Context, param WalletTransactionPara
 		return nil, err
 	}
 
-	wal, err := s.repo.GetWalletByXID(ctx, param.OwnerXID)
-	if err != nil && err != ErrWalletNotFound {
-		return nil, errors.Wrap(err, "failed getting wallet")
+	t, err := movementType(param, TransactionTypeDeposit)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []change
+	defer s.publish(ctx, &changes)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	wal, err := s.getActiveWallet(ctx, param.OwnerXID)
+	if err != nil {
+		return nil, err
 	}
 
-	if wal.Status == WalletStatusDisabled {
-		return nil, ErrWalletDisabled
+	replay, err := s.findReplay(ctx, wal, t, param)
+	if err != nil {
+		return nil, err
+	}
+	if replay != nil {
+		return newTransactionResult(*replay), nil
 	}
 
-	trx := WalletTransaction{
-		ID:          uuid.NewString(),
-		ActorXID:    param.ActorXID,
-		WalletID:    wal.ID,
-		ReferenceID: param.ReferenceID,
-		Type:        "deposit",
-		Date:        time.Now(),
-		Amount:      param.Amount,
-		Status:      "success",
+	err = s.checkLimits(ctx, wal, t, param.Amount)
+	if err != nil {
+		return nil, err
 	}
 
-	err = s.repo.CreateTransaction(ctx, trx)
+	assessment, err := s.assessRisk(ctx, wal, t, param)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed updating wallet")
+		return nil, err
 	}
 
-	wal.Balance += trx.Amount
-	err = s.repo.UpdateWallet(ctx, *wal)
+	trx := newTransaction(wal, t, param)
+	if assessment.Decision == RiskDecisionReview {
+		review(&trx, *assessment)
+	}
+	err = s.repo.CreateTransaction(ctx, trx)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed updating wallet")
 	}
 
-	return &WalletTransactionResult{
-		ID:          trx.ID,
-		DepositedAt: trx.Date,
-		DepositedBy: trx.ActorXID,
-		Amount:      trx.Amount,
-		Status:      trx.Status,
-		ReferenceID: trx.ReferenceID,
-	}, nil
+	if trx.Status == TransactionStatusSuccess {
+		wal.Balance += trx.Amount
+		err = s.repo.UpdateWallet(ctx, *wal)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed updating wallet")
+		}
+	}
+
+	changes = s.changed(changes, wal, trx)
+	return newTransactionResult(trx), nil
 }
 
 func (s *service) WithdrawWallet(ctx context.Context, param WalletTransactionParam) (*WalletTransactionResult, error) {
@@ -132,55 +272,292 @@ func (s *service) WithdrawWallet(ctx context.Context, param WalletTransactionPar
 		return nil, err
 	}
 
-	wal, err := s.repo.GetWalletByXID(ctx, param.OwnerXID)
-	if err != nil && err != ErrWalletNotFound {
-		return nil, errors.Wrap(err, "failed getting wallet")
+	param.Type, err = movementType(param, TransactionTypeWithdrawal)
+	if err != nil {
+		return nil, err
 	}
 
-	if wal.Status == WalletStatusDisabled {
-		return nil, ErrWalletDisabled
+	// only payouts leave the system, other debits stay internal
+	payout := s.payouts != nil && param.Type.IsPayout()
+	if payout {
+		if param.Destination == nil {
+			ve := NewValidationError()
+			ve.AddError("bank_account", ErrMissingRequiredParameter)
+			return nil, ve
+		}
+		if err := param.Destination.Validate(); err != nil {
+			ve := NewValidationError()
+			ve.AddError("bank_account", err)
+			return nil, ve
+		}
+		param.Pending = true
 	}
 
-	if wal.Balance < param.Amount {
-		return nil, ErrInsufficientBalance
+	trx, fee, replayed, err := s.withdraw(ctx, param)
+	if err != nil {
+		return nil, err
 	}
 
-	trx := WalletTransaction{
-		ID:          uuid.NewString(),
+	// a withdrawal in review is paid out once approved
+	if payout && !replayed && trx.Status == TransactionStatusPending {
+		trx, err = s.submitPayout(ctx, trx, *param.Destination)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	result := newTransactionResult(*trx)
+	result.Fee = fee
+	return result, nil
+}
+
+// withdraw records the withdrawal along with its fee and reports whether it
+// is a replay of a withdrawal made earlier with the same reference.
+func (s *service) withdraw(ctx context.Context, param WalletTransactionParam) (*WalletTransaction, int, bool, error) {
+	var changes []change
+	defer s.publish(ctx, &changes)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	wal, err := s.getWalletFor(ctx, param.OwnerXID, param.Type)
+	if err != nil {
+		return nil, 0, false, err
+	}
+
+	replay, err := s.findReplay(ctx, wal, param.Type, param)
+	if err != nil {
+		return nil, 0, false, err
+	}
+	if replay != nil {
+		fee, err := s.findFee(ctx, *replay)
+		if err != nil {
+			return nil, 0, false, err
+		}
+		if fee == nil {
+			return replay, 0, true, nil
+		}
+		return replay, fee.Amount, true, nil
+	}
+
+	err = s.checkLimits(ctx, wal, param.Type, param.Amount)
+	if err != nil {
+		return nil, 0, false, err
+	}
+
+	fee := s.calculateFee(param.Type, param.Amount)
+	// interest is charged even when it takes the wallet past its credit
+	// limit, while only cash can be set aside in a pocket
+	switch {
+	case param.Type == TransactionTypeCreditInterest:
+	case param.Type == TransactionTypeToPocket && wal.AvailableBalance() < param.Amount+fee:
+		return nil, 0, false, ErrInsufficientBalance
+	case wal.SpendableBalance() < param.Amount+fee:
+		return nil, 0, false, ErrInsufficientBalance
+	}
+
+	assessment, err := s.assessRisk(ctx, wal, param.Type, param)
+	if err != nil {
+		return nil, 0, false, err
+	}
+
+	trx := newTransaction(wal, param.Type, param)
+	if assessment.Decision == RiskDecisionReview {
+		review(&trx, *assessment)
+	}
+	err = s.repo.CreateTransaction(ctx, trx)
+	if err != nil {
+		return nil, 0, false, errors.Wrap(err, "failed updating wallet")
+	}
+
+	var feeTrx WalletTransaction
+	if fee > 0 {
+		feeTrx = newFeeTransaction(trx, fee)
+		err = s.repo.CreateTransaction(ctx, feeTrx)
+		if err != nil {
+			return nil, 0, false, errors.Wrap(err, "failed updating wallet")
+		}
+	}
+
+	if trx.Status == TransactionStatusSuccess {
+		wal.Balance -= trx.Amount + fee
+	} else {
+		wal.Held += trx.Amount + fee
+	}
+	err = s.repo.UpdateWallet(ctx, *wal)
+	if err != nil {
+		return nil, 0, false, errors.Wrap(err, "failed updating wallet")
+	}
+
+	if fee > 0 && trx.Status == TransactionStatusSuccess {
+		err = s.collectFee(ctx, feeTrx)
+		if err != nil {
+			return nil, 0, false, err
+		}
+	}
+
+	changes = s.changed(changes, wal, trx)
+	return &trx, fee, false, nil
+}
+
+// submitPayout hands a pending withdrawal over to the disbursement provider.
+// The provider is called without holding the lock since it may call back
+// into the service right away.
+func (s *service) submitPayout(ctx context.Context, trx *WalletTransaction, destination disbursement.BankAccount) (*WalletTransaction, error) {
+	payout, err := s.payouts.Submit(ctx, disbursement.PayoutRequest{
+		ReferenceID: trx.ID,
+		Destination: destination,
+		Amount:      trx.Amount,
+	})
+	if err != nil {
+		_, ferr := s.FailTransaction(ctx, TransitionTransactionParam{
+			TransactionID: trx.ID,
+			Reason:        err.Error(),
+		})
+		if ferr != nil {
+			return nil, errors.Wrap(ferr, "failed releasing withdrawal hold")
+		}
+		return nil, errors.Wrap(err, "failed submitting payout")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, err := s.repo.GetTransaction(ctx, trx.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed getting transaction")
+	}
+	current.ExternalID = payout.ID
+	err = s.repo.UpdateTransaction(ctx, *current)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed updating transaction")
+	}
+
+	return current, nil
+}
+
+// TransferWallet moves money between two wallets. Both legs are booked
+// together, so the transfer either fully happens or does not at all.
+func (s *service) TransferWallet(ctx context.Context, param TransferWalletParam) (*TransferWalletResult, error) {
+	err := param.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []change
+	defer s.publish(ctx, &changes)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	from, err := s.getActiveWallet(ctx, param.FromXID)
+	if err != nil {
+		return nil, err
+	}
+	to, err := s.getActiveWallet(ctx, param.ToXID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed getting destination wallet")
+	}
+
+	movement := WalletTransactionParam{
 		ActorXID:    param.ActorXID,
-		WalletID:    wal.ID,
 		ReferenceID: param.ReferenceID,
-		Type:        "withdrawal",
-		Date:        time.Now(),
 		Amount:      param.Amount,
-		Status:      "success",
+	}
+	replay, err := s.findReplay(ctx, from, TransactionTypeTransferOut, movement)
+	if err != nil {
+		return nil, err
+	}
+	if replay != nil {
+		credit, err := s.repo.GetTransaction(ctx, replay.RelatedID)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed getting transaction")
+		}
+		fee, err := s.findFee(ctx, *replay)
+		if err != nil {
+			return nil, err
+		}
+		return &TransferWalletResult{
+			Debit:  *replay,
+			Credit: *credit,
+			Fee:    fee,
+		}, nil
 	}
 
-	err = s.repo.CreateTransaction(ctx, trx)
+	fee := s.calculateFee(TransactionTypeTransferOut, param.Amount)
+	if from.SpendableBalance() < param.Amount+fee {
+		return nil, ErrInsufficientBalance
+	}
+	err = s.checkLimits(ctx, from, TransactionTypeTransferOut, param.Amount)
+	if err != nil {
+		return nil, err
+	}
+	err = s.checkLimits(ctx, to, TransactionTypeTransferIn, param.Amount)
+	if err != nil {
+		return nil, err
+	}
+	err = s.assessTransfer(ctx, from, to, movement)
+	if err != nil {
+		return nil, err
+	}
+
+	debit := newTransaction(from, TransactionTypeTransferOut, movement)
+	credit := newTransaction(to, TransactionTypeTransferIn, movement)
+	debit.RelatedID = credit.ID
+	credit.RelatedID = debit.ID
+
+	err = s.repo.CreateTransaction(ctx, debit)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed updating wallet")
+	}
+	err = s.repo.CreateTransaction(ctx, credit)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed updating wallet")
 	}
 
-	wal.Balance -= trx.Amount
-	err = s.repo.UpdateWallet(ctx, *wal)
+	result := &TransferWalletResult{
+		Debit:  debit,
+		Credit: credit,
+	}
+	if fee > 0 {
+		feeTrx := newFeeTransaction(debit, fee)
+		err = s.repo.CreateTransaction(ctx, feeTrx)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed updating wallet")
+		}
+		result.Fee = &feeTrx
+	}
+
+	from.Balance -= param.Amount + fee
+	to.Balance += param.Amount
+	err = s.repo.UpdateWallet(ctx, *from)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed updating wallet")
+	}
+	err = s.repo.UpdateWallet(ctx, *to)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed updating wallet")
 	}
 
-	return &WalletTransactionResult{
-		ID:          trx.ID,
-		DepositedAt: trx.Date,
-		DepositedBy: trx.ActorXID,
-		Amount:      trx.Amount,
-		Status:      trx.Status,
-		ReferenceID: trx.ReferenceID,
-	}, nil
+	if result.Fee != nil {
+		err = s.collectFee(ctx, *result.Fee)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	changes = s.changed(changes, from, debit)
+	changes = s.changed(changes, to, credit)
+	return result, nil
 }
 
+// EnableWallet creates the wallet of the owner on first use. Owners can only
+// enable a disabled wallet, not one an operator froze or closed.
 func (s *service) EnableWallet(ctx context.Context, param EnableWalletParam) (*Wallet, error) {
-	wal, err := s.repo.GetWalletByXID(ctx, param.OwnerXID)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	wal, err := s.getWallet(ctx, param.OwnerXID)
 	if err != nil && err != ErrWalletNotFound {
-		return nil, errors.Wrap(err, "failed getting wallet")
+		return nil, err
 	}
 
 	if wal == nil {
@@ -198,6 +575,9 @@ func (s *service) EnableWallet(ctx context.Context, param EnableWalletParam) (*W
 	if wal.Status == WalletStatusEnabled {
 		return nil, ErrWalletEnabled
 	}
+	if wal.Status != WalletStatusDisabled {
+		return nil, StatusError(wal.Status)
+	}
 	wal.Status = WalletStatusEnabled
 	wal.EnabledAt = time.Now()
 
@@ -210,6 +590,37 @@ func (s *service) EnableWallet(ctx context.Context, param EnableWalletParam) (*W
 }
 
 func (s *service) DisableWallet(ctx context.Context, param DisableWalletParam) (*Wallet, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	wal, err := s.getWallet(ctx, param.OwnerXID)
+	if err != nil {
+		return nil, err
+	}
+
+	if wal.Status != WalletStatusEnabled {
+		return nil, StatusError(wal.Status)
+	}
+	wal.Status = WalletStatusDisabled
+
+	err = s.repo.UpdateWallet(ctx, *wal)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed updating wallet")
+	}
+
+	return wal, nil
+}
+
+// SetCreditLimit grants the wallet a credit line of param.Limit, zero takes
+// it away. A limit cannot be lowered below the credit already drawn.
+func (s *service) SetCreditLimit(ctx context.Context, param SetCreditLimitParam) (*Wallet, error) {
+	if param.Limit < 0 {
+		return nil, ErrInvalidCreditLimit
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	wal, err := s.repo.GetWalletByXID(ctx, param.OwnerXID)
 	if err != nil && err == ErrWalletNotFound {
 		return nil, ErrWalletNotFound
@@ -217,10 +628,10 @@ func (s *service) DisableWallet(ctx context.Context, param DisableWalletParam) (
 		return nil, errors.Wrap(err, "failed getting wallet")
 	}
 
-	if wal.Status == WalletStatusDisabled {
-		return nil, ErrWalletDisabled
+	if wal.AvailableBalance()+param.Limit < 0 {
+		return nil, ErrInvalidCreditLimit
 	}
-	wal.Status = WalletStatusDisabled
+	wal.CreditLimit = param.Limit
 
 	err = s.repo.UpdateWallet(ctx, *wal)
 	if err != nil {
@@ -231,7 +642,15 @@ func (s *service) DisableWallet(ctx context.Context, param DisableWalletParam) (
 }
 
 func (s *service) GetWalletByXID(ctx context.Context, xid string) (*Wallet, error) {
-	return s.repo.GetWalletByXID(ctx, xid)
+	return s.getWallet(ctx, xid)
+}
+
+func (s *service) GetWallets(ctx context.Context) ([]Wallet, error) {
+	wallets, err := s.repo.GetWallets(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed getting wallets")
+	}
+	return wallets, nil
 }
 
 func (s *service) GetWalletTransactions(ctx context.Context, param GetWalletTransactionsParam) (*GetWalletTransactionsResult, error) {
@@ -244,3 +663,231 @@ func (s *service) GetWalletTransactions(ctx context.Context, param GetWalletTran
 		Transactions: transactions,
 	}, nil
 }
+
+// GetSettledTransactions gets the transactions of the wallet that succeeded
+// in the period, in the order they settled.
+func (s *service) GetSettledTransactions(ctx context.Context, param GetSettledTransactionsParam) ([]WalletTransaction, error) {
+	transactions, err := s.repo.GetSettledTransactions(ctx, param.WalletID, param.From, param.To)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed getting settled transactions")
+	}
+	return transactions, nil
+}
+
+func (s *service) GetTransaction(ctx context.Context, id string) (*WalletTransaction, error) {
+	return s.repo.GetTransaction(ctx, id)
+}
+
+// SettleTransaction completes a pending transaction. A deposit is credited to
+// the balance, a withdrawal is debited and its hold released.
+func (s *service) SettleTransaction(ctx context.Context, param TransitionTransactionParam) (*WalletTransaction, error) {
+	return s.transitionFrom(ctx, param, TransactionStatusPending, TransactionStatusSuccess)
+}
+
+// FailTransaction marks a pending transaction as failed, releasing the hold
+// of a withdrawal without moving the balance.
+func (s *service) FailTransaction(ctx context.Context, param TransitionTransactionParam) (*WalletTransaction, error) {
+	return s.transitionFrom(ctx, param, TransactionStatusPending, TransactionStatusFailed)
+}
+
+// ExpireTransaction marks a pending transaction that was never completed as
+// expired. Like a failure it does not move the balance.
+func (s *service) ExpireTransaction(ctx context.Context, param TransitionTransactionParam) (*WalletTransaction, error) {
+	return s.transitionFrom(ctx, param, TransactionStatusPending, TransactionStatusExpired)
+}
+
+// transitionFrom moves a transaction in status from to status, so a payout
+// callback cannot settle a movement still waiting for review.
+func (s *service) transitionFrom(ctx context.Context, param TransitionTransactionParam, from TransactionStatus, status TransactionStatus) (*WalletTransaction, error) {
+	if param.TransactionID == "" {
+		ve := NewValidationError()
+		ve.AddError("transaction_id", ErrMissingRequiredParameter)
+		return nil, ve
+	}
+
+	var changes []change
+	defer s.publish(ctx, &changes)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	trx, err := s.repo.GetTransaction(ctx, param.TransactionID)
+	if err != nil && err == ErrTransactionNotFound {
+		return nil, ErrTransactionNotFound
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed getting transaction")
+	}
+
+	// callbacks may be delivered more than once, repeating the transition
+	// the transaction already went through is not an error.
+	if trx.Status == status {
+		return trx, nil
+	}
+	// fees only move together with the movement they were charged for
+	if trx.Status != from || !trx.Status.CanTransitionTo(status) || trx.Type == TransactionTypeFee {
+		return nil, ErrInvalidTransition
+	}
+
+	wal, err := s.repo.GetWalletByID(ctx, trx.WalletID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed getting wallet")
+	}
+	err = checkApproval(wal, trx, status)
+	if err != nil {
+		return nil, err
+	}
+
+	// the fee of a movement follows the movement itself
+	fee, err := s.findFee(ctx, *trx)
+	if err != nil {
+		return nil, err
+	}
+
+	applyTransition(wal, trx, status, param.Reason)
+	err = s.repo.UpdateTransaction(ctx, *trx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed updating transaction")
+	}
+	if fee != nil {
+		applyTransition(wal, fee, status, param.Reason)
+		err = s.repo.UpdateTransaction(ctx, *fee)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed updating transaction")
+		}
+	}
+
+	err = s.repo.UpdateWallet(ctx, *wal)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed updating wallet")
+	}
+
+	if fee != nil && status == TransactionStatusSuccess {
+		err = s.collectFee(ctx, *fee)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	changes = s.changed(changes, wal, *trx)
+	return trx, nil
+}
+
+// applyTransition moves trx to status and updates the balance of wal with its
+// effect. Pending debits release their hold whatever the outcome.
+func applyTransition(wal *Wallet, trx *WalletTransaction, status TransactionStatus, reason string) {
+	if trx.Type.IsCredit() {
+		if status == TransactionStatusSuccess {
+			wal.Balance += trx.Amount
+		}
+	} else {
+		wal.Held -= trx.Amount
+		if status == TransactionStatusSuccess {
+			wal.Balance -= trx.Amount
+		}
+	}
+
+	trx.Status = status
+	trx.SettledAt = time.Now()
+	trx.Reason = reason
+}
+
+func (s *service) getActiveWallet(ctx context.Context, xid string) (*Wallet, error) {
+	wal, err := s.getWallet(ctx, xid)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := StatusError(wal.Status); err != nil {
+		return nil, err
+	}
+
+	return wal, nil
+}
+
+// getWalletFor gets the wallet a movement of type t is booked on. Interest
+// owed on credit is charged whether the owner disabled the wallet or an
+// operator froze it, other movements need an enabled wallet.
+func (s *service) getWalletFor(ctx context.Context, xid string, t TransactionType) (*Wallet, error) {
+	wal, err := s.getWallet(ctx, xid)
+	if err != nil {
+		return nil, err
+	}
+	if err := movementError(wal, t); err != nil {
+		return nil, err
+	}
+	return wal, nil
+}
+
+// movementError is the error a movement of type t on wal fails with, nil when
+// the wallet takes it.
+func movementError(wal *Wallet, t TransactionType) error {
+	if t == TransactionTypeCreditInterest && wal.Status != WalletStatusClosed {
+		return nil
+	}
+	return StatusError(wal.Status)
+}
+
+// findReplay looks up the transaction previously made on wal with the same
+// reference. The reference is the idempotency key of a movement, so a retried
+// request gets the original transaction back instead of moving money twice.
+func (s *service) findReplay(ctx context.Context, wal *Wallet, t TransactionType, param WalletTransactionParam) (*WalletTransaction, error) {
+	trx, err := s.repo.GetTransactionByReference(ctx, wal.ID, param.ReferenceID)
+	if err != nil && err == ErrTransactionNotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed getting transaction")
+	}
+
+	if trx.Type != t || trx.Amount != param.Amount {
+		return nil, ErrDuplicateReference
+	}
+
+	return trx, nil
+}
+
+// movementType is the type to book param under, def when it does not ask
+// for one.
+func movementType(param WalletTransactionParam, def TransactionType) (TransactionType, error) {
+	if param.Type == "" {
+		return def, nil
+	}
+	if param.Type.IsCredit() != def.IsCredit() {
+		ve := NewValidationError()
+		ve.AddError("type", ErrInvalidTransactionType)
+		return "", ve
+	}
+	return param.Type, nil
+}
+
+func newTransaction(wal *Wallet, t TransactionType, param WalletTransactionParam) WalletTransaction {
+	trx := WalletTransaction{
+		ID:          uuid.NewString(),
+		ActorXID:    param.ActorXID,
+		WalletID:    wal.ID,
+		ReferenceID: param.ReferenceID,
+		Type:        t,
+		Date:        time.Now(),
+		Amount:      param.Amount,
+		Status:      TransactionStatusSuccess,
+		PocketID:    param.PocketID,
+		RelatedID:   param.RelatedID,
+		CampaignID:  param.CampaignID,
+		Destination: param.Destination,
+	}
+	if param.Pending {
+		trx.Status = TransactionStatusPending
+	} else {
+		trx.SettledAt = trx.Date
+	}
+	return trx
+}
+
+func newTransactionResult(trx WalletTransaction) *WalletTransactionResult {
+	return &WalletTransactionResult{
+		ID:          trx.ID,
+		DepositedAt: trx.Date,
+		DepositedBy: trx.ActorXID,
+		Amount:      trx.Amount,
+		Status:      trx.Status,
+		ReferenceID: trx.ReferenceID,
+	}
+}
@@ -2,8 +2,10 @@ package wallet_test
 
 import (
 	"context"
+	"julo/internal/disbursement"
 	"julo/internal/wallet"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -91,3 +93,1104 @@ func TestDisableWallet(t *testing.T) {
 		})
 	})
 }
+
+func TestPendingTransaction(t *testing.T) {
+	ctx := context.Background()
+	service := wallet.NewService(wallet.NewInMemoryRepository())
+
+	xid := uuid.NewString()
+	_, err := service.EnableWallet(ctx, wallet.EnableWalletParam{
+		OwnerXID: xid,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("pending deposit, should not move balance until settled", func(t *testing.T) {
+		result, err := service.DepositWallet(ctx, wallet.WalletTransactionParam{
+			ActorXID:    xid,
+			OwnerXID:    xid,
+			ReferenceID: uuid.NewString(),
+			Amount:      100000,
+			Pending:     true,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Status != wallet.TransactionStatusPending {
+			t.Fatalf("expecting status %s, got %s", wallet.TransactionStatusPending, result.Status)
+		}
+
+		wal, err := service.GetWalletByXID(ctx, xid)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if wal.Balance != 0 {
+			t.Fatalf("expecting balance %d, got %d", 0, wal.Balance)
+		}
+
+		trx, err := service.SettleTransaction(ctx, wallet.TransitionTransactionParam{
+			TransactionID: result.ID,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if trx.Status != wallet.TransactionStatusSuccess {
+			t.Fatalf("expecting status %s, got %s", wallet.TransactionStatusSuccess, trx.Status)
+		}
+
+		wal, err = service.GetWalletByXID(ctx, xid)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if wal.Balance != 100000 {
+			t.Fatalf("expecting balance %d, got %d", 100000, wal.Balance)
+		}
+
+		t.Run("settle already settled transaction, should be a no-op", func(t *testing.T) {
+			_, err := service.SettleTransaction(ctx, wallet.TransitionTransactionParam{
+				TransactionID: result.ID,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			wal, err := service.GetWalletByXID(ctx, xid)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if wal.Balance != 100000 {
+				t.Fatalf("expecting balance %d, got %d", 100000, wal.Balance)
+			}
+		})
+
+		t.Run("fail settled transaction, should fail", func(t *testing.T) {
+			_, err := service.FailTransaction(ctx, wallet.TransitionTransactionParam{
+				TransactionID: result.ID,
+			})
+			if err != wallet.ErrInvalidTransition {
+				t.Fatalf("expecting error %s, got %s", wallet.ErrInvalidTransition, err)
+			}
+		})
+	})
+
+	t.Run("pending withdrawal, should hold amount until failed", func(t *testing.T) {
+		result, err := service.WithdrawWallet(ctx, wallet.WalletTransactionParam{
+			ActorXID:    xid,
+			OwnerXID:    xid,
+			ReferenceID: uuid.NewString(),
+			Amount:      60000,
+			Pending:     true,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		wal, err := service.GetWalletByXID(ctx, xid)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if wal.Balance != 100000 || wal.AvailableBalance() != 40000 {
+			t.Fatalf("expecting balance %d with %d available, got %d with %d available", 100000, 40000, wal.Balance, wal.AvailableBalance())
+		}
+
+		_, err = service.WithdrawWallet(ctx, wallet.WalletTransactionParam{
+			ActorXID:    xid,
+			OwnerXID:    xid,
+			ReferenceID: uuid.NewString(),
+			Amount:      50000,
+		})
+		if err != wallet.ErrInsufficientBalance {
+			t.Fatalf("expecting error %s, got %s", wallet.ErrInsufficientBalance, err)
+		}
+
+		_, err = service.FailTransaction(ctx, wallet.TransitionTransactionParam{
+			TransactionID: result.ID,
+			Reason:        "rejected by bank",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		wal, err = service.GetWalletByXID(ctx, xid)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if wal.Balance != 100000 || wal.AvailableBalance() != 100000 {
+			t.Fatalf("expecting balance %d with %d available, got %d with %d available", 100000, 100000, wal.Balance, wal.AvailableBalance())
+		}
+	})
+
+	t.Run("settle inexist transaction, should fail", func(t *testing.T) {
+		_, err := service.SettleTransaction(ctx, wallet.TransitionTransactionParam{
+			TransactionID: uuid.NewString(),
+		})
+		if err != wallet.ErrTransactionNotFound {
+			t.Fatalf("expecting error %s, got %s", wallet.ErrTransactionNotFound, err)
+		}
+	})
+}
+
+func TestTransferWallet(t *testing.T) {
+	ctx := context.Background()
+	service := wallet.NewService(wallet.NewInMemoryRepository())
+
+	from, to := uuid.NewString(), uuid.NewString()
+	for _, xid := range []string{from, to} {
+		_, err := service.EnableWallet(ctx, wallet.EnableWalletParam{
+			OwnerXID: xid,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err := service.DepositWallet(ctx, wallet.WalletTransactionParam{
+		ActorXID:    from,
+		OwnerXID:    from,
+		ReferenceID: uuid.NewString(),
+		Amount:      50000,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	balance := func(t *testing.T, xid string) int {
+		wal, err := service.GetWalletByXID(ctx, xid)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return wal.Balance
+	}
+
+	t.Run("transfer, should move balance", func(t *testing.T) {
+		param := wallet.TransferWalletParam{
+			ActorXID:    from,
+			FromXID:     from,
+			ToXID:       to,
+			ReferenceID: uuid.NewString(),
+			Amount:      20000,
+		}
+		result, err := service.TransferWallet(ctx, param)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Debit.RelatedID != result.Credit.ID {
+			t.Fatal("expecting debit linked to credit")
+		}
+		if balance(t, from) != 30000 || balance(t, to) != 20000 {
+			t.Fatalf("expecting balances %d and %d, got %d and %d", 30000, 20000, balance(t, from), balance(t, to))
+		}
+
+		t.Run("retry transfer with same reference, should not move balance again", func(t *testing.T) {
+			retry, err := service.TransferWallet(ctx, param)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if retry.Debit.ID != result.Debit.ID {
+				t.Fatalf("expecting transaction %s, got %s", result.Debit.ID, retry.Debit.ID)
+			}
+			if balance(t, from) != 30000 {
+				t.Fatalf("expecting balance %d, got %d", 30000, balance(t, from))
+			}
+		})
+	})
+
+	t.Run("transfer more than balance, should fail", func(t *testing.T) {
+		_, err := service.TransferWallet(ctx, wallet.TransferWalletParam{
+			ActorXID:    from,
+			FromXID:     from,
+			ToXID:       to,
+			ReferenceID: uuid.NewString(),
+			Amount:      40000,
+		})
+		if err != wallet.ErrInsufficientBalance {
+			t.Fatalf("expecting error %s, got %s", wallet.ErrInsufficientBalance, err)
+		}
+	})
+}
+
+type feeFunc func(kind string, amount int) int
+
+func (f feeFunc) Calculate(kind string, amount int) int {
+	return f(kind, amount)
+}
+
+func TestWithdrawalFee(t *testing.T) {
+	ctx := context.Background()
+	fees := feeFunc(func(kind string, amount int) int {
+		if kind == string(wallet.TransactionTypeWithdrawal) {
+			return 2500
+		}
+		return 0
+	})
+	house := uuid.NewString()
+	service := wallet.NewService(wallet.NewInMemoryRepository(), wallet.WithFees(fees, house))
+
+	xid := uuid.NewString()
+	_, err := service.EnableWallet(ctx, wallet.EnableWalletParam{
+		OwnerXID: xid,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = service.DepositWallet(ctx, wallet.WalletTransactionParam{
+		ActorXID:    xid,
+		OwnerXID:    xid,
+		ReferenceID: uuid.NewString(),
+		Amount:      50000,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	balance := func(t *testing.T, xid string) int {
+		wal, err := service.GetWalletByXID(ctx, xid)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return wal.Balance
+	}
+
+	t.Run("withdraw, should charge fee to house wallet", func(t *testing.T) {
+		result, err := service.WithdrawWallet(ctx, wallet.WalletTransactionParam{
+			ActorXID:    xid,
+			OwnerXID:    xid,
+			ReferenceID: uuid.NewString(),
+			Amount:      20000,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Fee != 2500 {
+			t.Fatalf("expecting fee %d, got %d", 2500, result.Fee)
+		}
+		if b := balance(t, xid); b != 27500 {
+			t.Fatalf("expecting balance %d, got %d", 27500, b)
+		}
+		if b := balance(t, house); b != 2500 {
+			t.Fatalf("expecting house balance %d, got %d", 2500, b)
+		}
+
+		wal, _ := service.GetWalletByXID(ctx, xid)
+		transactions, err := service.GetWalletTransactions(ctx, wallet.GetWalletTransactionsParam{
+			WalletID: wal.ID,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var linked bool
+		for _, trx := range transactions.Transactions {
+			if trx.Type == wallet.TransactionTypeFee && trx.RelatedID == result.ID {
+				linked = true
+			}
+		}
+		if !linked {
+			t.Fatal("expecting fee transaction linked to the withdrawal")
+		}
+	})
+
+	t.Run("withdraw whole balance without room for fee, should fail", func(t *testing.T) {
+		_, err := service.WithdrawWallet(ctx, wallet.WalletTransactionParam{
+			ActorXID:    xid,
+			OwnerXID:    xid,
+			ReferenceID: uuid.NewString(),
+			Amount:      27500,
+		})
+		if err != wallet.ErrInsufficientBalance {
+			t.Fatalf("expecting error %s, got %s", wallet.ErrInsufficientBalance, err)
+		}
+	})
+
+	t.Run("failed pending withdrawal, should refund fee", func(t *testing.T) {
+		result, err := service.WithdrawWallet(ctx, wallet.WalletTransactionParam{
+			ActorXID:    xid,
+			OwnerXID:    xid,
+			ReferenceID: uuid.NewString(),
+			Amount:      10000,
+			Pending:     true,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		wal, _ := service.GetWalletByXID(ctx, xid)
+		if wal.AvailableBalance() != 15000 {
+			t.Fatalf("expecting available balance %d, got %d", 15000, wal.AvailableBalance())
+		}
+
+		_, err = service.FailTransaction(ctx, wallet.TransitionTransactionParam{
+			TransactionID: result.ID,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		wal, _ = service.GetWalletByXID(ctx, xid)
+		if wal.AvailableBalance() != 27500 {
+			t.Fatalf("expecting available balance %d, got %d", 27500, wal.AvailableBalance())
+		}
+		if b := balance(t, house); b != 2500 {
+			t.Fatalf("expecting house balance %d, got %d", 2500, b)
+		}
+	})
+}
+
+func TestTransactionListener(t *testing.T) {
+	ctx := context.Background()
+	service := wallet.NewService(wallet.NewInMemoryRepository())
+
+	var completed []wallet.TransactionType
+	service.OnTransactionCompleted(func(ctx context.Context, ownerXID string, trx wallet.WalletTransaction) {
+		completed = append(completed, trx.Type)
+		// listeners may call back into the service
+		_, err := service.GetWalletByXID(ctx, ownerXID)
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	from, to := uuid.NewString(), uuid.NewString()
+	for _, xid := range []string{from, to} {
+		_, err := service.EnableWallet(ctx, wallet.EnableWalletParam{OwnerXID: xid})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err := service.DepositWallet(ctx, wallet.WalletTransactionParam{
+		ActorXID:    from,
+		OwnerXID:    from,
+		ReferenceID: uuid.NewString(),
+		Amount:      10000,
+		Pending:     true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(completed) != 0 {
+		t.Fatalf("expecting pending deposit not reported, got %v", completed)
+	}
+
+	deposit, err := service.DepositWallet(ctx, wallet.WalletTransactionParam{
+		ActorXID:    from,
+		OwnerXID:    from,
+		ReferenceID: uuid.NewString(),
+		Amount:      10000,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = service.TransferWallet(ctx, wallet.TransferWalletParam{
+		ActorXID:    from,
+		FromXID:     from,
+		ToXID:       to,
+		ReferenceID: uuid.NewString(),
+		Amount:      4000,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a replayed deposit is not reported again
+	_, err = service.DepositWallet(ctx, wallet.WalletTransactionParam{
+		ActorXID:    from,
+		OwnerXID:    from,
+		ReferenceID: deposit.ReferenceID,
+		Amount:      10000,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []wallet.TransactionType{wallet.TransactionTypeDeposit, wallet.TransactionTypeTransferOut, wallet.TransactionTypeTransferIn}
+	if len(completed) != len(expected) {
+		t.Fatalf("expecting %v reported, got %v", expected, completed)
+	}
+	for i := range expected {
+		if completed[i] != expected[i] {
+			t.Fatalf("expecting %v reported, got %v", expected, completed)
+		}
+	}
+}
+
+func TestTransactionChanged(t *testing.T) {
+	ctx := context.Background()
+	service := wallet.NewService(wallet.NewInMemoryRepository())
+
+	type seen struct {
+		t      wallet.TransactionType
+		status wallet.TransactionStatus
+		held   int
+	}
+	var changed []seen
+	var completed int
+	service.OnTransactionChanged(func(ctx context.Context, ownerXID string, trx wallet.WalletTransaction) {
+		wal, err := service.GetWalletByXID(ctx, ownerXID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		changed = append(changed, seen{trx.Type, trx.Status, wal.Held})
+	})
+	service.OnTransactionCompleted(func(ctx context.Context, ownerXID string, trx wallet.WalletTransaction) {
+		completed++
+	})
+
+	xid := uuid.NewString()
+	_, err := service.EnableWallet(ctx, wallet.EnableWalletParam{OwnerXID: xid})
+	if err != nil {
+		t.Fatal(err)
+	}
+	deposit, err := service.DepositWallet(ctx, wallet.WalletTransactionParam{
+		ActorXID:    xid,
+		OwnerXID:    xid,
+		ReferenceID: uuid.NewString(),
+		Amount:      10000,
+		Pending:     true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = service.SettleTransaction(ctx, wallet.TransitionTransactionParam{TransactionID: deposit.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	withdrawal, err := service.WithdrawWallet(ctx, wallet.WalletTransactionParam{
+		ActorXID:    xid,
+		OwnerXID:    xid,
+		ReferenceID: uuid.NewString(),
+		Amount:      4000,
+		Pending:     true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = service.FailTransaction(ctx, wallet.TransitionTransactionParam{TransactionID: withdrawal.ID, Reason: "bank rejected"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []seen{
+		{wallet.TransactionTypeDeposit, wallet.TransactionStatusPending, 0},
+		{wallet.TransactionTypeDeposit, wallet.TransactionStatusSuccess, 0},
+		{wallet.TransactionTypeWithdrawal, wallet.TransactionStatusPending, 4000},
+		{wallet.TransactionTypeWithdrawal, wallet.TransactionStatusFailed, 0},
+	}
+	if len(changed) != len(expected) {
+		t.Fatalf("expecting %v reported, got %v", expected, changed)
+	}
+	for i := range expected {
+		if changed[i] != expected[i] {
+			t.Fatalf("expecting %v reported, got %v", expected, changed)
+		}
+	}
+	if completed != 1 {
+		t.Fatalf("expecting 1 completion reported, got %d", completed)
+	}
+}
+
+type riskFunc func(check wallet.RiskCheck) wallet.RiskDecision
+
+func (f riskFunc) Assess(ctx context.Context, check wallet.RiskCheck) wallet.RiskAssessment {
+	return wallet.RiskAssessment{Decision: f(check), Reasons: []string{"flagged"}}
+}
+
+func TestRiskReview(t *testing.T) {
+	ctx := context.Background()
+	// withdrawals of 20000 and more are reviewed, 40000 and more denied
+	assessor := riskFunc(func(check wallet.RiskCheck) wallet.RiskDecision {
+		switch {
+		case check.Type == wallet.TransactionTypeWithdrawal && check.Amount >= 40000:
+			return wallet.RiskDecisionDeny
+		case check.Type == wallet.TransactionTypeWithdrawal && check.Amount >= 20000:
+			return wallet.RiskDecisionReview
+		}
+		return wallet.RiskDecisionAllow
+	})
+	service := wallet.NewService(wallet.NewInMemoryRepository(), wallet.WithRiskAssessor(assessor))
+
+	xid := uuid.NewString()
+	_, err := service.EnableWallet(ctx, wallet.EnableWalletParam{
+		OwnerXID: xid,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = service.DepositWallet(ctx, wallet.WalletTransactionParam{
+		ActorXID:    xid,
+		OwnerXID:    xid,
+		ReferenceID: uuid.NewString(),
+		Amount:      50000,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	withdraw := func(t *testing.T, amount int) (*wallet.WalletTransactionResult, error) {
+		return service.WithdrawWallet(ctx, wallet.WalletTransactionParam{
+			ActorXID:    xid,
+			OwnerXID:    xid,
+			ReferenceID: uuid.NewString(),
+			Amount:      amount,
+		})
+	}
+
+	t.Run("denied withdrawal, should fail", func(t *testing.T) {
+		_, err := withdraw(t, 40000)
+		if err != wallet.ErrTransactionDenied {
+			t.Fatalf("expecting error %s, got %s", wallet.ErrTransactionDenied, err)
+		}
+	})
+
+	t.Run("reviewed withdrawal, should hold amount until approved", func(t *testing.T) {
+		result, err := withdraw(t, 20000)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Status != wallet.TransactionStatusReview {
+			t.Fatalf("expecting status %s, got %s", wallet.TransactionStatusReview, result.Status)
+		}
+		wal, _ := service.GetWalletByXID(ctx, xid)
+		if wal.Balance != 50000 || wal.AvailableBalance() != 30000 {
+			t.Fatalf("expecting balance 50000 with 30000 available, got %d with %d", wal.Balance, wal.AvailableBalance())
+		}
+
+		queue, err := service.GetReviewQueue(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(queue) != 1 || queue[0].ID != result.ID || queue[0].Reason != "flagged" {
+			t.Fatalf("expecting withdrawal in review queue, got %v", queue)
+		}
+
+		// payout callbacks only act on pending transactions
+		_, err = service.SettleTransaction(ctx, wallet.TransitionTransactionParam{
+			TransactionID: result.ID,
+		})
+		if err != wallet.ErrInvalidTransition {
+			t.Fatalf("expecting error %s, got %s", wallet.ErrInvalidTransition, err)
+		}
+
+		trx, err := service.ApproveTransaction(ctx, wallet.TransitionTransactionParam{
+			TransactionID: result.ID,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if trx.Status != wallet.TransactionStatusSuccess {
+			t.Fatalf("expecting status %s, got %s", wallet.TransactionStatusSuccess, trx.Status)
+		}
+		wal, _ = service.GetWalletByXID(ctx, xid)
+		if wal.Balance != 30000 || wal.Held != 0 {
+			t.Fatalf("expecting balance 30000 with nothing held, got %d with %d", wal.Balance, wal.Held)
+		}
+
+		queue, _ = service.GetReviewQueue(ctx)
+		if len(queue) != 0 {
+			t.Fatalf("expecting empty review queue, got %v", queue)
+		}
+	})
+
+	t.Run("rejected withdrawal, should release hold", func(t *testing.T) {
+		result, err := withdraw(t, 25000)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = service.RejectTransaction(ctx, wallet.TransitionTransactionParam{
+			TransactionID: result.ID,
+			Reason:        "confirmed fraud",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		wal, _ := service.GetWalletByXID(ctx, xid)
+		if wal.Balance != 30000 || wal.Held != 0 {
+			t.Fatalf("expecting balance 30000 with nothing held, got %d with %d", wal.Balance, wal.Held)
+		}
+
+		t.Run("approve rejected withdrawal, should fail", func(t *testing.T) {
+			_, err := service.ApproveTransaction(ctx, wallet.TransitionTransactionParam{
+				TransactionID: result.ID,
+			})
+			if err != wallet.ErrInvalidTransition {
+				t.Fatalf("expecting error %s, got %s", wallet.ErrInvalidTransition, err)
+			}
+		})
+	})
+}
+
+func TestRiskTransfer(t *testing.T) {
+	ctx := context.Background()
+	blocked := uuid.NewString()
+	// transfers involving blocked are denied, those of 20000 and more reviewed
+	assessor := riskFunc(func(check wallet.RiskCheck) wallet.RiskDecision {
+		switch {
+		case check.Type != wallet.TransactionTypeTransferOut && check.Type != wallet.TransactionTypeTransferIn:
+			return wallet.RiskDecisionAllow
+		case check.OwnerXID == blocked || check.ActorXID == blocked:
+			return wallet.RiskDecisionDeny
+		case check.Amount >= 20000:
+			return wallet.RiskDecisionReview
+		}
+		return wallet.RiskDecisionAllow
+	})
+	service := wallet.NewService(wallet.NewInMemoryRepository(), wallet.WithRiskAssessor(assessor))
+
+	customer := uuid.NewString()
+	for _, xid := range []string{customer, blocked} {
+		_, err := service.EnableWallet(ctx, wallet.EnableWalletParam{
+			OwnerXID: xid,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = service.DepositWallet(ctx, wallet.WalletTransactionParam{
+			ActorXID:    xid,
+			OwnerXID:    xid,
+			ReferenceID: uuid.NewString(),
+			Amount:      50000,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	transfer := func(from string, to string, amount int) error {
+		_, err := service.TransferWallet(ctx, wallet.TransferWalletParam{
+			ActorXID:    from,
+			FromXID:     from,
+			ToXID:       to,
+			ReferenceID: uuid.NewString(),
+			Amount:      amount,
+		})
+		return err
+	}
+
+	cases := []struct {
+		name   string
+		from   string
+		to     string
+		amount int
+	}{
+		{"transfer from blocklisted customer", blocked, customer, 10000},
+		{"transfer to blocklisted customer", customer, blocked, 10000},
+		{"reviewed transfer", customer, blocked, 20000},
+	}
+	for _, c := range cases {
+		t.Run(c.name+", should fail", func(t *testing.T) {
+			err := transfer(c.from, c.to, c.amount)
+			if err != wallet.ErrTransactionDenied {
+				t.Fatalf("expecting error %s, got %s", wallet.ErrTransactionDenied, err)
+			}
+			for _, xid := range []string{customer, blocked} {
+				wal, _ := service.GetWalletByXID(ctx, xid)
+				if wal.Balance != 50000 {
+					t.Fatalf("expecting balance 50000, got %d", wal.Balance)
+				}
+			}
+		})
+	}
+
+	t.Run("allowed transfer, should succeed", func(t *testing.T) {
+		other := uuid.NewString()
+		_, err := service.EnableWallet(ctx, wallet.EnableWalletParam{
+			OwnerXID: other,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = transfer(customer, other, 10000)
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+type limitsFunc func(ownerXID string) *wallet.Limits
+
+func (f limitsFunc) Limits(ctx context.Context, ownerXID string) (*wallet.Limits, error) {
+	return f(ownerXID), nil
+}
+
+func TestLimits(t *testing.T) {
+	ctx := context.Background()
+	unverified, verified := uuid.NewString(), uuid.NewString()
+	limits := limitsFunc(func(ownerXID string) *wallet.Limits {
+		if ownerXID == unverified {
+			return &wallet.Limits{MaxBalance: 100000, BlockWithdrawals: true}
+		}
+		return &wallet.Limits{MaxTransaction: 80000}
+	})
+	service := wallet.NewService(wallet.NewInMemoryRepository(), wallet.WithLimits(limits))
+
+	for _, xid := range []string{unverified, verified} {
+		_, err := service.EnableWallet(ctx, wallet.EnableWalletParam{OwnerXID: xid})
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = service.DepositWallet(ctx, wallet.WalletTransactionParam{
+			ActorXID:    xid,
+			OwnerXID:    xid,
+			ReferenceID: uuid.NewString(),
+			Amount:      50000,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cases := []struct {
+		name string
+		err  error
+		do   func() error
+	}{
+		{"deposit past balance limit", wallet.ErrBalanceLimitExceeded, func() error {
+			_, err := service.DepositWallet(ctx, wallet.WalletTransactionParam{ActorXID: unverified, OwnerXID: unverified, ReferenceID: uuid.NewString(), Amount: 60000})
+			return err
+		}},
+		{"deposit past balance limit with money in a pocket", wallet.ErrBalanceLimitExceeded, func() error {
+			pocket := wallet.WalletTransactionParam{ActorXID: unverified, OwnerXID: unverified, ReferenceID: uuid.NewString(), Amount: 40000, Type: wallet.TransactionTypeToPocket, PocketID: "savings"}
+			_, err := service.WithdrawWallet(ctx, pocket)
+			if err != nil {
+				return err
+			}
+			_, depositErr := service.DepositWallet(ctx, wallet.WalletTransactionParam{ActorXID: unverified, OwnerXID: unverified, ReferenceID: uuid.NewString(), Amount: 60000})
+			pocket.ReferenceID, pocket.Type = uuid.NewString(), wallet.TransactionTypeFromPocket
+			_, err = service.DepositWallet(ctx, pocket)
+			if err != nil {
+				return err
+			}
+			return depositErr
+		}},
+		{"withdraw unverified", wallet.ErrWithdrawalNotAllowed, func() error {
+			_, err := service.WithdrawWallet(ctx, wallet.WalletTransactionParam{ActorXID: unverified, OwnerXID: unverified, ReferenceID: uuid.NewString(), Amount: 10000})
+			return err
+		}},
+		{"transfer past balance limit of recipient", wallet.ErrBalanceLimitExceeded, func() error {
+			_, err := service.DepositWallet(ctx, wallet.WalletTransactionParam{ActorXID: verified, OwnerXID: verified, ReferenceID: uuid.NewString(), Amount: 50000})
+			if err != nil {
+				return err
+			}
+			_, err = service.TransferWallet(ctx, wallet.TransferWalletParam{ActorXID: verified, FromXID: verified, ToXID: unverified, ReferenceID: uuid.NewString(), Amount: 60000})
+			return err
+		}},
+		{"withdraw past transaction limit", wallet.ErrTransactionLimitExceeded, func() error {
+			_, err := service.WithdrawWallet(ctx, wallet.WalletTransactionParam{ActorXID: verified, OwnerXID: verified, ReferenceID: uuid.NewString(), Amount: 90000})
+			return err
+		}},
+		{"withdraw verified", nil, func() error {
+			_, err := service.WithdrawWallet(ctx, wallet.WalletTransactionParam{ActorXID: verified, OwnerXID: verified, ReferenceID: uuid.NewString(), Amount: 50000})
+			return err
+		}},
+		{"transfer to unverified within limit", nil, func() error {
+			_, err := service.TransferWallet(ctx, wallet.TransferWalletParam{ActorXID: verified, FromXID: verified, ToXID: unverified, ReferenceID: uuid.NewString(), Amount: 50000})
+			return err
+		}},
+	}
+	for _, c := range cases {
+		if err := c.do(); err != c.err {
+			t.Fatalf("%s: expecting error %v, got %v", c.name, c.err, err)
+		}
+	}
+}
+
+func TestFreezeWallet(t *testing.T) {
+	ctx := context.Background()
+	service := wallet.NewService(wallet.NewInMemoryRepository())
+
+	xid := uuid.NewString()
+	_, err := service.EnableWallet(ctx, wallet.EnableWalletParam{OwnerXID: xid})
+	if err != nil {
+		t.Fatal(err)
+	}
+	deposit := func() error {
+		_, err := service.DepositWallet(ctx, wallet.WalletTransactionParam{
+			ActorXID:    xid,
+			OwnerXID:    xid,
+			ReferenceID: uuid.NewString(),
+			Amount:      10000,
+		})
+		return err
+	}
+
+	t.Run("freeze wallet, should block movements and owner", func(t *testing.T) {
+		_, err := service.FreezeWallet(ctx, wallet.FreezeWalletParam{
+			OwnerXID: xid,
+			ActorXID: "operator",
+			Reason:   wallet.FreezeReasonFraud,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := deposit(); err != wallet.ErrWalletFrozen {
+			t.Fatalf("expecting error %s, got %v", wallet.ErrWalletFrozen, err)
+		}
+		_, err = service.DisableWallet(ctx, wallet.DisableWalletParam{OwnerXID: xid})
+		if err != wallet.ErrWalletFrozen {
+			t.Fatalf("expecting error %s, got %v", wallet.ErrWalletFrozen, err)
+		}
+		_, err = service.EnableWallet(ctx, wallet.EnableWalletParam{OwnerXID: xid})
+		if err != wallet.ErrWalletFrozen {
+			t.Fatalf("expecting error %s, got %v", wallet.ErrWalletFrozen, err)
+		}
+
+		t.Run("unfreeze wallet, should restore previous status", func(t *testing.T) {
+			wal, err := service.UnfreezeWallet(ctx, wallet.UnfreezeWalletParam{OwnerXID: xid, ActorXID: "operator"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if wal.Status != wallet.WalletStatusEnabled || wal.Freeze != nil {
+				t.Fatalf("expecting enabled wallet, got %s", wal.Status)
+			}
+			if err := deposit(); err != nil {
+				t.Fatal(err)
+			}
+		})
+	})
+
+	t.Run("freeze with expiry, should lift once expired", func(t *testing.T) {
+		_, err := service.FreezeWallet(ctx, wallet.FreezeWalletParam{
+			OwnerXID:  xid,
+			ActorXID:  "operator",
+			Reason:    wallet.FreezeReasonDispute,
+			ExpiresAt: time.Now().Add(20 * time.Millisecond),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(30 * time.Millisecond)
+		if err := deposit(); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("freeze with unknown reason, should fail", func(t *testing.T) {
+		_, err := service.FreezeWallet(ctx, wallet.FreezeWalletParam{
+			OwnerXID: xid,
+			ActorXID: "operator",
+			Reason:   "bored",
+		})
+		if _, ok := err.(wallet.ValidationError); !ok {
+			t.Fatalf("expecting validation error, got %v", err)
+		}
+	})
+
+	t.Run("close wallet with balance, should fail", func(t *testing.T) {
+		_, err := service.CloseWallet(ctx, wallet.CloseWalletParam{OwnerXID: xid, ActorXID: "operator"})
+		if err != wallet.ErrWalletNotEmpty {
+			t.Fatalf("expecting error %s, got %v", wallet.ErrWalletNotEmpty, err)
+		}
+	})
+
+	t.Run("close wallet with money in a pocket, should fail", func(t *testing.T) {
+		move := func(t wallet.TransactionType, move func(context.Context, wallet.WalletTransactionParam) (*wallet.WalletTransactionResult, error)) error {
+			_, err := move(ctx, wallet.WalletTransactionParam{
+				ActorXID:    xid,
+				OwnerXID:    xid,
+				ReferenceID: uuid.NewString(),
+				Amount:      20000,
+				Type:        t,
+				PocketID:    "savings",
+			})
+			return err
+		}
+		if err := move(wallet.TransactionTypeToPocket, service.WithdrawWallet); err != nil {
+			t.Fatal(err)
+		}
+		_, err := service.CloseWallet(ctx, wallet.CloseWalletParam{OwnerXID: xid, ActorXID: "operator"})
+		if err != wallet.ErrWalletNotEmpty {
+			t.Fatalf("expecting error %s, got %v", wallet.ErrWalletNotEmpty, err)
+		}
+		if err := move(wallet.TransactionTypeFromPocket, service.DepositWallet); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("close empty wallet, should be terminal", func(t *testing.T) {
+		_, err := service.WithdrawWallet(ctx, wallet.WalletTransactionParam{
+			ActorXID:    xid,
+			OwnerXID:    xid,
+			ReferenceID: uuid.NewString(),
+			Amount:      20000,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = service.CloseWallet(ctx, wallet.CloseWalletParam{OwnerXID: xid, ActorXID: "operator"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = service.EnableWallet(ctx, wallet.EnableWalletParam{OwnerXID: xid})
+		if err != wallet.ErrWalletClosed {
+			t.Fatalf("expecting error %s, got %v", wallet.ErrWalletClosed, err)
+		}
+		_, err = service.FreezeWallet(ctx, wallet.FreezeWalletParam{OwnerXID: xid, ActorXID: "operator", Reason: wallet.FreezeReasonLegal})
+		if err != wallet.ErrInvalidWalletTransition {
+			t.Fatalf("expecting error %s, got %v", wallet.ErrInvalidWalletTransition, err)
+		}
+	})
+}
+
+// stubProvider accepts payouts and leaves their outcome to the test, never
+// calling back.
+type stubProvider struct {
+	payouts map[string]*disbursement.Payout
+}
+
+func (p *stubProvider) Submit(ctx context.Context, req disbursement.PayoutRequest) (*disbursement.Payout, error) {
+	payout := &disbursement.Payout{
+		ID:          uuid.NewString(),
+		ReferenceID: req.ReferenceID,
+		Amount:      req.Amount,
+		Status:      disbursement.PayoutStatusPending,
+	}
+	p.payouts[payout.ID] = payout
+	return payout, nil
+}
+
+func (p *stubProvider) GetPayout(ctx context.Context, id string) (*disbursement.Payout, error) {
+	payout, ok := p.payouts[id]
+	if !ok {
+		return nil, disbursement.ErrPayoutNotFound
+	}
+	return payout, nil
+}
+
+func (p *stubProvider) OnCallback(fn disbursement.CallbackFunc) {}
+
+func TestReconcilePayouts(t *testing.T) {
+	ctx := context.Background()
+	provider := &stubProvider{payouts: map[string]*disbursement.Payout{}}
+	service := wallet.NewService(wallet.NewInMemoryRepository(), wallet.WithDisbursementProvider(provider))
+
+	xid := uuid.NewString()
+	_, err := service.EnableWallet(ctx, wallet.EnableWalletParam{OwnerXID: xid})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = service.DepositWallet(ctx, wallet.WalletTransactionParam{
+		ActorXID:    xid,
+		OwnerXID:    xid,
+		ReferenceID: uuid.NewString(),
+		Amount:      100000,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	withdraw := func(t *testing.T) *wallet.WalletTransaction {
+		result, err := service.WithdrawWallet(ctx, wallet.WalletTransactionParam{
+			ActorXID:    xid,
+			OwnerXID:    xid,
+			ReferenceID: uuid.NewString(),
+			Amount:      10000,
+			Destination: &disbursement.BankAccount{BankCode: "BCA", AccountNumber: "1234567890"},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		trx, err := service.GetTransaction(ctx, result.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return trx
+	}
+	reconcile := func(t *testing.T, before time.Time) {
+		_, err := service.ReconcilePayouts(ctx, wallet.ReconcilePayoutsParam{Before: before})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	status := func(t *testing.T, id string) wallet.TransactionStatus {
+		trx, err := service.GetTransaction(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return trx.Status
+	}
+
+	succeeded := withdraw(t)
+	provider.payouts[succeeded.ExternalID].Status = disbursement.PayoutStatusSuccess
+	lost := withdraw(t)
+	delete(provider.payouts, lost.ExternalID)
+	inFlight := withdraw(t)
+
+	t.Run("reconcile within grace, should leave payouts alone", func(t *testing.T) {
+		reconcile(t, succeeded.Date)
+		if s := status(t, succeeded.ID); s != wallet.TransactionStatusPending {
+			t.Fatalf("expecting status %s, got %s", wallet.TransactionStatusPending, s)
+		}
+	})
+
+	t.Run("reconcile, should complete payouts the provider finished or lost", func(t *testing.T) {
+		reconcile(t, time.Now().Add(time.Second))
+		if s := status(t, succeeded.ID); s != wallet.TransactionStatusSuccess {
+			t.Fatalf("expecting status %s, got %s", wallet.TransactionStatusSuccess, s)
+		}
+		if s := status(t, lost.ID); s != wallet.TransactionStatusFailed {
+			t.Fatalf("expecting status %s, got %s", wallet.TransactionStatusFailed, s)
+		}
+		if s := status(t, inFlight.ID); s != wallet.TransactionStatusPending {
+			t.Fatalf("expecting status %s, got %s", wallet.TransactionStatusPending, s)
+		}
+
+		wal, err := service.GetWalletByXID(ctx, xid)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if wal.Balance != 90000 || wal.Held != 10000 {
+			t.Fatalf("expecting balance 90000 holding 10000, got %d holding %d", wal.Balance, wal.Held)
+		}
+	})
+}
+
+func TestApproveClosurePayout(t *testing.T) {
+	ctx := context.Background()
+	provider := &stubProvider{payouts: map[string]*disbursement.Payout{}}
+	assessor := riskFunc(func(check wallet.RiskCheck) wallet.RiskDecision {
+		if check.Type.IsPayout() {
+			return wallet.RiskDecisionReview
+		}
+		return wallet.RiskDecisionAllow
+	})
+	service := wallet.NewService(wallet.NewInMemoryRepository(), wallet.WithDisbursementProvider(provider), wallet.WithRiskAssessor(assessor))
+
+	xid := uuid.NewString()
+	_, err := service.EnableWallet(ctx, wallet.EnableWalletParam{OwnerXID: xid})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = service.DepositWallet(ctx, wallet.WalletTransactionParam{
+		ActorXID:    xid,
+		OwnerXID:    xid,
+		ReferenceID: uuid.NewString(),
+		Amount:      30000,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := service.WithdrawWallet(ctx, wallet.WalletTransactionParam{
+		ActorXID:    xid,
+		OwnerXID:    xid,
+		ReferenceID: uuid.NewString(),
+		Amount:      30000,
+		Type:        wallet.TransactionTypeClosurePayout,
+		Destination: &disbursement.BankAccount{BankCode: "BCA", AccountNumber: "1234567890"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Status != wallet.TransactionStatusReview {
+		t.Fatalf("expecting status %s, got %s", wallet.TransactionStatusReview, result.Status)
+	}
+
+	trx, err := service.ApproveTransaction(ctx, wallet.TransitionTransactionParam{TransactionID: result.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if trx.Status != wallet.TransactionStatusPending || provider.payouts[trx.ExternalID] == nil {
+		t.Fatalf("expecting pending transaction sent to the provider, got %s %q", trx.Status, trx.ExternalID)
+	}
+	wal, err := service.GetWalletByXID(ctx, xid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wal.Balance != 30000 || wal.Held != 30000 {
+		t.Fatalf("expecting balance 30000 holding 30000, got %d holding %d", wal.Balance, wal.Held)
+	}
+}
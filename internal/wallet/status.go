@@ -0,0 +1,186 @@
+package wallet
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type FreezeWalletParam struct {
+	OwnerXID string
+	ActorXID string
+	Reason   FreezeReason
+	// ExpiresAt lifts the freeze on its own, zero keeps the wallet frozen
+	// until an operator lifts it.
+	ExpiresAt time.Time
+}
+
+func (p FreezeWalletParam) Validate() error {
+	ve := NewValidationError()
+	if p.OwnerXID == "" {
+		ve.AddError("owner_xid", ErrMissingRequiredParameter)
+	}
+	if p.ActorXID == "" {
+		ve.AddError("actor", ErrMissingRequiredParameter)
+	}
+	if !p.Reason.IsValid() {
+		ve.AddError("reason", ErrInvalidFreezeReason)
+	}
+	if !p.ExpiresAt.IsZero() && !p.ExpiresAt.After(time.Now()) {
+		ve.AddError("expires_at", ErrInvalidFreezeExpiry)
+	}
+	if len(ve.GetErrors()) > 0 {
+		return ve
+	}
+	return nil
+}
+
+type UnfreezeWalletParam struct {
+	OwnerXID string
+	ActorXID string
+}
+
+type CloseWalletParam struct {
+	OwnerXID string
+	ActorXID string
+}
+
+// FreezeWallet stops all deposits and withdrawals of the wallet until the
+// freeze is lifted or expires. Freezing a frozen wallet replaces its freeze.
+func (s *service) FreezeWallet(ctx context.Context, param FreezeWalletParam) (*Wallet, error) {
+	err := param.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	wal, err := s.getWallet(ctx, param.OwnerXID)
+	if err != nil {
+		return nil, err
+	}
+
+	previous := wal.Status
+	if wal.Status == WalletStatusFrozen {
+		previous = wal.Freeze.PreviousStatus
+	} else if !wal.Status.CanTransitionTo(WalletStatusFrozen) {
+		return nil, ErrInvalidWalletTransition
+	}
+	wal.Status = WalletStatusFrozen
+	wal.Freeze = &Freeze{
+		Reason:         param.Reason,
+		ActorXID:       param.ActorXID,
+		FrozenAt:       time.Now(),
+		ExpiresAt:      param.ExpiresAt,
+		PreviousStatus: previous,
+	}
+
+	err = s.repo.UpdateWallet(ctx, *wal)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed updating wallet")
+	}
+	return wal, nil
+}
+
+// UnfreezeWallet lifts the freeze, the wallet going back to the status it
+// had before.
+func (s *service) UnfreezeWallet(ctx context.Context, param UnfreezeWalletParam) (*Wallet, error) {
+	if param.OwnerXID == "" || param.ActorXID == "" {
+		return nil, ErrMissingRequiredParameter
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	wal, err := s.getWallet(ctx, param.OwnerXID)
+	if err != nil {
+		return nil, err
+	}
+	if wal.Status != WalletStatusFrozen {
+		return nil, ErrInvalidWalletTransition
+	}
+	thaw(wal)
+
+	err = s.repo.UpdateWallet(ctx, *wal)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed updating wallet")
+	}
+	return wal, nil
+}
+
+// CloseWallet closes the wallet for good. Only an empty wallet with nothing
+// held, nothing set aside in pockets and no credit drawn can be closed.
+func (s *service) CloseWallet(ctx context.Context, param CloseWalletParam) (*Wallet, error) {
+	if param.OwnerXID == "" || param.ActorXID == "" {
+		return nil, ErrMissingRequiredParameter
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	wal, err := s.getWallet(ctx, param.OwnerXID)
+	if err != nil {
+		return nil, err
+	}
+	if !wal.Status.CanTransitionTo(WalletStatusClosed) {
+		if wal.Status == WalletStatusClosed {
+			return nil, ErrWalletClosed
+		}
+		return nil, ErrInvalidWalletTransition
+	}
+	if wal.Balance != 0 || wal.Held != 0 {
+		return nil, ErrWalletNotEmpty
+	}
+	transactions, err := s.repo.GetTransactions(ctx, wal.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed getting wallet transactions")
+	}
+	if PocketBalance(transactions) != 0 {
+		return nil, ErrWalletNotEmpty
+	}
+
+	wal.Status = WalletStatusClosed
+	wal.CreditLimit = 0
+	wal.ClosedAt = time.Now()
+	err = s.repo.UpdateWallet(ctx, *wal)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed updating wallet")
+	}
+	return wal, nil
+}
+
+// getWallet returns the wallet of xid, lifting a freeze that expired.
+func (s *service) getWallet(ctx context.Context, xid string) (*Wallet, error) {
+	wal, err := s.repo.GetWalletByXID(ctx, xid)
+	if err != nil && err == ErrWalletNotFound {
+		return nil, ErrWalletNotFound
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed getting wallet")
+	}
+
+	if wal.Status == WalletStatusFrozen && wal.Freeze.IsExpired(time.Now()) {
+		thaw(wal)
+	}
+	return wal, nil
+}
+
+func thaw(wal *Wallet) {
+	wal.Status = wal.Freeze.PreviousStatus
+	wal.Freeze = nil
+}
+
+// StatusError is the error a movement on a wallet in status st fails with,
+// nil for an enabled wallet.
+func StatusError(st WalletStatus) error {
+	switch st {
+	case WalletStatusDisabled:
+		return ErrWalletDisabled
+	case WalletStatusFrozen:
+		return ErrWalletFrozen
+	case WalletStatusClosed:
+		return ErrWalletClosed
+	}
+	return nil
+}